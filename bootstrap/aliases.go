@@ -10,7 +10,10 @@
  */
 package bootstrap
 
-import "github.com/kamalyes/go-stress/types"
+import (
+	"github.com/kamalyes/go-stress/executor"
+	"github.com/kamalyes/go-stress/types"
+)
 
 // 运行模式别名
 type (
@@ -47,4 +50,14 @@
 	ProtocolHTTP      = types.ProtocolHTTP
 	ProtocolGRPC      = types.ProtocolGRPC
 	ProtocolWebSocket = types.ProtocolWebSocket
+	ProtocolSSE       = types.ProtocolSSE
+	ProtocolTCP       = types.ProtocolTCP
+	ProtocolUDP       = types.ProtocolUDP
+	ProtocolMQTT      = types.ProtocolMQTT
 )
+
+// SLO 阈值未达标
+var ErrThresholdsBreached = executor.ErrThresholdsBreached
+
+// SLO 阈值未达标时的进程退出码
+const ExitCodeThresholdsBreached = executor.ExitCodeThresholdsBreached
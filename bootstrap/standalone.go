@@ -22,14 +22,19 @@
 type StandaloneOptions struct {
 	ConfigFile   string
 	CurlFile     string
+	HARFile      string
+	PostmanFile  string
+	ImportFilter *config.ImportFilter // HAR/Postman 导入过滤器
 	Concurrency  uint64
 	Requests     uint64
+	Duration     time.Duration
 	Timeout      time.Duration
 	StorageMode  StorageMode
 	ReportPrefix string
 	MaxMemory    string
 	Logger       logger.ILogger
 	ConfigFunc   func() *config.Config
+	NoWait       bool // 压测结束后直接退出（不保留实时报告服务器，适用于 CI）
 }
 
 // RunStandalone 运行独立模式
@@ -37,14 +42,19 @@ func RunStandalone(opts StandaloneOptions) error {
 	result := executor.RunTask(executor.RunOptions{
 		ConfigFile:    opts.ConfigFile,
 		CurlFile:      opts.CurlFile,
+		HARFile:       opts.HARFile,
+		PostmanFile:   opts.PostmanFile,
+		ImportFilter:  opts.ImportFilter,
 		Concurrency:   opts.Concurrency,
 		Requests:      opts.Requests,
+		Duration:      opts.Duration,
 		Timeout:       opts.Timeout,
 		StorageMode:   executor.StorageMode(opts.StorageMode),
 		ReportPrefix:  opts.ReportPrefix,
 		MaxMemory:     opts.MaxMemory,
 		Logger:        opts.Logger,
 		ConfigFunc:    opts.ConfigFunc,
+		NoWait:        opts.NoWait,
 		IsDistributed: false,
 	})
 
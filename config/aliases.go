@@ -29,6 +29,10 @@
 	ProtocolHTTP      = types.ProtocolHTTP
 	ProtocolGRPC      = types.ProtocolGRPC
 	ProtocolWebSocket = types.ProtocolWebSocket
+	ProtocolSSE       = types.ProtocolSSE
+	ProtocolTCP       = types.ProtocolTCP
+	ProtocolUDP       = types.ProtocolUDP
+	ProtocolMQTT      = types.ProtocolMQTT
 
 	// 验证类型
 	VerifyTypeStatusCode = types.VerifyTypeStatusCode
@@ -42,3 +46,8 @@
 	ExtractorTypeRegex    = types.ExtractorTypeRegex
 	ExtractorTypeHeader   = types.ExtractorTypeHeader
 )
+
+// 函数别名
+var (
+	APIStatsKey = types.APIStatsKey
+)
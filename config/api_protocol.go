@@ -0,0 +1,80 @@
+/*
+ * @Author: kamalyes 501893067@qq.com
+ * @Date: 2026-02-23 00:00:00
+ * @LastEditors: kamalyes 501893067@qq.com
+ * @LastEditTime: 2026-02-23 00:00:00
+ * @FilePath: \go-stress\config\api_protocol.go
+ * @Description: API 级协议配置 - 混合协议场景下按API生成客户端配置
+ *
+ * Copyright (c) 2026 by kamalyes, All Rights Reserved.
+ */
+package config
+
+// SupportedProtocols 支持的协议
+var SupportedProtocols = []ProtocolType{
+	ProtocolHTTP,
+	ProtocolGRPC,
+	ProtocolWebSocket,
+	ProtocolSSE,
+	ProtocolTCP,
+	ProtocolUDP,
+	ProtocolMQTT,
+}
+
+// hasProtocolSettings 是否配置了协议特定设置
+func (a *APIConfig) hasProtocolSettings() bool {
+	return a.GRPC != nil || a.WebSocket != nil || a.SSE != nil || a.Socket != nil || a.MQTT != nil
+}
+
+// BindsAddress 客户端的连接是否绑定地址（WebSocket 和 gRPC 连接时确定地址，其他协议按请求地址发送）
+func (a *APIConfig) BindsAddress() bool {
+	return a.Protocol == ProtocolWebSocket || a.Protocol == ProtocolGRPC
+}
+
+// ClientKey 客户端复用键（未单独配置协议时为空，使用全局协议的客户端）
+// HTTP/SSE/TCP/UDP/MQTT 按请求地址发送，同一协议共用一个客户端；WebSocket 和 gRPC 的连接绑定地址，
+// 按协议+地址区分（应对未渲染的配置调用，模板地址渲染后变化时由调用方重建连接）；配置了协议特定设置的API使用独立的客户端
+func (a *APIConfig) ClientKey() string {
+	if a.Protocol == "" {
+		return ""
+	}
+
+	key := string(a.Protocol)
+	if a.BindsAddress() {
+		key += " " + a.URL
+	}
+	if a.hasProtocolSettings() {
+		key += " @" + a.Name
+	}
+	return key
+}
+
+// ProtocolConfig 生成单独配置了协议的API使用的客户端配置：
+// 复制全局配置，协议、地址和请求头取自API，API 的协议特定设置覆盖全局设置
+func (c *Config) ProtocolConfig(api *APIConfig) *Config {
+	cfg := *c
+	cfg.Protocol = api.Protocol
+	cfg.URL = api.URL
+	cfg.Method = api.Method
+	cfg.Headers = api.Headers
+	cfg.Body = api.Body
+	cfg.APIs = nil
+	cfg.Scenarios = nil
+
+	if api.GRPC != nil {
+		cfg.GRPC = api.GRPC
+	}
+	if api.WebSocket != nil {
+		cfg.WebSocket = api.WebSocket
+	}
+	if api.SSE != nil {
+		cfg.SSE = api.SSE
+	}
+	if api.Socket != nil {
+		cfg.Socket = api.Socket
+	}
+	if api.MQTT != nil {
+		cfg.MQTT = api.MQTT
+	}
+	return &cfg
+}
@@ -0,0 +1,128 @@
+/*
+ * @Author: kamalyes 501893067@qq.com
+ * @Date: 2026-02-23 00:00:00
+ * @LastEditors: kamalyes 501893067@qq.com
+ * @LastEditTime: 2026-02-23 00:00:00
+ * @FilePath: \go-stress\config\api_protocol_test.go
+ * @Description: API 级协议配置测试
+ *
+ * Copyright (c) 2026 by kamalyes, All Rights Reserved.
+ */
+package config
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+// 测试客户端复用键：请求级协议按协议复用，连接级协议按地址区分，独立设置的API单独使用客户端
+func TestAPIConfigClientKey(t *testing.T) {
+	assert.Equal(t, "", (&APIConfig{Name: "a", URL: "http://x/a"}).ClientKey())
+	assert.Equal(t, "http", (&APIConfig{Name: "a", URL: "http://x/a", Protocol: ProtocolHTTP}).ClientKey())
+	assert.Equal(t, "mqtt", (&APIConfig{Name: "a", URL: "mqtt://x/t1", Protocol: ProtocolMQTT}).ClientKey())
+	assert.Equal(t, "websocket ws://x/chat", (&APIConfig{Name: "a", URL: "ws://x/chat", Protocol: ProtocolWebSocket}).ClientKey())
+	assert.Equal(t, "grpc x:50051 @list", (&APIConfig{
+		Name: "list", URL: "x:50051", Protocol: ProtocolGRPC,
+		GRPC: &GRPCConfig{Service: "s", Method: "List"},
+	}).ClientKey())
+	assert.Equal(t, "websocket ws://x/chat?token={{.token}}", (&APIConfig{Name: "a", URL: "ws://x/chat?token={{.token}}", Protocol: ProtocolWebSocket}).ClientKey(), "按未渲染的地址区分")
+
+	assert.True(t, (&APIConfig{Protocol: ProtocolWebSocket}).BindsAddress())
+	assert.True(t, (&APIConfig{Protocol: ProtocolGRPC}).BindsAddress())
+	assert.False(t, (&APIConfig{Protocol: ProtocolTCP}).BindsAddress(), "TCP/UDP 按请求地址发送")
+}
+
+// 测试API的客户端配置覆盖全局协议、地址和协议特定设置
+func TestProtocolConfig(t *testing.T) {
+	global := &Config{
+		Protocol:  ProtocolHTTP,
+		URL:       "http://x",
+		APIs:      []APIConfig{{Name: "a"}},
+		WebSocket: &WebSocketConfig{Mode: WebSocketModeSession},
+		MQTT:      &MQTTConfig{QoS: 1},
+	}
+	api := &APIConfig{
+		Name: "chat", URL: "ws://x/chat", Protocol: ProtocolWebSocket,
+		Headers:   map[string]string{"Authorization": "Bearer t"},
+		WebSocket: &WebSocketConfig{Mode: WebSocketModeRequest},
+	}
+
+	cfg := global.ProtocolConfig(api)
+	assert.Equal(t, ProtocolWebSocket, cfg.Protocol)
+	assert.Equal(t, "ws://x/chat", cfg.URL)
+	assert.Equal(t, "Bearer t", cfg.Headers["Authorization"])
+	assert.Equal(t, WebSocketModeRequest, cfg.WebSocket.Mode)
+	assert.Equal(t, uint8(1), cfg.MQTT.QoS, "未覆盖的设置沿用全局配置")
+	assert.Empty(t, cfg.APIs)
+	assert.Equal(t, ProtocolHTTP, global.Protocol, "不修改全局配置")
+}
+
+// 测试加载时按API的客户端配置验证协议
+func TestValidateAPIProtocols(t *testing.T) {
+	load := func(apis string) error {
+		_, err := NewLoader().LoadFromBytes([]byte("protocol: http\nconcurrency: 1\nrequests: 1\napis:\n"+apis), "yaml")
+		return err
+	}
+
+	assert.NoError(t, load(`
+  - name: login
+    url: http://localhost/login
+  - name: list
+    url: localhost:50051
+    protocol: grpc
+    grpc: {use_reflection: true, service: chat.History, method: List}
+`))
+	assert.ErrorContains(t, load(`
+  - name: list
+    url: localhost:50051
+    protocol: grpc
+`), "API [list] 未启用反射时必须指定proto文件或描述符集")
+	assert.ErrorContains(t, load(`
+  - name: pub
+    url: localhost:1883
+    protocol: amqp
+`), "不支持的协议: amqp")
+	assert.ErrorContains(t, load(`
+  - name: pub
+    url: mqtt://localhost:1883/t
+    mqtt: {qos: 1}
+`), "未指定 protocol")
+}
+
+// 测试 gRPC 接口的 metadata 与 grpc.metadata 合并（API 优先），不混入请求头
+func TestGRPCMetadataMerge(t *testing.T) {
+	cfg, err := NewLoader().LoadFromBytes([]byte(`
+protocol: grpc
+url: localhost:50051
+concurrency: 1
+requests: 1
+headers:
+  Content-Type: application/json
+grpc:
+  use_reflection: true
+  service: s
+  method: Get
+  metadata:
+    authorization: "Bearer {{.row.token}}"
+    x-tenant: t1
+apis:
+  - name: get
+    metadata:
+      x-tenant: t2
+  - name: list
+    protocol: grpc
+    grpc:
+      use_reflection: true
+      service: s
+      method: List
+      metadata:
+        x-app: list
+`), "yaml")
+	assert.NoError(t, err)
+
+	assert.Equal(t, map[string]string{"authorization": "Bearer {{.row.token}}", "x-tenant": "t2"}, cfg.APIs[0].Metadata)
+	assert.Equal(t, map[string]string{"x-app": "list"}, cfg.APIs[1].Metadata, "API 配置了 grpc 时取其 metadata")
+	assert.NotContains(t, cfg.APIs[0].Headers, "authorization")
+	assert.Equal(t, map[string]string{"authorization": "Bearer {{.row.token}}", "x-tenant": "t1"}, cfg.GRPC.Metadata)
+}
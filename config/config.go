@@ -12,6 +12,7 @@
 package config
 
 import (
+	"slices"
 	"time"
 
 	"github.com/kamalyes/go-logger"
@@ -26,6 +27,13 @@ type Config struct {
 	Duration    time.Duration `json:"duration" yaml:"duration"`       // 压测持续时间(优先级高于requests)
 	Timeout     time.Duration `json:"timeout" yaml:"timeout"`         // 单个请求超时
 
+	// 多阶段负载配置（设置后按阶段运行，忽略 requests/duration）
+	Stages []StageConfig `json:"stages,omitempty" yaml:"stages,omitempty"`
+
+	// 用户节奏配置（等待时间计为空闲，不计入请求延迟和活跃并发）
+	ThinkTime *ThinkTimeConfig `json:"think_time,omitempty" yaml:"think_time,omitempty"` // 公共思考时间（可被APIs覆盖）
+	Pacing    time.Duration    `json:"pacing,omitempty" yaml:"pacing,omitempty"`         // 每轮迭代（单个API或完整依赖链）的目标时长，不足时等待补齐
+
 	// 请求配置（作为公共配置，可被APIs覆盖）
 	Host    string            `json:"host,omitempty" yaml:"host,omitempty"` // 公共Host（如：https://api.example.com）
 	URL     string            `json:"url,omitempty" yaml:"url,omitempty"`   // 完整URL（向后兼容，优先级低于Host+Path）
@@ -36,11 +44,23 @@ type Config struct {
 	// 多API配置（如果定义了APIs，则URL等字段作为公共配置）
 	APIs []APIConfig `json:"apis,omitempty" yaml:"apis,omitempty"`
 
+	// 多场景配置（与 apis 二选一，worker 按权重分配到各场景，每个场景执行自己的API流程）
+	Scenarios []ScenarioConfig `json:"scenarios,omitempty" yaml:"scenarios,omitempty"`
+
+	// 生命周期步骤（按顺序执行，请求不计入统计）
+	Setup     []APIConfig `json:"setup,omitempty" yaml:"setup,omitempty"`           // worker 启动时执行一次（如登录），提取的变量在该 worker 的每轮迭代中可用
+	Teardown  []APIConfig `json:"teardown,omitempty" yaml:"teardown,omitempty"`     // worker 退出时执行（含压测被取消）
+	BeforeAll []APIConfig `json:"before_all,omitempty" yaml:"before_all,omitempty"` // 压测开始前执行一次，提取的变量对所有 worker 可用（分布式模式下 slave 不执行）
+	AfterAll  []APIConfig `json:"after_all,omitempty" yaml:"after_all,omitempty"`   // 压测结束后执行一次（含压测被取消，分布式模式下 slave 不执行）
+
 	// 变量配置
 	Variables map[string]any `json:"variables,omitempty" yaml:"variables,omitempty"` // 静态变量
 	// 动态变量解析器（运行时注入，不序列化）
 	VarResolver *VariableResolver `json:"-" yaml:"-"`
 
+	// 数据源配置（CSV/JSONL 参数化数据，模板中通过 {{.row.列名}} 访问）
+	DataSources []DataSourceConfig `json:"data_sources,omitempty" yaml:"data_sources,omitempty"`
+
 	// HTTP 特定配置
 	HTTP *HTTPConfig `json:"http,omitempty" yaml:"http,omitempty"`
 
@@ -50,12 +70,24 @@ type Config struct {
 	// WebSocket 特定配置
 	WebSocket *WebSocketConfig `json:"websocket,omitempty" yaml:"websocket,omitempty"`
 
+	// SSE 特定配置
+	SSE *SSEConfig `json:"sse,omitempty" yaml:"sse,omitempty"`
+
+	// TCP/UDP 特定配置
+	Socket *SocketConfig `json:"socket,omitempty" yaml:"socket,omitempty"`
+
+	// MQTT 特定配置
+	MQTT *MQTTConfig `json:"mqtt,omitempty" yaml:"mqtt,omitempty"`
+
 	// 高级配置
 	Advanced *AdvancedConfig `json:"advanced,omitempty" yaml:"advanced,omitempty"`
 
 	// 验证配置
 	Verify *VerifyConfig `json:"verify,omitempty" yaml:"verify,omitempty"`
 
+	// SLO 阈值（压测结束后评估，任一未达标时进程以非零状态退出）
+	Thresholds []ThresholdConfig `json:"thresholds,omitempty" yaml:"thresholds,omitempty"`
+
 	// 运行模式标识（用于报告展示）
 	RunMode RunMode `json:"run_mode,omitempty" yaml:"run_mode,omitempty"`
 
@@ -72,6 +104,28 @@ func (c *Config) SetLogger(log logger.ILogger) {
 	c.logger = log
 }
 
+// AllAPIs 返回所有API配置（多场景模式下为各场景API的合集）
+func (c *Config) AllAPIs() []APIConfig {
+	if len(c.Scenarios) == 0 {
+		return c.APIs
+	}
+	apis := slices.Clone(c.APIs)
+	for _, scenario := range c.Scenarios {
+		apis = append(apis, scenario.APIs...)
+	}
+	return apis
+}
+
+// ScenarioConfig 场景配置（一类用户的完整流程）
+type ScenarioConfig struct {
+	Name      string         `json:"name" yaml:"name"`                               // 场景名称（统计和报告按场景分组）
+	Weight    int            `json:"weight,omitempty" yaml:"weight,omitempty"`       // 权重（按比例分配worker，默认1）
+	APIs      []APIConfig    `json:"apis" yaml:"apis"`                               // 场景的API流程（继承公共配置）
+	Variables map[string]any `json:"variables,omitempty" yaml:"variables,omitempty"` // 场景变量（覆盖同名全局变量）
+	Setup     []APIConfig    `json:"setup,omitempty" yaml:"setup,omitempty"`         // 场景前置步骤（在全局 setup 之后执行）
+	Teardown  []APIConfig    `json:"teardown,omitempty" yaml:"teardown,omitempty"`   // 场景后置步骤（在全局 teardown 之前执行）
+}
+
 // APIConfig 单个API配置（可继承公共配置）
 type APIConfig struct {
 	Name       string            `json:"name,omitempty" yaml:"name,omitempty"`             // API名称（可选）
@@ -80,12 +134,28 @@ type APIConfig struct {
 	URL        string            `json:"url,omitempty" yaml:"url,omitempty"`               // 完整URL（可选，优先级高于Host+Path）
 	Method     string            `json:"method,omitempty" yaml:"method,omitempty"`         // 可选，继承自公共配置
 	Headers    map[string]string `json:"headers,omitempty" yaml:"headers,omitempty"`       // 可选，与公共配置合并
+	Metadata   map[string]string `json:"metadata,omitempty" yaml:"metadata,omitempty"`     // gRPC 调用 metadata（可选，与 grpc.metadata 合并）
 	Body       string            `json:"body,omitempty" yaml:"body,omitempty"`             // 可选，继承自公共配置
 	Weight     int               `json:"weight,omitempty" yaml:"weight,omitempty"`         // 权重（用于负载分配，默认1）
 	Repeat     int               `json:"repeat,omitempty" yaml:"repeat,omitempty"`         // 重复执行次数（默认1）
 	Verify     []VerifyConfig    `json:"verify,omitempty" yaml:"verify,omitempty"`         // 可选，覆盖公共验证配置，支持多个验证规则
 	DependsOn  []string          `json:"depends_on,omitempty" yaml:"depends_on,omitempty"` // 依赖的API名称列表
 	Extractors []ExtractorConfig `json:"extractors,omitempty" yaml:"extractors,omitempty"` // 响应数据提取器
+	GraphQL    *GraphQLConfig    `json:"graphql,omitempty" yaml:"graphql,omitempty"`       // GraphQL 请求（设置后由查询生成请求体，默认POST）
+	ThinkTime  *ThinkTimeConfig  `json:"think_time,omitempty" yaml:"think_time,omitempty"` // 请求完成后的思考时间（可选，继承自公共配置）
+
+	// 协议（混合协议场景，为空时使用全局协议；协议特定设置为空时使用全局设置）
+	Protocol  ProtocolType     `json:"protocol,omitempty" yaml:"protocol,omitempty"`   // 该API使用的协议
+	GRPC      *GRPCConfig      `json:"grpc,omitempty" yaml:"grpc,omitempty"`           // gRPC 设置（服务、方法等）
+	WebSocket *WebSocketConfig `json:"websocket,omitempty" yaml:"websocket,omitempty"` // WebSocket 设置
+	SSE       *SSEConfig       `json:"sse,omitempty" yaml:"sse,omitempty"`             // SSE 设置
+	Socket    *SocketConfig    `json:"socket,omitempty" yaml:"socket,omitempty"`       // TCP/UDP 设置
+	MQTT      *MQTTConfig      `json:"mqtt,omitempty" yaml:"mqtt,omitempty"`           // MQTT 设置
+
+	// 控制流（依赖链模式下生效，执行顺序为 foreach 遍历 → condition 判断 → repeat 重复 → loop 轮询）
+	Condition string         `json:"condition,omitempty" yaml:"condition,omitempty"` // 执行条件，不成立时跳过（如 {{.get_order.status}} == "PENDING"）
+	Loop      *LoopConfig    `json:"loop,omitempty" yaml:"loop,omitempty"`           // while/until 轮询
+	Foreach   *ForeachConfig `json:"foreach,omitempty" yaml:"foreach,omitempty"`     // 遍历 JSON 数组
 }
 
 // ExtractorConfig 数据提取器配置
@@ -99,6 +169,7 @@ type ExtractorConfig struct {
 	Expression string            `json:"expression,omitempty" yaml:"expression,omitempty"` // 表达式（如：{{.first_name}} {{.last_name}}）
 	Transforms []TransformConfig `json:"transforms,omitempty" yaml:"transforms,omitempty"` // 数据转换管道
 	Default    string            `json:"default,omitempty" yaml:"default,omitempty"`       // 默认值（提取失败时使用）
+	Event      string            `json:"event,omitempty" yaml:"event,omitempty"`           // SSE 事件类型过滤（SSE 响应逐个事件提取时只匹配该类型的事件）
 }
 
 // ExtractorSource 提取源
@@ -118,34 +189,138 @@ type TransformConfig struct {
 
 // HTTPConfig HTTP协议配置
 type HTTPConfig struct {
-	HTTP2           bool `json:"http2" yaml:"http2"`                           // 是否使用HTTP/2
-	KeepAlive       bool `json:"keepalive" yaml:"keepalive"`                   // 是否保持连接
+	HTTP2           bool `json:"http2" yaml:"http2"`                           // 强制使用HTTP/2（https 走 h2，http 走 h2c）
+	KeepAlive       bool `json:"keepalive" yaml:"keepalive"`                   // 是否保持连接（启用大容量空闲连接池）
 	FollowRedirects bool `json:"follow_redirects" yaml:"follow_redirects"`     // 是否跟随重定向
-	MaxConnsPerHost int  `json:"max_conns_per_host" yaml:"max_conns_per_host"` // 每个host的最大连接数
+	MaxConnsPerHost int  `json:"max_conns_per_host" yaml:"max_conns_per_host"` // 每个host的最大连接数（0 表示不限制）
+
+	// 连接池
+	MaxIdleConns        int           `json:"max_idle_conns,omitempty" yaml:"max_idle_conns,omitempty"`                   // 最大空闲连接数（0 表示不限制）
+	MaxIdleConnsPerHost int           `json:"max_idle_conns_per_host,omitempty" yaml:"max_idle_conns_per_host,omitempty"` // 每个host最大空闲连接数（默认 keepalive 时1000，否则2）
+	IdleConnTimeout     time.Duration `json:"idle_conn_timeout,omitempty" yaml:"idle_conn_timeout,omitempty"`             // 空闲连接超时（默认90s）
+	DisableKeepAlives   bool          `json:"disable_keepalives,omitempty" yaml:"disable_keepalives,omitempty"`           // 禁用连接复用（每个请求新建连接）
+
+	// 传输
+	DialTimeout        time.Duration `json:"dial_timeout,omitempty" yaml:"dial_timeout,omitempty"`               // 建立连接超时（默认30s）
+	DisableCompression bool          `json:"disable_compression,omitempty" yaml:"disable_compression,omitempty"` // 不自动请求 gzip 压缩
+	MaxRedirects       int           `json:"max_redirects,omitempty" yaml:"max_redirects,omitempty"`             // 最大重定向次数（默认10，仅 follow_redirects 时生效）
+	Proxy              string        `json:"proxy,omitempty" yaml:"proxy,omitempty"`                             // 代理地址（为空时读取 HTTP_PROXY/HTTPS_PROXY 环境变量）
+	LocalAddr          string        `json:"local_addr,omitempty" yaml:"local_addr,omitempty"`                   // 绑定的本地源IP（多网卡或规避端口耗尽时使用）
+
+	// TLS/mTLS（是否使用 TLS 由 URL scheme 决定，无需设置 enabled）
+	TLS *TLSConfig `json:"tls,omitempty" yaml:"tls,omitempty"`
 }
 
 // GRPCConfig gRPC协议配置
 type GRPCConfig struct {
-	UseReflection bool              `json:"use_reflection" yaml:"use_reflection"` // 是否使用反射
-	Service       string            `json:"service" yaml:"service"`               // 服务名
-	Method        string            `json:"method" yaml:"method"`                 // 方法名
-	ProtoFile     string            `json:"proto_file" yaml:"proto_file"`         // proto文件路径
-	Metadata      map[string]string `json:"metadata" yaml:"metadata"`             // gRPC metadata
-	TLS           *TLSConfig        `json:"tls,omitempty" yaml:"tls,omitempty"`   // TLS配置
+	UseReflection bool              `json:"use_reflection" yaml:"use_reflection"`                     // 是否使用反射
+	Service       string            `json:"service" yaml:"service"`                                   // 服务名
+	Method        string            `json:"method" yaml:"method"`                                     // 方法名
+	ProtoFile     string            `json:"proto_file" yaml:"proto_file"`                             // proto文件路径（未启用反射时本地解析）
+	ImportPaths   []string          `json:"import_paths,omitempty" yaml:"import_paths,omitempty"`     // proto导入路径
+	DescriptorSet string            `json:"descriptor_set,omitempty" yaml:"descriptor_set,omitempty"` // 编译好的 FileDescriptorSet 文件
+	Metadata      map[string]string `json:"metadata" yaml:"metadata"`                                 // gRPC metadata
+	TLS           *TLSConfig        `json:"tls,omitempty" yaml:"tls,omitempty"`                       // TLS配置
+	Stream        *GRPCStreamConfig `json:"stream,omitempty" yaml:"stream,omitempty"`                 // 流式调用配置
 }
 
+// GRPCStreamConfig gRPC流式调用配置（流类型由方法描述自动识别）
+type GRPCStreamConfig struct {
+	Messages int           `json:"messages" yaml:"messages"` // 每个流发送的消息数（客户端流/双向流），默认为请求体中的消息数
+	Interval time.Duration `json:"interval" yaml:"interval"` // 消息发送间隔
+	Lifetime time.Duration `json:"lifetime" yaml:"lifetime"` // 流最长存活时间，到期主动结束并视为正常，0 表示直到服务端结束（按时长运行时最迟到截止时间）
+}
+
+// WebSocketMode WebSocket 压测模式
+type WebSocketMode string
+
+const (
+	WebSocketModeRequest WebSocketMode = "request" // 请求模式（默认）：在长连接上一发一收
+	WebSocketModeSession WebSocketMode = "session" // 会话模式：每次请求建立新连接，按脚本发送并持续接收服务端推送
+)
+
 // WebSocketConfig WebSocket协议配置
 type WebSocketConfig struct {
-	PingInterval time.Duration `json:"ping_interval" yaml:"ping_interval"` // ping间隔
-	PingTimeout  time.Duration `json:"ping_timeout" yaml:"ping_timeout"`   // ping超时
+	PingInterval     time.Duration `json:"ping_interval" yaml:"ping_interval"`                             // ping间隔，0 表示不发送心跳
+	PingTimeout      time.Duration `json:"ping_timeout" yaml:"ping_timeout"`                               // ping超时（会话模式下超过 ping间隔+ping超时 未收到任何数据判定连接失效）
+	Mode             WebSocketMode `json:"mode,omitempty" yaml:"mode,omitempty"`                           // 压测模式：request(默认) | session
+	Hold             time.Duration `json:"hold,omitempty" yaml:"hold,omitempty"`                           // 会话连接保持时长，到期主动断开并视为正常，0 表示直到服务端断开（按时长运行时最迟到截止时间）
+	Messages         int           `json:"messages,omitempty" yaml:"messages,omitempty"`                   // 会话脚本发送的消息数，默认为请求体中的消息数
+	Interval         time.Duration `json:"interval,omitempty" yaml:"interval,omitempty"`                   // 会话脚本消息发送间隔
+	CorrelationField string        `json:"correlation_field,omitempty" yaml:"correlation_field,omitempty"` // 关联ID的JSONPath（如 $.id），收到与已发送消息ID相同的消息时记录往返延迟
+}
+
+// SSEConfig Server-Sent Events 协议配置（连接使用 http 配置的传输层）
+type SSEConfig struct {
+	Lifetime      time.Duration `json:"lifetime,omitempty" yaml:"lifetime,omitempty"`             // 事件流最长存活时间，到期主动断开并视为正常，0 表示直到服务端结束（按时长运行时最迟到截止时间）
+	MaxEvents     int           `json:"max_events,omitempty" yaml:"max_events,omitempty"`         // 收到指定数量的事件后主动断开，0 表示不限制
+	MaxReconnects int           `json:"max_reconnects,omitempty" yaml:"max_reconnects,omitempty"` // 连接中断后携带 Last-Event-ID 重连的最大次数，0 表示不重连
+	RetryDelay    time.Duration `json:"retry_delay,omitempty" yaml:"retry_delay,omitempty"`       // 重连前等待时间（服务端 retry 字段优先），默认 1s
+}
+
+// PayloadEncoding 请求体编码
+type PayloadEncoding string
+
+const (
+	PayloadEncodingText   PayloadEncoding = "text"   // 原文（默认）
+	PayloadEncodingHex    PayloadEncoding = "hex"    // 十六进制（允许空格）
+	PayloadEncodingBase64 PayloadEncoding = "base64" // 标准 Base64
+)
+
+// FramingType 报文分帧方式
+type FramingType string
+
+const (
+	FramingNone      FramingType = "none"      // 不分帧（默认）：原样发送，读取一次到达的数据（UDP 为一个数据报）
+	FramingLength    FramingType = "length"    // 长度前缀：报文前加 length_bytes 字节的长度
+	FramingDelimiter FramingType = "delimiter" // 分隔符：报文以 delimiter 结尾
+	FramingFixed     FramingType = "fixed"     // 定长：报文固定 fixed_size 字节（不足补零）
+)
+
+// SocketConfig TCP/UDP 协议配置（请求体按 encoding 解码后按 framing 分帧发送）
+type SocketConfig struct {
+	Encoding     PayloadEncoding `json:"encoding,omitempty" yaml:"encoding,omitempty"`             // 请求体编码：text(默认) | hex | base64，响应体按同样的编码记录
+	Framing      FramingType     `json:"framing,omitempty" yaml:"framing,omitempty"`               // 分帧方式：none(默认) | length | delimiter | fixed
+	LengthBytes  int             `json:"length_bytes,omitempty" yaml:"length_bytes,omitempty"`     // 长度前缀字节数：1/2/4（默认4）
+	LittleEndian bool            `json:"little_endian,omitempty" yaml:"little_endian,omitempty"`   // 长度前缀使用小端序（默认大端）
+	Delimiter    string          `json:"delimiter,omitempty" yaml:"delimiter,omitempty"`           // 分隔符（默认 "\n"）
+	FixedSize    int             `json:"fixed_size,omitempty" yaml:"fixed_size,omitempty"`         // 定长报文字节数
+	MaxFrameSize int             `json:"max_frame_size,omitempty" yaml:"max_frame_size,omitempty"` // 读取单个帧的最大字节数（默认16MB，超过时读取失败）
+	WriteOnly    bool            `json:"write_only,omitempty" yaml:"write_only,omitempty"`         // 只发送不读取响应
+	ReadTimeout  time.Duration   `json:"read_timeout,omitempty" yaml:"read_timeout,omitempty"`     // 读取响应超时（默认使用 timeout）
+}
+
+// MQTTMode MQTT 压测模式
+type MQTTMode string
+
+const (
+	MQTTModePublish   MQTTMode = "publish"   // 每次请求发布一条消息（默认）
+	MQTTModeSubscribe MQTTMode = "subscribe" // 每次请求等待订阅收到消息，不发布
+)
+
+// MQTTConfig MQTT 协议配置（url 为 mqtt://host:1883/主题，主题随 url 按请求渲染模板）
+type MQTTConfig struct {
+	Mode           MQTTMode      `json:"mode,omitempty" yaml:"mode,omitempty"`                         // 压测模式：publish(默认) | subscribe
+	ClientIDPrefix string        `json:"client_id_prefix,omitempty" yaml:"client_id_prefix,omitempty"` // 客户端ID前缀（默认 go-stress，后接随机后缀保证唯一）
+	Username       string        `json:"username,omitempty" yaml:"username,omitempty"`
+	Password       string        `json:"password,omitempty" yaml:"password,omitempty"`
+	KeepAlive      time.Duration `json:"keepalive,omitempty" yaml:"keepalive,omitempty"`             // 心跳间隔（默认30s）
+	PersistSession bool          `json:"persist_session,omitempty" yaml:"persist_session,omitempty"` // 保留会话（clean_session=false）
+	QoS            byte          `json:"qos,omitempty" yaml:"qos,omitempty"`                         // 发布 QoS：0/1/2
+	Retain         bool          `json:"retain,omitempty" yaml:"retain,omitempty"`                   // 发布保留消息
+	Subscribe      []string      `json:"subscribe,omitempty" yaml:"subscribe,omitempty"`             // 连接后订阅的主题（支持通配符）
+	SubscribeQoS   byte          `json:"subscribe_qos,omitempty" yaml:"subscribe_qos,omitempty"`     // 订阅 QoS：0/1/2
+	TimestampField string        `json:"timestamp_field,omitempty" yaml:"timestamp_field,omitempty"` // 发布时向 JSON 对象消息写入发布时间戳（纳秒）的字段，订阅端据此计算端到端延迟
+	TLS            *TLSConfig    `json:"tls,omitempty" yaml:"tls,omitempty"`                         // TLS配置（mqtts://、ssl://、wss:// 地址使用）
 }
 
 // TLSConfig TLS配置
 type TLSConfig struct {
 	Enabled            bool   `json:"enabled" yaml:"enabled"`
-	CertFile           string `json:"cert_file" yaml:"cert_file"`
-	KeyFile            string `json:"key_file" yaml:"key_file"`
-	CAFile             string `json:"ca_file" yaml:"ca_file"`
+	CertFile           string `json:"cert_file" yaml:"cert_file"`                         // 客户端证书（mTLS）
+	KeyFile            string `json:"key_file" yaml:"key_file"`                           // 客户端私钥（mTLS）
+	CAFile             string `json:"ca_file" yaml:"ca_file"`                             // 自定义CA证书
+	ServerName         string `json:"server_name,omitempty" yaml:"server_name,omitempty"` // SNI 及证书校验使用的主机名
 	InsecureSkipVerify bool   `json:"insecure_skip_verify" yaml:"insecure_skip_verify"`
 }
 
@@ -161,6 +336,33 @@ type AdvancedConfig struct {
 
 	RampUp       time.Duration `json:"ramp_up" yaml:"ramp_up"`             // 渐进式启动时间
 	RealtimePort int           `json:"realtime_port" yaml:"realtime_port"` // 实时报告服务器端口（默认8088）
+
+	TargetRPS float64 `json:"target_rps,omitempty" yaml:"target_rps,omitempty"` // 目标到达速率（>0 时启用开放模型，concurrency 作为worker池上限）
+
+	CorrectOmission  bool          `json:"correct_omission,omitempty" yaml:"correct_omission,omitempty"`   // 启用协调遗漏校正（按期望间隔回填慢请求期间缺失的样本，开放模型下不生效）
+	ExpectedInterval time.Duration `json:"expected_interval,omitempty" yaml:"expected_interval,omitempty"` // 单个worker的期望请求间隔（启用校正时必填）
+
+	TimeSeriesInterval time.Duration `json:"time_series_interval,omitempty" yaml:"time_series_interval,omitempty"` // 时间序列分桶间隔（默认1s）
+}
+
+// StageConfig 负载阶段配置
+// 阶段内目标值从上一阶段的目标（首个阶段从0开始）线性过渡到本阶段目标，
+// 相邻阶段目标相同即为平台期，时长很短的阶段可用于阶梯或尖峰
+type StageConfig struct {
+	Name      string        `json:"name,omitempty" yaml:"name,omitempty"`             // 阶段名称（可选，用于展示）
+	Duration  time.Duration `json:"duration" yaml:"duration"`                         // 阶段持续时间
+	Target    uint64        `json:"target,omitempty" yaml:"target,omitempty"`         // 目标并发数（闭合模型）
+	TargetRPS float64       `json:"target_rps,omitempty" yaml:"target_rps,omitempty"` // 目标RPS（开放模型）
+}
+
+// StagesUseRPS 阶段是否以RPS为目标（开放模型）
+func StagesUseRPS(stages []StageConfig) bool {
+	for _, stage := range stages {
+		if stage.TargetRPS > 0 {
+			return true
+		}
+	}
+	return false
 }
 
 // VerifyConfig 验证配置
@@ -0,0 +1,228 @@
+/*
+ * @Author: kamalyes 501893067@qq.com
+ * @Date: 2026-02-08 00:00:00
+ * @LastEditors: kamalyes 501893067@qq.com
+ * @LastEditTime: 2026-02-08 00:00:00
+ * @FilePath: \go-stress\config\datasource.go
+ * @Description: 数据源配置 - 从 CSV/JSONL 文件加载参数化数据
+ *
+ * Copyright (c) 2026 by kamalyes, All Rights Reserved.
+ */
+package config
+
+import (
+	"bufio"
+	"bytes"
+	"encoding/csv"
+	"encoding/json"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"strings"
+	"unicode/utf8"
+)
+
+// DataSourceFormat 数据文件格式
+type DataSourceFormat string
+
+const (
+	DataFormatCSV   DataSourceFormat = "csv"   // CSV（首行为列名）
+	DataFormatJSONL DataSourceFormat = "jsonl" // 每行一个 JSON 对象
+)
+
+// DataSourcePolicy 数据分配策略
+type DataSourcePolicy string
+
+const (
+	DataPolicySequential      DataSourcePolicy = "sequential"        // 所有worker共享游标顺序取行，到末尾后从头循环
+	DataPolicyRandom          DataSourcePolicy = "random"            // 每轮随机取一行
+	DataPolicyUniquePerWorker DataSourcePolicy = "unique_per_worker" // 每个worker固定使用独占的一行（如每个虚拟用户一个账号）
+	DataPolicyUniqueOnce      DataSourcePolicy = "unique_once"       // 每行只使用一次，全部用完后停止压测
+)
+
+// Exclusive 该策略下同一行只能分给一个使用者（分布式模式下各 Slave 的数据不能重叠）
+func (p DataSourcePolicy) Exclusive() bool {
+	return p == DataPolicyUniquePerWorker || p == DataPolicyUniqueOnce
+}
+
+// DataSourceConfig 数据源配置
+type DataSourceConfig struct {
+	Name      string              `json:"name,omitempty" yaml:"name,omitempty"`           // 数据源名称（用于日志）
+	File      string              `json:"file,omitempty" yaml:"file,omitempty"`           // 数据文件路径
+	Format    DataSourceFormat    `json:"format,omitempty" yaml:"format,omitempty"`       // 文件格式（为空时按扩展名推断）
+	Delimiter string              `json:"delimiter,omitempty" yaml:"delimiter,omitempty"` // CSV 分隔符（默认逗号）
+	Policy    DataSourcePolicy    `json:"policy,omitempty" yaml:"policy,omitempty"`       // 分配策略（默认 sequential）
+	Rows      []map[string]string `json:"rows,omitempty" yaml:"rows,omitempty"`           // 内联数据（分布式模式下由 Master 写入分片）
+}
+
+// DisplayName 数据源显示名称
+func (d *DataSourceConfig) DisplayName() string {
+	if d.Name != "" {
+		return d.Name
+	}
+	return filepath.Base(d.File)
+}
+
+// GetPolicy 获取分配策略（默认顺序）
+func (d *DataSourceConfig) GetPolicy() DataSourcePolicy {
+	if d.Policy == "" {
+		return DataPolicySequential
+	}
+	return d.Policy
+}
+
+// GetFormat 获取文件格式（未指定时按扩展名推断）
+func (d *DataSourceConfig) GetFormat() DataSourceFormat {
+	if d.Format != "" {
+		return DataSourceFormat(strings.ToLower(string(d.Format)))
+	}
+	switch strings.ToLower(filepath.Ext(d.File)) {
+	case ".jsonl", ".ndjson":
+		return DataFormatJSONL
+	default:
+		return DataFormatCSV
+	}
+}
+
+// Validate 校验数据源配置
+func (d *DataSourceConfig) Validate() error {
+	if d.File == "" && len(d.Rows) == 0 {
+		return fmt.Errorf("数据源 [%s] 必须指定 file 或 rows", d.DisplayName())
+	}
+	switch d.GetPolicy() {
+	case DataPolicySequential, DataPolicyRandom, DataPolicyUniquePerWorker, DataPolicyUniqueOnce:
+	default:
+		return fmt.Errorf("数据源 [%s] 不支持的分配策略: %s", d.DisplayName(), d.Policy)
+	}
+	if d.File != "" {
+		switch d.GetFormat() {
+		case DataFormatCSV, DataFormatJSONL:
+		default:
+			return fmt.Errorf("数据源 [%s] 不支持的文件格式: %s (仅支持csv/jsonl)", d.DisplayName(), d.Format)
+		}
+		if utf8.RuneCountInString(d.Delimiter) > 1 {
+			return fmt.Errorf("数据源 [%s] CSV 分隔符只能是单个字符: %q", d.DisplayName(), d.Delimiter)
+		}
+	}
+	return nil
+}
+
+// LoadRows 加载数据行（优先使用内联数据）
+func (d *DataSourceConfig) LoadRows() ([]map[string]string, error) {
+	if len(d.Rows) > 0 || d.File == "" {
+		return d.Rows, nil
+	}
+
+	data, err := os.ReadFile(d.File)
+	if err != nil {
+		return nil, fmt.Errorf("读取数据文件失败: %w", err)
+	}
+
+	var rows []map[string]string
+	switch d.GetFormat() {
+	case DataFormatJSONL:
+		rows, err = parseJSONLRows(data)
+	default:
+		rows, err = parseCSVRows(data, d.Delimiter)
+	}
+	if err != nil {
+		return nil, fmt.Errorf("解析数据文件 %s 失败: %w", d.File, err)
+	}
+	return rows, nil
+}
+
+// parseCSVRows 解析 CSV（首行为列名）
+func parseCSVRows(data []byte, delimiter string) ([]map[string]string, error) {
+	reader := csv.NewReader(bytes.NewReader(bytes.TrimPrefix(data, []byte("\ufeff"))))
+	if delimiter != "" {
+		reader.Comma, _ = utf8.DecodeRuneInString(delimiter)
+	}
+	reader.TrimLeadingSpace = true
+
+	header, err := reader.Read()
+	if err == io.EOF {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+	for i := range header {
+		header[i] = strings.TrimSpace(header[i])
+	}
+
+	var rows []map[string]string
+	for {
+		record, err := reader.Read()
+		if err == io.EOF {
+			return rows, nil
+		}
+		if err != nil {
+			return nil, err
+		}
+		row := make(map[string]string, len(header))
+		for i, name := range header {
+			if i < len(record) {
+				row[name] = record[i]
+			}
+		}
+		rows = append(rows, row)
+	}
+}
+
+// parseJSONLRows 解析 JSONL（每行一个对象，嵌套值保留为 JSON 字符串）
+func parseJSONLRows(data []byte) ([]map[string]string, error) {
+	var rows []map[string]string
+	scanner := bufio.NewScanner(bytes.NewReader(data))
+	scanner.Buffer(make([]byte, 0, 64*1024), 16*1024*1024)
+
+	for lineNo := 1; scanner.Scan(); lineNo++ {
+		line := bytes.TrimSpace(scanner.Bytes())
+		if len(line) == 0 {
+			continue
+		}
+
+		decoder := json.NewDecoder(bytes.NewReader(line))
+		decoder.UseNumber() // 避免大整数ID被转成科学计数法
+		var obj map[string]any
+		if err := decoder.Decode(&obj); err != nil {
+			return nil, fmt.Errorf("第%d行: %w", lineNo, err)
+		}
+
+		row := make(map[string]string, len(obj))
+		for k, v := range obj {
+			row[k] = jsonValueString(v)
+		}
+		rows = append(rows, row)
+	}
+	return rows, scanner.Err()
+}
+
+// jsonValueString 将 JSON 值转换为模板中使用的字符串
+func jsonValueString(v any) string {
+	switch val := v.(type) {
+	case nil:
+		return ""
+	case string:
+		return val
+	case json.Number:
+		return val.String()
+	case bool:
+		return fmt.Sprintf("%t", val)
+	default:
+		data, _ := json.Marshal(val)
+		return string(data)
+	}
+}
+
+// PartitionRows 取第 index 个分片（共 count 个，按行号取模，分片之间互不重叠）
+func PartitionRows(rows []map[string]string, index, count int) []map[string]string {
+	if count <= 1 {
+		return rows
+	}
+	part := make([]map[string]string, 0, len(rows)/count+1)
+	for i := index; i < len(rows); i += count {
+		part = append(part, rows[i])
+	}
+	return part
+}
@@ -0,0 +1,86 @@
+/*
+ * @Author: kamalyes 501893067@qq.com
+ * @Date: 2026-02-08 00:00:00
+ * @LastEditors: kamalyes 501893067@qq.com
+ * @LastEditTime: 2026-02-08 00:00:00
+ * @FilePath: \go-stress\config\datasource_test.go
+ * @Description: 数据源加载测试
+ *
+ * Copyright (c) 2026 by kamalyes, All Rights Reserved.
+ */
+package config
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+// 测试 CSV 数据加载（首行为列名，支持自定义分隔符）
+func TestDataSourceLoadCSV(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "users.csv")
+	assert.NoError(t, os.WriteFile(path, []byte("user_id;name\n1001;alice\n1002; bob\n"), 0o644))
+
+	ds := &DataSourceConfig{File: path, Delimiter: ";"}
+	assert.NoError(t, ds.Validate())
+	assert.Equal(t, DataFormatCSV, ds.GetFormat())
+	assert.Equal(t, DataPolicySequential, ds.GetPolicy())
+
+	rows, err := ds.LoadRows()
+	assert.NoError(t, err)
+	assert.Equal(t, []map[string]string{
+		{"user_id": "1001", "name": "alice"},
+		{"user_id": "1002", "name": "bob"},
+	}, rows)
+}
+
+// 测试 JSONL 数据加载（大整数保持原样，嵌套值转为 JSON 字符串）
+func TestDataSourceLoadJSONL(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "users.jsonl")
+	data := `{"user_id": 9007199254740993, "vip": true, "tags": ["a", "b"]}
+
+{"user_id": 2, "name": "bob"}
+`
+	assert.NoError(t, os.WriteFile(path, []byte(data), 0o644))
+
+	ds := &DataSourceConfig{File: path, Policy: DataPolicyUniqueOnce}
+	assert.Equal(t, DataFormatJSONL, ds.GetFormat())
+
+	rows, err := ds.LoadRows()
+	assert.NoError(t, err)
+	assert.Len(t, rows, 2)
+	assert.Equal(t, "9007199254740993", rows[0]["user_id"])
+	assert.Equal(t, "true", rows[0]["vip"])
+	assert.Equal(t, `["a","b"]`, rows[0]["tags"])
+	assert.Equal(t, "bob", rows[1]["name"])
+}
+
+// 测试数据源配置校验
+func TestDataSourceValidate(t *testing.T) {
+	assert.Error(t, (&DataSourceConfig{Name: "empty"}).Validate())
+	assert.Error(t, (&DataSourceConfig{File: "a.csv", Policy: "shuffle"}).Validate())
+	assert.Error(t, (&DataSourceConfig{File: "a.xml", Format: "xml"}).Validate())
+	assert.NoError(t, (&DataSourceConfig{Rows: []map[string]string{{"id": "1"}}, Policy: DataPolicyRandom}).Validate())
+}
+
+// 测试数据分片互不重叠且覆盖全部数据
+func TestPartitionRows(t *testing.T) {
+	rows := make([]map[string]string, 7)
+	for i := range rows {
+		rows[i] = map[string]string{"id": string(rune('a' + i))}
+	}
+
+	seen := make(map[string]int)
+	for i := 0; i < 3; i++ {
+		for _, row := range PartitionRows(rows, i, 3) {
+			seen[row["id"]]++
+		}
+	}
+	assert.Len(t, seen, 7)
+	for _, count := range seen {
+		assert.Equal(t, 1, count)
+	}
+	assert.Len(t, PartitionRows(rows, 0, 1), 7)
+}
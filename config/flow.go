@@ -0,0 +1,251 @@
+/*
+ * @Author: kamalyes 501893067@qq.com
+ * @Date: 2026-02-20 00:00:00
+ * @LastEditors: kamalyes 501893067@qq.com
+ * @LastEditTime: 2026-02-20 00:00:00
+ * @FilePath: \go-stress\config\flow.go
+ * @Description: 场景控制流配置 - 条件执行、while/until 轮询和 foreach 遍历
+ *
+ * Copyright (c) 2026 by kamalyes, All Rights Reserved.
+ */
+package config
+
+import (
+	"cmp"
+	"encoding/json"
+	"fmt"
+	"regexp"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// DefaultLoopMaxIterations 轮询默认最大执行次数
+const DefaultLoopMaxIterations = 10
+
+// conditionOperators 条件操作符（按匹配优先级排列，单词操作符两侧须有空格）
+var conditionOperators = []string{"==", "!=", ">=", "<=", ">", "<", " contains ", " matches "}
+
+// LoopConfig 轮询配置：重复执行同一个API直到条件满足或达到最大次数
+// while/until 在每次执行（含变量提取）后判断，二者只能设置一个
+type LoopConfig struct {
+	While         string        `json:"while,omitempty" yaml:"while,omitempty"`                   // 条件成立时继续执行
+	Until         string        `json:"until,omitempty" yaml:"until,omitempty"`                   // 条件成立时停止执行
+	MaxIterations int           `json:"max_iterations,omitempty" yaml:"max_iterations,omitempty"` // 最大执行次数（默认10）
+	Interval      time.Duration `json:"interval,omitempty" yaml:"interval,omitempty"`             // 两次执行之间的等待时间（计为空闲）
+}
+
+// Continue 判断是否需要继续执行
+func (l *LoopConfig) Continue(eval func(expr string) bool) bool {
+	if l.While != "" {
+		return eval(l.While)
+	}
+	return !eval(l.Until)
+}
+
+// ForeachConfig 遍历配置：对 JSON 数组的每个元素执行一次API
+// 元素通过 {{.item}} 访问（对象字段为 {{.item.字段}}，下标为 {{.item_index}}），item 可通过 as 修改
+type ForeachConfig struct {
+	Items    string `json:"items" yaml:"items"`                             // 渲染结果为 JSON 数组的模板，如 {{.get_cart.items}}
+	As       string `json:"as,omitempty" yaml:"as,omitempty"`               // 元素变量名（默认 item）
+	MaxItems int    `json:"max_items,omitempty" yaml:"max_items,omitempty"` // 最多遍历的元素数（0 表示不限制）
+}
+
+// Expand 解析渲染后的 JSON 数组，每个元素展开为一组以元素变量名为前缀的变量
+func (f *ForeachConfig) Expand(rendered string) ([]map[string]string, error) {
+	decoder := json.NewDecoder(strings.NewReader(rendered))
+	decoder.UseNumber()
+	var items []any
+	if err := decoder.Decode(&items); err != nil {
+		return nil, fmt.Errorf("foreach 数据不是 JSON 数组: %w", err)
+	}
+	if f.MaxItems > 0 && len(items) > f.MaxItems {
+		items = items[:f.MaxItems]
+	}
+
+	as := f.name()
+	result := make([]map[string]string, 0, len(items))
+	for i, item := range items {
+		vars := map[string]string{as + "_index": strconv.Itoa(i)}
+		flattenValue(vars, as, item)
+		result = append(result, vars)
+	}
+	return result, nil
+}
+
+// name 元素变量名
+func (f *ForeachConfig) name() string {
+	if f.As == "" {
+		return "item"
+	}
+	return f.As
+}
+
+// flattenValue 将 JSON 值展开为 前缀.字段 形式的变量（数组元素为 前缀.下标）
+func flattenValue(vars map[string]string, prefix string, value any) {
+	switch v := value.(type) {
+	case map[string]any:
+		for k, item := range v {
+			flattenValue(vars, prefix+"."+k, item)
+		}
+	case []any:
+		for i, item := range v {
+			flattenValue(vars, prefix+"."+strconv.Itoa(i), item)
+		}
+	}
+	vars[prefix] = jsonValueString(value)
+}
+
+// Condition 条件表达式：左值 操作符 右值，或单个值（按真假判断）
+// 两侧分别作为模板渲染后比较，均为数字时按数值比较，否则按字符串比较
+type Condition struct {
+	Left     string
+	Operator string // ==, !=, >=, <=, >, <, contains, matches；为空时按真假判断
+	Right    string
+}
+
+// ParseCondition 解析条件表达式（模板 {{ }} 和引号内的操作符不参与拆分）
+func ParseCondition(expr string) (*Condition, error) {
+	expr = strings.TrimSpace(expr)
+	if expr == "" {
+		return nil, fmt.Errorf("条件表达式不能为空")
+	}
+
+	depth, quote := 0, byte(0)
+	for i := 0; i < len(expr); i++ {
+		switch c := expr[i]; {
+		case quote != 0:
+			if c == quote {
+				quote = 0
+			}
+			continue
+		case strings.HasPrefix(expr[i:], "{{"):
+			depth++
+			i++
+			continue
+		case strings.HasPrefix(expr[i:], "}}") && depth > 0:
+			depth--
+			i++
+			continue
+		case depth > 0:
+			continue
+		case c == '"' || c == '\'':
+			quote = c
+			continue
+		}
+		for _, op := range conditionOperators {
+			if !strings.HasPrefix(expr[i:], op) {
+				continue
+			}
+			cond := &Condition{
+				Left:     strings.TrimSpace(expr[:i]),
+				Operator: strings.TrimSpace(op),
+				Right:    strings.TrimSpace(expr[i+len(op):]),
+			}
+			if cond.Left == "" || cond.Right == "" {
+				return nil, fmt.Errorf("条件表达式格式错误: %q（示例: {{.get_order.status}} == \"PENDING\"）", expr)
+			}
+			if cond.Operator == "matches" && !strings.Contains(cond.Right, "{{") {
+				if _, err := regexp.Compile(conditionOperand(cond.Right)); err != nil {
+					return nil, fmt.Errorf("条件 %q 正则表达式错误: %w", expr, err)
+				}
+			}
+			return cond, nil
+		}
+	}
+	return &Condition{Left: expr}, nil
+}
+
+// Eval 使用 render 渲染两侧模板后求值
+func (c *Condition) Eval(render func(string) string) bool {
+	left := conditionOperand(render(c.Left))
+	if c.Operator == "" {
+		switch strings.ToLower(left) {
+		case "", "0", "false", "null", "nil":
+			return false
+		}
+		return true
+	}
+
+	right := conditionOperand(render(c.Right))
+	switch c.Operator {
+	case "contains":
+		return strings.Contains(left, right)
+	case "matches":
+		re, err := regexp.Compile(right)
+		return err == nil && re.MatchString(left)
+	}
+
+	result := strings.Compare(left, right)
+	l, lErr := strconv.ParseFloat(left, 64)
+	r, rErr := strconv.ParseFloat(right, 64)
+	if lErr == nil && rErr == nil {
+		result = cmp.Compare(l, r)
+	}
+	switch c.Operator {
+	case "==":
+		return result == 0
+	case "!=":
+		return result != 0
+	case ">=":
+		return result >= 0
+	case "<=":
+		return result <= 0
+	case ">":
+		return result > 0
+	case "<":
+		return result < 0
+	}
+	return false
+}
+
+// conditionOperand 规整操作数：去除两侧空白和引号，未能解析的模板视为空值
+func conditionOperand(s string) string {
+	s = strings.TrimSpace(s)
+	if strings.Contains(s, "{{") && strings.Contains(s, "}}") || s == "<no value>" {
+		return ""
+	}
+	if len(s) >= 2 && (s[0] == '"' || s[0] == '\'') && s[len(s)-1] == s[0] {
+		if s[0] == '"' {
+			if unquoted, err := strconv.Unquote(s); err == nil {
+				return unquoted
+			}
+		}
+		return s[1 : len(s)-1]
+	}
+	return s
+}
+
+// HasFlow 是否配置了控制流
+func (a *APIConfig) HasFlow() bool {
+	return a.Condition != "" || a.Loop != nil || a.Foreach != nil
+}
+
+// ValidateFlow 验证API的控制流配置
+func (a *APIConfig) ValidateFlow() error {
+	if a.Condition != "" {
+		if _, err := ParseCondition(a.Condition); err != nil {
+			return err
+		}
+	}
+	if loop := a.Loop; loop != nil {
+		if (loop.While == "") == (loop.Until == "") {
+			return fmt.Errorf("loop 必须且只能设置 while 或 until 之一")
+		}
+		if _, err := ParseCondition(loop.While + loop.Until); err != nil {
+			return err
+		}
+		if loop.MaxIterations < 0 || loop.Interval < 0 {
+			return fmt.Errorf("loop 的 max_iterations 和 interval 不能为负数")
+		}
+	}
+	if foreach := a.Foreach; foreach != nil {
+		if strings.TrimSpace(foreach.Items) == "" {
+			return fmt.Errorf("foreach 必须设置 items")
+		}
+		if foreach.MaxItems < 0 {
+			return fmt.Errorf("foreach 的 max_items 不能为负数")
+		}
+	}
+	return nil
+}
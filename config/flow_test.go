@@ -0,0 +1,91 @@
+/*
+ * @Author: kamalyes 501893067@qq.com
+ * @Date: 2026-02-20 00:00:00
+ * @LastEditors: kamalyes 501893067@qq.com
+ * @LastEditTime: 2026-02-20 00:00:00
+ * @FilePath: \go-stress\config\flow_test.go
+ * @Description: 场景控制流配置测试
+ *
+ * Copyright (c) 2026 by kamalyes, All Rights Reserved.
+ */
+package config
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+// 测试条件表达式解析和求值
+func TestConditionEval(t *testing.T) {
+	vars := map[string]string{
+		"{{.order.status}}": "PENDING",
+		"{{.order.total}}":  "120.5",
+		"{{.order.count}}":  "9",
+		"{{.order.paid}}":   "false",
+		"{{.order.note}}":   "a == b",
+	}
+	render := func(s string) string {
+		for k, v := range vars {
+			s = strings.ReplaceAll(s, k, v)
+		}
+		return s
+	}
+
+	cases := map[string]bool{
+		`{{.order.status}} == "PENDING"`:        true,
+		`{{.order.status}} != 'PENDING'`:        false,
+		`{{.order.total}} > 100`:                true,
+		`{{.order.count}} < 10`:                 true, // 数值比较
+		`{{.order.count}} >= "10"`:              false,
+		`{{.order.status}} contains "END"`:      true,
+		`{{.order.status}} matches "^P[A-Z]+$"`: true,
+		`{{.order.note}} == "a == b"`:           true, // 引号内的操作符不参与拆分
+		`{{.order.paid}}`:                       false,
+		`{{.order.status}}`:                     true,
+		`{{.order.missing}}`:                    false, // 未解析的模板视为空值
+		`{{.order.missing}} == ""`:              true,
+		`{{if eq .x "a"}}1{{end}} == 1`:         false,
+	}
+	for expr, expected := range cases {
+		cond, err := ParseCondition(expr)
+		assert.NoError(t, err, expr)
+		assert.Equal(t, expected, cond.Eval(render), expr)
+	}
+
+	for _, expr := range []string{"", "== 1", `{{.a}} matches "("`} {
+		_, err := ParseCondition(expr)
+		assert.Error(t, err, expr)
+	}
+}
+
+// 测试 foreach 数组展开
+func TestForeachExpand(t *testing.T) {
+	f := &ForeachConfig{As: "sku", MaxItems: 2}
+	items, err := f.Expand(`[{"id":1001,"tags":["a","b"],"price":9.9},{"id":1002},{"id":1003}]`)
+	assert.NoError(t, err)
+	assert.Len(t, items, 2)
+	assert.Equal(t, "0", items[0]["sku_index"])
+	assert.Equal(t, "1001", items[0]["sku.id"])
+	assert.Equal(t, "9.9", items[0]["sku.price"])
+	assert.Equal(t, "b", items[0]["sku.tags.1"])
+	assert.Equal(t, `["a","b"]`, items[0]["sku.tags"])
+	assert.Equal(t, `{"id":1002}`, items[1]["sku"])
+
+	items, err = (&ForeachConfig{}).Expand(`["x","y"]`)
+	assert.NoError(t, err)
+	assert.Equal(t, "y", items[1]["item"])
+
+	_, err = (&ForeachConfig{}).Expand("not-json")
+	assert.Error(t, err)
+}
+
+// 测试控制流配置验证
+func TestValidateFlow(t *testing.T) {
+	assert.NoError(t, (&APIConfig{Condition: "{{.a.b}}", Loop: &LoopConfig{Until: `{{.a.b}} == "DONE"`}, Foreach: &ForeachConfig{Items: "{{.a.list}}"}}).ValidateFlow())
+	assert.Error(t, (&APIConfig{Loop: &LoopConfig{}}).ValidateFlow())
+	assert.Error(t, (&APIConfig{Loop: &LoopConfig{While: "{{.a}}", Until: "{{.b}}"}}).ValidateFlow())
+	assert.Error(t, (&APIConfig{Loop: &LoopConfig{Until: "{{.a}}", MaxIterations: -1}}).ValidateFlow())
+	assert.Error(t, (&APIConfig{Foreach: &ForeachConfig{}}).ValidateFlow())
+}
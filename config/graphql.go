@@ -0,0 +1,50 @@
+/*
+ * @Author: kamalyes 501893067@qq.com
+ * @Date: 2026-02-17 00:00:00
+ * @LastEditors: kamalyes 501893067@qq.com
+ * @LastEditTime: 2026-02-17 00:00:00
+ * @FilePath: \go-stress\config\graphql.go
+ * @Description: GraphQL 接口配置 - 查询、变量、操作名和自动持久化查询（APQ）
+ *
+ * Copyright (c) 2026 by kamalyes, All Rights Reserved.
+ */
+package config
+
+import (
+	"fmt"
+	"os"
+	"regexp"
+	"strings"
+)
+
+// GraphQLConfig GraphQL 请求配置（设置后请求体由查询、变量和操作名生成，忽略 body）
+type GraphQLConfig struct {
+	Query          string         `json:"query,omitempty" yaml:"query,omitempty"`                     // 查询文档
+	QueryFile      string         `json:"query_file,omitempty" yaml:"query_file,omitempty"`           // 从文件加载查询文档（加载配置时读取到 query）
+	Variables      map[string]any `json:"variables,omitempty" yaml:"variables,omitempty"`             // 查询变量（字符串值支持模板）
+	OperationName  string         `json:"operation_name,omitempty" yaml:"operation_name,omitempty"`   // 操作名（为空时取查询中第一个具名操作）
+	PersistedQuery bool           `json:"persisted_query,omitempty" yaml:"persisted_query,omitempty"` // 自动持久化查询：先只发送 sha256 哈希，服务端未缓存时再携带查询重发
+}
+
+// graphQLOperationRegex 匹配查询文档中的具名操作（如 query GetUser(...)）
+var graphQLOperationRegex = regexp.MustCompile(`(?m)^\s*(?:query|mutation|subscription)\s+([_A-Za-z][_0-9A-Za-z]*)`)
+
+// Prepare 加载查询文件并补全操作名
+func (g *GraphQLConfig) Prepare() error {
+	if g.Query == "" && g.QueryFile != "" {
+		data, err := os.ReadFile(g.QueryFile)
+		if err != nil {
+			return fmt.Errorf("读取GraphQL查询文件失败: %w", err)
+		}
+		g.Query = string(data)
+	}
+	if strings.TrimSpace(g.Query) == "" {
+		return fmt.Errorf("GraphQL查询不能为空（需要query或query_file）")
+	}
+	if g.OperationName == "" {
+		if m := graphQLOperationRegex.FindStringSubmatch(g.Query); m != nil {
+			g.OperationName = m[1]
+		}
+	}
+	return nil
+}
@@ -0,0 +1,163 @@
+/*
+ * @Author: kamalyes 501893067@qq.com
+ * @Date: 2026-02-09 00:00:00
+ * @LastEditors: kamalyes 501893067@qq.com
+ * @LastEditTime: 2026-02-09 00:00:00
+ * @FilePath: \go-stress\config\har.go
+ * @Description: HAR 导入 - 将浏览器录制的会话转换为按顺序执行的多API配置
+ *
+ * Copyright (c) 2026 by kamalyes, All Rights Reserved.
+ */
+package config
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/url"
+	"strings"
+
+	"github.com/kamalyes/go-logger"
+)
+
+// harFile HAR 文件结构（只保留导入需要的字段）
+type harFile struct {
+	Log struct {
+		Entries []harEntry `json:"entries"`
+	} `json:"log"`
+}
+
+// harEntry HAR 请求记录
+type harEntry struct {
+	ResourceType string `json:"_resourceType"` // Chrome 扩展字段：document/xhr/fetch/script/stylesheet/image...
+	Request      struct {
+		Method   string      `json:"method"`
+		URL      string      `json:"url"`
+		Headers  []harNVPair `json:"headers"`
+		PostData *struct {
+			MimeType string      `json:"mimeType"`
+			Text     string      `json:"text"`
+			Params   []harNVPair `json:"params"`
+		} `json:"postData"`
+	} `json:"request"`
+	Response struct {
+		Content struct {
+			MimeType string `json:"mimeType"`
+		} `json:"content"`
+	} `json:"response"`
+}
+
+// harNVPair HAR 键值对
+type harNVPair struct {
+	Name  string `json:"name"`
+	Value string `json:"value"`
+}
+
+// harStaticResourceTypes Chrome 标记的静态资源类型
+var harStaticResourceTypes = map[string]bool{
+	"stylesheet": true,
+	"script":     true,
+	"image":      true,
+	"font":       true,
+	"media":      true,
+	"manifest":   true,
+}
+
+// HARParser HAR 文件解析器
+type HARParser struct {
+	filter *ImportFilter
+	logger logger.ILogger
+}
+
+// NewHARParser 解析 HAR 文件内容并返回配置（API 保持录制顺序，filter 为 nil 时默认过滤静态资源）
+func NewHARParser(data []byte, filter *ImportFilter, log logger.ILogger) (*Config, error) {
+	f, err := newImportFilter(filter)
+	if err != nil {
+		return nil, err
+	}
+	parser := &HARParser{filter: f, logger: log}
+	return parser.parse(data)
+}
+
+// parse 解析 HAR
+func (p *HARParser) parse(data []byte) (*Config, error) {
+	var har harFile
+	if err := json.Unmarshal(data, &har); err != nil {
+		return nil, fmt.Errorf("解析HAR文件失败: %w", err)
+	}
+	if len(har.Log.Entries) == 0 {
+		return nil, fmt.Errorf("HAR文件中没有请求记录")
+	}
+
+	namer := newAPINamer()
+	apis := make([]APIConfig, 0, len(har.Log.Entries))
+	skipped := 0
+	for _, entry := range har.Log.Entries {
+		if !p.allow(&entry) {
+			skipped++
+			continue
+		}
+		api := p.convertEntry(&entry)
+		api.Name = namer.name(requestName(api.Method, api.URL))
+		apis = append(apis, api)
+	}
+
+	p.logger.Infof("📄 HAR导入: %d 个请求，过滤 %d 个", len(apis), skipped)
+	if len(apis) == 0 {
+		return nil, fmt.Errorf("HAR文件中的请求已全部被过滤")
+	}
+	return newImportedConfig(apis, p.filter.Chain), nil
+}
+
+// allow 判断记录是否保留
+func (p *HARParser) allow(entry *harEntry) bool {
+	if entry.Request.URL == "" || strings.HasPrefix(entry.Request.URL, "data:") {
+		return false
+	}
+	if !p.filter.KeepStatic && harStaticResourceTypes[entry.ResourceType] {
+		return false
+	}
+	return p.filter.Allow(entry.Request.URL, entry.Response.Content.MimeType)
+}
+
+// convertEntry 将 HAR 记录转换为API配置
+func (p *HARParser) convertEntry(entry *harEntry) APIConfig {
+	req := entry.Request
+	api := APIConfig{
+		URL:     req.URL,
+		Method:  strings.ToUpper(req.Method),
+		Headers: make(map[string]string),
+		Weight:  1,
+	}
+
+	for _, h := range req.Headers {
+		if keepImportedHeader(h.Name) {
+			api.Headers[h.Name] = h.Value
+		}
+	}
+
+	if req.PostData != nil {
+		api.Body = req.PostData.Text
+		// 部分工具只记录表单参数，不记录原始文本
+		if api.Body == "" && len(req.PostData.Params) > 0 {
+			form := url.Values{}
+			for _, param := range req.PostData.Params {
+				form.Add(param.Name, param.Value)
+			}
+			api.Body = form.Encode()
+		}
+		if req.PostData.MimeType != "" && !hasHeader(api.Headers, "Content-Type") {
+			api.Headers["Content-Type"] = req.PostData.MimeType
+		}
+	}
+	return api
+}
+
+// hasHeader 判断请求头是否存在（忽略大小写）
+func hasHeader(headers map[string]string, name string) bool {
+	for k := range headers {
+		if strings.EqualFold(k, name) {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,119 @@
+/*
+ * @Author: kamalyes 501893067@qq.com
+ * @Date: 2026-02-09 00:00:00
+ * @LastEditors: kamalyes 501893067@qq.com
+ * @LastEditTime: 2026-02-09 00:00:00
+ * @FilePath: \go-stress\config\har_test.go
+ * @Description: HAR 导入测试
+ *
+ * Copyright (c) 2026 by kamalyes, All Rights Reserved.
+ */
+package config
+
+import (
+	"testing"
+
+	"github.com/kamalyes/go-stress/logger"
+	"github.com/stretchr/testify/assert"
+)
+
+const testHAR = `{
+  "log": {
+    "entries": [
+      {
+        "_resourceType": "document",
+        "request": {
+          "method": "GET",
+          "url": "https://example.com/",
+          "headers": [{"name": ":authority", "value": "example.com"}, {"name": "Accept", "value": "text/html"}]
+        },
+        "response": {"content": {"mimeType": "text/html"}}
+      },
+      {
+        "request": {"method": "GET", "url": "https://example.com/static/app.js", "headers": []},
+        "response": {"content": {"mimeType": "application/javascript"}}
+      },
+      {
+        "request": {"method": "GET", "url": "https://cdn.example.com/logo", "headers": []},
+        "response": {"content": {"mimeType": "image/png"}}
+      },
+      {
+        "_resourceType": "xhr",
+        "request": {
+          "method": "post",
+          "url": "https://example.com/api/login",
+          "headers": [{"name": "Host", "value": "example.com"}, {"name": "Authorization", "value": "Bearer t"}],
+          "postData": {"mimeType": "application/json", "text": "{\"user\":\"a\"}"}
+        },
+        "response": {"content": {"mimeType": "application/json"}}
+      },
+      {
+        "request": {
+          "method": "POST",
+          "url": "https://example.com/api/login",
+          "headers": [],
+          "postData": {"mimeType": "application/x-www-form-urlencoded", "params": [{"name": "user", "value": "b"}]}
+        },
+        "response": {"content": {"mimeType": "application/json"}}
+      }
+    ]
+  }
+}`
+
+// 测试 HAR 导入的请求头、请求体、顺序和静态资源过滤
+func TestNewHARParser(t *testing.T) {
+	cfg, err := NewHARParser([]byte(testHAR), nil, logger.New())
+	assert.NoError(t, err)
+	assert.Equal(t, ProtocolHTTP, cfg.Protocol)
+	assert.Len(t, cfg.APIs, 3, "静态资源应被过滤")
+
+	home, login, login2 := cfg.APIs[0], cfg.APIs[1], cfg.APIs[2]
+	assert.Equal(t, "GET /", home.Name)
+	assert.Equal(t, map[string]string{"Accept": "text/html"}, home.Headers, "伪头应被丢弃")
+	assert.Empty(t, home.DependsOn)
+
+	assert.Equal(t, "POST /api/login", login.Name)
+	assert.Equal(t, "POST", login.Method)
+	assert.Equal(t, `{"user":"a"}`, login.Body)
+	assert.Equal(t, "Bearer t", login.Headers["Authorization"])
+	assert.Equal(t, "application/json", login.Headers["Content-Type"])
+	assert.NotContains(t, login.Headers, "Host")
+	assert.Empty(t, login.DependsOn, "默认不串联，请求失败不影响后续请求")
+
+	assert.Equal(t, "POST /api/login_2", login2.Name, "重名API应追加序号")
+	assert.Equal(t, "user=b", login2.Body)
+	assert.Empty(t, login2.DependsOn)
+}
+
+// 测试指定串联时按录制顺序通过 depends_on 串联
+func TestHARParserChain(t *testing.T) {
+	cfg, err := NewHARParser([]byte(testHAR), &ImportFilter{Chain: true}, logger.New())
+	assert.NoError(t, err)
+	assert.Len(t, cfg.APIs, 3)
+	assert.Empty(t, cfg.APIs[0].DependsOn)
+	assert.Equal(t, []string{"GET /"}, cfg.APIs[1].DependsOn, "应按录制顺序串联")
+	assert.Equal(t, []string{"POST /api/login"}, cfg.APIs[2].DependsOn)
+}
+
+// 测试导入过滤器
+func TestImportFilter(t *testing.T) {
+	// 保留静态资源
+	cfg, err := NewHARParser([]byte(testHAR), &ImportFilter{KeepStatic: true}, logger.New())
+	assert.NoError(t, err)
+	assert.Len(t, cfg.APIs, 5)
+
+	// 按URL正则包含/排除
+	cfg, err = NewHARParser([]byte(testHAR), &ImportFilter{
+		IncludePatterns: []string{`/api/`},
+		ExcludePatterns: []string{`login$`},
+	}, logger.New())
+	assert.Error(t, err, "全部过滤时应返回错误")
+	assert.Nil(t, cfg)
+
+	_, err = NewHARParser([]byte(testHAR), &ImportFilter{IncludePatterns: []string{`(`}}, logger.New())
+	assert.Error(t, err, "无效正则应返回错误")
+
+	assert.True(t, IsStaticAsset("https://a.com/x.CSS?v=1", ""))
+	assert.True(t, IsStaticAsset("https://a.com/logo", "image/svg+xml"))
+	assert.False(t, IsStaticAsset("https://a.com/api/users", "application/json"))
+}
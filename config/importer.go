@@ -0,0 +1,189 @@
+/*
+ * @Author: kamalyes 501893067@qq.com
+ * @Date: 2026-02-09 00:00:00
+ * @LastEditors: kamalyes 501893067@qq.com
+ * @LastEditTime: 2026-02-09 00:00:00
+ * @FilePath: \go-stress\config\importer.go
+ * @Description: 场景导入公共逻辑 - 静态资源过滤、API命名、请求头清理
+ *
+ * Copyright (c) 2026 by kamalyes, All Rights Reserved.
+ */
+package config
+
+import (
+	"fmt"
+	"net/url"
+	"path"
+	"regexp"
+	"strings"
+)
+
+// staticExtPattern 静态资源URL（按扩展名）
+var staticExtPattern = regexp.MustCompile(`(?i)\.(css|js|mjs|map|png|jpe?g|gif|bmp|svg|ico|webp|avif|woff2?|ttf|otf|eot|mp4|webm|mp3|wav)$`)
+
+// staticContentTypes 静态资源的 Content-Type 前缀
+var staticContentTypes = []string{
+	"text/css",
+	"text/javascript",
+	"application/javascript",
+	"application/x-javascript",
+	"image/",
+	"font/",
+	"application/font",
+	"application/x-font",
+	"video/",
+	"audio/",
+}
+
+// importSkipHeaders 导入时丢弃的请求头（由客户端自动生成或与连接相关）
+var importSkipHeaders = map[string]bool{
+	"host":              true,
+	"content-length":    true,
+	"connection":        true,
+	"keep-alive":        true,
+	"transfer-encoding": true,
+	"upgrade":           true,
+	"accept-encoding":   true, // 保留会导致响应被压缩，校验和提取无法直接读取
+}
+
+// ImportFilter 导入过滤器和导入选项
+type ImportFilter struct {
+	KeepStatic      bool     // 保留静态资源（css/js/图片/字体等）
+	IncludePatterns []string // 只保留URL匹配任一正则的请求（为空时不限制）
+	ExcludePatterns []string // 丢弃URL匹配任一正则的请求
+	Chain           bool     // 按导入顺序通过 depends_on 串联（前一个请求失败时跳过后续请求）
+
+	include []*regexp.Regexp
+	exclude []*regexp.Regexp
+}
+
+// compile 编译过滤正则
+func (f *ImportFilter) compile() error {
+	compile := func(patterns []string) ([]*regexp.Regexp, error) {
+		result := make([]*regexp.Regexp, 0, len(patterns))
+		for _, p := range patterns {
+			re, err := regexp.Compile(p)
+			if err != nil {
+				return nil, fmt.Errorf("无效的URL过滤正则 %q: %w", p, err)
+			}
+			result = append(result, re)
+		}
+		return result, nil
+	}
+
+	var err error
+	if f.include, err = compile(f.IncludePatterns); err != nil {
+		return err
+	}
+	f.exclude, err = compile(f.ExcludePatterns)
+	return err
+}
+
+// Allow 判断请求是否保留（contentType 为响应类型，可为空）
+func (f *ImportFilter) Allow(rawURL, contentType string) bool {
+	if !f.KeepStatic && IsStaticAsset(rawURL, contentType) {
+		return false
+	}
+	for _, re := range f.exclude {
+		if re.MatchString(rawURL) {
+			return false
+		}
+	}
+	if len(f.include) == 0 {
+		return true
+	}
+	for _, re := range f.include {
+		if re.MatchString(rawURL) {
+			return true
+		}
+	}
+	return false
+}
+
+// IsStaticAsset 按 Content-Type 或URL扩展名判断是否为静态资源
+func IsStaticAsset(rawURL, contentType string) bool {
+	contentType = strings.ToLower(strings.TrimSpace(contentType))
+	for _, prefix := range staticContentTypes {
+		if strings.HasPrefix(contentType, prefix) {
+			return true
+		}
+	}
+
+	p := rawURL
+	if u, err := url.Parse(rawURL); err == nil {
+		p = u.Path
+	}
+	return staticExtPattern.MatchString(p)
+}
+
+// newImportFilter 复制并编译过滤器（nil 表示默认过滤静态资源）
+func newImportFilter(filter *ImportFilter) (*ImportFilter, error) {
+	f := &ImportFilter{}
+	if filter != nil {
+		f.KeepStatic = filter.KeepStatic
+		f.IncludePatterns = filter.IncludePatterns
+		f.ExcludePatterns = filter.ExcludePatterns
+		f.Chain = filter.Chain
+	}
+	if err := f.compile(); err != nil {
+		return nil, err
+	}
+	return f, nil
+}
+
+// apiNamer 生成不重复的API名称
+type apiNamer struct {
+	used map[string]int
+}
+
+// newAPINamer 创建API命名器
+func newAPINamer() *apiNamer {
+	return &apiNamer{used: make(map[string]int)}
+}
+
+// name 返回唯一名称（重名时追加序号）
+func (n *apiNamer) name(base string) string {
+	base = strings.TrimSpace(base)
+	if base == "" {
+		base = "api"
+	}
+	n.used[base]++
+	if count := n.used[base]; count > 1 {
+		return fmt.Sprintf("%s_%d", base, count)
+	}
+	return base
+}
+
+// requestName 由方法和URL路径生成API名称，如 "GET /api/users"
+func requestName(method, rawURL string) string {
+	p := rawURL
+	if u, err := url.Parse(rawURL); err == nil {
+		p = u.Path
+	}
+	return fmt.Sprintf("%s %s", strings.ToUpper(method), path.Clean("/"+p))
+}
+
+// keepImportedHeader 是否保留导入的请求头（丢弃 HTTP/2 伪头和连接相关头）
+func keepImportedHeader(name string) bool {
+	return name != "" && !strings.HasPrefix(name, ":") && !importSkipHeaders[strings.ToLower(name)]
+}
+
+// chainAPIs 按导入顺序串联API（每轮按录制/集合顺序依次执行，前一个请求失败时跳过后续请求）
+func chainAPIs(apis []APIConfig) {
+	for i := 1; i < len(apis); i++ {
+		apis[i].DependsOn = []string{apis[i-1].Name}
+	}
+}
+
+// newImportedConfig 创建导入场景的基础配置（chain 为 true 时按导入顺序串联API）
+func newImportedConfig(apis []APIConfig, chain bool) *Config {
+	if chain {
+		chainAPIs(apis)
+	}
+	cfg := &Config{
+		Protocol: ProtocolHTTP,
+		APIs:     apis,
+	}
+	cfg.VarResolver = NewVariableResolver()
+	return cfg
+}
@@ -13,8 +13,12 @@
 import (
 	"encoding/json"
 	"fmt"
+	"net"
+	"net/url"
 	"os"
 	"path/filepath"
+	"slices"
+	"strings"
 
 	"github.com/kamalyes/go-toolbox/pkg/mathx"
 	"gopkg.in/yaml.v3"
@@ -86,6 +90,9 @@ func (l *Loader) processConfig(config *Config) (*Config, error) {
 			fmt.Printf("  [%d] %s: %s %s\n", i+1, api.Name, api.Method, api.URL)
 		}
 	}
+	for _, scenario := range config.Scenarios {
+		fmt.Printf("🎭 场景 [%s] 权重=%d，%d 个API\n", scenario.Name, scenario.Weight, len(scenario.APIs))
+	}
 
 	// 验证配置
 	if err := l.validate(config); err != nil {
@@ -95,16 +102,57 @@ func (l *Loader) processConfig(config *Config) (*Config, error) {
 	return config, nil
 }
 
-// mergeAPIsWithCommon 将公共配置合并到各个API配置中
+// mergeAPIsWithCommon 将公共配置合并到各个API配置中（含各场景的API）
 func (l *Loader) mergeAPIsWithCommon(config *Config) error {
-	// 如果没有定义APIs，则使用单个配置模式（向后兼容）
-	if len(config.APIs) == 0 {
-		return nil
+	// 没有定义APIs时不做处理，使用单个配置模式（向后兼容）
+	if err := mergeAPIs(config, config.APIs); err != nil {
+		return err
+	}
+
+	for i := range config.Scenarios {
+		scenario := &config.Scenarios[i]
+		scenario.Weight = mathx.IfNotZero(scenario.Weight, 1)
+		if err := mergeAPIs(config, scenario.APIs); err != nil {
+			return fmt.Errorf("场景 [%s] %w", scenario.Name, err)
+		}
+	}
+
+	for _, stage := range lifecycleStages(config) {
+		if err := mergeAPIs(config, stage.steps); err != nil {
+			return fmt.Errorf("%s %w", stage.name, err)
+		}
 	}
+	return nil
+}
+
+// lifecycleStage 一组生命周期步骤
+type lifecycleStage struct {
+	name  string
+	steps []APIConfig
+}
+
+// lifecycleStages 所有生命周期步骤（含各场景的 setup/teardown）
+func lifecycleStages(config *Config) []lifecycleStage {
+	stages := []lifecycleStage{
+		{name: "setup", steps: config.Setup},
+		{name: "teardown", steps: config.Teardown},
+		{name: "before_all", steps: config.BeforeAll},
+		{name: "after_all", steps: config.AfterAll},
+	}
+	for _, scenario := range config.Scenarios {
+		stages = append(stages,
+			lifecycleStage{name: fmt.Sprintf("场景 [%s] setup", scenario.Name), steps: scenario.Setup},
+			lifecycleStage{name: fmt.Sprintf("场景 [%s] teardown", scenario.Name), steps: scenario.Teardown},
+		)
+	}
+	return stages
+}
 
+// mergeAPIs 将公共配置合并到一组API配置中
+func mergeAPIs(config *Config, apis []APIConfig) error {
 	// 遍历每个API配置，合并公共配置
-	for i := range config.APIs {
-		api := &config.APIs[i]
+	for i := range apis {
+		api := &apis[i]
 
 		// 构建完整URL - 优先级：api.URL > api.Host+api.Path > config.Host+api.Path > config.URL
 		api.URL = mathx.IfEmpty(api.URL, buildAPIURL(api, config))
@@ -112,6 +160,18 @@ func (l *Loader) mergeAPIsWithCommon(config *Config) error {
 			return fmt.Errorf("第%d个API [%s] 的URL不能为空（需要URL或Host+Path）", i+1, api.Name)
 		}
 
+		// GraphQL 接口：补全操作名，未命名时以操作名作为统计分组，默认 POST JSON
+		if api.GraphQL != nil {
+			if err := api.GraphQL.Prepare(); err != nil {
+				return fmt.Errorf("第%d个API [%s] %w", i+1, api.Name, err)
+			}
+			api.Name = mathx.IfEmpty(api.Name, api.GraphQL.OperationName)
+			api.Method = mathx.IfEmpty(api.Method, "POST")
+			if _, ok := api.Headers["Content-Type"]; !ok && api.Method != "GET" {
+				api.Headers = mergeHeaders(map[string]string{"Content-Type": "application/json"}, api.Headers)
+			}
+		}
+
 		// 继承公共配置
 		api.Method = mathx.IfEmpty(api.Method, mathx.IfEmpty(config.Method, "GET"))
 		api.Body = mathx.IfEmpty(api.Body, config.Body)
@@ -119,6 +179,22 @@ func (l *Loader) mergeAPIsWithCommon(config *Config) error {
 		// 合并Headers（公共headers + API特定headers，API的优先）
 		api.Headers = mergeHeaders(config.Headers, api.Headers)
 
+		// gRPC 接口合并 metadata（grpc.metadata + API metadata，API的优先；API 配置了 grpc 时取其 metadata）
+		if api.Protocol == ProtocolGRPC || (api.Protocol == "" && config.Protocol == ProtocolGRPC) {
+			grpcCfg := config.GRPC
+			if api.GRPC != nil {
+				grpcCfg = api.GRPC
+			}
+			if grpcCfg != nil && len(grpcCfg.Metadata) > 0 {
+				api.Metadata = mergeHeaders(grpcCfg.Metadata, api.Metadata)
+			}
+		}
+
+		// 继承思考时间
+		if api.ThinkTime == nil {
+			api.ThinkTime = config.ThinkTime
+		}
+
 		// 继承Verify配置
 		if len(api.Verify) == 0 {
 			api.Verify = []VerifyConfig{*config.Verify}
@@ -167,8 +243,12 @@ func mergeHeaders(common, specific map[string]string) map[string]string {
 func (l *Loader) validate(config *Config) error {
 	fmt.Printf("🔍 验证配置: APIs数量=%d, config.URL=%s\n", len(config.APIs), config.URL)
 
-	// 如果定义了APIs，已经在mergeAPIsWithCommon中验证过了
-	if len(config.APIs) > 0 {
+	if err := validateScenarios(config); err != nil {
+		return err
+	}
+
+	// 如果定义了APIs或场景，已经在mergeAPIsWithCommon中验证过了
+	if len(config.APIs) > 0 || len(config.Scenarios) > 0 {
 		fmt.Printf("✅ 使用多API模式，跳过单URL验证\n")
 		// APIs配置已经通过merge验证
 		// 这里只需要验证基础配置
@@ -184,24 +264,304 @@ func (l *Loader) validate(config *Config) error {
 		return fmt.Errorf("并发数必须大于0")
 	}
 
-	if config.Requests == 0 && config.Duration == 0 {
+	if len(config.Stages) > 0 {
+		if err := validateStages(config.Stages); err != nil {
+			return err
+		}
+	} else if config.Requests == 0 && config.Duration == 0 {
 		return fmt.Errorf("请求数和持续时间至少要设置一个")
 	}
 
-	// 协议特定验证
+	if err := validateThresholds(config); err != nil {
+		return err
+	}
+
+	if err := validatePacing(config); err != nil {
+		return err
+	}
+
+	if err := validateLifecycle(config); err != nil {
+		return err
+	}
+
+	if err := validateDataSources(config.DataSources); err != nil {
+		return err
+	}
+
+	if err := validateHTTPConfig(config.HTTP); err != nil {
+		return err
+	}
+
+	if err := validateProtocol(config); err != nil {
+		return err
+	}
+
+	if err := validateAPIProtocols(config); err != nil {
+		return err
+	}
+
+	return nil
+}
+
+// validateProtocol 协议特定验证
+func validateProtocol(config *Config) error {
 	switch config.Protocol {
 	case ProtocolGRPC:
 		if config.GRPC == nil {
 			return fmt.Errorf("gRPC配置不能为空")
 		}
-		if !config.GRPC.UseReflection && config.GRPC.ProtoFile == "" {
-			return fmt.Errorf("未启用反射时必须指定proto文件")
+		if !config.GRPC.UseReflection && config.GRPC.ProtoFile == "" && config.GRPC.DescriptorSet == "" {
+			return fmt.Errorf("未启用反射时必须指定proto文件或描述符集")
 		}
 		if config.GRPC.Service == "" || config.GRPC.Method == "" {
 			return fmt.Errorf("gRPC服务名和方法名不能为空")
 		}
+	case ProtocolWebSocket:
+		if config.WebSocket != nil {
+			switch config.WebSocket.Mode {
+			case "", WebSocketModeRequest, WebSocketModeSession:
+			default:
+				return fmt.Errorf("不支持的WebSocket模式: %s (可选 request/session)", config.WebSocket.Mode)
+			}
+		}
+	case ProtocolTCP, ProtocolUDP:
+		if err := validateSocketConfig(config.Socket); err != nil {
+			return err
+		}
+	case ProtocolMQTT:
+		if mqtt := config.MQTT; mqtt != nil {
+			switch mqtt.Mode {
+			case "", MQTTModePublish:
+			case MQTTModeSubscribe:
+				if len(mqtt.Subscribe) == 0 {
+					return fmt.Errorf("MQTT订阅模式必须配置subscribe主题")
+				}
+			default:
+				return fmt.Errorf("不支持的MQTT模式: %s (可选 publish/subscribe)", mqtt.Mode)
+			}
+			if mqtt.QoS > 2 || mqtt.SubscribeQoS > 2 {
+				return fmt.Errorf("MQTT QoS 只能为 0/1/2")
+			}
+		}
+	}
+
+	return nil
+}
+
+// validateAPIProtocols 验证单独配置了协议的API（含生命周期步骤），按该API的客户端配置执行协议特定验证
+func validateAPIProtocols(config *Config) error {
+	apis := config.AllAPIs()
+	for _, stage := range lifecycleStages(config) {
+		apis = slices.Concat(apis, stage.steps)
+	}
+
+	for i := range apis {
+		api := &apis[i]
+		if api.Protocol == "" {
+			if api.hasProtocolSettings() {
+				return fmt.Errorf("API [%s] 配置了协议特定设置但未指定 protocol", api.Name)
+			}
+			continue
+		}
+		if !slices.Contains(SupportedProtocols, api.Protocol) {
+			return fmt.Errorf("API [%s] 不支持的协议: %s", api.Name, api.Protocol)
+		}
+		if err := validateProtocol(config.ProtocolConfig(api)); err != nil {
+			return fmt.Errorf("API [%s] %w", api.Name, err)
+		}
+	}
+	return nil
+}
+
+// validatePacing 验证思考时间、迭代节奏和控制流配置
+func validatePacing(config *Config) error {
+	if config.Pacing < 0 {
+		return fmt.Errorf("pacing 不能为负数")
+	}
+	if config.ThinkTime != nil {
+		if err := config.ThinkTime.Validate(); err != nil {
+			return err
+		}
+	}
+	for _, api := range config.AllAPIs() {
+		if err := api.ValidateFlow(); err != nil {
+			return fmt.Errorf("API [%s] %w", api.Name, err)
+		}
+		if api.ThinkTime == nil || api.ThinkTime == config.ThinkTime {
+			continue
+		}
+		if err := api.ThinkTime.Validate(); err != nil {
+			return fmt.Errorf("API [%s] %w", api.Name, err)
+		}
+	}
+	return nil
+}
+
+// validateLifecycle 验证生命周期步骤：按顺序执行，必须命名且不支持依赖和控制流
+func validateLifecycle(config *Config) error {
+	for _, stage := range lifecycleStages(config) {
+		for i, step := range stage.steps {
+			if step.Name == "" {
+				return fmt.Errorf("%s 第%d个步骤的名称不能为空", stage.name, i+1)
+			}
+			if len(step.DependsOn) > 0 || step.HasFlow() {
+				return fmt.Errorf("%s 步骤 [%s] 按顺序执行，不支持 depends_on、condition、loop 和 foreach", stage.name, step.Name)
+			}
+		}
+	}
+	return nil
+}
+
+// validateScenarios 验证场景配置：与 apis 互斥，名称唯一且非空
+func validateScenarios(config *Config) error {
+	if len(config.Scenarios) == 0 {
+		return nil
+	}
+	if len(config.APIs) > 0 {
+		return fmt.Errorf("scenarios 和 apis 不能同时配置")
+	}
+	names := make(map[string]bool, len(config.Scenarios))
+	for i, scenario := range config.Scenarios {
+		if scenario.Name == "" {
+			return fmt.Errorf("第%d个场景的名称不能为空", i+1)
+		}
+		if names[scenario.Name] {
+			return fmt.Errorf("场景名称重复: %s", scenario.Name)
+		}
+		names[scenario.Name] = true
+		if len(scenario.APIs) == 0 {
+			return fmt.Errorf("场景 [%s] 至少需要一个API", scenario.Name)
+		}
+		if scenario.Weight < 0 {
+			return fmt.Errorf("场景 [%s] 的权重不能为负数", scenario.Name)
+		}
+	}
+	return nil
+}
+
+// validateStages 验证阶段配置：所有阶段必须统一使用并发数或RPS作为目标
+func validateStages(stages []StageConfig) error {
+	useRPS := StagesUseRPS(stages)
+	for i, stage := range stages {
+		if stage.Duration <= 0 {
+			return fmt.Errorf("阶段[%d]持续时间必须大于0", i)
+		}
+		if useRPS && stage.Target > 0 {
+			return fmt.Errorf("阶段[%d]同时出现 target 和 target_rps，所有阶段须统一使用其中一种", i)
+		}
+	}
+	return nil
+}
+
+// validateThresholds 验证阈值表达式和作用的场景、API名称
+// 多场景模式下只指定API的阈值必须能唯一确定所属场景，并补全场景名称
+func validateThresholds(config *Config) error {
+	thresholds, err := ParseThresholds(config.Thresholds)
+	if err != nil {
+		return err
+	}
+	for i, t := range thresholds {
+		if len(config.Scenarios) == 0 {
+			if t.Scenario != "" {
+				return fmt.Errorf("阈值 %q 指定了场景 %s，但未配置 scenarios", t.Expr, t.Scenario)
+			}
+			if t.API != "" && len(config.APIs) > 0 && !hasAPIName(config.APIs, t.API) {
+				return fmt.Errorf("阈值 %q 引用了不存在的API: %s", t.Expr, t.API)
+			}
+			continue
+		}
+
+		if t.Scenario != "" {
+			idx := slices.IndexFunc(config.Scenarios, func(s ScenarioConfig) bool { return s.Name == t.Scenario })
+			if idx < 0 {
+				return fmt.Errorf("阈值 %q 引用了不存在的场景: %s", t.Expr, t.Scenario)
+			}
+			if t.API != "" && !hasAPIName(config.Scenarios[idx].APIs, t.API) {
+				return fmt.Errorf("阈值 %q 引用的API %s 不在场景 [%s] 中", t.Expr, t.API, t.Scenario)
+			}
+			continue
+		}
+		if t.API == "" {
+			continue
+		}
+
+		var owners []string
+		for _, scenario := range config.Scenarios {
+			if hasAPIName(scenario.APIs, t.API) {
+				owners = append(owners, scenario.Name)
+			}
+		}
+		switch len(owners) {
+		case 0:
+			return fmt.Errorf("阈值 %q 引用了不存在的API: %s", t.Expr, t.API)
+		case 1:
+			config.Thresholds[i].Scenario = owners[0]
+		default:
+			return fmt.Errorf("阈值 %q 引用的API %s 存在于多个场景 (%s)，请通过 scenario 指定", t.Expr, t.API, strings.Join(owners, ", "))
+		}
+	}
+	return nil
+}
+
+// hasAPIName 是否存在指定名称的API
+func hasAPIName(apis []APIConfig, name string) bool {
+	return slices.ContainsFunc(apis, func(api APIConfig) bool { return api.Name == name })
+}
+
+// validateHTTPConfig 验证HTTP传输层配置（代理、源IP、客户端证书）
+func validateHTTPConfig(cfg *HTTPConfig) error {
+	if cfg == nil {
+		return nil
+	}
+	if cfg.Proxy != "" {
+		if u, err := url.Parse(cfg.Proxy); err != nil || u.Host == "" {
+			return fmt.Errorf("无效的代理地址: %s", cfg.Proxy)
+		}
+	}
+	if cfg.LocalAddr != "" && net.ParseIP(cfg.LocalAddr) == nil {
+		return fmt.Errorf("无效的本地源IP: %s", cfg.LocalAddr)
 	}
+	if cfg.MaxRedirects < 0 {
+		return fmt.Errorf("max_redirects 不能为负数")
+	}
+	if tls := cfg.TLS; tls != nil && (tls.CertFile == "") != (tls.KeyFile == "") {
+		return fmt.Errorf("HTTP TLS 客户端证书和私钥必须同时配置")
+	}
+	return nil
+}
+
+// validateSocketConfig 验证TCP/UDP编码和分帧配置
+func validateSocketConfig(cfg *SocketConfig) error {
+	if cfg == nil {
+		return nil
+	}
+	switch cfg.Encoding {
+	case "", PayloadEncodingText, PayloadEncodingHex, PayloadEncodingBase64:
+	default:
+		return fmt.Errorf("不支持的报文编码: %s (可选 text/hex/base64)", cfg.Encoding)
+	}
+	switch cfg.Framing {
+	case "", FramingNone, FramingDelimiter, FramingLength, FramingFixed:
+		// 分帧参数（length_bytes、fixed_size、max_frame_size）由客户端创建分帧器时校验
+	default:
+		return fmt.Errorf("不支持的分帧方式: %s (可选 none/length/delimiter/fixed)", cfg.Framing)
+	}
+	return nil
+}
 
+// validateDataSources 验证数据源配置
+func validateDataSources(sources []DataSourceConfig) error {
+	for i := range sources {
+		ds := &sources[i]
+		if err := ds.Validate(); err != nil {
+			return err
+		}
+		if ds.File != "" && len(ds.Rows) == 0 {
+			if _, err := os.Stat(ds.File); err != nil {
+				return fmt.Errorf("数据源 [%s] 文件不可用: %w", ds.DisplayName(), err)
+			}
+		}
+	}
 	return nil
 }
 
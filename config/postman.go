@@ -0,0 +1,376 @@
+/*
+ * @Author: kamalyes 501893067@qq.com
+ * @Date: 2026-02-09 00:00:00
+ * @LastEditors: kamalyes 501893067@qq.com
+ * @LastEditTime: 2026-02-09 00:00:00
+ * @FilePath: \go-stress\config\postman.go
+ * @Description: Postman 导入 - 将 v2.1 集合转换为按顺序执行的多API配置
+ *
+ * Copyright (c) 2026 by kamalyes, All Rights Reserved.
+ */
+package config
+
+import (
+	"bytes"
+	"encoding/base64"
+	"encoding/json"
+	"fmt"
+	"mime/multipart"
+	"net/url"
+	"regexp"
+	"strings"
+
+	"github.com/kamalyes/go-logger"
+	"github.com/kamalyes/go-toolbox/pkg/mathx"
+)
+
+// postmanCollection Postman v2.1 集合（只保留导入需要的字段）
+type postmanCollection struct {
+	Info struct {
+		Name   string `json:"name"`
+		Schema string `json:"schema"`
+	} `json:"info"`
+	Item     []postmanItem     `json:"item"`
+	Variable []postmanVariable `json:"variable"`
+	Auth     *postmanAuth      `json:"auth"`
+}
+
+// postmanItem 请求或文件夹（文件夹包含子项）
+type postmanItem struct {
+	Name    string          `json:"name"`
+	Item    []postmanItem   `json:"item"`
+	Request *postmanRequest `json:"request"`
+	Auth    *postmanAuth    `json:"auth"`
+}
+
+// postmanRequest 请求定义
+type postmanRequest struct {
+	Method string       `json:"method"`
+	Header []postmanKV  `json:"header"`
+	URL    postmanURL   `json:"url"`
+	Body   *postmanBody `json:"body"`
+	Auth   *postmanAuth `json:"auth"`
+}
+
+// UnmarshalJSON 支持请求简写为URL字符串
+func (r *postmanRequest) UnmarshalJSON(data []byte) error {
+	var raw string
+	if err := json.Unmarshal(data, &raw); err == nil {
+		r.Method = "GET"
+		r.URL.Raw = raw
+		return nil
+	}
+	type plain postmanRequest
+	return json.Unmarshal(data, (*plain)(r))
+}
+
+// postmanURL 请求URL（字符串或对象）
+type postmanURL struct {
+	Raw string `json:"raw"`
+}
+
+// UnmarshalJSON 支持字符串和对象两种形式
+func (u *postmanURL) UnmarshalJSON(data []byte) error {
+	var raw string
+	if err := json.Unmarshal(data, &raw); err == nil {
+		u.Raw = raw
+		return nil
+	}
+	type plain postmanURL
+	return json.Unmarshal(data, (*plain)(u))
+}
+
+// postmanKV 键值对（请求头、表单字段）
+type postmanKV struct {
+	Key      string `json:"key"`
+	Value    string `json:"value"`
+	Type     string `json:"type"`
+	Disabled bool   `json:"disabled"`
+}
+
+// postmanVariable 集合变量
+type postmanVariable struct {
+	Key      string `json:"key"`
+	Value    any    `json:"value"`
+	Disabled bool   `json:"disabled"`
+}
+
+// postmanBody 请求体
+type postmanBody struct {
+	Mode       string      `json:"mode"`
+	Raw        string      `json:"raw"`
+	URLEncoded []postmanKV `json:"urlencoded"`
+	FormData   []postmanKV `json:"formdata"`
+	GraphQL    *struct {
+		Query     string `json:"query"`
+		Variables string `json:"variables"`
+	} `json:"graphql"`
+	Options struct {
+		Raw struct {
+			Language string `json:"language"`
+		} `json:"raw"`
+	} `json:"options"`
+}
+
+// postmanAuth 认证配置（支持 bearer/basic/apikey/noauth）
+type postmanAuth struct {
+	Type   string      `json:"type"`
+	Bearer []postmanKV `json:"bearer"`
+	Basic  []postmanKV `json:"basic"`
+	APIKey []postmanKV `json:"apikey"`
+}
+
+// postmanVarPattern Postman 变量引用 {{name}} / {{$dynamic}}
+var postmanVarPattern = regexp.MustCompile(`{{\s*([^{}]+?)\s*}}`)
+
+// postmanIdentPattern 可直接用 {{.name}} 访问的变量名
+var postmanIdentPattern = regexp.MustCompile(`^[A-Za-z_][A-Za-z0-9_]*$`)
+
+// postmanDynamicVars Postman 动态变量到模板函数的映射
+var postmanDynamicVars = map[string]string{
+	"$guid":            "{{uuid}}",
+	"$randomUUID":      "{{uuid}}",
+	"$timestamp":       "{{unix}}",
+	"$isoTimestamp":    `{{date "2006-01-02T15:04:05Z07:00"}}`,
+	"$randomInt":       "{{randomInt 0 1000}}",
+	"$randomEmail":     "{{randomEmail}}",
+	"$randomIP":        "{{randomIP}}",
+	"$randomColor":     "{{randomColor}}",
+	"$randomBoolean":   "{{randomBool}}",
+	"$randomUserAgent": "{{randomUserAgent}}",
+}
+
+// PostmanParser Postman 集合解析器
+type PostmanParser struct {
+	filter    *ImportFilter
+	namer     *apiNamer
+	variables map[string]any
+	unknown   map[string]bool // 无法转换的动态变量
+	logger    logger.ILogger
+}
+
+// NewPostmanParser 解析 Postman v2.1 集合并返回配置（集合变量映射到 Variables，filter 为 nil 时默认过滤静态资源）
+func NewPostmanParser(data []byte, filter *ImportFilter, log logger.ILogger) (*Config, error) {
+	f, err := newImportFilter(filter)
+	if err != nil {
+		return nil, err
+	}
+	parser := &PostmanParser{
+		filter:    f,
+		namer:     newAPINamer(),
+		variables: make(map[string]any),
+		unknown:   make(map[string]bool),
+		logger:    log,
+	}
+	return parser.parse(data)
+}
+
+// parse 解析集合
+func (p *PostmanParser) parse(data []byte) (*Config, error) {
+	var collection postmanCollection
+	if err := json.Unmarshal(data, &collection); err != nil {
+		return nil, fmt.Errorf("解析Postman集合失败: %w", err)
+	}
+	if collection.Info.Schema != "" && !strings.Contains(collection.Info.Schema, "v2.1") {
+		p.logger.Warnf("⚠️  Postman集合版本为 %s，仅保证支持 v2.1", collection.Info.Schema)
+	}
+
+	for _, v := range collection.Variable {
+		if !v.Disabled && v.Key != "" {
+			p.variables[v.Key] = v.Value
+		}
+	}
+
+	var apis []APIConfig
+	skipped := p.walk(collection.Item, "", collection.Auth, &apis)
+
+	for name := range p.unknown {
+		p.logger.Warnf("⚠️  Postman动态变量 {{%s}} 不支持，已原样保留", name)
+	}
+	p.logger.Infof("📄 Postman导入 [%s]: %d 个请求，过滤 %d 个，%d 个变量", collection.Info.Name, len(apis), skipped, len(p.variables))
+	if len(apis) == 0 {
+		return nil, fmt.Errorf("Postman集合中没有可用的请求")
+	}
+
+	cfg := newImportedConfig(apis, p.filter.Chain)
+	cfg.Variables = p.variables
+	cfg.VarResolver.SetVariables(p.variables)
+	return cfg, nil
+}
+
+// walk 按集合顺序遍历请求（文件夹名作为前缀，认证配置逐级继承），返回过滤掉的请求数
+func (p *PostmanParser) walk(items []postmanItem, prefix string, auth *postmanAuth, apis *[]APIConfig) int {
+	skipped := 0
+	for i := range items {
+		item := &items[i]
+		name := item.Name
+		if prefix != "" {
+			name = prefix + "/" + item.Name
+		}
+		itemAuth := auth
+		if item.Auth != nil {
+			itemAuth = item.Auth
+		}
+
+		if item.Request == nil {
+			skipped += p.walk(item.Item, name, itemAuth, apis)
+			continue
+		}
+
+		api := p.convertRequest(item.Request, itemAuth)
+		if !p.filter.Allow(api.URL, "") {
+			skipped++
+			continue
+		}
+		api.Name = p.namer.name(name)
+		*apis = append(*apis, api)
+	}
+	return skipped
+}
+
+// convertRequest 将 Postman 请求转换为API配置
+func (p *PostmanParser) convertRequest(req *postmanRequest, auth *postmanAuth) APIConfig {
+	api := APIConfig{
+		URL:     p.convertVars(req.URL.Raw),
+		Method:  strings.ToUpper(req.Method),
+		Headers: make(map[string]string),
+		Weight:  1,
+	}
+	if api.Method == "" {
+		api.Method = "GET"
+	}
+
+	for _, h := range req.Header {
+		if !h.Disabled && keepImportedHeader(h.Key) {
+			api.Headers[h.Key] = p.convertVars(h.Value)
+		}
+	}
+
+	if req.Auth != nil {
+		auth = req.Auth
+	}
+	p.applyAuth(&api, auth)
+
+	if req.Body != nil {
+		body, contentType := p.convertBody(req.Body)
+		api.Body = p.convertVars(body)
+		if contentType != "" && !hasHeader(api.Headers, "Content-Type") {
+			api.Headers["Content-Type"] = contentType
+		}
+	}
+	return api
+}
+
+// convertBody 转换请求体，返回请求体和默认 Content-Type
+func (p *PostmanParser) convertBody(body *postmanBody) (string, string) {
+	switch body.Mode {
+	case "raw":
+		switch body.Options.Raw.Language {
+		case "json":
+			return body.Raw, "application/json"
+		case "xml":
+			return body.Raw, "application/xml"
+		}
+		return body.Raw, ""
+	case "urlencoded":
+		form := url.Values{}
+		for _, kv := range body.URLEncoded {
+			if !kv.Disabled {
+				form.Add(kv.Key, kv.Value)
+			}
+		}
+		// 编码会转义 {{ }}，变量引用需要还原
+		return unescapeTemplateBraces(form.Encode()), "application/x-www-form-urlencoded"
+	case "formdata":
+		var buf bytes.Buffer
+		writer := multipart.NewWriter(&buf)
+		_ = writer.SetBoundary("go-stress-form-boundary")
+		for _, kv := range body.FormData {
+			if kv.Disabled {
+				continue
+			}
+			if kv.Type == "file" {
+				p.logger.Warnf("⚠️  Postman表单文件字段 [%s] 不支持，已忽略", kv.Key)
+				continue
+			}
+			_ = writer.WriteField(kv.Key, kv.Value)
+		}
+		_ = writer.Close()
+		return buf.String(), writer.FormDataContentType()
+	case "graphql":
+		if body.GraphQL == nil {
+			return "", ""
+		}
+		payload := map[string]any{"query": body.GraphQL.Query}
+		if vars := strings.TrimSpace(body.GraphQL.Variables); vars != "" {
+			payload["variables"] = json.RawMessage(vars)
+		}
+		data, err := json.Marshal(payload)
+		if err != nil {
+			p.logger.Warnf("⚠️  GraphQL变量不是有效的JSON: %v", err)
+			data, _ = json.Marshal(map[string]any{"query": body.GraphQL.Query})
+		}
+		return string(data), "application/json"
+	}
+	return "", ""
+}
+
+// applyAuth 将认证配置转换为请求头
+func (p *PostmanParser) applyAuth(api *APIConfig, auth *postmanAuth) {
+	if auth == nil {
+		return
+	}
+	get := func(kvs []postmanKV, key string) string {
+		for _, kv := range kvs {
+			if kv.Key == key {
+				return p.convertVars(kv.Value)
+			}
+		}
+		return ""
+	}
+
+	switch auth.Type {
+	case "bearer":
+		api.Headers["Authorization"] = "Bearer " + get(auth.Bearer, "token")
+	case "basic":
+		user, pass := get(auth.Basic, "username"), get(auth.Basic, "password")
+		if strings.Contains(user+pass, "{{") {
+			p.logger.Warnf("⚠️  Basic认证中包含变量，无法在导入时编码，请手动设置 Authorization 请求头")
+			return
+		}
+		api.Headers["Authorization"] = "Basic " + base64.StdEncoding.EncodeToString([]byte(user+":"+pass))
+	case "apikey":
+		if get(auth.APIKey, "in") == "query" {
+			sep := mathx.IF(strings.Contains(api.URL, "?"), "&", "?")
+			api.URL += sep + get(auth.APIKey, "key") + "=" + get(auth.APIKey, "value")
+			return
+		}
+		api.Headers[get(auth.APIKey, "key")] = get(auth.APIKey, "value")
+	}
+}
+
+// convertVars 将 Postman 变量引用转换为模板语法（{{name}} -> {{.name}}）
+func (p *PostmanParser) convertVars(s string) string {
+	if !strings.Contains(s, "{{") {
+		return s
+	}
+	return postmanVarPattern.ReplaceAllStringFunc(s, func(match string) string {
+		name := strings.TrimSpace(match[2 : len(match)-2])
+		if strings.HasPrefix(name, "$") {
+			if tmpl, ok := postmanDynamicVars[name]; ok {
+				return tmpl
+			}
+			p.unknown[name] = true
+			return match
+		}
+		if postmanIdentPattern.MatchString(name) {
+			return "{{." + name + "}}"
+		}
+		return fmt.Sprintf("{{index .Variables %q}}", name)
+	})
+}
+
+// unescapeTemplateBraces 还原被URL编码的模板括号
+func unescapeTemplateBraces(s string) string {
+	return strings.NewReplacer("%7B%7B", "{{", "%7D%7D", "}}").Replace(s)
+}
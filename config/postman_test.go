@@ -0,0 +1,90 @@
+/*
+ * @Author: kamalyes 501893067@qq.com
+ * @Date: 2026-02-09 00:00:00
+ * @LastEditors: kamalyes 501893067@qq.com
+ * @LastEditTime: 2026-02-09 00:00:00
+ * @FilePath: \go-stress\config\postman_test.go
+ * @Description: Postman 导入测试
+ *
+ * Copyright (c) 2026 by kamalyes, All Rights Reserved.
+ */
+package config
+
+import (
+	"testing"
+
+	"github.com/kamalyes/go-stress/logger"
+	"github.com/stretchr/testify/assert"
+)
+
+const testPostman = `{
+  "info": {"name": "demo", "schema": "https://schema.getpostman.com/json/collection/v2.1.0/collection.json"},
+  "auth": {"type": "bearer", "bearer": [{"key": "token", "value": "{{token}}"}]},
+  "variable": [
+    {"key": "base_url", "value": "https://api.example.com"},
+    {"key": "token", "value": "abc"},
+    {"key": "api-version", "value": "v2"}
+  ],
+  "item": [
+    {
+      "name": "auth",
+      "item": [
+        {
+          "name": "login",
+          "request": {
+            "method": "POST",
+            "header": [
+              {"key": "X-Trace", "value": "{{$guid}}"},
+              {"key": "X-Disabled", "value": "1", "disabled": true}
+            ],
+            "url": {"raw": "{{base_url}}/{{api-version}}/login", "host": ["{{base_url}}"]},
+            "body": {"mode": "raw", "raw": "{\"user\":\"{{user}}\"}", "options": {"raw": {"language": "json"}}}
+          }
+        }
+      ]
+    },
+    {
+      "name": "profile",
+      "request": {
+        "method": "PUT",
+        "url": "{{base_url}}/profile",
+        "auth": {"type": "noauth"},
+        "body": {"mode": "urlencoded", "urlencoded": [{"key": "name", "value": "{{name}}"}, {"key": "age", "value": "1"}]}
+      }
+    },
+    {"name": "logo", "request": "https://cdn.example.com/logo.png"}
+  ]
+}`
+
+// 测试 Postman 集合导入
+func TestNewPostmanParser(t *testing.T) {
+	cfg, err := NewPostmanParser([]byte(testPostman), nil, logger.New())
+	assert.NoError(t, err)
+	assert.Len(t, cfg.APIs, 2, "静态资源应被过滤")
+
+	// 集合变量映射到 Variables
+	assert.Equal(t, "https://api.example.com", cfg.Variables["base_url"])
+	assert.Equal(t, "abc", cfg.Variables["token"])
+
+	login := cfg.APIs[0]
+	assert.Equal(t, "auth/login", login.Name, "文件夹名应作为前缀")
+	assert.Equal(t, "POST", login.Method)
+	assert.Equal(t, `{{.base_url}}/{{index .Variables "api-version"}}/login`, login.URL)
+	assert.Equal(t, `{"user":"{{.user}}"}`, login.Body)
+	assert.Equal(t, "{{uuid}}", login.Headers["X-Trace"])
+	assert.NotContains(t, login.Headers, "X-Disabled")
+	assert.Equal(t, "application/json", login.Headers["Content-Type"])
+	assert.Equal(t, "Bearer {{.token}}", login.Headers["Authorization"], "应继承集合认证")
+
+	profile := cfg.APIs[1]
+	assert.Equal(t, "PUT", profile.Method)
+	assert.Equal(t, "age=1&name={{.name}}", profile.Body)
+	assert.Equal(t, "application/x-www-form-urlencoded", profile.Headers["Content-Type"])
+	assert.NotContains(t, profile.Headers, "Authorization", "noauth 应覆盖集合认证")
+	assert.Empty(t, profile.DependsOn, "默认不串联，请求失败不影响后续请求")
+
+	// 转换后的模板可以被变量解析器解析
+	url, err := cfg.VarResolver.Resolve(login.URL)
+	assert.NoError(t, err)
+	assert.Equal(t, "https://api.example.com/v2/login", url)
+}
@@ -0,0 +1,81 @@
+/*
+ * @Author: kamalyes 501893067@qq.com
+ * @Date: 2026-02-19 00:00:00
+ * @LastEditors: kamalyes 501893067@qq.com
+ * @LastEditTime: 2026-02-19 00:00:00
+ * @FilePath: \go-stress\config\think_time.go
+ * @Description: 思考时间配置 - 固定、均匀分布、正态分布和指数分布
+ *
+ * Copyright (c) 2026 by kamalyes, All Rights Reserved.
+ */
+package config
+
+import (
+	"fmt"
+	"math/rand/v2"
+	"time"
+)
+
+// ThinkTimeDistribution 思考时间分布
+type ThinkTimeDistribution string
+
+const (
+	ThinkTimeFixed       ThinkTimeDistribution = "fixed"       // 固定时长 duration（默认）
+	ThinkTimeUniform     ThinkTimeDistribution = "uniform"     // [min, max] 均匀分布
+	ThinkTimeNormal      ThinkTimeDistribution = "normal"      // 均值 duration、标准差 stddev 的正态分布
+	ThinkTimeExponential ThinkTimeDistribution = "exponential" // 均值 duration 的指数分布
+)
+
+// ThinkTimeConfig 思考时间配置（请求完成后、下一个请求前的空闲等待）
+// normal/exponential 的采样结果按 min/max 截断（max 为 0 表示不限制上限）
+type ThinkTimeConfig struct {
+	Distribution ThinkTimeDistribution `json:"distribution,omitempty" yaml:"distribution,omitempty"` // 分布：fixed(默认) | uniform | normal | exponential
+	Duration     time.Duration         `json:"duration,omitempty" yaml:"duration,omitempty"`         // 固定时长，或 normal/exponential 的均值
+	Min          time.Duration         `json:"min,omitempty" yaml:"min,omitempty"`                   // 下限
+	Max          time.Duration         `json:"max,omitempty" yaml:"max,omitempty"`                   // 上限
+	StdDev       time.Duration         `json:"stddev,omitempty" yaml:"stddev,omitempty"`             // normal 的标准差
+}
+
+// Validate 验证思考时间配置
+func (t *ThinkTimeConfig) Validate() error {
+	if t.Duration < 0 || t.Min < 0 || t.Max < 0 || t.StdDev < 0 {
+		return fmt.Errorf("思考时间不能为负数")
+	}
+	if t.Max > 0 && t.Max < t.Min {
+		return fmt.Errorf("思考时间 max 不能小于 min")
+	}
+	switch t.Distribution {
+	case "", ThinkTimeFixed:
+	case ThinkTimeUniform:
+		if t.Max == 0 {
+			return fmt.Errorf("uniform 思考时间必须设置 max")
+		}
+	case ThinkTimeNormal, ThinkTimeExponential:
+		if t.Duration == 0 {
+			return fmt.Errorf("%s 思考时间必须设置均值 duration", t.Distribution)
+		}
+	default:
+		return fmt.Errorf("不支持的思考时间分布: %s (可选 fixed/uniform/normal/exponential)", t.Distribution)
+	}
+	return nil
+}
+
+// Sample 按分布采样一次思考时间
+func (t *ThinkTimeConfig) Sample() time.Duration {
+	var d time.Duration
+	switch t.Distribution {
+	case ThinkTimeUniform:
+		d = t.Min + time.Duration(rand.Int64N(int64(t.Max-t.Min)+1))
+	case ThinkTimeNormal:
+		d = t.Duration + time.Duration(rand.NormFloat64()*float64(t.StdDev))
+	case ThinkTimeExponential:
+		d = time.Duration(rand.ExpFloat64() * float64(t.Duration))
+	default:
+		return t.Duration
+	}
+	d = max(d, t.Min)
+	if t.Max > 0 {
+		d = min(d, t.Max)
+	}
+	return d
+}
@@ -0,0 +1,74 @@
+/*
+ * @Author: kamalyes 501893067@qq.com
+ * @Date: 2026-02-19 00:00:00
+ * @LastEditors: kamalyes 501893067@qq.com
+ * @LastEditTime: 2026-02-19 00:00:00
+ * @FilePath: \go-stress\config\think_time_test.go
+ * @Description: 思考时间配置测试
+ *
+ * Copyright (c) 2026 by kamalyes, All Rights Reserved.
+ */
+package config
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+// 测试思考时间配置验证
+func TestThinkTimeValidate(t *testing.T) {
+	valid := []ThinkTimeConfig{
+		{Duration: time.Second},
+		{Distribution: ThinkTimeUniform, Min: time.Second, Max: 3 * time.Second},
+		{Distribution: ThinkTimeNormal, Duration: time.Second, StdDev: 200 * time.Millisecond},
+		{Distribution: ThinkTimeExponential, Duration: time.Second, Max: 5 * time.Second},
+	}
+	for _, cfg := range valid {
+		assert.NoError(t, cfg.Validate(), cfg.Distribution)
+	}
+
+	invalid := []ThinkTimeConfig{
+		{Duration: -time.Second},
+		{Distribution: ThinkTimeUniform, Min: time.Second},
+		{Distribution: ThinkTimeUniform, Min: 2 * time.Second, Max: time.Second},
+		{Distribution: ThinkTimeNormal, StdDev: time.Second},
+		{Distribution: "poisson", Duration: time.Second},
+	}
+	for _, cfg := range invalid {
+		assert.Error(t, cfg.Validate(), cfg.Distribution)
+	}
+}
+
+// 测试各分布的采样范围和均值
+func TestThinkTimeSample(t *testing.T) {
+	const n = 20000
+	mean := func(cfg *ThinkTimeConfig) time.Duration {
+		var total time.Duration
+		for i := 0; i < n; i++ {
+			d := cfg.Sample()
+			assert.GreaterOrEqual(t, d, cfg.Min)
+			if cfg.Max > 0 {
+				assert.LessOrEqual(t, d, cfg.Max)
+			}
+			total += d
+		}
+		return total / n
+	}
+
+	assert.Equal(t, time.Second, (&ThinkTimeConfig{Duration: time.Second}).Sample())
+	assert.InDelta(t, float64(2*time.Second), float64(mean(&ThinkTimeConfig{
+		Distribution: ThinkTimeUniform, Min: time.Second, Max: 3 * time.Second,
+	})), float64(50*time.Millisecond))
+	assert.InDelta(t, float64(time.Second), float64(mean(&ThinkTimeConfig{
+		Distribution: ThinkTimeNormal, Duration: time.Second, StdDev: 100 * time.Millisecond,
+	})), float64(20*time.Millisecond))
+	assert.InDelta(t, float64(time.Second), float64(mean(&ThinkTimeConfig{
+		Distribution: ThinkTimeExponential, Duration: time.Second,
+	})), float64(50*time.Millisecond))
+
+	// 截断到 [min, max]
+	mean(&ThinkTimeConfig{Distribution: ThinkTimeNormal, Duration: time.Second, StdDev: time.Second, Min: 500 * time.Millisecond, Max: 1500 * time.Millisecond})
+	mean(&ThinkTimeConfig{Distribution: ThinkTimeExponential, Duration: time.Second, Max: 2 * time.Second})
+}
@@ -0,0 +1,237 @@
+/*
+ * @Author: kamalyes 501893067@qq.com
+ * @Date: 2026-02-07 00:00:00
+ * @LastEditors: kamalyes 501893067@qq.com
+ * @LastEditTime: 2026-02-07 00:00:00
+ * @FilePath: \go-stress\config\threshold.go
+ * @Description: SLO 阈值配置 - 解析 "p95 < 300ms" 形式的阈值表达式
+ *
+ * Copyright (c) 2026 by kamalyes, All Rights Reserved.
+ */
+package config
+
+import (
+	"encoding/json"
+	"fmt"
+	"regexp"
+	"strconv"
+	"strings"
+	"time"
+
+	"gopkg.in/yaml.v3"
+)
+
+// ThresholdMetric 阈值指标
+type ThresholdMetric string
+
+const (
+	MetricAvg         ThresholdMetric = "avg"          // 平均延迟
+	MetricMin         ThresholdMetric = "min"          // 最小延迟
+	MetricMax         ThresholdMetric = "max"          // 最大延迟
+	MetricP50         ThresholdMetric = "p50"          // P50 延迟
+	MetricP90         ThresholdMetric = "p90"          // P90 延迟
+	MetricP95         ThresholdMetric = "p95"          // P95 延迟
+	MetricP99         ThresholdMetric = "p99"          // P99 延迟
+	MetricP999        ThresholdMetric = "p999"         // P99.9 延迟（仅全局）
+	MetricP9999       ThresholdMetric = "p9999"        // P99.99 延迟（仅全局）
+	MetricErrorRate   ThresholdMetric = "error_rate"   // 错误率（百分比）
+	MetricSuccessRate ThresholdMetric = "success_rate" // 成功率（百分比）
+	MetricQPS         ThresholdMetric = "qps"          // 吞吐
+	MetricRequests    ThresholdMetric = "requests"     // 总请求数
+	MetricFailed      ThresholdMetric = "failed"       // 失败请求数
+)
+
+// thresholdPercentiles 百分位指标对应的百分位值
+var thresholdPercentiles = map[ThresholdMetric]float64{
+	MetricP50:   50,
+	MetricP90:   90,
+	MetricP95:   95,
+	MetricP99:   99,
+	MetricP999:  99.9,
+	MetricP9999: 99.99,
+}
+
+// thresholdExprPattern 阈值表达式：指标 操作符 值
+var thresholdExprPattern = regexp.MustCompile(`^\s*([a-z0-9_]+)\s*(<=|>=|==|!=|<|>)\s*(\S+)\s*$`)
+
+// ThresholdConfig 阈值配置
+// 可直接写成表达式字符串（如 "p95 < 300ms"），也可写成对象以指定作用的场景、API和提前终止
+type ThresholdConfig struct {
+	Expr        string `json:"expr" yaml:"expr"`                                       // 阈值表达式，如 p95 < 300ms、error_rate < 1%、qps > 500
+	Scenario    string `json:"scenario,omitempty" yaml:"scenario,omitempty"`           // 作用的场景名称（多场景模式，只设置场景时按场景整体统计）
+	API         string `json:"api,omitempty" yaml:"api,omitempty"`                     // 作用的API名称（为空表示全局）
+	AbortOnFail bool   `json:"abort_on_fail,omitempty" yaml:"abort_on_fail,omitempty"` // 确定无法达标时立即终止压测
+}
+
+// UnmarshalYAML 支持字符串简写
+func (t *ThresholdConfig) UnmarshalYAML(value *yaml.Node) error {
+	if value.Kind == yaml.ScalarNode {
+		t.Expr = value.Value
+		return nil
+	}
+	type plain ThresholdConfig
+	return value.Decode((*plain)(t))
+}
+
+// UnmarshalJSON 支持字符串简写
+func (t *ThresholdConfig) UnmarshalJSON(data []byte) error {
+	var expr string
+	if err := json.Unmarshal(data, &expr); err == nil {
+		t.Expr = expr
+		return nil
+	}
+	type plain ThresholdConfig
+	return json.Unmarshal(data, (*plain)(t))
+}
+
+// Threshold 解析后的阈值
+type Threshold struct {
+	Metric      ThresholdMetric
+	Operator    string
+	Value       float64 // 延迟类为毫秒，比率类为百分比
+	Expr        string
+	Scenario    string
+	API         string
+	AbortOnFail bool
+}
+
+// ParseThreshold 解析阈值表达式
+func ParseThreshold(cfg ThresholdConfig) (*Threshold, error) {
+	matches := thresholdExprPattern.FindStringSubmatch(strings.ToLower(cfg.Expr))
+	if matches == nil {
+		return nil, fmt.Errorf("阈值表达式格式错误: %q（示例: p95 < 300ms）", cfg.Expr)
+	}
+
+	t := &Threshold{
+		Metric:      ThresholdMetric(matches[1]),
+		Operator:    matches[2],
+		Expr:        strings.TrimSpace(cfg.Expr),
+		Scenario:    cfg.Scenario,
+		API:         cfg.API,
+		AbortOnFail: cfg.AbortOnFail,
+	}
+	if (t.API != "" || t.Scenario != "") && (t.Metric == MetricP999 || t.Metric == MetricP9999) {
+		return nil, fmt.Errorf("阈值 %q: 按API或场景统计不支持 %s", cfg.Expr, t.Metric)
+	}
+
+	value, err := parseThresholdValue(t.Metric, matches[3])
+	if err != nil {
+		return nil, fmt.Errorf("阈值 %q: %w", cfg.Expr, err)
+	}
+	t.Value = value
+	return t, nil
+}
+
+// parseThresholdValue 按指标类型解析阈值
+func parseThresholdValue(metric ThresholdMetric, raw string) (float64, error) {
+	switch {
+	case metric.IsLatency():
+		// 延迟：支持 300ms/1.5s 等时长格式，纯数字按毫秒处理
+		if v, err := strconv.ParseFloat(raw, 64); err == nil {
+			return v, nil
+		}
+		d, err := time.ParseDuration(raw)
+		if err != nil {
+			return 0, fmt.Errorf("无效的时长: %s", raw)
+		}
+		return float64(d) / float64(time.Millisecond), nil
+	case metric.IsRate():
+		// 比率：支持 1% 或 1（均表示百分之一）
+		v, err := strconv.ParseFloat(strings.TrimSuffix(raw, "%"), 64)
+		if err != nil || v < 0 || v > 100 {
+			return 0, fmt.Errorf("无效的百分比: %s", raw)
+		}
+		return v, nil
+	case metric == MetricQPS || metric == MetricRequests || metric == MetricFailed:
+		v, err := strconv.ParseFloat(raw, 64)
+		if err != nil {
+			return 0, fmt.Errorf("无效的数值: %s", raw)
+		}
+		return v, nil
+	default:
+		return 0, fmt.Errorf("不支持的指标: %s", metric)
+	}
+}
+
+// IsLatency 是否为延迟类指标
+func (m ThresholdMetric) IsLatency() bool {
+	switch m {
+	case MetricAvg, MetricMin, MetricMax:
+		return true
+	}
+	_, ok := thresholdPercentiles[m]
+	return ok
+}
+
+// IsRate 是否为比率类指标
+func (m ThresholdMetric) IsRate() bool {
+	return m == MetricErrorRate || m == MetricSuccessRate
+}
+
+// Percentile 百分位指标对应的百分位值，非百分位指标返回 false
+func (m ThresholdMetric) Percentile() (float64, bool) {
+	p, ok := thresholdPercentiles[m]
+	return p, ok
+}
+
+// Passed 判断实际值是否达标
+func (t *Threshold) Passed(actual float64) bool {
+	switch t.Operator {
+	case "<":
+		return actual < t.Value
+	case "<=":
+		return actual <= t.Value
+	case ">":
+		return actual > t.Value
+	case ">=":
+		return actual >= t.Value
+	case "==":
+		return actual == t.Value
+	case "!=":
+		return actual != t.Value
+	}
+	return false
+}
+
+// Scope 阈值作用范围：全局、场景、API 或 场景/API
+func (t *Threshold) Scope() string {
+	switch {
+	case t.API != "":
+		return APIStatsKey(t.Scenario, t.API)
+	case t.Scenario != "":
+		return "场景 " + t.Scenario
+	}
+	return "全局"
+}
+
+// UpperBound 阈值是否为上限（实际值越小越好）
+func (t *Threshold) UpperBound() bool {
+	return t.Operator == "<" || t.Operator == "<="
+}
+
+// FormatValue 按指标类型格式化数值
+func (t *Threshold) FormatValue(v float64) string {
+	switch {
+	case t.Metric.IsLatency():
+		return fmt.Sprintf("%.2fms", v)
+	case t.Metric.IsRate():
+		return fmt.Sprintf("%.2f%%", v)
+	case t.Metric == MetricQPS:
+		return fmt.Sprintf("%.2f", v)
+	default:
+		return fmt.Sprintf("%.0f", v)
+	}
+}
+
+// ParseThresholds 解析全部阈值
+func ParseThresholds(cfgs []ThresholdConfig) ([]*Threshold, error) {
+	thresholds := make([]*Threshold, 0, len(cfgs))
+	for _, cfg := range cfgs {
+		t, err := ParseThreshold(cfg)
+		if err != nil {
+			return nil, err
+		}
+		thresholds = append(thresholds, t)
+	}
+	return thresholds, nil
+}
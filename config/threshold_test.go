@@ -0,0 +1,148 @@
+/*
+ * @Author: kamalyes 501893067@qq.com
+ * @Date: 2026-02-07 00:00:00
+ * @LastEditors: kamalyes 501893067@qq.com
+ * @LastEditTime: 2026-02-07 00:00:00
+ * @FilePath: \go-stress\config\threshold_test.go
+ * @Description: SLO 阈值解析测试
+ *
+ * Copyright (c) 2026 by kamalyes, All Rights Reserved.
+ */
+package config
+
+import (
+	"encoding/json"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"gopkg.in/yaml.v3"
+)
+
+// 测试阈值表达式解析
+func TestParseThreshold(t *testing.T) {
+	cases := []struct {
+		expr     string
+		metric   ThresholdMetric
+		operator string
+		value    float64
+	}{
+		{"p95 < 300ms", MetricP95, "<", 300},
+		{"P99<=1.5s", MetricP99, "<=", 1500},
+		{"avg < 20", MetricAvg, "<", 20},
+		{"error_rate < 1%", MetricErrorRate, "<", 1},
+		{"success_rate >= 99.5", MetricSuccessRate, ">=", 99.5},
+		{"qps > 500", MetricQPS, ">", 500},
+		{"failed == 0", MetricFailed, "==", 0},
+	}
+	for _, tc := range cases {
+		th, err := ParseThreshold(ThresholdConfig{Expr: tc.expr})
+		assert.NoError(t, err, tc.expr)
+		assert.Equal(t, tc.metric, th.Metric, tc.expr)
+		assert.Equal(t, tc.operator, th.Operator, tc.expr)
+		assert.InDelta(t, tc.value, th.Value, 1e-9, tc.expr)
+	}
+
+	for _, expr := range []string{"p95", "p95 < abc", "latency < 1s", "error_rate < 120%"} {
+		_, err := ParseThreshold(ThresholdConfig{Expr: expr})
+		assert.Error(t, err, expr)
+	}
+
+	// 按API统计不支持 P99.9
+	_, err := ParseThreshold(ThresholdConfig{Expr: "p999 < 1s", API: "login"})
+	assert.Error(t, err)
+}
+
+// 测试多场景模式下阈值的场景和API范围
+func TestValidateThresholdScopes(t *testing.T) {
+	load := func(thresholds string) (*Config, error) {
+		return NewLoader().LoadFromBytes([]byte(`
+concurrency: 1
+requests: 1
+scenarios:
+  - name: browser
+    apis:
+      - {name: login, url: http://localhost/login}
+      - {name: search, url: http://localhost/search}
+  - name: buyer
+    apis:
+      - {name: login, url: http://localhost/login}
+      - {name: pay, url: http://localhost/pay}
+thresholds:
+`+thresholds), "yaml")
+	}
+
+	// 只属于一个场景的API自动补全场景
+	cfg, err := load(`
+  - {expr: "p95 < 300ms", api: search}
+  - {expr: "p95 < 300ms", scenario: buyer, api: login}
+  - {expr: "error_rate < 1%", scenario: buyer}
+`)
+	assert.NoError(t, err)
+	assert.Equal(t, "browser", cfg.Thresholds[0].Scenario)
+	assert.Equal(t, "buyer", cfg.Thresholds[1].Scenario)
+
+	thresholds, err := ParseThresholds(cfg.Thresholds)
+	assert.NoError(t, err)
+	assert.Equal(t, "browser/search", thresholds[0].Scope())
+	assert.Equal(t, "buyer/login", thresholds[1].Scope())
+	assert.Equal(t, "场景 buyer", thresholds[2].Scope())
+
+	_, err = load(`  - {expr: "p95 < 300ms", api: login}`)
+	assert.ErrorContains(t, err, "存在于多个场景 (browser, buyer)")
+	_, err = load(`  - {expr: "p95 < 300ms", scenario: browser, api: pay}`)
+	assert.ErrorContains(t, err, "不在场景 [browser] 中")
+	_, err = load(`  - {expr: "p95 < 300ms", scenario: admin}`)
+	assert.ErrorContains(t, err, "不存在的场景: admin")
+	_, err = load(`  - {expr: "p999 < 1s", scenario: buyer}`)
+	assert.ErrorContains(t, err, "按API或场景统计不支持")
+
+	_, err = NewLoader().LoadFromBytes([]byte(`
+url: http://localhost
+concurrency: 1
+requests: 1
+thresholds:
+  - {expr: "p95 < 300ms", scenario: buyer}
+`), "yaml")
+	assert.ErrorContains(t, err, "未配置 scenarios")
+}
+
+// 测试阈值判定
+func TestThresholdPassed(t *testing.T) {
+	th, _ := ParseThreshold(ThresholdConfig{Expr: "p95 < 300ms"})
+	assert.True(t, th.Passed(299))
+	assert.False(t, th.Passed(300))
+	assert.True(t, th.UpperBound())
+	assert.Equal(t, "300.00ms", th.FormatValue(300))
+
+	th, _ = ParseThreshold(ThresholdConfig{Expr: "qps >= 100"})
+	assert.True(t, th.Passed(100))
+	assert.False(t, th.UpperBound())
+}
+
+// 测试字符串简写和对象两种配置形式
+func TestThresholdConfigUnmarshal(t *testing.T) {
+	var cfg struct {
+		Thresholds []ThresholdConfig `yaml:"thresholds" json:"thresholds"`
+	}
+
+	yamlData := `
+thresholds:
+  - "p95 < 300ms"
+  - expr: "max < 2s"
+    api: login
+    abort_on_fail: true
+`
+	assert.NoError(t, yaml.Unmarshal([]byte(yamlData), &cfg))
+	assert.Equal(t, []ThresholdConfig{
+		{Expr: "p95 < 300ms"},
+		{Expr: "max < 2s", API: "login", AbortOnFail: true},
+	}, cfg.Thresholds)
+
+	cfg.Thresholds = nil
+	jsonData := `{"thresholds": ["error_rate < 1%", {"expr": "qps > 10", "api": "search"}]}`
+	assert.NoError(t, json.Unmarshal([]byte(jsonData), &cfg))
+	assert.Equal(t, []ThresholdConfig{
+		{Expr: "error_rate < 1%"},
+		{Expr: "qps > 10", API: "search"},
+	}, cfg.Thresholds)
+}
@@ -407,6 +407,11 @@ func (v *VariableResolver) VariableCount() int {
 // 2. {{randomString 8}} 调用模板函数
 // 3. {{.Env.PATH}}, {{.Time.Unix}} 访问特殊命名空间
 func (v *VariableResolver) Resolve(input string) (string, error) {
+	return v.ResolveWith(input, nil)
+}
+
+// ResolveWith 变量解析方法，extra 中的数据在本次解析中展开到根级别（如数据源的 row）
+func (v *VariableResolver) ResolveWith(input string, extra map[string]any) (string, error) {
 	// 快速路径：如果不包含模板语法，直接返回（性能优化）
 	if len(input) == 0 || !contains(input, templateOpen) || !contains(input, templateClose) {
 		return input, nil
@@ -427,6 +432,9 @@ func (v *VariableResolver) Resolve(input string) (string, error) {
 			ctx[k] = val
 		}
 	}
+	for k, val := range extra {
+		ctx[k] = val
+	}
 
 	if err := tmpl.Execute(&buf, ctx); err != nil {
 		return "", fmt.Errorf("执行模板失败: %w", err)
@@ -76,4 +76,5 @@ type SlaveStats struct {
 	TotalQPS        float64          `json:"total_qps"`
 	StatusCodes     map[int]int64    `json:"status_codes"`
 	ErrorTypes      map[string]int64 `json:"error_types"`
+	Histogram       []byte           `json:"-"` // 本周期延迟直方图（statistics.Histogram 二进制编码）
 }
@@ -11,10 +11,10 @@
 package master
 
 import (
-	"sort"
 	"time"
 
 	"github.com/kamalyes/go-stress/distributed/common"
+	"github.com/kamalyes/go-stress/statistics"
 	"github.com/kamalyes/go-toolbox/pkg/mathx"
 	"github.com/kamalyes/go-toolbox/pkg/syncx"
 )
@@ -35,7 +35,8 @@ type TaskAggregation struct {
 	TotalRequests   int64
 	SuccessRequests int64
 	FailedRequests  int64
-	Latencies       []float64
+	TotalLatency    float64               // 累计延迟（毫秒），用于计算准确的平均延迟
+	Histogram       *statistics.Histogram // 所有 Slave 合并后的延迟直方图
 	StatusCodes     map[int]int64
 	ErrorTypes      map[string]int64
 }
@@ -63,7 +64,7 @@ func (da *DataAggregator) Add(stats *common.SlaveStats) {
 				SlaveStats:  make(map[string]*common.SlaveStats),
 				StatusCodes: make(map[int]int64),
 				ErrorTypes:  make(map[string]int64),
-				Latencies:   make([]float64, 0),
+				Histogram:   statistics.NewHistogram(),
 			}
 			da.taskData[taskID] = agg
 		}
@@ -77,10 +78,18 @@ func (da *DataAggregator) Add(stats *common.SlaveStats) {
 		agg.SuccessRequests += stats.SuccessRequests
 		agg.FailedRequests += stats.FailedRequests
 
+		agg.TotalLatency += stats.AvgLatency * float64(stats.TotalRequests)
+		da.mergeHistogram(agg, stats)
+
 		// 聚合状态码
 		for code, count := range stats.StatusCodes {
 			agg.StatusCodes[code] += count
 		}
+
+		// 聚合错误类型
+		for errType, count := range stats.ErrorTypes {
+			agg.ErrorTypes[errType] += count
+		}
 	})
 }
 
@@ -125,27 +134,22 @@ func (da *DataAggregator) buildAggregatedStats(agg *TaskAggregation) *common.Agg
 		stats.SuccessRate = float64(stats.SuccessRequests) / float64(stats.TotalRequests) * 100
 	}
 
-	// 收集所有延迟数据
-	latencies := make([]float64, 0)
 	totalQPS := 0.0
-
 	for _, slaveStats := range agg.SlaveStats {
-		latencies = append(latencies, slaveStats.AvgLatency)
-		latencies = append(latencies, slaveStats.P95Latency)
-		latencies = append(latencies, slaveStats.P99Latency)
 		totalQPS += slaveStats.QPS
 	}
 
-	// 计算延迟统计
-	if len(latencies) > 0 {
-		sort.Float64s(latencies)
-		stats.MinLatency = latencies[0]
-		stats.MaxLatency = latencies[len(latencies)-1]
-		stats.AvgLatency = mathx.Mean(latencies)
-		stats.P50Latency = mathx.Percentile(latencies, 50)
-		stats.P90Latency = mathx.Percentile(latencies, 90)
-		stats.P95Latency = mathx.Percentile(latencies, 95)
-		stats.P99Latency = mathx.Percentile(latencies, 99)
+	// 基于合并后的直方图计算延迟统计，百分位在全部样本上计算而非对各节点结果取平均
+	if hist := agg.Histogram; hist.Count() > 0 {
+		stats.MinLatency = durationToMs(hist.Min())
+		stats.MaxLatency = durationToMs(hist.Max())
+		stats.P50Latency = durationToMs(hist.Percentile(50))
+		stats.P90Latency = durationToMs(hist.Percentile(90))
+		stats.P95Latency = durationToMs(hist.Percentile(95))
+		stats.P99Latency = durationToMs(hist.Percentile(99))
+	}
+	if agg.TotalRequests > 0 {
+		stats.AvgLatency = agg.TotalLatency / float64(agg.TotalRequests)
 	}
 
 	stats.TotalQPS = totalQPS
@@ -153,6 +157,30 @@ func (da *DataAggregator) buildAggregatedStats(agg *TaskAggregation) *common.Agg
 	return stats
 }
 
+// mergeHistogram 合并 Slave 上报的延迟直方图
+// 未携带直方图的旧版本 Slave 按平均延迟近似记录，保证请求数与其他节点同权重
+func (da *DataAggregator) mergeHistogram(agg *TaskAggregation, stats *common.SlaveStats) {
+	if len(stats.Histogram) > 0 {
+		if hist, err := statistics.DecodeHistogram(stats.Histogram); err == nil {
+			agg.Histogram.Merge(hist)
+			return
+		}
+	}
+	if stats.TotalRequests > 0 {
+		agg.Histogram.RecordN(msToDuration(stats.AvgLatency), uint64(stats.TotalRequests))
+	}
+}
+
+// durationToMs 转换为毫秒（保留小数）
+func durationToMs(d time.Duration) float64 {
+	return float64(d) / float64(time.Millisecond)
+}
+
+// msToDuration 毫秒转换为 time.Duration
+func msToDuration(ms float64) time.Duration {
+	return time.Duration(ms * float64(time.Millisecond))
+}
+
 // Clear 清空指定任务的数据
 func (da *DataAggregator) Clear(taskID string) {
 	syncx.WithLock(da.mu, func() {
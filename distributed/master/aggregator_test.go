@@ -0,0 +1,74 @@
+/*
+ * @Author: kamalyes 501893067@qq.com
+ * @Date: 2026-02-05 00:00:00
+ * @LastEditors: kamalyes 501893067@qq.com
+ * @LastEditTime: 2026-02-05 00:00:00
+ * @FilePath: \go-stress\distributed\master\aggregator_test.go
+ * @Description: 数据聚合器测试
+ *
+ * Copyright (c) 2026 by kamalyes, All Rights Reserved.
+ */
+package master
+
+import (
+	"testing"
+	"time"
+
+	"github.com/kamalyes/go-stress/distributed/common"
+	"github.com/kamalyes/go-stress/statistics"
+	"github.com/stretchr/testify/assert"
+)
+
+// buildSlaveStats 构建带直方图的 Slave 统计数据
+func buildSlaveStats(t *testing.T, slaveID string, latency time.Duration, count int) *common.SlaveStats {
+	hist := statistics.NewHistogram()
+	hist.RecordN(latency, uint64(count))
+	data, err := hist.MarshalBinary()
+	assert.NoError(t, err)
+
+	return &common.SlaveStats{
+		TaskID:          "task-1",
+		SlaveID:         slaveID,
+		TotalRequests:   int64(count),
+		SuccessRequests: int64(count),
+		AvgLatency:      float64(latency.Milliseconds()),
+		P95Latency:      float64(latency.Milliseconds()),
+		P99Latency:      float64(latency.Milliseconds()),
+		ErrorTypes:      map[string]int64{"timeout": 1},
+		Histogram:       data,
+	}
+}
+
+// TestAggregatorMergesHistograms 测试按任务合并直方图后计算全局百分位
+func TestAggregatorMergesHistograms(t *testing.T) {
+	da := NewDataAggregator()
+
+	// 快节点 980 个 10ms 请求，慢节点 20 个 1s 请求
+	da.Add(buildSlaveStats(t, "fast", 10*time.Millisecond, 980))
+	da.Add(buildSlaveStats(t, "slow", time.Second, 20))
+
+	stats, ok := da.GetAggregation("task-1")
+	assert.True(t, ok)
+	assert.Equal(t, int64(1000), stats.TotalRequests)
+	assert.InDelta(t, 10, stats.MinLatency, 0.5)
+	assert.InDelta(t, 1000, stats.MaxLatency, 10)
+	assert.InDelta(t, 10, stats.P50Latency, 0.5)
+	assert.InDelta(t, 10, stats.P95Latency, 0.5)
+	assert.InDelta(t, 1000, stats.P99Latency, 10)
+	assert.InDelta(t, 29.8, stats.AvgLatency, 0.01)
+	assert.Equal(t, int64(2), stats.ErrorTypes["timeout"])
+}
+
+// TestAggregatorWithoutHistogram 测试未携带直方图时按平均延迟近似
+func TestAggregatorWithoutHistogram(t *testing.T) {
+	da := NewDataAggregator()
+
+	stats := buildSlaveStats(t, "legacy", 20*time.Millisecond, 100)
+	stats.Histogram = nil
+	da.Add(stats)
+
+	agg, ok := da.GetAggregation("task-1")
+	assert.True(t, ok)
+	assert.InDelta(t, 20, agg.P99Latency, 0.5)
+	assert.InDelta(t, 20, agg.AvgLatency, 0.01)
+}
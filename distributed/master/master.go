@@ -394,6 +394,14 @@ func (m *Master) processTaskWithSlaves(task *common.Task, slaves []*common.Slave
 		return
 	}
 
+	// 数据源按 Slave 分片，保证各 Slave 使用的数据互不重叠
+	if err := PartitionDataSources(subTasks); err != nil {
+		errMsg := errorx.WrapError("failed to partition data sources", err).Error()
+		m.logger.ErrorKV(errMsg, "task_id", task.ID)
+		m.taskQueue.MoveToFailed(task.ID, errMsg)
+		return
+	}
+
 	// 更新任务状态
 	task.AssignedSlaves = make([]string, len(slaves))
 	for i, slave := range slaves {
@@ -126,10 +126,14 @@ func (s *MasterServiceServer) ReportStats(stream pb.MasterService_ReportStatsSer
 			SuccessRequests: stats.SuccessRequests,
 			FailedRequests:  stats.FailedRequests,
 			AvgLatency:      stats.AvgLatency,
+			MinLatency:      stats.MinLatency,
+			MaxLatency:      stats.MaxLatency,
 			P95Latency:      stats.P95Latency,
 			P99Latency:      stats.P99Latency,
 			QPS:             stats.Qps,
 			StatusCodes:     convertStatusCodes(stats.StatusCodes),
+			ErrorTypes:      stats.ErrorTypes,
+			Histogram:       stats.LatencyHistogram,
 		}
 
 		// 计算成功率
@@ -11,8 +11,10 @@
 package master
 
 import (
+	"encoding/json"
 	"fmt"
 
+	"github.com/kamalyes/go-stress/config"
 	"github.com/kamalyes/go-stress/distributed/common"
 )
 
@@ -171,3 +173,55 @@ func GetSplitter(strategy common.SplitStrategy) TaskSplitter {
 		return NewEqualSplitter()
 	}
 }
+
+// PartitionDataSources 将数据源按 Slave 分片写入各子任务配置
+// Master 加载数据文件后把互不重叠的行内联到子任务中，Slave 无需访问原始文件；
+// sequential/random 数据源行数少于 Slave 数时每个 Slave 共用全部数据行
+func PartitionDataSources(subTasks []*common.SubTask) error {
+	if len(subTasks) == 0 {
+		return nil
+	}
+
+	var cfg config.Config
+	if err := json.Unmarshal(subTasks[0].Config, &cfg); err != nil {
+		return fmt.Errorf("failed to parse task config: %w", err)
+	}
+	if len(cfg.DataSources) == 0 {
+		return nil
+	}
+
+	// 加载全部数据源
+	sources := cfg.DataSources
+	allRows := make([][]map[string]string, len(sources))
+	for i := range sources {
+		rows, err := sources[i].LoadRows()
+		if err != nil {
+			return fmt.Errorf("failed to load data source %s: %w", sources[i].DisplayName(), err)
+		}
+		if len(rows) < len(subTasks) && sources[i].GetPolicy().Exclusive() {
+			return fmt.Errorf("data source %s has %d rows, fewer than %d slaves", sources[i].DisplayName(), len(rows), len(subTasks))
+		}
+		allRows[i] = rows
+	}
+
+	// 每个子任务写入各数据源的第 i 个分片
+	for i, subTask := range subTasks {
+		partitioned := make([]config.DataSourceConfig, len(sources))
+		for j, ds := range sources {
+			ds.File = ""
+			ds.Rows = allRows[j]
+			if len(allRows[j]) >= len(subTasks) {
+				ds.Rows = config.PartitionRows(allRows[j], i, len(subTasks))
+			}
+			partitioned[j] = ds
+		}
+		cfg.DataSources = partitioned
+
+		data, err := json.Marshal(&cfg)
+		if err != nil {
+			return fmt.Errorf("failed to marshal subtask config: %w", err)
+		}
+		subTask.Config = data
+	}
+	return nil
+}
@@ -0,0 +1,102 @@
+/*
+ * @Author: kamalyes 501893067@qq.com
+ * @Date: 2026-02-08 00:00:00
+ * @LastEditors: kamalyes 501893067@qq.com
+ * @LastEditTime: 2026-02-08 00:00:00
+ * @FilePath: \go-stress\distributed\master\splitter_test.go
+ * @Description: 任务分片测试
+ *
+ * Copyright (c) 2026 by kamalyes, All Rights Reserved.
+ */
+package master
+
+import (
+	"encoding/json"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/kamalyes/go-stress/config"
+	"github.com/kamalyes/go-stress/distributed/common"
+	"github.com/stretchr/testify/assert"
+)
+
+// TestPartitionDataSources 测试数据源按 Slave 分片后互不重叠
+func TestPartitionDataSources(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "users.csv")
+	assert.NoError(t, os.WriteFile(path, []byte("user_id\n1\n2\n3\n4\n5\n"), 0o644))
+
+	cfgData, err := json.Marshal(&config.Config{
+		Concurrency: 4,
+		URL:         "http://localhost",
+		DataSources: []config.DataSourceConfig{{Name: "users", File: path, Policy: config.DataPolicyUniqueOnce}},
+	})
+	assert.NoError(t, err)
+
+	task := &common.Task{ID: "task-1", TotalWorkers: 4, ConfigData: cfgData}
+	slaves := []*common.SlaveInfo{{ID: "s1"}, {ID: "s2"}}
+	subTasks, err := NewEqualSplitter().Split(task, slaves)
+	assert.NoError(t, err)
+	assert.NoError(t, PartitionDataSources(subTasks))
+
+	seen := make(map[string]bool)
+	for _, st := range subTasks {
+		var cfg config.Config
+		assert.NoError(t, json.Unmarshal(st.Config, &cfg))
+		assert.Len(t, cfg.DataSources, 1)
+		ds := cfg.DataSources[0]
+		assert.Empty(t, ds.File)
+		assert.Equal(t, config.DataPolicyUniqueOnce, ds.Policy)
+		for _, row := range ds.Rows {
+			assert.False(t, seen[row["user_id"]], "行 %s 被分配给多个 Slave", row["user_id"])
+			seen[row["user_id"]] = true
+		}
+	}
+	assert.Len(t, seen, 5)
+
+	// 数据行少于 Slave 数时拒绝分片
+	task.TotalWorkers = 8
+	manySlaves := make([]*common.SlaveInfo, 6)
+	for i := range manySlaves {
+		manySlaves[i] = &common.SlaveInfo{ID: string(rune('a' + i))}
+	}
+	subTasks, err = NewEqualSplitter().Split(task, manySlaves)
+	assert.NoError(t, err)
+	assert.Error(t, PartitionDataSources(subTasks))
+}
+
+// TestPartitionDataSourcesShared 测试可共用的数据源行数少于 Slave 数时每个 Slave 使用全部数据行
+func TestPartitionDataSourcesShared(t *testing.T) {
+	for _, policy := range []config.DataSourcePolicy{config.DataPolicySequential, config.DataPolicyRandom} {
+		cfgData, err := json.Marshal(&config.Config{
+			Concurrency: 4,
+			URL:         "http://localhost",
+			DataSources: []config.DataSourceConfig{{Name: "keywords", Policy: policy, Rows: []map[string]string{{"q": "go"}, {"q": "rust"}}}},
+		})
+		assert.NoError(t, err)
+
+		task := &common.Task{ID: "task-1", TotalWorkers: 3, ConfigData: cfgData}
+		slaves := []*common.SlaveInfo{{ID: "s1"}, {ID: "s2"}, {ID: "s3"}}
+		subTasks, err := NewEqualSplitter().Split(task, slaves)
+		assert.NoError(t, err)
+		assert.NoError(t, PartitionDataSources(subTasks), "策略 %s", policy)
+
+		for _, st := range subTasks {
+			var cfg config.Config
+			assert.NoError(t, json.Unmarshal(st.Config, &cfg))
+			assert.Len(t, cfg.DataSources[0].Rows, 2)
+		}
+	}
+
+	// unique_per_worker 不能共用
+	cfgData, err := json.Marshal(&config.Config{
+		Concurrency: 4,
+		URL:         "http://localhost",
+		DataSources: []config.DataSourceConfig{{Name: "accounts", Policy: config.DataPolicyUniquePerWorker, Rows: []map[string]string{{"u": "1"}}}},
+	})
+	assert.NoError(t, err)
+	task := &common.Task{ID: "task-2", TotalWorkers: 2, ConfigData: cfgData}
+	subTasks, err := NewEqualSplitter().Split(task, []*common.SlaveInfo{{ID: "s1"}, {ID: "s2"}})
+	assert.NoError(t, err)
+	assert.Error(t, PartitionDataSources(subTasks))
+}
@@ -938,23 +938,24 @@ func (x *SlaveStatus) GetTimestamp() int64 {
 // 统计数据 | EN Statistics Data
 // 从节点上报的压测任务实时统计数据 | EN Real-time statistics data of stress test task reported by slave node
 type StatsData struct {
-	state           protoimpl.MessageState `protogen:"open.v1"`
-	SlaveId         string                 `protobuf:"bytes,1,opt,name=slave_id,json=slaveId,proto3" json:"slave_id,omitempty"`                                                                                         // 从节点 ID | EN Slave node ID
-	TaskId          string                 `protobuf:"bytes,2,opt,name=task_id,json=taskId,proto3" json:"task_id,omitempty"`                                                                                            // 任务 ID | EN Task ID
-	Timestamp       int64                  `protobuf:"varint,3,opt,name=timestamp,proto3" json:"timestamp,omitempty"`                                                                                                   // 统计数据采集时间戳(毫秒) | EN Statistics collection timestamp (milliseconds)
-	TotalRequests   int64                  `protobuf:"varint,4,opt,name=total_requests,json=totalRequests,proto3" json:"total_requests,omitempty"`                                                                      // 累计请求总数 | EN Total cumulative requests
-	SuccessRequests int64                  `protobuf:"varint,5,opt,name=success_requests,json=successRequests,proto3" json:"success_requests,omitempty"`                                                                // 成功请求数 | EN Number of successful requests
-	FailedRequests  int64                  `protobuf:"varint,6,opt,name=failed_requests,json=failedRequests,proto3" json:"failed_requests,omitempty"`                                                                   // 失败请求数 | EN Number of failed requests
-	AvgLatency      float64                `protobuf:"fixed64,7,opt,name=avg_latency,json=avgLatency,proto3" json:"avg_latency,omitempty"`                                                                              // 平均响应延迟(毫秒) | EN Average response latency (milliseconds)
-	P95Latency      float64                `protobuf:"fixed64,8,opt,name=p95_latency,json=p95Latency,proto3" json:"p95_latency,omitempty"`                                                                              // P95 响应延迟（毫秒）：95%的请求延迟小于该值 | EN P95 response latency (milliseconds): 95% of requests have latency less than this value
-	P99Latency      float64                `protobuf:"fixed64,9,opt,name=p99_latency,json=p99Latency,proto3" json:"p99_latency,omitempty"`                                                                              // P99 响应延迟（毫秒）：99%的请求延迟小于该值 | EN P99 response latency (milliseconds): 99% of requests have latency less than this value
-	MinLatency      float64                `protobuf:"fixed64,10,opt,name=min_latency,json=minLatency,proto3" json:"min_latency,omitempty"`                                                                             // 最小响应延迟（毫秒） | EN Minimum response latency (milliseconds)
-	MaxLatency      float64                `protobuf:"fixed64,11,opt,name=max_latency,json=maxLatency,proto3" json:"max_latency,omitempty"`                                                                             // 最大响应延迟（毫秒） | EN Maximum response latency (milliseconds)
-	Qps             float64                `protobuf:"fixed64,12,opt,name=qps,proto3" json:"qps,omitempty"`                                                                                                             // 每秒请求数（Queries Per Second） | EN Queries Per Second
-	StatusCodes     map[string]int64       `protobuf:"bytes,13,rep,name=status_codes,json=statusCodes,proto3" json:"status_codes,omitempty" protobuf_key:"bytes,1,opt,name=key" protobuf_val:"varint,2,opt,name=value"` // HTTP 状态码/GRPC 错误码计数（如 "200": 1000, "500": 50） | EN HTTP status code/GRPC error code count (e.g., "200": 1000, "500": 50)
-	ErrorTypes      map[string]int64       `protobuf:"bytes,14,rep,name=error_types,json=errorTypes,proto3" json:"error_types,omitempty" protobuf_key:"bytes,1,opt,name=key" protobuf_val:"varint,2,opt,name=value"`    // 错误类型计数（如 "timeout": 20, "connection_error": 10） | EN Error type count (e.g., "timeout": 20, "connection_error": 10)
-	unknownFields   protoimpl.UnknownFields
-	sizeCache       protoimpl.SizeCache
+	state            protoimpl.MessageState `protogen:"open.v1"`
+	SlaveId          string                 `protobuf:"bytes,1,opt,name=slave_id,json=slaveId,proto3" json:"slave_id,omitempty"`                                                                                         // 从节点 ID | EN Slave node ID
+	TaskId           string                 `protobuf:"bytes,2,opt,name=task_id,json=taskId,proto3" json:"task_id,omitempty"`                                                                                            // 任务 ID | EN Task ID
+	Timestamp        int64                  `protobuf:"varint,3,opt,name=timestamp,proto3" json:"timestamp,omitempty"`                                                                                                   // 统计数据采集时间戳(毫秒) | EN Statistics collection timestamp (milliseconds)
+	TotalRequests    int64                  `protobuf:"varint,4,opt,name=total_requests,json=totalRequests,proto3" json:"total_requests,omitempty"`                                                                      // 累计请求总数 | EN Total cumulative requests
+	SuccessRequests  int64                  `protobuf:"varint,5,opt,name=success_requests,json=successRequests,proto3" json:"success_requests,omitempty"`                                                                // 成功请求数 | EN Number of successful requests
+	FailedRequests   int64                  `protobuf:"varint,6,opt,name=failed_requests,json=failedRequests,proto3" json:"failed_requests,omitempty"`                                                                   // 失败请求数 | EN Number of failed requests
+	AvgLatency       float64                `protobuf:"fixed64,7,opt,name=avg_latency,json=avgLatency,proto3" json:"avg_latency,omitempty"`                                                                              // 平均响应延迟(毫秒) | EN Average response latency (milliseconds)
+	P95Latency       float64                `protobuf:"fixed64,8,opt,name=p95_latency,json=p95Latency,proto3" json:"p95_latency,omitempty"`                                                                              // P95 响应延迟（毫秒）：95%的请求延迟小于该值 | EN P95 response latency (milliseconds): 95% of requests have latency less than this value
+	P99Latency       float64                `protobuf:"fixed64,9,opt,name=p99_latency,json=p99Latency,proto3" json:"p99_latency,omitempty"`                                                                              // P99 响应延迟（毫秒）：99%的请求延迟小于该值 | EN P99 response latency (milliseconds): 99% of requests have latency less than this value
+	MinLatency       float64                `protobuf:"fixed64,10,opt,name=min_latency,json=minLatency,proto3" json:"min_latency,omitempty"`                                                                             // 最小响应延迟（毫秒） | EN Minimum response latency (milliseconds)
+	MaxLatency       float64                `protobuf:"fixed64,11,opt,name=max_latency,json=maxLatency,proto3" json:"max_latency,omitempty"`                                                                             // 最大响应延迟（毫秒） | EN Maximum response latency (milliseconds)
+	Qps              float64                `protobuf:"fixed64,12,opt,name=qps,proto3" json:"qps,omitempty"`                                                                                                             // 每秒请求数（Queries Per Second） | EN Queries Per Second
+	StatusCodes      map[string]int64       `protobuf:"bytes,13,rep,name=status_codes,json=statusCodes,proto3" json:"status_codes,omitempty" protobuf_key:"bytes,1,opt,name=key" protobuf_val:"varint,2,opt,name=value"` // HTTP 状态码/GRPC 错误码计数（如 "200": 1000, "500": 50） | EN HTTP status code/GRPC error code count (e.g., "200": 1000, "500": 50)
+	ErrorTypes       map[string]int64       `protobuf:"bytes,14,rep,name=error_types,json=errorTypes,proto3" json:"error_types,omitempty" protobuf_key:"bytes,1,opt,name=key" protobuf_val:"varint,2,opt,name=value"`    // 错误类型计数（如 "timeout": 20, "connection_error": 10） | EN Error type count (e.g., "timeout": 20, "connection_error": 10)
+	LatencyHistogram []byte                 `protobuf:"bytes,15,opt,name=latency_histogram,json=latencyHistogram,proto3" json:"latency_histogram,omitempty"`                                                             // 本次上报周期的延迟直方图（HDR 编码），主节点按任务合并后计算全局百分位 | EN Latency histogram of this report interval (HDR encoded), merged per task by master to compute global percentiles
+	unknownFields    protoimpl.UnknownFields
+	sizeCache        protoimpl.SizeCache
 }
 
 func (x *StatsData) Reset() {
@@ -1085,6 +1086,13 @@ func (x *StatsData) GetErrorTypes() map[string]int64 {
 	return nil
 }
 
+func (x *StatsData) GetLatencyHistogram() []byte {
+	if x != nil {
+		return x.LatencyHistogram
+	}
+	return nil
+}
+
 // 上报响应 | EN Report Response
 // 主节点对统计数据上报的响应 | EN Master node's response to statistics reporting
 type ReportResponse struct {
@@ -1937,7 +1945,7 @@ func (x *DetailsResponse) GetSkippedCount() int64 {
 	"\fmemory_usage\x18\x05 \x01(\x01R\vmemoryUsage\x12'\n" +
 	"\x0frunning_workers\x18\x06 \x01(\x03R\x0erunningWorkers\x12%\n" +
 	"\x0etotal_requests\x18\a \x01(\x03R\rtotalRequests\x12\x1c\n" +
-	"\ttimestamp\x18\b \x01(\x03R\ttimestamp\"\x99\x05\n" +
+	"\ttimestamp\x18\b \x01(\x03R\ttimestamp\"\xc6\x05\n" +
 	"\tStatsData\x12\x19\n" +
 	"\bslave_id\x18\x01 \x01(\tR\aslaveId\x12\x17\n" +
 	"\atask_id\x18\x02 \x01(\tR\x06taskId\x12\x1c\n" +
@@ -1959,7 +1967,8 @@ func (x *DetailsResponse) GetSkippedCount() int64 {
 	"\x03qps\x18\f \x01(\x01R\x03qps\x12E\n" +
 	"\fstatus_codes\x18\r \x03(\v2\".stress.StatsData.StatusCodesEntryR\vstatusCodes\x12B\n" +
 	"\verror_types\x18\x0e \x03(\v2!.stress.StatsData.ErrorTypesEntryR\n" +
-	"errorTypes\x1a>\n" +
+	"errorTypes\x12+\n" +
+	"\x11latency_histogram\x18\x0f \x01(\fR\x10latencyHistogram\x1a>\n" +
 	"\x10StatusCodesEntry\x12\x10\n" +
 	"\x03key\x18\x01 \x01(\tR\x03key\x12\x14\n" +
 	"\x05value\x18\x02 \x01(\x03R\x05value:\x028\x01\x1a=\n" +
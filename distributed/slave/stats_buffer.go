@@ -13,14 +13,13 @@
 import (
 	"context"
 	"fmt"
-	"sort"
 	"time"
 
 	"github.com/kamalyes/go-logger"
 	"github.com/kamalyes/go-stress/distributed/common"
 	pb "github.com/kamalyes/go-stress/distributed/proto"
+	"github.com/kamalyes/go-stress/statistics"
 	"github.com/kamalyes/go-stress/types"
-	"github.com/kamalyes/go-toolbox/pkg/mathx"
 	"github.com/kamalyes/go-toolbox/pkg/syncx"
 )
 
@@ -148,17 +147,21 @@ func (sb *StatsBuffer) sendToMaster(stats *common.SlaveStats) error {
 
 	// 构建 proto 消息
 	statsData := &pb.StatsData{
-		SlaveId:         sb.slaveID,
-		TaskId:          sb.taskID,
-		Timestamp:       time.Now().Unix(),
-		TotalRequests:   stats.TotalRequests,
-		SuccessRequests: stats.SuccessRequests,
-		FailedRequests:  stats.FailedRequests,
-		AvgLatency:      stats.AvgLatency,
-		P95Latency:      stats.P95Latency,
-		P99Latency:      stats.P99Latency,
-		Qps:             stats.QPS,
-		StatusCodes:     statusCodes,
+		SlaveId:          sb.slaveID,
+		TaskId:           sb.taskID,
+		Timestamp:        time.Now().Unix(),
+		TotalRequests:    stats.TotalRequests,
+		SuccessRequests:  stats.SuccessRequests,
+		FailedRequests:   stats.FailedRequests,
+		AvgLatency:       stats.AvgLatency,
+		MinLatency:       stats.MinLatency,
+		MaxLatency:       stats.MaxLatency,
+		P95Latency:       stats.P95Latency,
+		P99Latency:       stats.P99Latency,
+		Qps:              stats.QPS,
+		StatusCodes:      statusCodes,
+		ErrorTypes:       stats.ErrorTypes,
+		LatencyHistogram: stats.Histogram,
 	}
 
 	// 发送数据
@@ -206,7 +209,9 @@ func (sb *StatsBuffer) aggregate(results []*types.RequestResult) *common.SlaveSt
 		return stats
 	}
 
-	latencies := make([]float64, 0, len(results))
+	// 使用直方图记录延迟：主节点合并各节点直方图后可得到准确的全局百分位
+	hist := statistics.NewHistogram()
+	var totalLatency time.Duration
 
 	for _, r := range results {
 		stats.TotalRequests++
@@ -219,20 +224,21 @@ func (sb *StatsBuffer) aggregate(results []*types.RequestResult) *common.SlaveSt
 				stats.ErrorTypes[r.Error.Error()]++
 			}
 		}
-		latencies = append(latencies, float64(r.Duration.Milliseconds()))
+		hist.Record(r.Duration)
+		totalLatency += r.Duration
 		stats.StatusCodes[r.StatusCode]++
 	}
 
 	// 计算延迟统计
-	if len(latencies) > 0 {
-		sort.Float64s(latencies)
-		stats.MinLatency = latencies[0]
-		stats.MaxLatency = latencies[len(latencies)-1]
-		stats.AvgLatency = mathx.Mean(latencies)
-		stats.P50Latency = mathx.Percentile(latencies, 50)
-		stats.P90Latency = mathx.Percentile(latencies, 90)
-		stats.P95Latency = mathx.Percentile(latencies, 95)
-		stats.P99Latency = mathx.Percentile(latencies, 99)
+	stats.MinLatency = durationToMs(hist.Min())
+	stats.MaxLatency = durationToMs(hist.Max())
+	stats.AvgLatency = durationToMs(totalLatency / time.Duration(len(results)))
+	stats.P50Latency = durationToMs(hist.Percentile(50))
+	stats.P90Latency = durationToMs(hist.Percentile(90))
+	stats.P95Latency = durationToMs(hist.Percentile(95))
+	stats.P99Latency = durationToMs(hist.Percentile(99))
+	if data, err := hist.MarshalBinary(); err == nil {
+		stats.Histogram = data
 	}
 
 	// 计算成功率和 QPS
@@ -244,3 +250,8 @@ func (sb *StatsBuffer) aggregate(results []*types.RequestResult) *common.SlaveSt
 
 	return stats
 }
+
+// durationToMs 转换为毫秒（保留小数）
+func durationToMs(d time.Duration) float64 {
+	return float64(d) / float64(time.Millisecond)
+}
@@ -43,7 +43,10 @@
 	VerificationResult = types.VerificationResult
 
 	// 配置相关 - 直接使用 config.APIConfig，不再转换
-	APIConfig = config.APIConfig
+	APIConfig       = config.APIConfig
+	StageConfig     = config.StageConfig
+	GraphQLConfig   = config.GraphQLConfig
+	ThinkTimeConfig = config.ThinkTimeConfig
 )
 
 // 常量别名
@@ -51,6 +54,12 @@
 	ProtocolHTTP      = types.ProtocolHTTP
 	ProtocolGRPC      = types.ProtocolGRPC
 	ProtocolWebSocket = types.ProtocolWebSocket
+	ProtocolSSE       = types.ProtocolSSE
+	ProtocolTCP       = types.ProtocolTCP
+	ProtocolUDP       = types.ProtocolUDP
+	ProtocolMQTT      = types.ProtocolMQTT
+
+	GRPCMetadataKey = types.GRPCMetadataKey
 
 	ExtractorTypeJSONPath   = types.ExtractorTypeJSONPath
 	ExtractorTypeRegex      = types.ExtractorTypeRegex
@@ -61,3 +70,8 @@
 	StorageModeSQLite = types.StorageModeSQLite
 	StorageModeBadger = types.StorageModeBadger
 )
+
+// 函数别名
+var (
+	StatusCodeLabel = types.StatusCodeLabel
+)
@@ -0,0 +1,77 @@
+/*
+ * @Author: kamalyes 501893067@qq.com
+ * @Date: 2026-02-23 00:00:00
+ * @LastEditors: kamalyes 501893067@qq.com
+ * @LastEditTime: 2026-02-23 00:00:00
+ * @FilePath: \go-stress\executor\api_clients.go
+ * @Description: 混合协议 - worker 按API配置的协议懒加载客户端
+ *
+ * Copyright (c) 2026 by kamalyes, All Rights Reserved.
+ */
+package executor
+
+import (
+	"context"
+	"fmt"
+)
+
+// APIClientFactory 为单独配置了协议的API创建客户端
+type APIClientFactory func(api *APIConfig) (Client, error)
+
+// apiClient 按API协议创建的客户端及以其为最底层的处理器
+type apiClient struct {
+	client  Client
+	handler RequestHandler
+	url     string // 建立连接时渲染后的地址
+}
+
+// handlerFor 返回发送该API使用的处理器：未单独配置协议时使用主客户端的处理器，
+// 否则按未渲染配置的复用键懒加载客户端（首次使用时按渲染后的配置创建并建立连接，worker 退出时关闭）
+// WebSocket/gRPC 的连接绑定地址，渲染后的地址变化时（如按迭代更换的 token）关闭旧连接并按新地址重建
+func (w *Worker) handlerFor(ctx context.Context, key string, api *APIConfig) (RequestHandler, error) {
+	if key == "" {
+		return w.handler, nil
+	}
+	if c, ok := w.apiClients[key]; ok {
+		if !api.BindsAddress() || c.url == api.URL {
+			return c.handler, nil
+		}
+		if err := c.client.Close(); err != nil {
+			w.logger.Warnf("⚠️  Worker %d: 关闭客户端 [%s] 失败: %v", w.id, key, err)
+		}
+		delete(w.apiClients, key)
+	}
+	if w.apiClientFactory == nil {
+		return nil, fmt.Errorf("未配置 %s 协议的客户端工厂", api.Protocol)
+	}
+
+	client, err := w.apiClientFactory(api)
+	if err != nil {
+		return nil, fmt.Errorf("创建 %s 客户端失败: %w", api.Protocol, err)
+	}
+	if err := client.Connect(ctx); err != nil {
+		return nil, fmt.Errorf("%s 连接失败: %w", api.Protocol, err)
+	}
+	handler := w.newHandler(client)
+	w.apiClients[key] = apiClient{client: client, handler: handler, url: api.URL}
+	return handler, nil
+}
+
+// send 通过中间件链发送请求，最终由该API对应协议的客户端发送（key 为未渲染配置的客户端复用键）
+func (w *Worker) send(ctx context.Context, key string, apiCfg *APIConfig, req *Request) (*Response, error) {
+	handler, err := w.handlerFor(ctx, key, apiCfg)
+	if err != nil {
+		return nil, err
+	}
+	return handler(ctx, req)
+}
+
+// closeAPIClients 关闭按协议创建的客户端
+func (w *Worker) closeAPIClients() {
+	for key, c := range w.apiClients {
+		if err := c.client.Close(); err != nil {
+			w.logger.Warnf("⚠️  Worker %d: 关闭客户端 [%s] 失败: %v", w.id, key, err)
+		}
+		delete(w.apiClients, key)
+	}
+}
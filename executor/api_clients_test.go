@@ -0,0 +1,203 @@
+/*
+ * @Author: kamalyes 501893067@qq.com
+ * @Date: 2026-02-23 00:00:00
+ * @LastEditors: kamalyes 501893067@qq.com
+ * @LastEditTime: 2026-02-23 00:00:00
+ * @FilePath: \go-stress\executor\api_clients_test.go
+ * @Description: 混合协议测试
+ *
+ * Copyright (c) 2026 by kamalyes, All Rights Reserved.
+ */
+package executor
+
+import (
+	"context"
+	"fmt"
+	"testing"
+
+	"github.com/kamalyes/go-stress/config"
+	"github.com/kamalyes/go-stress/logger"
+	"github.com/kamalyes/go-stress/statistics"
+	"github.com/kamalyes/go-stress/storage"
+	"github.com/stretchr/testify/assert"
+)
+
+// protocolClient 记录发送内容的客户端
+type protocolClient struct {
+	protocol ProtocolType
+	log      *[]string
+	closed   *int
+}
+
+func (c protocolClient) Connect(ctx context.Context) error { return nil }
+func (c protocolClient) Send(ctx context.Context, req *Request) (*Response, error) {
+	*c.log = append(*c.log, string(c.protocol)+" "+req.URL+" "+req.Body)
+	return &Response{StatusCode: 200, Protocol: c.protocol, Body: []byte(`{"token":"t-1","room":"r-9"}`)}, nil
+}
+func (c protocolClient) Close() error {
+	*c.closed++
+	return nil
+}
+func (c protocolClient) Type() ProtocolType { return c.protocol }
+
+// 测试依赖链中各API按配置的协议发送，客户端每个 worker 懒加载一次，变量跨协议传递
+func TestWorkerMixedProtocols(t *testing.T) {
+	collector := statistics.NewCollector(storage.NewMemoryStorage("test", logger.Default), logger.Default)
+	defer collector.Close()
+
+	var (
+		sent    []string
+		created []string
+		closed  int
+	)
+	factory := func(api *APIConfig) (Client, error) {
+		created = append(created, string(api.Protocol)+" "+api.URL)
+		return protocolClient{protocol: api.Protocol, log: &sent, closed: &closed}, nil
+	}
+
+	cfg := &config.Config{APIs: []config.APIConfig{
+		{Name: "login", URL: "http://localhost/login", Method: "POST",
+			Extractors: []config.ExtractorConfig{{Name: "token", JSONPath: "$.token"}}},
+		{Name: "chat", URL: "ws://localhost/chat?token={{.login.token}}", Protocol: ProtocolWebSocket,
+			Body: `{"say":"hi"}`, DependsOn: []string{"login"},
+			Extractors: []config.ExtractorConfig{{Name: "room", JSONPath: "$.room"}}},
+		{Name: "history", URL: "localhost:50051", Protocol: ProtocolGRPC,
+			GRPC: &config.GRPCConfig{Service: "chat.History", Method: "List"},
+			Body: `{"room":"{{.chat.room}}"}`, DependsOn: []string{"chat"}},
+	}}
+	cfg.SetLogger(logger.Default)
+	worker := NewWorker(WorkerConfig{
+		Client:           protocolClient{protocol: ProtocolHTTP, log: &sent, closed: &closed},
+		Collector:        collector,
+		ReqCount:         2,
+		APISelector:      CreateAPISelector(cfg),
+		APIClientFactory: factory,
+		Logger:           logger.Default,
+	}, config.NewVariableResolver())
+	assert.NoError(t, worker.Run(context.Background()))
+
+	iteration := []string{
+		"http http://localhost/login ",
+		`websocket ws://localhost/chat?token=t-1 {"say":"hi"}`,
+		`grpc localhost:50051 {"room":"r-9"}`,
+	}
+	assert.Equal(t, append(iteration, iteration...), sent)
+	assert.Equal(t, []string{
+		"websocket ws://localhost/chat?token=t-1",
+		"grpc localhost:50051",
+	}, created, "每种协议的客户端只创建一次（按首次使用时渲染的配置）")
+	assert.Equal(t, 3, closed, "主客户端和按协议创建的客户端在 worker 退出时关闭")
+	assert.Equal(t, uint64(6), collector.GetSnapshot().TotalRequests)
+}
+
+// 测试 gRPC metadata 按请求完成变量替换后随请求单独携带，不合并到请求头也不修改配置
+func TestWorkerGRPCMetadata(t *testing.T) {
+	collector := statistics.NewCollector(storage.NewMemoryStorage("test", logger.Default), logger.Default)
+	defer collector.Close()
+
+	var reqs []*Request
+	handler := func(ctx context.Context, req *Request) (*Response, error) {
+		reqs = append(reqs, req)
+		return &Response{Body: []byte(`{"token":"t-1"}`)}, nil
+	}
+
+	cfg := &config.Config{
+		Protocol: ProtocolGRPC,
+		URL:      "localhost:50051",
+		Headers:  map[string]string{"Content-Type": "application/json"},
+		GRPC: &config.GRPCConfig{Service: "s", Method: "Get",
+			Metadata: map[string]string{"authorization": "Bearer {{.login.token}}"}},
+	}
+	cfg.SetLogger(logger.Default)
+	selector := CreateAPISelector(cfg)
+	assert.NotContains(t, cfg.APIs[0].Headers, "authorization")
+
+	worker := NewWorker(WorkerConfig{
+		Client:      handlerClient(handler),
+		Collector:   collector,
+		ReqCount:    1,
+		APISelector: selector,
+		Hooks: &WorkerHooks{Setup: []config.APIConfig{{Name: "login", URL: "localhost:50051",
+			Extractors: []config.ExtractorConfig{{Name: "token", JSONPath: "$.token"}}}}},
+		Logger: logger.Default,
+	}, config.NewVariableResolver())
+	assert.NoError(t, worker.Run(context.Background()))
+
+	req := reqs[len(reqs)-1]
+	assert.Equal(t, map[string]string{"authorization": "Bearer t-1"}, req.Metadata[GRPCMetadataKey])
+	assert.Equal(t, map[string]string{"Content-Type": "application/json"}, req.Headers)
+	assert.Equal(t, "Bearer {{.login.token}}", cfg.APIs[0].Metadata["authorization"], "配置中的模板不被修改")
+}
+
+// boundClient 记录建立连接时的地址、实际发送地址和同时打开的连接数的客户端
+type boundClient struct {
+	url     string
+	sent    *[]string
+	open    *int
+	maxOpen *int
+}
+
+func (c boundClient) Connect(ctx context.Context) error {
+	*c.open++
+	*c.maxOpen = max(*c.maxOpen, *c.open)
+	return nil
+}
+func (c boundClient) Send(ctx context.Context, req *Request) (*Response, error) {
+	*c.sent = append(*c.sent, c.url+" <- "+req.URL)
+	return &Response{StatusCode: 200, Protocol: ProtocolWebSocket}, nil
+}
+func (c boundClient) Close() error {
+	*c.open--
+	return nil
+}
+func (c boundClient) Type() ProtocolType { return ProtocolWebSocket }
+
+// 测试 WebSocket 地址中的模板渲染后变化时关闭旧连接并按新地址重建，地址不变时复用，同时只保持一个连接
+func TestWorkerTemplatedConnectionURL(t *testing.T) {
+	collector := statistics.NewCollector(storage.NewMemoryStorage("test", logger.Default), logger.Default)
+	defer collector.Close()
+
+	var (
+		logins  int
+		created int
+		open    int
+		maxOpen int
+		sent    []string
+	)
+	login := func(ctx context.Context, req *Request) (*Response, error) {
+		logins++
+		return &Response{StatusCode: 200, Body: []byte(fmt.Sprintf(`{"token":"t-%d"}`, logins/2))}, nil
+	}
+	factory := func(api *APIConfig) (Client, error) {
+		created++
+		return boundClient{url: api.URL, sent: &sent, open: &open, maxOpen: &maxOpen}, nil
+	}
+
+	cfg := &config.Config{APIs: []config.APIConfig{
+		{Name: "login", URL: "http://localhost/login", Method: "POST",
+			Extractors: []config.ExtractorConfig{{Name: "token", JSONPath: "$.token"}}},
+		{Name: "chat", URL: "ws://localhost/chat?token={{.login.token}}", Protocol: ProtocolWebSocket, DependsOn: []string{"login"}},
+	}}
+	cfg.SetLogger(logger.Default)
+	worker := NewWorker(WorkerConfig{
+		Client:           handlerClient(login),
+		Collector:        collector,
+		ReqCount:         5,
+		APISelector:      CreateAPISelector(cfg),
+		APIClientFactory: factory,
+		Logger:           logger.Default,
+	}, config.NewVariableResolver())
+	assert.NoError(t, worker.Run(context.Background()))
+
+	assert.Equal(t, []string{
+		"ws://localhost/chat?token=t-0 <- ws://localhost/chat?token=t-0",
+		"ws://localhost/chat?token=t-1 <- ws://localhost/chat?token=t-1",
+		"ws://localhost/chat?token=t-1 <- ws://localhost/chat?token=t-1",
+		"ws://localhost/chat?token=t-2 <- ws://localhost/chat?token=t-2",
+		"ws://localhost/chat?token=t-2 <- ws://localhost/chat?token=t-2",
+	}, sent, "每个连接只发送到自己建立连接时的地址")
+	assert.Equal(t, 3, created, "地址不变时复用已建立的连接")
+	assert.Equal(t, 1, maxOpen, "地址变化时先关闭旧连接，同时只保持一个连接")
+	assert.Equal(t, 0, open, "worker 退出时关闭所有连接")
+	assert.Empty(t, worker.apiClients)
+}
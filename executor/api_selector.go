@@ -190,29 +190,36 @@ func CreateAPISelector(cfg *config.Config) APISelector {
 			verify = []config.VerifyConfig{*cfg.Verify}
 		}
 
+		var metadata map[string]string
+		if cfg.Protocol == ProtocolGRPC && cfg.GRPC != nil {
+			metadata = cfg.GRPC.Metadata
+		}
+
 		cfg.APIs = []config.APIConfig{
 			{
-				Name:    "default",
-				URL:     cfg.URL,
-				Method:  cfg.Method,
-				Headers: cfg.Headers,
-				Body:    cfg.Body,
-				Weight:  1,
-				Verify:  verify,
+				Name:      "default",
+				URL:       cfg.URL,
+				Method:    cfg.Method,
+				Headers:   cfg.Headers,
+				Metadata:  metadata,
+				Body:      cfg.Body,
+				Weight:    1,
+				Verify:    verify,
+				ThinkTime: cfg.ThinkTime,
 			},
 		}
 	}
 
-	// 检查是否有依赖关系
+	// 检查是否有依赖关系（控制流同样按场景顺序执行）
 	hasDeps := false
 	for _, api := range cfg.APIs {
-		if len(api.DependsOn) > 0 || len(api.Extractors) > 0 {
+		if len(api.DependsOn) > 0 || len(api.Extractors) > 0 || api.HasFlow() {
 			hasDeps = true
 			break
 		}
 	}
 
-	// 如果有依赖关系或提取器，使用依赖选择器
+	// 如果有依赖关系、提取器或控制流，使用依赖选择器
 	if hasDeps {
 		selector, err := NewDependencySelector(cfg.APIs, cfg.GetLogger())
 		if err != nil {
@@ -241,11 +248,24 @@ func CreateAPISelector(cfg *config.Config) APISelector {
 }
 
 // BuildRequest 从API配置构建请求
-func BuildRequest(apiCfg *APIConfig) *Request {
-	return &Request{
+// GraphQL 接口由查询、变量和操作名生成请求（变量无法编码时返回错误）
+func BuildRequest(apiCfg *APIConfig) (*Request, error) {
+	if apiCfg.GraphQL != nil {
+		return buildGraphQLRequest(&graphQLRequest{
+			config:  apiCfg.GraphQL,
+			url:     apiCfg.URL,
+			method:  apiCfg.Method,
+			headers: apiCfg.Headers,
+		}, false)
+	}
+	req := &Request{
 		URL:     apiCfg.URL,
 		Method:  apiCfg.Method,
 		Headers: apiCfg.Headers,
 		Body:    apiCfg.Body,
 	}
+	if len(apiCfg.Metadata) > 0 {
+		req.Metadata = map[string]any{GRPCMetadataKey: apiCfg.Metadata}
+	}
+	return req, nil
 }
@@ -0,0 +1,194 @@
+/*
+ * @Author: kamalyes 501893067@qq.com
+ * @Date: 2026-02-02 00:00:00
+ * @LastEditors: kamalyes 501893067@qq.com
+ * @LastEditTime: 2026-02-02 00:00:00
+ * @FilePath: \go-stress\executor\arrival_rate.go
+ * @Description: 开放模型调度 - 按固定到达速率（RPS）派发请求
+ *
+ * Copyright (c) 2026 by kamalyes, All Rights Reserved.
+ */
+package executor
+
+import (
+	"context"
+	"sync"
+	"time"
+
+	"github.com/kamalyes/go-toolbox/pkg/syncx"
+)
+
+// minArrivalTick 派发器的最小节拍（高RPS时每个节拍补发多个到达）
+const minArrivalTick = time.Millisecond
+
+// runArrivalRate 开放模型运行：共享节拍按目标RPS产生到达，派发给有界worker池
+// 与闭合模型不同，目标变慢时到达速率不变，没有空闲worker的到达会被记为排队或丢弃
+func (s *Scheduler) runArrivalRate(ctx context.Context) error {
+	var wg sync.WaitGroup
+	errChan := make(chan error, s.workerCount)
+
+	// 启动进度跟踪
+	progressCtx, cancelProgress := context.WithCancel(ctx)
+	defer cancelProgress()
+	go s.progress.Start(progressCtx)
+
+	// 到达队列容量与worker数一致：队列满时说明积压过多，直接丢弃
+	arrivals := make(chan time.Time, s.workerCount)
+	idle := syncx.NewInt64(0)
+
+	s.logger.Infof("🎯 开放模型: 目标 %.2f RPS，worker池上限 %d", s.peakRPS(), s.workerCount)
+
+	// 启动worker池
+	for i := uint64(0); i < s.workerCount; i++ {
+		wg.Add(1)
+		go func(workerID uint64) {
+			defer wg.Done()
+
+			if err := s.runArrivalWorker(ctx, workerID, arrivals, idle); err != nil {
+				select {
+				case errChan <- err:
+				default:
+				}
+			}
+		}(i)
+	}
+
+	// 派发到达（阻塞直到截止时间/计划数量/停止）
+	s.dispatchArrivals(ctx, arrivals, idle)
+	close(arrivals)
+
+	// 等待进行中的请求执行完毕
+	wg.Wait()
+	close(errChan)
+
+	// 停止进度跟踪并关闭表格
+	cancelProgress()
+	s.progress.Complete()
+
+	for err := range errChan {
+		if err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+// dispatchArrivals 按目标RPS派发到达时间，直到截止时间/计划数量/停止
+// 每个节拍按当前速率累积应到达数量，并把到达时间均匀分布在节拍区间内，
+// 因此多阶段模式下速率随时间变化也能精确跟随
+func (s *Scheduler) dispatchArrivals(ctx context.Context, arrivals chan<- time.Time, idle *syncx.Int64) {
+	ticker := time.NewTicker(max(time.Duration(float64(time.Second)/s.peakRPS()), minArrivalTick))
+	defer ticker.Stop()
+
+	var deadline time.Time
+	if s.duration > 0 {
+		deadline = time.Now().Add(s.duration)
+	}
+	planned := s.workerCount * s.requestPerWorker
+
+	start := time.Now()
+	last := start
+	currentStage := -1
+	var due float64       // 累计应到达数量
+	var dispatched uint64 // 已派发数量（含丢弃）
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case now := <-ticker.C:
+			if s.isStopped() {
+				return
+			}
+			if s.controller.IsPaused() {
+				// 暂停期间不产生到达，恢复后从当前时间继续，避免一次性补发
+				pauseStart := time.Now()
+				if WaitWhilePaused(s.controller) {
+					return
+				}
+				paused := time.Since(pauseStart)
+				start = start.Add(paused)
+				last = time.Now()
+				continue
+			}
+			if !deadline.IsZero() && !now.Before(deadline) {
+				return
+			}
+
+			rate := s.targetRPS
+			if s.stagePlan != nil {
+				index, target, remaining := s.stagePlan.At(now.Sub(start))
+				if index < 0 {
+					return
+				}
+				if index != currentStage {
+					currentStage = index
+					s.logStageChange(index)
+				}
+				rate = target
+				s.publishStage(index, target, remaining, s.workerCount-uint64(max(idle.Load(), 0)))
+			}
+
+			// 累积本节拍应到达数量，按比例换算每个到达的计划时间
+			prevDue := due
+			due += rate * now.Sub(last).Seconds()
+			for next := float64(dispatched + 1); next <= due; next = float64(dispatched + 1) {
+				if s.stagePlan == nil && deadline.IsZero() && dispatched >= planned {
+					return
+				}
+				frac := (next - prevDue) / (due - prevDue)
+				s.offerArrival(arrivals, last.Add(time.Duration(frac*float64(now.Sub(last)))), idle)
+				dispatched++
+			}
+			last = now
+		}
+	}
+}
+
+// peakRPS 派发期间的最大目标RPS（用于确定节拍间隔）
+func (s *Scheduler) peakRPS() float64 {
+	if s.stagePlan != nil {
+		return max(s.stagePlan.Peak(), 1)
+	}
+	return s.targetRPS
+}
+
+// offerArrival 尝试把到达交给worker池：无空闲worker时记为排队，队列已满时丢弃
+func (s *Scheduler) offerArrival(arrivals chan<- time.Time, scheduledAt time.Time, idle *syncx.Int64) {
+	busy := idle.Load() <= 0
+	select {
+	case arrivals <- scheduledAt:
+		if busy {
+			s.collector.RecordDelayed()
+		}
+	default:
+		s.collector.RecordDropped()
+	}
+}
+
+// runArrivalWorker 运行开放模型下的单个worker
+func (s *Scheduler) runArrivalWorker(ctx context.Context, workerID uint64, arrivals <-chan time.Time, idle *syncx.Int64) error {
+	client, err := s.clientPool.Get()
+	if err != nil {
+		s.logger.Errorf("❌ Worker %d: 获取客户端失败: %v", workerID, err)
+		return err
+	}
+	defer s.clientPool.Put(client)
+
+	worker := NewWorker(WorkerConfig{
+		ID:               workerID,
+		Client:           client,
+		Handler:          s.middlewares.Handler,
+		Progress:         s.progress,
+		Collector:        s.collector,
+		APISelector:      s.apiSelector,
+		Scenario:         s.scenarios.Assign(workerID),
+		DataFeeder:       s.dataFeeder,
+		Controller:       s.controller,
+		Hooks:            s.hooks,
+		APIClientFactory: s.apiClientFactory,
+		Logger:           s.logger,
+	}, s.varResolver)
+
+	return worker.RunArrivals(ctx, arrivals, idle)
+}
@@ -0,0 +1,63 @@
+/*
+ * @Author: kamalyes 501893067@qq.com
+ * @Date: 2026-02-24 00:00:00
+ * @LastEditors: kamalyes 501893067@qq.com
+ * @LastEditTime: 2026-02-24 00:00:00
+ * @FilePath: \go-stress\executor\arrival_rate_test.go
+ * @Description: 开放模型调度测试
+ *
+ * Copyright (c) 2026 by kamalyes, All Rights Reserved.
+ */
+package executor
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/kamalyes/go-stress/config"
+	"github.com/kamalyes/go-stress/logger"
+	"github.com/kamalyes/go-stress/statistics"
+	"github.com/kamalyes/go-stress/storage"
+	"github.com/stretchr/testify/assert"
+)
+
+// 测试目标变慢时到达速率不变：积压的到达记为排队或丢弃，排队时间计入请求耗时
+func TestSchedulerArrivalRateSlowTarget(t *testing.T) {
+	collector := statistics.NewCollector(storage.NewMemoryStorage("test", logger.Default), logger.Default)
+	defer collector.Close()
+
+	// 目标每个请求处理 100ms，但只上报 1ms 的服务耗时
+	handler := func(ctx context.Context, req *Request) (*Response, error) {
+		time.Sleep(100 * time.Millisecond)
+		return &Response{StatusCode: 200, Duration: time.Millisecond}, nil
+	}
+
+	cfg := &config.Config{APIs: []config.APIConfig{{Name: "ping", URL: "http://localhost/ping"}}}
+	cfg.SetLogger(logger.Default)
+	pool := NewClientPool(func() (Client, error) { return handlerClient(handler), nil }, 1)
+	defer pool.Close()
+
+	// 1 个 worker、100 RPS、共 20 个到达：worker 忙碌期间队列只能容纳 1 个到达
+	scheduler := NewScheduler(SchedulerConfig{
+		WorkerCount:      1,
+		RequestPerWorker: 20,
+		TargetRPS:        100,
+		ClientPool:       pool,
+		Collector:        collector,
+		APISelector:      CreateAPISelector(cfg),
+		VarResolver:      config.NewVariableResolver(),
+		Logger:           logger.Default,
+	})
+	assert.NoError(t, scheduler.Run(context.Background()))
+
+	report := statistics.NewReportBuilder(collector).BuildSummary(time.Second)
+	assert.Equal(t, uint64(20), report.TotalRequests+report.DroppedRequests, "派发的到达要么执行要么丢弃")
+	assert.GreaterOrEqual(t, report.DroppedRequests, uint64(10), "队列已满的到达被丢弃")
+	assert.GreaterOrEqual(t, report.DelayedRequests, uint64(1), "无空闲worker时的到达记为排队")
+	assert.LessOrEqual(t, report.DelayedRequests, report.TotalRequests)
+
+	// 首个到达立即执行，排队的到达耗时包含等待 worker 的时间
+	assert.Less(t, report.MinLatency, 20*time.Millisecond)
+	assert.GreaterOrEqual(t, report.MaxLatency, 80*time.Millisecond)
+}
@@ -25,6 +25,11 @@ func BuildRequestResult(resp *Response, err error) *RequestResult {
 	if resp != nil {
 		result.StatusCode = resp.StatusCode
 		result.Duration = resp.Duration
+		result.Phases = resp.Phases
+		result.Stream = resp.Stream
+		result.WebSocket = resp.WebSocket
+		result.MQTT = resp.MQTT
+		result.Protocol = resp.Protocol
 		result.Size = float64(len(resp.Body))
 
 		// 填充请求详情
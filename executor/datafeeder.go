@@ -0,0 +1,134 @@
+/*
+ * @Author: kamalyes 501893067@qq.com
+ * @Date: 2026-02-08 00:00:00
+ * @LastEditors: kamalyes 501893067@qq.com
+ * @LastEditTime: 2026-02-08 00:00:00
+ * @FilePath: \go-stress\executor\datafeeder.go
+ * @Description: 数据供给器 - 按分配策略为每轮请求提供一行参数化数据
+ *
+ * Copyright (c) 2026 by kamalyes, All Rights Reserved.
+ */
+package executor
+
+import (
+	"fmt"
+	"maps"
+	"math/rand"
+
+	"github.com/kamalyes/go-logger"
+	"github.com/kamalyes/go-stress/config"
+	"github.com/kamalyes/go-toolbox/pkg/syncx"
+)
+
+// dataSource 单个已加载的数据源
+type dataSource struct {
+	name   string
+	policy config.DataSourcePolicy
+	rows   []map[string]string
+	cursor *syncx.Uint64
+}
+
+// next 按策略取一行（unique_once 使用供给器统一占用的行号 onceIdx）
+func (ds *dataSource) next(workerID, onceIdx uint64) map[string]string {
+	total := uint64(len(ds.rows))
+	switch ds.policy {
+	case config.DataPolicyRandom:
+		return ds.rows[rand.Intn(len(ds.rows))]
+	case config.DataPolicyUniquePerWorker:
+		return ds.rows[workerID%total]
+	case config.DataPolicyUniqueOnce:
+		return ds.rows[onceIdx]
+	default:
+		return ds.rows[(ds.cursor.Add(1)-1)%total]
+	}
+}
+
+// DataFeeder 数据供给器（多个数据源的列合并为一行，同名列以后面的数据源为准）
+// 所有 unique_once 数据源共用一个游标：每轮先占用行号，行号超出最短的 unique_once 数据源时
+// 直接结束，不再从其他数据源取行，避免已取出的行被丢弃
+type DataFeeder struct {
+	sources    []*dataSource
+	once       *dataSource   // 行数最少的 unique_once 数据源（决定可执行的轮数，未配置时为 nil）
+	onceCursor *syncx.Uint64 // unique_once 数据源共用的游标
+	exhausted  *syncx.Bool
+	logger     logger.ILogger
+}
+
+// NewDataFeeder 加载数据源并创建数据供给器，未配置数据源时返回 nil
+func NewDataFeeder(cfgs []config.DataSourceConfig, workers uint64, log logger.ILogger) (*DataFeeder, error) {
+	if len(cfgs) == 0 {
+		return nil, nil
+	}
+
+	feeder := &DataFeeder{
+		sources:    make([]*dataSource, 0, len(cfgs)),
+		onceCursor: syncx.NewUint64(0),
+		exhausted:  syncx.NewBool(false),
+		logger:     log,
+	}
+	for i := range cfgs {
+		cfg := &cfgs[i]
+		if err := cfg.Validate(); err != nil {
+			return nil, err
+		}
+		rows, err := cfg.LoadRows()
+		if err != nil {
+			return nil, fmt.Errorf("数据源 [%s]: %w", cfg.DisplayName(), err)
+		}
+		if len(rows) == 0 {
+			return nil, fmt.Errorf("数据源 [%s] 没有数据", cfg.DisplayName())
+		}
+
+		policy := cfg.GetPolicy()
+		if policy == config.DataPolicyUniquePerWorker && uint64(len(rows)) < workers {
+			log.Warnf("⚠️  数据源 [%s] 只有 %d 行，少于 %d 个worker，部分worker将共用数据", cfg.DisplayName(), len(rows), workers)
+		}
+		log.Infof("📂 数据源 [%s]: %d 行，策略 %s", cfg.DisplayName(), len(rows), policy)
+
+		ds := &dataSource{
+			name:   cfg.DisplayName(),
+			policy: policy,
+			rows:   rows,
+			cursor: syncx.NewUint64(0),
+		}
+		feeder.sources = append(feeder.sources, ds)
+		if policy == config.DataPolicyUniqueOnce && (feeder.once == nil || len(rows) < len(feeder.once.rows)) {
+			feeder.once = ds
+		}
+	}
+	return feeder, nil
+}
+
+// Next 为指定worker取下一行数据，unique_once 数据源用完时返回 false
+func (f *DataFeeder) Next(workerID uint64) (map[string]string, bool) {
+	if f.exhausted.Load() {
+		return nil, false
+	}
+
+	// 先占用 unique_once 行号，用完时不再从其他数据源取行
+	var onceIdx uint64
+	if f.once != nil {
+		onceIdx = f.onceCursor.Add(1) - 1
+		if onceIdx >= uint64(len(f.once.rows)) {
+			if f.exhausted.CAS(false, true) {
+				f.logger.Infof("📂 数据源 [%s] 的 %d 行数据已全部使用，停止压测", f.once.name, len(f.once.rows))
+			}
+			return nil, false
+		}
+	}
+
+	if len(f.sources) == 1 {
+		return f.sources[0].next(workerID, onceIdx), true
+	}
+
+	row := make(map[string]string)
+	for _, ds := range f.sources {
+		maps.Copy(row, ds.next(workerID, onceIdx))
+	}
+	return row, true
+}
+
+// Exhausted 是否已有 unique_once 数据源用完
+func (f *DataFeeder) Exhausted() bool {
+	return f != nil && f.exhausted.Load()
+}
@@ -0,0 +1,126 @@
+/*
+ * @Author: kamalyes 501893067@qq.com
+ * @Date: 2026-02-08 00:00:00
+ * @LastEditors: kamalyes 501893067@qq.com
+ * @LastEditTime: 2026-02-08 00:00:00
+ * @FilePath: \go-stress\executor\datafeeder_test.go
+ * @Description: 数据供给器测试
+ *
+ * Copyright (c) 2026 by kamalyes, All Rights Reserved.
+ */
+package executor
+
+import (
+	"testing"
+
+	"github.com/kamalyes/go-stress/config"
+	"github.com/kamalyes/go-stress/logger"
+	"github.com/stretchr/testify/assert"
+)
+
+// testRows 生成测试数据行
+func testRows(ids ...string) []map[string]string {
+	rows := make([]map[string]string, len(ids))
+	for i, id := range ids {
+		rows[i] = map[string]string{"user_id": id}
+	}
+	return rows
+}
+
+// 测试各分配策略
+func TestDataFeederPolicies(t *testing.T) {
+	newFeeder := func(policy config.DataSourcePolicy) *DataFeeder {
+		feeder, err := NewDataFeeder([]config.DataSourceConfig{
+			{Name: "users", Policy: policy, Rows: testRows("a", "b", "c")},
+		}, 2, logger.Default)
+		assert.NoError(t, err)
+		return feeder
+	}
+
+	// 顺序：所有worker共享游标，循环使用
+	feeder := newFeeder(config.DataPolicySequential)
+	var got []string
+	for i := 0; i < 4; i++ {
+		row, ok := feeder.Next(uint64(i % 2))
+		assert.True(t, ok)
+		got = append(got, row["user_id"])
+	}
+	assert.Equal(t, []string{"a", "b", "c", "a"}, got)
+
+	// 每个worker固定使用一行
+	feeder = newFeeder(config.DataPolicyUniquePerWorker)
+	for i := 0; i < 3; i++ {
+		row, _ := feeder.Next(1)
+		assert.Equal(t, "b", row["user_id"])
+	}
+
+	// 随机：只返回已有数据
+	feeder = newFeeder(config.DataPolicyRandom)
+	row, ok := feeder.Next(0)
+	assert.True(t, ok)
+	assert.Contains(t, []string{"a", "b", "c"}, row["user_id"])
+
+	// 每行只使用一次，用完后停止
+	feeder = newFeeder(config.DataPolicyUniqueOnce)
+	for i := 0; i < 3; i++ {
+		_, ok := feeder.Next(0)
+		assert.True(t, ok)
+	}
+	assert.False(t, feeder.Exhausted())
+	_, ok = feeder.Next(1)
+	assert.False(t, ok)
+	assert.True(t, feeder.Exhausted())
+}
+
+// 测试多个数据源的列合并
+func TestDataFeederMerge(t *testing.T) {
+	feeder, err := NewDataFeeder([]config.DataSourceConfig{
+		{Name: "users", Rows: testRows("a")},
+		{Name: "products", Rows: []map[string]string{{"sku": "x1"}}},
+	}, 1, logger.Default)
+	assert.NoError(t, err)
+
+	row, ok := feeder.Next(0)
+	assert.True(t, ok)
+	assert.Equal(t, map[string]string{"user_id": "a", "sku": "x1"}, row)
+
+	// 未配置数据源时不创建供给器
+	feeder, err = NewDataFeeder(nil, 1, logger.Default)
+	assert.NoError(t, err)
+	assert.Nil(t, feeder)
+	assert.False(t, feeder.Exhausted())
+}
+
+// 测试 unique_once 数据源用完时不再从其他数据源取行
+func TestDataFeederUniqueOnceExhaustion(t *testing.T) {
+	feeder, err := NewDataFeeder([]config.DataSourceConfig{
+		{Name: "products", Rows: []map[string]string{{"sku": "x1"}, {"sku": "x2"}, {"sku": "x3"}}},
+		{Name: "coupons", Policy: config.DataPolicyUniqueOnce, Rows: []map[string]string{{"coupon": "c1"}, {"coupon": "c2"}, {"coupon": "c3"}}},
+		{Name: "users", Policy: config.DataPolicyUniqueOnce, Rows: testRows("a", "b")},
+	}, 1, logger.Default)
+	assert.NoError(t, err)
+
+	var got []map[string]string
+	for {
+		row, ok := feeder.Next(0)
+		if !ok {
+			break
+		}
+		got = append(got, row)
+	}
+	assert.Equal(t, []map[string]string{
+		{"sku": "x1", "coupon": "c1", "user_id": "a"},
+		{"sku": "x2", "coupon": "c2", "user_id": "b"},
+	}, got)
+	assert.True(t, feeder.Exhausted())
+
+	// 用完的那一轮没有从顺序数据源取行
+	assert.Equal(t, uint64(2), feeder.sources[0].cursor.Load())
+}
+
+// 测试数据行在模板中通过 {{.row.列名}} 访问
+func TestVariableReplacerRow(t *testing.T) {
+	replacer := NewVariableReplacer(config.NewVariableResolver(), nil).
+		WithRow(map[string]string{"user_id": "1001"})
+	assert.Equal(t, `{"uid":"1001"}`, replacer.ReplaceString(`{"uid":"{{.row.user_id}}"}`))
+}
@@ -92,8 +92,9 @@ func (r *DependencyResolver) resolveDependencies() error {
 	}
 
 	// 遍历所有API
-	for name := range r.apiMap {
-		if err := visit(name); err != nil {
+	// 按声明顺序遍历，保证无依赖关系的API顺序稳定
+	for i := range r.apiConfigs {
+		if err := visit(r.apiConfigs[i].Name); err != nil {
 			return err
 		}
 	}
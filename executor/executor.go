@@ -45,6 +45,7 @@ type Executor struct {
 	scheduler      *Scheduler
 	pool           *ClientPool
 	realtimeServer *statistics.RealtimeServer
+	thresholds     *ThresholdEvaluator // SLO 阈值评估（未配置时为 nil）
 	logger         logger.ILogger
 	// 分布式相关
 	statsReporter StatsReporter // 用于分布式模式下的统计上报
@@ -87,6 +88,25 @@ func NewExecutor(cfg *config.Config, storageMode StorageMode, storagePath string
 		e.config.Concurrency,
 		e.config.Requests,
 	)
+	e.collector.SetDuration(e.config.Duration)
+	if len(e.config.Stages) > 0 {
+		plan := NewStagePlan(e.config.Stages)
+		e.collector.SetDuration(plan.TotalDuration())
+		if !plan.UseRPS() {
+			// 按并发数分阶段时，报告展示峰值并发
+			e.collector.SetConfig(string(e.config.Protocol), uint64(plan.Peak()), e.config.Requests)
+		}
+	}
+
+	// 协调遗漏校正
+	if interval := e.expectedInterval(); interval > 0 {
+		e.collector.SetLatencyCorrection(interval)
+	}
+
+	// 时间序列分桶间隔
+	if e.config.Advanced != nil && e.config.Advanced.TimeSeriesInterval > 0 {
+		e.collector.SetTimeSeriesInterval(e.config.Advanced.TimeSeriesInterval)
+	}
 
 	// 1. 创建客户端工厂
 	clientFactory := e.createClientFactory()
@@ -95,68 +115,143 @@ func NewExecutor(cfg *config.Config, storageMode StorageMode, storagePath string
 	e.pool = NewClientPool(clientFactory, int(e.config.Concurrency))
 
 	// 3. 构建中间件链
-	handler, err := e.buildMiddlewareChain(clientFactory)
+	middlewares, err := e.buildMiddlewareChain(clientFactory)
 	if err != nil {
 		return nil, fmt.Errorf("构建中间件链失败: %w", err)
 	}
 
-	// 4. 创建API选择器（统一处理：CreateAPISelector 内部会判断单/多API）
-	apiSelector := CreateAPISelector(e.config)
+	// 4. 创建API选择器（统一处理：CreateAPISelector 内部会判断单/多API；多场景时每个场景各自创建）
+	if e.config.GetLogger() == nil {
+		e.config.SetLogger(e.logger)
+	}
+	var apiSelector APISelector
+	scenarios := NewScenarioMix(e.config)
+	if scenarios == nil {
+		apiSelector = CreateAPISelector(e.config)
+	}
 
-	apiCount := len(e.config.APIs)
+	apiCount := len(e.config.AllAPIs())
 	if apiCount == 0 {
 		apiCount = 1 // 单API模式
 	}
 	e.logger.Info("📋 API配置: %d个", apiCount)
+	for _, sc := range scenarios.Scenarios() {
+		e.logger.Info("🎭 场景 [%s]: 权重 %d", sc.Name, sc.Weight)
+	}
+
+	// 5. 加载数据源
+	dataFeeder, err := NewDataFeeder(e.config.DataSources, e.config.Concurrency, e.logger)
+	if err != nil {
+		return nil, fmt.Errorf("加载数据源失败: %w", err)
+	}
 
-	// 5. 创建调度器
+	// 6. 创建调度器
 	var rampUp time.Duration
+	var targetRPS float64
 	if e.config.Advanced != nil {
 		rampUp = e.config.Advanced.RampUp
+		targetRPS = e.config.Advanced.TargetRPS
 	}
 
 	// 直接从 config 取变量解析器
 	e.scheduler = NewScheduler(SchedulerConfig{
 		WorkerCount:      e.config.Concurrency,
 		RequestPerWorker: e.config.Requests,
+		Duration:         e.config.Duration,
+		TargetRPS:        targetRPS,
+		Stages:           e.config.Stages,
+		Pacing:           e.config.Pacing,
 		RampUpDuration:   rampUp,
 		ClientPool:       e.pool,
-		Handler:          handler,
+		Middlewares:      middlewares,
 		Collector:        e.collector,
 		APISelector:      apiSelector,
+		Scenarios:        scenarios,
+		APIClientFactory: e.createAPIClientFactory(),
+		Setup:            e.config.Setup,
+		Teardown:         e.config.Teardown,
+		BeforeAll:        e.config.BeforeAll,
+		AfterAll:         e.config.AfterAll,
 		VarResolver:      e.config.VarResolver,
+		DataFeeder:       dataFeeder,
 		Controller:       nil, // 稍后设置
 		Logger:           e.logger,
 	})
 
+	// 7. 创建阈值评估器
+	e.thresholds, err = NewThresholdEvaluator(e.config.Thresholds, e.collector, e.plannedRequests, e.logger)
+	if err != nil {
+		return nil, fmt.Errorf("解析阈值失败: %w", err)
+	}
+
 	return e, nil
 }
 
+// expectedInterval 计算协调遗漏校正使用的期望请求间隔，未启用校正时返回0
+func (e *Executor) expectedInterval() time.Duration {
+	adv := e.config.Advanced
+	if adv == nil || !adv.CorrectOmission {
+		return 0
+	}
+	// 开放模型下请求耗时已从计划发送时间开始计算（包含排队时间），再回填样本会重复校正
+	if adv.TargetRPS > 0 || config.StagesUseRPS(e.config.Stages) {
+		e.logger.Info("ℹ️  开放模型的耗时已包含排队时间，无需协调遗漏校正，correct_omission 不生效")
+		return 0
+	}
+	if adv.ExpectedInterval > 0 {
+		return adv.ExpectedInterval
+	}
+	e.logger.Warn("⚠️  已启用协调遗漏校正，但未设置 expected_interval，校正不生效")
+	return 0
+}
+
 // createClientFactory 创建客户端工厂
 func (e *Executor) createClientFactory() ClientFactory {
 	return func() (Client, error) {
 		e.logger.Infof("创建客户端: protocol=%s (type=%T)", e.config.Protocol, e.config.Protocol)
-		switch e.config.Protocol {
-		case ProtocolHTTP:
-			return protocol.NewHTTPClient(e.config)
-		case ProtocolGRPC:
-			return protocol.NewGRPCClient(e.config)
-		case ProtocolWebSocket:
-			return protocol.NewWebSocketClient(e.config)
-		default:
-			return nil, fmt.Errorf("不支持的协议: %s (type=%T, raw=%q)", e.config.Protocol, e.config.Protocol, string(e.config.Protocol))
-		}
+		return NewProtocolClient(e.config)
+	}
+}
+
+// createAPIClientFactory 创建单独配置了协议的API使用的客户端工厂
+func (e *Executor) createAPIClientFactory() APIClientFactory {
+	return func(api *APIConfig) (Client, error) {
+		e.logger.Infof("创建客户端: protocol=%s api=%s", api.Protocol, api.Name)
+		return NewProtocolClient(e.config.ProtocolConfig(api))
 	}
 }
 
-// buildMiddlewareChain 构建中间件链
-// 执行顺序：熔断器 -> 重试器 -> 验证器 -> 客户端
-func (e *Executor) buildMiddlewareChain(factory ClientFactory) (RequestHandler, error) {
-	// 创建临时客户端用于中间件
+// NewProtocolClient 按配置的协议创建客户端
+func NewProtocolClient(cfg *config.Config) (Client, error) {
+	switch cfg.Protocol {
+	case ProtocolHTTP:
+		return protocol.NewHTTPClient(cfg)
+	case ProtocolGRPC:
+		return protocol.NewGRPCClient(cfg)
+	case ProtocolWebSocket:
+		return protocol.NewWebSocketClient(cfg)
+	case ProtocolSSE:
+		return protocol.NewSSEClient(cfg)
+	case ProtocolTCP:
+		return protocol.NewTCPClient(cfg)
+	case ProtocolUDP:
+		return protocol.NewUDPClient(cfg)
+	case ProtocolMQTT:
+		return protocol.NewMQTTClient(cfg)
+	default:
+		return nil, fmt.Errorf("不支持的协议: %s (type=%T, raw=%q)", cfg.Protocol, cfg.Protocol, string(cfg.Protocol))
+	}
+}
+
+// buildMiddlewareChain 构建中间件链（每个 worker 以自己的客户端为最底层构建处理器）
+// 执行顺序：熔断器 -> 重试器 -> 验证器 -> GraphQL -> 客户端
+func (e *Executor) buildMiddlewareChain(factory ClientFactory) (*MiddlewareChain, error) {
+	// 提前创建一次客户端以校验协议配置（连接池创建客户端时不返回错误）
 	client, err := factory()
 	if err != nil {
 		return nil, fmt.Errorf("创建客户端失败: %w", err)
 	}
+	_ = client.Close()
 
 	chain := NewMiddlewareChain()
 
@@ -185,10 +280,12 @@ func (e *Executor) buildMiddlewareChain(factory ClientFactory) (RequestHandler,
 		chain.Use(VerifyMiddleware(verifier))
 	}
 
-	// 4. 构建处理器（客户端是最底层）
-	handler := chain.Build(ClientMiddleware(client))
+	// 4. GraphQL 中间件（APQ 重发和 errors 判定需在验证之前完成）
+	if hasGraphQLAPIs(e.config.AllAPIs()) {
+		chain.Use(GraphQLMiddleware())
+	}
 
-	return handler, nil
+	return chain, nil
 }
 
 // Run 执行压测
@@ -196,6 +293,15 @@ func (e *Executor) Run(ctx context.Context) (*statistics.Report, error) {
 	// 打印启动信息
 	e.printStartInfo()
 
+	// 全局前置步骤（不计入统计和压测时长）
+	globalHooks := e.runGlobalHooks()
+	if globalHooks {
+		if err := e.scheduler.RunBeforeAll(ctx); err != nil {
+			e.pool.Close()
+			return nil, fmt.Errorf("执行 before_all 失败: %w", err)
+		}
+	}
+
 	// 启动实时报告服务器
 	port := 8088 // 默认端口
 	if e.config.Advanced != nil && e.config.Advanced.RealtimePort > 0 {
@@ -217,10 +323,37 @@ func (e *Executor) Run(ctx context.Context) (*statistics.Report, error) {
 
 	startTime := time.Now()
 
+	// 配置了提前终止的阈值时，由评估器在确定无法达标时取消压测
+	runCtx, cancel := context.WithCancel(ctx)
+	defer cancel()
+	watchDone := make(chan struct{})
+	if e.thresholds != nil && e.thresholds.HasAbortThresholds() {
+		go func() {
+			defer close(watchDone)
+			e.thresholds.Watch(runCtx, cancel)
+		}()
+	} else {
+		close(watchDone)
+	}
+
 	// 运行调度器
-	err := e.scheduler.Run(ctx)
+	err := e.scheduler.Run(runCtx)
 
 	totalDuration := time.Since(startTime)
+	cancel()
+	<-watchDone
+
+	// 全局后置步骤（压测被中断时同样执行）
+	if globalHooks {
+		if hookErr := e.scheduler.RunAfterAll(ctx); hookErr != nil {
+			e.logger.Warnf("⚠️  after_all 执行失败: %v", hookErr)
+		}
+	}
+
+	// 阈值触发的提前终止视为正常结束
+	if err != nil && errors.Is(err, context.Canceled) && ctx.Err() == nil && e.thresholds != nil && e.thresholds.AbortedBy() != nil {
+		err = nil
+	}
 
 	// 标记测试完成（固定 QPS 计算时间）
 	if e.realtimeServer != nil {
@@ -234,6 +367,12 @@ func (e *Executor) Run(ctx context.Context) (*statistics.Report, error) {
 	builder := statistics.NewReportBuilder(e.collector)
 	report := builder.BuildSummary(totalDuration)
 
+	// 评估 SLO 阈值（同时写入收集器，保证导出的报告包含评估结果）
+	if e.thresholds != nil {
+		report.Thresholds = e.thresholds.Evaluate(report)
+		e.collector.SetThresholdResults(report.Thresholds)
+	}
+
 	// 检查是否因为context取消而中断
 	if err != nil {
 		// 如果是用户主动取消，不关闭实时服务器，返回当前报告
@@ -259,11 +398,20 @@ func (e *Executor) printStartInfo() {
 	e.logger.Info("\n🚀 开始压测...")
 	e.logger.Info("📊 协议: %s", e.config.Protocol)
 	e.logger.Info("🔢 并发数: %d", e.config.Concurrency)
-	e.logger.Info("📈 每并发请求数: %d", e.config.Requests)
+	if len(e.config.Stages) > 0 {
+		e.logger.Info("📶 负载阶段: %d个，总时长 %v", len(e.config.Stages), NewStagePlan(e.config.Stages).TotalDuration())
+	} else if e.config.Duration > 0 {
+		e.logger.Info("⏳ 持续时间: %v", e.config.Duration)
+	} else {
+		e.logger.Info("📈 每并发请求数: %d", e.config.Requests)
+	}
 	e.logger.Info("⏱️  超时时间: %v", e.config.Timeout)
 	if e.config.Advanced != nil && e.config.Advanced.RampUp > 0 {
 		e.logger.Info("⏲️  渐进启动: %v", e.config.Advanced.RampUp)
 	}
+	if e.config.Advanced != nil && e.config.Advanced.TargetRPS > 0 {
+		e.logger.Info("🎯 目标RPS: %.2f（开放模型）", e.config.Advanced.TargetRPS)
+	}
 	e.logger.Info("")
 }
 
@@ -298,6 +446,18 @@ func (e *Executor) SetStatsReporter(reporter StatsReporter) {
 	}
 }
 
+// runGlobalHooks 是否执行 before_all/after_all
+// 分布式模式下每个 slave 各自运行执行器，全局步骤会被重复执行，因此 slave 跳过
+func (e *Executor) runGlobalHooks() bool {
+	if !e.isDistributed {
+		return true
+	}
+	if len(e.config.BeforeAll) > 0 || len(e.config.AfterAll) > 0 {
+		e.logger.Warn("⚠️  分布式模式下 slave 不执行 before_all/after_all，请在压测前后单独准备和清理数据")
+	}
+	return false
+}
+
 // IsDistributed 是否为分布式模式
 func (e *Executor) IsDistributed() bool {
 	return e.isDistributed
@@ -0,0 +1,92 @@
+/*
+ * @Author: kamalyes 501893067@qq.com
+ * @Date: 2026-02-24 00:00:00
+ * @LastEditors: kamalyes 501893067@qq.com
+ * @LastEditTime: 2026-02-24 00:00:00
+ * @FilePath: \go-stress\executor\executor_test.go
+ * @Description: 协调遗漏校正测试
+ *
+ * Copyright (c) 2026 by kamalyes, All Rights Reserved.
+ */
+package executor
+
+import (
+	"context"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/kamalyes/go-stress/config"
+	"github.com/kamalyes/go-stress/logger"
+	"github.com/kamalyes/go-stress/statistics"
+	"github.com/kamalyes/go-stress/storage"
+	"github.com/stretchr/testify/assert"
+)
+
+// runSlowRequest 按配置运行调度器，第 10 个请求耗时 slow，其余请求上报 1ms，返回报告
+func runSlowRequest(t *testing.T, cfg *config.Config, slow time.Duration) *statistics.Report {
+	collector := statistics.NewCollector(storage.NewMemoryStorage("test", logger.Default), logger.Default)
+	defer collector.Close()
+
+	e := &Executor{config: cfg, logger: logger.Default}
+	if interval := e.expectedInterval(); interval > 0 {
+		collector.SetLatencyCorrection(interval)
+	}
+
+	var count atomic.Int64
+	handler := func(ctx context.Context, req *Request) (*Response, error) {
+		if count.Add(1) == 10 {
+			time.Sleep(slow)
+			return &Response{StatusCode: 200, Duration: slow}, nil
+		}
+		return &Response{StatusCode: 200, Duration: time.Millisecond}, nil
+	}
+
+	cfg.APIs = []config.APIConfig{{Name: "ping", URL: "http://localhost/ping"}}
+	cfg.SetLogger(logger.Default)
+	pool := NewClientPool(func() (Client, error) { return handlerClient(handler), nil }, int(cfg.Concurrency))
+	defer pool.Close()
+
+	scheduler := NewScheduler(SchedulerConfig{
+		WorkerCount:      cfg.Concurrency,
+		RequestPerWorker: cfg.Requests,
+		TargetRPS:        cfg.Advanced.TargetRPS,
+		ClientPool:       pool,
+		Collector:        collector,
+		APISelector:      CreateAPISelector(cfg),
+		VarResolver:      config.NewVariableResolver(),
+		Logger:           logger.Default,
+	})
+	assert.NoError(t, scheduler.Run(context.Background()))
+	return statistics.NewReportBuilder(collector).BuildSummary(time.Second)
+}
+
+// 测试闭合模型下慢请求按期望间隔回填样本，P99 反映被阻塞的请求
+func TestCorrectOmissionClosedModel(t *testing.T) {
+	report := runSlowRequest(t, &config.Config{
+		Concurrency: 1,
+		Requests:    100,
+		Advanced:    &config.AdvancedConfig{CorrectOmission: true, ExpectedInterval: 10 * time.Millisecond},
+	}, 200*time.Millisecond)
+
+	// 99 个 1ms 样本 + 200ms 慢请求 + 回填的 190ms、180ms ... 10ms 共 19 个样本
+	assert.Equal(t, uint64(100), report.TotalRequests)
+	assert.Equal(t, uint64(19), report.CorrectedSamples)
+	assert.InDelta(t, float64(190*time.Millisecond), float64(report.P99Latency), float64(2*time.Millisecond))
+}
+
+// 测试开放模型下耗时已包含排队时间，不再回填样本（避免重复校正抬高 P99）
+func TestCorrectOmissionOpenModel(t *testing.T) {
+	report := runSlowRequest(t, &config.Config{
+		Concurrency: 1,
+		Requests:    500,
+		Advanced:    &config.AdvancedConfig{CorrectOmission: true, TargetRPS: 1000},
+	}, 50*time.Millisecond)
+
+	assert.Equal(t, uint64(0), report.CorrectedSamples)
+	assert.Greater(t, report.DroppedRequests, uint64(0), "慢请求期间积压的到达被丢弃")
+
+	// 慢请求和它之后排队的请求各计一次，不回填时 P99 仍由快请求决定
+	assert.GreaterOrEqual(t, report.MaxLatency, 50*time.Millisecond)
+	assert.Less(t, report.P99Latency, 20*time.Millisecond)
+}
@@ -259,6 +259,41 @@ funcCall += "}}"
 	return value, nil
 }
 
+// ======================== SSE 事件提取器 ========================
+
+// EventExtractor 在 SSE 响应上逐个事件提取：依次以每个事件的数据作为响应体，返回第一个提取成功的值
+type EventExtractor struct {
+	inner Extractor
+	event string // 事件类型过滤（为空时匹配所有事件）
+}
+
+func NewEventExtractor(inner Extractor, event string) *EventExtractor {
+	return &EventExtractor{inner: inner, event: event}
+}
+
+func (e *EventExtractor) Extract(ctx *ExtractorContext) (string, error) {
+	if ctx.Response == nil || len(ctx.Response.Events) == 0 {
+		return e.inner.Extract(ctx)
+	}
+
+	err := fmt.Errorf("没有类型为 [%s] 的事件", e.event)
+	for _, event := range ctx.Response.Events {
+		if e.event != "" && event.Event != e.event {
+			continue
+		}
+		resp := *ctx.Response
+		resp.Body = []byte(event.Data)
+		eventCtx := *ctx
+		eventCtx.Response = &resp
+
+		var value string
+		if value, err = e.inner.Extract(&eventCtx); err == nil {
+			return value, nil
+		}
+	}
+	return "", err
+}
+
 // ======================== 提取器管理器 ========================
 
 type ExtractorManager struct {
@@ -308,13 +343,17 @@ func createExtractor(cfg config.ExtractorConfig) (Extractor, error) {
 		if cfg.JSONPath == "" {
 			return nil, fmt.Errorf("JSONPath不能为空")
 		}
-		return NewJSONPathExtractor(cfg.JSONPath, source), nil
+		return withEvents(NewJSONPathExtractor(cfg.JSONPath, source), cfg), nil
 
 	case types.ExtractorTypeRegex:
 		if cfg.Regex == "" {
 			return nil, fmt.Errorf("正则表达式不能为空")
 		}
-		return NewRegexExtractor(cfg.Regex, source)
+		extractor, err := NewRegexExtractor(cfg.Regex, source)
+		if err != nil {
+			return nil, err
+		}
+		return withEvents(extractor, cfg), nil
 
 	case types.ExtractorTypeHeader:
 		if cfg.Header == "" {
@@ -333,6 +372,14 @@ func createExtractor(cfg config.ExtractorConfig) (Extractor, error) {
 	}
 }
 
+// withEvents 从响应体提取的提取器在 SSE 响应上逐个事件提取
+func withEvents(extractor Extractor, cfg config.ExtractorConfig) Extractor {
+	if cfg.Source == config.ExtractorSourceRequest {
+		return extractor
+	}
+	return NewEventExtractor(extractor, cfg.Event)
+}
+
 func (m *ExtractorManager) ExtractAll(ctx *ExtractorContext, defaultValues map[string]string) map[string]string {
 	results := make(map[string]string)
 
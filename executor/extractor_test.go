@@ -374,6 +374,36 @@ func TestExtractorManager_DefaultValue(t *testing.T) {
 	assert.Equal(t, "default_value", results["missing_field"])
 }
 
+// 测试 SSE 响应逐个事件提取
+func TestExtractorManager_SSEEvents(t *testing.T) {
+	configs := []config.ExtractorConfig{
+		{Name: "job_id", Type: types.ExtractorTypeJSONPath, JSONPath: "$.job_id"},
+		{Name: "result", Type: types.ExtractorTypeJSONPath, JSONPath: "$.value", Event: "done"},
+		{Name: "progress", Type: types.ExtractorTypeRegex, Regex: `progress=(\d+)`},
+		{Name: "missing", Type: types.ExtractorTypeJSONPath, JSONPath: "$.value", Event: "error"},
+	}
+
+	manager, err := NewExtractorManager(configs, logger.New())
+	assert.NoError(t, err)
+
+	ctx := &ExtractorContext{
+		Response: &types.Response{
+			Body: []byte(`{"value":"final"}`),
+			Events: []types.SSEEvent{
+				{Event: "message", Data: `{"job_id":"j-1"}`},
+				{Event: "message", Data: `progress=50`},
+				{Event: "done", Data: `{"value":"final"}`},
+			},
+		},
+	}
+
+	results := manager.ExtractAll(ctx, nil)
+	assert.Equal(t, "j-1", results["job_id"], "逐个事件提取，返回第一个匹配的值")
+	assert.Equal(t, "final", results["result"])
+	assert.Equal(t, "50", results["progress"])
+	assert.NotContains(t, results, "missing")
+}
+
 // 测试创建无效的提取器
 func TestCreateExtractor_Invalid(t *testing.T) {
 	// JSONPath 为空
@@ -0,0 +1,134 @@
+/*
+ * @Author: kamalyes 501893067@qq.com
+ * @Date: 2026-02-20 00:00:00
+ * @LastEditors: kamalyes 501893067@qq.com
+ * @LastEditTime: 2026-02-20 00:00:00
+ * @FilePath: \go-stress\executor\flow.go
+ * @Description: 场景控制流执行 - foreach 遍历、条件执行、repeat 重复和 while/until 轮询
+ *
+ * Copyright (c) 2026 by kamalyes, All Rights Reserved.
+ */
+package executor
+
+import (
+	"context"
+	"maps"
+
+	"github.com/kamalyes/go-stress/config"
+	"github.com/kamalyes/go-toolbox/pkg/mathx"
+)
+
+// stepRun 依赖链中一个步骤的执行状态
+type stepRun struct {
+	api      *APIConfig
+	resolver *DependencyResolver
+	groupID  uint64
+	runs     int // 已执行次数（首次执行使用本轮的 groupID，之后每次执行分配新的分组ID）
+}
+
+// hasFlowSteps 是否存在控制流步骤（条件、轮询和遍历使每轮请求数不固定，无法预先确定计划请求数）
+func hasFlowSteps(apis []APIConfig) bool {
+	for i := range apis {
+		if apis[i].HasFlow() {
+			return true
+		}
+	}
+	return false
+}
+
+// runStep 执行依赖链中的一个步骤，返回 false 表示应该退出
+func (w *Worker) runStep(ctx context.Context, api *APIConfig, resolver *DependencyResolver, groupID uint64) bool {
+	step := &stepRun{api: api, resolver: resolver, groupID: groupID}
+	if api.Foreach == nil {
+		return w.runGuarded(ctx, step)
+	}
+
+	items, err := api.Foreach.Expand(w.render(api.Foreach.Items))
+	if err != nil {
+		w.logger.Warnf("⚠️  Worker %d: API [%s] %v，跳过遍历", w.id, api.Name, err)
+		return true
+	}
+
+	// 每个元素的变量只在本次遍历内可见
+	defer func() {
+		for _, vars := range items {
+			for key := range vars {
+				delete(w.depContext.extractedVars, key)
+			}
+		}
+	}()
+	for _, vars := range items {
+		maps.Copy(w.depContext.extractedVars, vars)
+		if !w.runGuarded(ctx, step) {
+			return false
+		}
+	}
+	return true
+}
+
+// runGuarded 条件成立时按 repeat 次数执行，每次按 loop 配置轮询
+func (w *Worker) runGuarded(ctx context.Context, step *stepRun) bool {
+	if step.api.Condition != "" && !w.evalCondition(step.api.Condition) {
+		w.logger.Debugf("⏭️  Worker %d: API [%s] 条件不成立，跳过", w.id, step.api.Name)
+		return true
+	}
+
+	for r := 0; r < mathx.IfNotZero(step.api.Repeat, 1); r++ {
+		if !w.runLoop(ctx, step) {
+			return false
+		}
+	}
+	return true
+}
+
+// runLoop 执行一次请求，配置了 loop 时轮询到条件满足、请求失败或达到最大次数
+func (w *Worker) runLoop(ctx context.Context, step *stepRun) bool {
+	loop := step.api.Loop
+	maxIterations := 1
+	if loop != nil {
+		maxIterations = mathx.IfNotZero(loop.MaxIterations, config.DefaultLoopMaxIterations)
+	}
+
+	for i := 1; ; i++ {
+		if w.checkControlState() {
+			return false
+		}
+
+		groupID := step.groupID
+		if step.runs > 0 {
+			groupID = w.nextGroupID()
+		}
+		sent := w.executeRequestByName(ctx, step.api.Name, step.resolver, groupID)
+		step.runs++
+		if !w.think(ctx, sent) {
+			return false
+		}
+
+		// 跳过或失败时不再轮询
+		if loop == nil || sent == nil || w.depContext.failedAPIs[step.api.Name] || !loop.Continue(w.evalCondition) {
+			return true
+		}
+		if i >= maxIterations {
+			w.logger.Warnf("⚠️  Worker %d: API [%s] 轮询达到最大次数 %d", w.id, step.api.Name, maxIterations)
+			return true
+		}
+		if !w.pause(ctx, loop.Interval) {
+			return false
+		}
+	}
+}
+
+// evalCondition 使用当前变量求值条件表达式（已在加载配置时验证）
+func (w *Worker) evalCondition(expr string) bool {
+	cond, err := config.ParseCondition(expr)
+	if err != nil {
+		w.logger.Errorf("❌ Worker %d: %v", w.id, err)
+		return false
+	}
+	return cond.Eval(w.render)
+}
+
+// render 使用提取变量、数据行、场景变量和动态变量渲染模板
+func (w *Worker) render(s string) string {
+	return w.newReplacer().ReplaceString(s)
+}
@@ -0,0 +1,182 @@
+/*
+ * @Author: kamalyes 501893067@qq.com
+ * @Date: 2026-02-20 00:00:00
+ * @LastEditors: kamalyes 501893067@qq.com
+ * @LastEditTime: 2026-02-20 00:00:00
+ * @FilePath: \go-stress\executor\flow_test.go
+ * @Description: 场景控制流执行测试
+ *
+ * Copyright (c) 2026 by kamalyes, All Rights Reserved.
+ */
+package executor
+
+import (
+	"context"
+	"strings"
+	"testing"
+
+	"github.com/kamalyes/go-stress/config"
+	"github.com/kamalyes/go-stress/logger"
+	"github.com/kamalyes/go-stress/statistics"
+	"github.com/kamalyes/go-stress/storage"
+	"github.com/stretchr/testify/assert"
+)
+
+// 测试异步任务轮询、条件分支和购物车遍历
+func TestWorkerControlFlow(t *testing.T) {
+	collector := statistics.NewCollector(storage.NewMemoryStorage("test", logger.Default), logger.Default)
+	defer collector.Close()
+
+	var (
+		sent  []string
+		polls int
+	)
+	handler := func(ctx context.Context, req *Request) (*Response, error) {
+		path := strings.TrimPrefix(req.URL, "http://localhost")
+		sent = append(sent, req.Method+" "+path)
+		body := `{}`
+		switch {
+		case path == "/orders/42":
+			// 前两次轮询处理中，第三次完成
+			polls++
+			body = `{"status":"PENDING"}`
+			if polls >= 3 {
+				body = `{"status":"DONE","items":[{"sku":"A1","qty":2},{"sku":"B2","qty":1}]}`
+			}
+		case path == "/orders":
+			body = `{"id":42}`
+		}
+		return &Response{StatusCode: 200, Body: []byte(body)}, nil
+	}
+
+	apis := []config.APIConfig{
+		{Name: "create", URL: "http://localhost/orders", Method: "POST",
+			Extractors: []config.ExtractorConfig{{Name: "id", JSONPath: "$.id"}}},
+		{Name: "poll", URL: "http://localhost/orders/{{.create.id}}", Method: "GET", DependsOn: []string{"create"},
+			Extractors: []config.ExtractorConfig{
+				{Name: "status", JSONPath: "$.status"},
+				{Name: "items", JSONPath: "$.items", Default: "[]"},
+			},
+			Loop: &config.LoopConfig{Until: `{{.poll.status}} == "DONE"`, MaxIterations: 5}},
+		{Name: "ship", URL: "http://localhost/ship/{{.line.sku}}?qty={{.line.qty}}&n={{.line_index}}", Method: "POST",
+			DependsOn: []string{"poll"}, Condition: `{{.poll.status}} == "DONE"`,
+			Foreach: &config.ForeachConfig{Items: "{{.poll.items}}", As: "line"}},
+		{Name: "cancel", URL: "http://localhost/cancel", Method: "POST",
+			DependsOn: []string{"poll"}, Condition: `{{.poll.status}} != "DONE"`},
+		{Name: "done", URL: "http://localhost/done/{{.create.id}}", Method: "GET", DependsOn: []string{"ship"}},
+	}
+	cfg := &config.Config{APIs: apis}
+	cfg.SetLogger(logger.Default)
+	worker := NewWorker(WorkerConfig{
+		Client:      handlerClient(handler),
+		Collector:   collector,
+		ReqCount:    1,
+		APISelector: CreateAPISelector(cfg),
+		Logger:      logger.Default,
+	}, config.NewVariableResolver())
+	assert.NoError(t, worker.Run(context.Background()))
+
+	assert.Equal(t, []string{
+		"POST /orders",
+		"GET /orders/42",
+		"GET /orders/42",
+		"GET /orders/42",
+		"POST /ship/A1?qty=2&n=0",
+		"POST /ship/B2?qty=1&n=1",
+		"GET /done/42",
+	}, sent)
+	assert.Equal(t, uint64(7), collector.GetSnapshot().TotalRequests)
+
+	// 遍历变量只在本步骤内可见
+	assert.NotContains(t, worker.depContext.extractedVars, "line")
+	assert.NotContains(t, worker.depContext.extractedVars, "line_index")
+	assert.Contains(t, worker.depContext.extractedVars, "create.id")
+}
+
+// 测试轮询达到最大次数后继续执行后续步骤
+func TestWorkerLoopMaxIterations(t *testing.T) {
+	collector := statistics.NewCollector(storage.NewMemoryStorage("test", logger.Default), logger.Default)
+	defer collector.Close()
+
+	var count int
+	handler := func(ctx context.Context, req *Request) (*Response, error) {
+		count++
+		return &Response{StatusCode: 200, Body: []byte(`{"running":true}`)}, nil
+	}
+	cfg := &config.Config{APIs: []config.APIConfig{{
+		Name: "job", URL: "http://localhost/job", Method: "GET", Repeat: 2,
+		Extractors: []config.ExtractorConfig{{Name: "running", JSONPath: "$.running"}},
+		Loop:       &config.LoopConfig{While: "{{.job.running}}", MaxIterations: 3},
+	}}}
+	cfg.SetLogger(logger.Default)
+	worker := NewWorker(WorkerConfig{
+		Client:      handlerClient(handler),
+		Collector:   collector,
+		ReqCount:    1,
+		APISelector: CreateAPISelector(cfg),
+		Logger:      logger.Default,
+	}, config.NewVariableResolver())
+	assert.NoError(t, worker.Run(context.Background()))
+	assert.Equal(t, 6, count, "每次重复各轮询 3 次")
+}
+
+// 测试重复和轮询执行的每次请求分配独立的分组ID，不同 worker 的分组ID不冲突
+func TestWorkerGroupIDs(t *testing.T) {
+	collector := statistics.NewCollector(storage.NewMemoryStorage("test", logger.Default), logger.Default)
+	defer collector.Close()
+
+	handler := func(ctx context.Context, req *Request) (*Response, error) {
+		return &Response{StatusCode: 200, Body: []byte(`{}`)}, nil
+	}
+	cfg := &config.Config{APIs: []config.APIConfig{
+		{Name: "login", URL: "http://localhost/login", Method: "POST"},
+		{Name: "job", URL: "http://localhost/job", Method: "GET", Repeat: 1001, DependsOn: []string{"login"}},
+	}}
+	cfg.SetLogger(logger.Default)
+	selector := CreateAPISelector(cfg)
+	for _, id := range []uint64{0, 1} {
+		worker := NewWorker(WorkerConfig{
+			ID:          id,
+			Client:      handlerClient(handler),
+			Collector:   collector,
+			ReqCount:    2,
+			APISelector: selector,
+			Logger:      logger.Default,
+		}, config.NewVariableResolver())
+		assert.NoError(t, worker.Run(context.Background()))
+	}
+
+	details := collector.GetRequestDetails(0, 10000, statistics.StatusFilterAll, "", "")
+	assert.Len(t, details, 2*2*1002)
+
+	groups := make(map[uint64][]string)
+	for _, d := range details {
+		groups[d.GroupID] = append(groups[d.GroupID], d.APIName)
+	}
+	// 每轮 login 与首次 job 共用本轮分组，其余 1000 次重复各自一个分组（超过 100 次重复和跨 worker 均不冲突）
+	assert.Len(t, groups, 2*2*1001)
+	for id, names := range groups {
+		assert.NotZero(t, id)
+		if len(names) > 1 {
+			assert.ElementsMatch(t, []string{"login", "job"}, names)
+		}
+	}
+}
+
+// 测试存在控制流步骤时计划请求数未知（阈值不按计划请求数提前判定）
+func TestPlannedRequestsWithFlow(t *testing.T) {
+	apis := []config.APIConfig{
+		{Name: "login", URL: "http://localhost/login", Method: "POST"},
+		{Name: "job", URL: "http://localhost/job", Method: "GET", Repeat: 3, DependsOn: []string{"login"}},
+	}
+	planned := func(apis []config.APIConfig) uint64 {
+		cfg := &config.Config{Concurrency: 2, Requests: 10, APIs: apis}
+		cfg.SetLogger(logger.Default)
+		e := &Executor{config: cfg, scheduler: &Scheduler{apiSelector: CreateAPISelector(cfg)}}
+		return e.plannedRequests("job")
+	}
+	assert.Equal(t, uint64(60), planned(apis), "重复次数固定时可确定")
+
+	apis[1].Loop = &config.LoopConfig{Until: `{{.job.done}} == "true"`}
+	assert.Equal(t, uint64(0), planned(apis))
+}
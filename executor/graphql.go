@@ -0,0 +1,200 @@
+/*
+ * @Author: kamalyes 501893067@qq.com
+ * @Date: 2026-02-17 00:00:00
+ * @LastEditors: kamalyes 501893067@qq.com
+ * @LastEditTime: 2026-02-17 00:00:00
+ * @FilePath: \go-stress\executor\graphql.go
+ * @Description: GraphQL 请求构建、自动持久化查询（APQ）和 errors 失败判定
+ *
+ * Copyright (c) 2026 by kamalyes, All Rights Reserved.
+ */
+package executor
+
+import (
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/url"
+	"strings"
+)
+
+const (
+	graphQLMetadataKey             = "graphql"                   // 请求 Metadata 中 GraphQL 请求的键
+	graphQLPersistedQueryNotFound  = "PersistedQueryNotFound"    // APQ 未命中的错误消息
+	graphQLPersistedQueryCode      = "PERSISTED_QUERY_NOT_FOUND" // APQ 未命中的错误码（extensions.code）
+	graphQLPersistedQueryVersion   = 1                           // APQ 协议版本
+	graphQLPersistedQueryExtension = "persistedQuery"            // APQ 扩展字段名
+)
+
+// graphQLRequest 请求 Metadata 中携带的 GraphQL 请求（APQ 未命中时据此携带查询重发）
+type graphQLRequest struct {
+	config  *GraphQLConfig
+	url     string // 未附加查询参数的原始URL
+	method  string
+	headers map[string]string
+}
+
+// graphQLPayload GraphQL over HTTP 请求体
+type graphQLPayload struct {
+	Query         string         `json:"query,omitempty"`
+	Variables     map[string]any `json:"variables,omitempty"`
+	OperationName string         `json:"operationName,omitempty"`
+	Extensions    map[string]any `json:"extensions,omitempty"`
+}
+
+// graphQLError 响应 errors 数组中的错误
+type graphQLError struct {
+	Message    string         `json:"message"`
+	Extensions map[string]any `json:"extensions,omitempty"`
+}
+
+// buildGraphQLRequest 构建 GraphQL 请求：POST 时为 JSON 请求体，GET 时编码为查询参数
+// 启用持久化查询且 withQuery 为 false 时只发送查询的 sha256 哈希，变量无法编码为 JSON 时返回错误
+func buildGraphQLRequest(gql *graphQLRequest, withQuery bool) (*Request, error) {
+	cfg := gql.config
+	payload := graphQLPayload{
+		Variables:     cfg.Variables,
+		OperationName: cfg.OperationName,
+	}
+	if !cfg.PersistedQuery || withQuery {
+		payload.Query = cfg.Query
+	}
+	if cfg.PersistedQuery {
+		sum := sha256.Sum256([]byte(cfg.Query))
+		payload.Extensions = map[string]any{
+			graphQLPersistedQueryExtension: map[string]any{
+				"version":    graphQLPersistedQueryVersion,
+				"sha256Hash": hex.EncodeToString(sum[:]),
+			},
+		}
+	}
+
+	req := &Request{
+		URL:      gql.url,
+		Method:   gql.method,
+		Headers:  gql.headers,
+		Metadata: map[string]any{graphQLMetadataKey: gql},
+	}
+	if strings.EqualFold(gql.method, http.MethodGet) {
+		query, err := appendGraphQLQuery(gql.url, payload)
+		if err != nil {
+			return nil, err
+		}
+		req.URL = query
+		return req, nil
+	}
+	body, err := json.Marshal(payload)
+	if err != nil {
+		return nil, fmt.Errorf("GraphQL请求体编码失败: %w", err)
+	}
+	req.Body = string(body)
+	return req, nil
+}
+
+// appendGraphQLQuery 将 GraphQL 请求编码为 URL 查询参数（query、variables、operationName、extensions）
+func appendGraphQLQuery(rawURL string, payload graphQLPayload) (string, error) {
+	params := url.Values{}
+	if payload.Query != "" {
+		params.Set("query", payload.Query)
+	}
+	if len(payload.Variables) > 0 {
+		variables, err := json.Marshal(payload.Variables)
+		if err != nil {
+			return "", fmt.Errorf("GraphQL变量编码失败: %w", err)
+		}
+		params.Set("variables", string(variables))
+	}
+	if payload.OperationName != "" {
+		params.Set("operationName", payload.OperationName)
+	}
+	if len(payload.Extensions) > 0 {
+		extensions, err := json.Marshal(payload.Extensions)
+		if err != nil {
+			return "", fmt.Errorf("GraphQL扩展编码失败: %w", err)
+		}
+		params.Set("extensions", string(extensions))
+	}
+	separator := "?"
+	if strings.Contains(rawURL, "?") {
+		separator = "&"
+	}
+	return rawURL + separator + params.Encode(), nil
+}
+
+// parseGraphQLErrors 解析响应体中的 errors 数组（非 JSON 响应返回空）
+func parseGraphQLErrors(body []byte) []graphQLError {
+	var result struct {
+		Errors []graphQLError `json:"errors"`
+	}
+	if err := json.Unmarshal(body, &result); err != nil {
+		return nil
+	}
+	return result.Errors
+}
+
+// isPersistedQueryNotFound 服务端是否因未缓存查询而拒绝了哈希请求
+func isPersistedQueryNotFound(errs []graphQLError) bool {
+	for _, e := range errs {
+		if e.Message == graphQLPersistedQueryNotFound || e.Extensions["code"] == graphQLPersistedQueryCode {
+			return true
+		}
+	}
+	return false
+}
+
+// GraphQLMiddleware GraphQL 中间件（位于客户端之上）
+// 持久化查询未命中时携带完整查询重发（耗时累加），响应 errors 数组非空时视为失败
+func GraphQLMiddleware() Middleware {
+	return func(next RequestHandler) RequestHandler {
+		return func(ctx context.Context, req *Request) (*Response, error) {
+			gql, ok := req.Metadata[graphQLMetadataKey].(*graphQLRequest)
+			if !ok {
+				return next(ctx, req)
+			}
+
+			resp, err := next(ctx, req)
+			if err != nil || resp == nil {
+				return resp, err
+			}
+			errs := parseGraphQLErrors(resp.Body)
+
+			if gql.config.PersistedQuery && isPersistedQueryNotFound(errs) {
+				hashOnly := resp.Duration
+				retry, err := buildGraphQLRequest(gql, true)
+				if err != nil {
+					resp.Error = err
+					return resp, err
+				}
+				resp, err = next(ctx, retry)
+				if err != nil || resp == nil {
+					return resp, err
+				}
+				resp.Duration += hashOnly
+				errs = parseGraphQLErrors(resp.Body)
+			}
+
+			if len(errs) > 0 {
+				err = fmt.Errorf("GraphQL错误: %s", errs[0].Message)
+				if len(errs) > 1 {
+					err = fmt.Errorf("GraphQL错误: %s（共%d个错误）", errs[0].Message, len(errs))
+				}
+				resp.Error = err
+				return resp, err
+			}
+			return resp, nil
+		}
+	}
+}
+
+// hasGraphQLAPIs 是否存在 GraphQL 接口
+func hasGraphQLAPIs(apis []APIConfig) bool {
+	for i := range apis {
+		if apis[i].GraphQL != nil {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,133 @@
+/*
+ * @Author: kamalyes 501893067@qq.com
+ * @Date: 2026-02-17 00:00:00
+ * @LastEditors: kamalyes 501893067@qq.com
+ * @LastEditTime: 2026-02-17 00:00:00
+ * @FilePath: \go-stress\executor\graphql_test.go
+ * @Description: GraphQL 请求构建和中间件测试
+ *
+ * Copyright (c) 2026 by kamalyes, All Rights Reserved.
+ */
+package executor
+
+import (
+	"context"
+	"encoding/json"
+	"math"
+	"net/url"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/kamalyes/go-stress/config"
+	"github.com/kamalyes/go-stress/logger"
+	"github.com/kamalyes/go-stress/statistics"
+	"github.com/kamalyes/go-stress/storage"
+	"github.com/stretchr/testify/assert"
+)
+
+const testGraphQLQuery = "query GetUser($id: ID!) { user(id: $id) { name } }"
+
+// 测试 GraphQL 请求体构建和变量替换
+func TestBuildGraphQLRequest(t *testing.T) {
+	gql := &config.GraphQLConfig{Query: testGraphQLQuery, Variables: map[string]any{"id": "{{.row.id}}", "tags": []any{"{{.row.id}}", 1}}}
+	assert.NoError(t, gql.Prepare())
+	assert.Equal(t, "GetUser", gql.OperationName)
+
+	replacer := NewVariableReplacer(config.NewVariableResolver(), nil).WithRow(map[string]string{"id": "42"})
+	apiCfg := replacer.ReplaceInAPIConfig(&APIConfig{URL: "http://localhost/graphql", Method: "POST", GraphQL: gql})
+	assert.Equal(t, "{{.row.id}}", gql.Variables["id"], "替换不修改原配置")
+
+	req, err := BuildRequest(apiCfg)
+	assert.NoError(t, err)
+	var payload map[string]any
+	assert.NoError(t, json.Unmarshal([]byte(req.Body), &payload))
+	assert.Equal(t, testGraphQLQuery, payload["query"])
+	assert.Equal(t, "GetUser", payload["operationName"])
+	assert.Equal(t, map[string]any{"id": "42", "tags": []any{"42", float64(1)}}, payload["variables"])
+
+	// GET 请求编码为查询参数，持久化查询只发送哈希
+	apiCfg.Method = "GET"
+	apiCfg.GraphQL.PersistedQuery = true
+	req, err = BuildRequest(apiCfg)
+	assert.NoError(t, err)
+	assert.Empty(t, req.Body)
+	u, err := url.Parse(req.URL)
+	assert.NoError(t, err)
+	assert.Empty(t, u.Query().Get("query"))
+	assert.Equal(t, "GetUser", u.Query().Get("operationName"))
+	assert.Contains(t, u.Query().Get("extensions"), `"sha256Hash":"`)
+}
+
+// 测试持久化查询未命中时携带查询重发，以及 errors 数组判定失败
+func TestGraphQLMiddleware(t *testing.T) {
+	var bodies []string
+	handler := GraphQLMiddleware()(func(ctx context.Context, req *Request) (*Response, error) {
+		bodies = append(bodies, req.Body)
+		body := `{"data":{"user":{"name":"alice"}}}`
+		switch {
+		case !strings.Contains(req.Body, `"query"`):
+			body = `{"errors":[{"message":"PersistedQueryNotFound","extensions":{"code":"PERSISTED_QUERY_NOT_FOUND"}}]}`
+		case strings.Contains(req.Body, "missing"):
+			body = `{"data":null,"errors":[{"message":"user not found"},{"message":"denied"}]}`
+		}
+		return &Response{StatusCode: 200, Body: []byte(body), Duration: time.Millisecond}, nil
+	})
+
+	gql := &config.GraphQLConfig{Query: testGraphQLQuery, OperationName: "GetUser", PersistedQuery: true}
+	req, err := BuildRequest(&APIConfig{Method: "POST", GraphQL: gql})
+	assert.NoError(t, err)
+	resp, err := handler(context.Background(), req)
+	assert.NoError(t, err)
+	assert.Len(t, bodies, 2)
+	assert.NotContains(t, bodies[0], `"query"`)
+	assert.Contains(t, bodies[1], `"query"`)
+	assert.Contains(t, bodies[1], `"sha256Hash"`)
+	assert.Equal(t, 2*time.Millisecond, resp.Duration, "耗时包含哈希请求")
+
+	gql = &config.GraphQLConfig{Query: testGraphQLQuery, Variables: map[string]any{"id": "missing"}}
+	req, err = BuildRequest(&APIConfig{Method: "POST", GraphQL: gql})
+	assert.NoError(t, err)
+	resp, err = handler(context.Background(), req)
+	assert.EqualError(t, err, "GraphQL错误: user not found（共2个错误）")
+	assert.Equal(t, 200, resp.StatusCode)
+	assert.Equal(t, err, resp.Error)
+
+	// 非 GraphQL 请求直接透传
+	resp, err = handler(context.Background(), &Request{Body: "missing"})
+	assert.NoError(t, err)
+	assert.NotNil(t, resp)
+}
+
+// 测试变量无法编码为 JSON 时不发送请求，记为请求失败
+func TestGraphQLEncodeFailure(t *testing.T) {
+	gql := &config.GraphQLConfig{Query: testGraphQLQuery, OperationName: "GetUser", Variables: map[string]any{"ratio": math.Inf(1)}}
+	_, err := BuildRequest(&APIConfig{Method: "POST", GraphQL: gql})
+	assert.ErrorContains(t, err, "GraphQL请求体编码失败")
+	_, err = BuildRequest(&APIConfig{Method: "GET", GraphQL: gql})
+	assert.ErrorContains(t, err, "GraphQL变量编码失败")
+
+	collector := statistics.NewCollector(storage.NewMemoryStorage("test", logger.Default), logger.Default)
+	defer collector.Close()
+
+	sent := 0
+	handler := func(ctx context.Context, req *Request) (*Response, error) {
+		sent++
+		return &Response{StatusCode: 200}, nil
+	}
+	cfg := &config.Config{APIs: []config.APIConfig{{Name: "GetUser", URL: "http://localhost/graphql", Method: "POST", GraphQL: gql}}}
+	cfg.SetLogger(logger.Default)
+	worker := NewWorker(WorkerConfig{
+		Client:      handlerClient(handler),
+		Collector:   collector,
+		ReqCount:    2,
+		APISelector: CreateAPISelector(cfg),
+		Logger:      logger.Default,
+	}, config.NewVariableResolver())
+	assert.NoError(t, worker.Run(context.Background()))
+
+	assert.Equal(t, 0, sent)
+	snapshot := collector.GetSnapshot()
+	assert.Equal(t, uint64(2), snapshot.TotalRequests)
+	assert.Equal(t, uint64(2), snapshot.FailedRequests)
+}
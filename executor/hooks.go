@@ -0,0 +1,185 @@
+/*
+ * @Author: kamalyes 501893067@qq.com
+ * @Date: 2026-02-22 00:00:00
+ * @LastEditors: kamalyes 501893067@qq.com
+ * @LastEditTime: 2026-02-22 00:00:00
+ * @FilePath: \go-stress\executor\hooks.go
+ * @Description: 生命周期步骤 - worker 级 setup/teardown 与全局 before_all/after_all
+ *
+ * Copyright (c) 2026 by kamalyes, All Rights Reserved.
+ */
+package executor
+
+import (
+	"context"
+	"fmt"
+	"maps"
+	"slices"
+	"time"
+)
+
+// cleanupTimeout 压测被取消后清理步骤（teardown/after_all）的最长执行时间
+const cleanupTimeout = 30 * time.Second
+
+// WorkerHooks worker 的生命周期步骤（请求不计入统计和进度）
+type WorkerHooks struct {
+	Setup    []APIConfig       // 前置步骤（worker 启动时执行一次）
+	Teardown []APIConfig       // 后置步骤（worker 退出时执行）
+	Vars     map[string]string // 初始变量（before_all 提取的变量）
+}
+
+// withScenario 追加场景的前置/后置步骤（场景 setup 在全局之后，teardown 在全局之前）
+func (h WorkerHooks) withScenario(scenario *Scenario) WorkerHooks {
+	if scenario == nil {
+		return h
+	}
+	h.Setup = slices.Concat(h.Setup, scenario.Setup)
+	h.Teardown = slices.Concat(scenario.Teardown, h.Teardown)
+	return h
+}
+
+// setupSession 执行前置步骤，提取的变量保存为会话变量（每轮迭代开始时注入依赖上下文）
+func (w *Worker) setupSession(ctx context.Context) error {
+	if len(w.hooks.Setup) == 0 {
+		return nil
+	}
+
+	// 前置步骤使用一行数据（如 unique_per_worker 策略下每个 worker 使用自己的账号登录），
+	// 该行同时作为首轮迭代的数据行，不会额外消耗 unique_once 数据
+	if w.dataFeeder != nil {
+		if row, ok := w.dataFeeder.Next(w.id); ok {
+			w.row = row
+			w.setupRow = true
+		}
+	}
+
+	w.resetDepContext()
+	err := w.runHook(ctx, "setup", w.hooks.Setup, true)
+	w.sessionVars = maps.Clone(w.depContext.extractedVars)
+	return err
+}
+
+// teardownSession 执行后置步骤（压测被取消时仍然执行）
+func (w *Worker) teardownSession(ctx context.Context) {
+	if len(w.hooks.Teardown) == 0 {
+		return
+	}
+
+	ctx, cancel := cleanupContext(ctx)
+	defer cancel()
+	w.resetDepContext()
+	_ = w.runHook(ctx, "teardown", w.hooks.Teardown, false)
+}
+
+// resetDepContext 重置本地依赖上下文，保留会话变量
+func (w *Worker) resetDepContext() {
+	w.depContext = NewWorkerDependencyContext()
+	maps.Copy(w.depContext.extractedVars, w.sessionVars)
+}
+
+// runHook 按顺序执行一组步骤，stopOnFailure 为 true 时遇到失败立即返回，否则记录后继续执行
+func (w *Worker) runHook(ctx context.Context, phase string, steps []APIConfig, stopOnFailure bool) error {
+	var firstErr error
+	for i := range steps {
+		err := w.runHookStep(ctx, &steps[i])
+		if err == nil {
+			w.logger.Debugf("🪝 Worker %d: %s 步骤 [%s] 完成", w.id, phase, steps[i].Name)
+			continue
+		}
+
+		err = fmt.Errorf("%s 步骤 [%s] 失败: %w", phase, steps[i].Name, err)
+		w.logger.Errorf("❌ Worker %d: %v", w.id, err)
+		if stopOnFailure {
+			return err
+		}
+		if firstErr == nil {
+			firstErr = err
+		}
+	}
+	return firstErr
+}
+
+// runHookStep 执行单个步骤：发送请求、提取变量并验证响应（结果不提交给收集器）
+func (w *Worker) runHookStep(ctx context.Context, api *APIConfig) error {
+	apiCfg := w.newReplacer().ReplaceInAPIConfig(api)
+	req, err := BuildRequest(apiCfg)
+	if err != nil {
+		return err
+	}
+
+	resp, err := w.send(ctx, api.ClientKey(), apiCfg, req)
+	if err != nil {
+		return err
+	}
+	if resp == nil {
+		return nil
+	}
+
+	if len(apiCfg.Extractors) > 0 {
+		w.extractAndStoreVarsLocal(apiCfg, req, resp)
+	}
+	if len(apiCfg.Verify) > 0 {
+		return w.executeVerifications(apiCfg, resp)
+	}
+	return nil
+}
+
+// cleanupContext 清理步骤使用的上下文：不随压测取消，但最多执行 cleanupTimeout
+func cleanupContext(ctx context.Context) (context.Context, context.CancelFunc) {
+	return context.WithTimeout(context.WithoutCancel(ctx), cleanupTimeout)
+}
+
+// RunBeforeAll 执行全局前置步骤，提取的变量注入之后创建的所有 worker
+func (s *Scheduler) RunBeforeAll(ctx context.Context) error {
+	if len(s.beforeAll) == 0 {
+		return nil
+	}
+
+	s.logger.Infof("🪝 执行 before_all: %d 个步骤", len(s.beforeAll))
+	return s.withHookWorker(ctx, func(w *Worker) error {
+		if err := w.runHook(ctx, "before_all", s.beforeAll, true); err != nil {
+			return err
+		}
+		s.hooks.Vars = maps.Clone(w.depContext.extractedVars)
+		return nil
+	})
+}
+
+// RunAfterAll 执行全局后置步骤（可使用 before_all 提取的变量，压测被取消时仍然执行）
+func (s *Scheduler) RunAfterAll(ctx context.Context) error {
+	if len(s.afterAll) == 0 {
+		return nil
+	}
+
+	ctx, cancel := cleanupContext(ctx)
+	defer cancel()
+	s.logger.Infof("🪝 执行 after_all: %d 个步骤", len(s.afterAll))
+	return s.withHookWorker(ctx, func(w *Worker) error {
+		return w.runHook(ctx, "after_all", s.afterAll, false)
+	})
+}
+
+// withHookWorker 使用连接池创建的客户端执行全局步骤
+// 客户端单独建立连接，用完关闭后不再放回连接池，避免 worker 取到已关闭的客户端
+func (s *Scheduler) withHookWorker(ctx context.Context, fn func(w *Worker) error) error {
+	client, err := s.clientPool.Get()
+	if err != nil {
+		return fmt.Errorf("获取客户端失败: %w", err)
+	}
+	defer client.Close()
+
+	if err := client.Connect(ctx); err != nil {
+		return fmt.Errorf("连接失败: %w", err)
+	}
+
+	worker := NewWorker(WorkerConfig{
+		Client:           client,
+		Handler:          s.middlewares.Handler,
+		Collector:        s.collector,
+		Hooks:            &WorkerHooks{Vars: s.hooks.Vars},
+		APIClientFactory: s.apiClientFactory,
+		Logger:           s.logger,
+	}, s.varResolver)
+	defer worker.closeAPIClients()
+	return fn(worker)
+}
@@ -0,0 +1,218 @@
+/*
+ * @Author: kamalyes 501893067@qq.com
+ * @Date: 2026-02-22 00:00:00
+ * @LastEditors: kamalyes 501893067@qq.com
+ * @LastEditTime: 2026-02-22 00:00:00
+ * @FilePath: \go-stress\executor\hooks_test.go
+ * @Description: 生命周期步骤测试
+ *
+ * Copyright (c) 2026 by kamalyes, All Rights Reserved.
+ */
+package executor
+
+import (
+	"context"
+	"errors"
+	"strings"
+	"sync"
+	"testing"
+
+	"github.com/kamalyes/go-stress/config"
+	"github.com/kamalyes/go-stress/logger"
+	"github.com/kamalyes/go-stress/statistics"
+	"github.com/kamalyes/go-stress/storage"
+	"github.com/stretchr/testify/assert"
+)
+
+// hookRecorder 记录请求路径的处理器（fail 指定的路径返回错误）
+type hookRecorder struct {
+	mu   sync.Mutex
+	sent []string
+	fail string // 返回错误的路径
+}
+
+func (r *hookRecorder) handle(ctx context.Context, req *Request) (*Response, error) {
+	path := strings.TrimPrefix(req.URL, "http://localhost")
+	r.mu.Lock()
+	r.sent = append(r.sent, path)
+	r.mu.Unlock()
+	if path == r.fail {
+		return nil, errors.New("connection refused")
+	}
+	return &Response{StatusCode: 200, Body: []byte(`{"token":"t-1","id":7}`)}, nil
+}
+
+// 测试 setup 提取的变量跨迭代保留，setup/teardown 请求不计入统计
+func TestWorkerSetupTeardown(t *testing.T) {
+	collector := statistics.NewCollector(storage.NewMemoryStorage("test", logger.Default), logger.Default)
+	defer collector.Close()
+
+	recorder := &hookRecorder{}
+	cfg := &config.Config{APIs: []config.APIConfig{
+		{Name: "orders", URL: "http://localhost/orders?token={{.login.token}}", Method: "GET"},
+	}}
+	cfg.SetLogger(logger.Default)
+	worker := NewWorker(WorkerConfig{
+		Client:      handlerClient(recorder.handle),
+		Collector:   collector,
+		ReqCount:    3,
+		APISelector: CreateAPISelector(cfg),
+		Hooks: &WorkerHooks{
+			Setup: []config.APIConfig{{Name: "login", URL: "http://localhost/login", Method: "POST",
+				Extractors: []config.ExtractorConfig{{Name: "token", JSONPath: "$.token"}}}},
+			Teardown: []config.APIConfig{{Name: "logout", URL: "http://localhost/logout?token={{.login.token}}", Method: "POST"}},
+		},
+		Logger: logger.Default,
+	}, config.NewVariableResolver())
+	assert.NoError(t, worker.Run(context.Background()))
+
+	assert.Equal(t, []string{
+		"/login",
+		"/orders?token=t-1",
+		"/orders?token=t-1",
+		"/orders?token=t-1",
+		"/logout?token=t-1",
+	}, recorder.sent)
+	assert.Equal(t, uint64(3), collector.GetSnapshot().TotalRequests)
+}
+
+// 测试 setup 失败时不执行迭代但仍执行 teardown，场景步骤包裹在全局步骤内
+func TestWorkerSetupFailure(t *testing.T) {
+	collector := statistics.NewCollector(storage.NewMemoryStorage("test", logger.Default), logger.Default)
+	defer collector.Close()
+
+	recorder := &hookRecorder{fail: "/fixture"}
+	cfg := &config.Config{APIs: []config.APIConfig{{Name: "orders", URL: "http://localhost/orders", Method: "GET"}}}
+	cfg.SetLogger(logger.Default)
+	worker := NewWorker(WorkerConfig{
+		Client:    handlerClient(recorder.handle),
+		Collector: collector,
+		ReqCount:  3,
+		Scenario: &Scenario{
+			Name:     "buyer",
+			Selector: CreateAPISelector(cfg),
+			Setup:    []config.APIConfig{{Name: "fixture", URL: "http://localhost/fixture", Method: "POST"}},
+			Teardown: []config.APIConfig{{Name: "cleanup", URL: "http://localhost/cleanup", Method: "DELETE"}},
+		},
+		Hooks: &WorkerHooks{
+			Setup:    []config.APIConfig{{Name: "login", URL: "http://localhost/login", Method: "POST"}},
+			Teardown: []config.APIConfig{{Name: "logout", URL: "http://localhost/logout", Method: "POST"}},
+		},
+		Logger: logger.Default,
+	}, config.NewVariableResolver())
+
+	err := worker.Run(context.Background())
+	assert.ErrorContains(t, err, "setup 步骤 [fixture] 失败")
+	assert.Equal(t, []string{"/login", "/fixture", "/cleanup", "/logout"}, recorder.sent)
+	assert.Equal(t, uint64(0), collector.GetSnapshot().TotalRequests)
+}
+
+// 测试 before_all 提取的变量对所有 worker 和 after_all 可用
+func TestSchedulerBeforeAfterAll(t *testing.T) {
+	collector := statistics.NewCollector(storage.NewMemoryStorage("test", logger.Default), logger.Default)
+	defer collector.Close()
+
+	recorder := &hookRecorder{}
+	cfg := &config.Config{APIs: []config.APIConfig{
+		{Name: "view", URL: "http://localhost/items/{{.create.id}}", Method: "GET"},
+	}}
+	cfg.SetLogger(logger.Default)
+	pool := NewClientPool(func() (Client, error) { return handlerClient(recorder.handle), nil }, 2)
+	defer pool.Close()
+	scheduler := NewScheduler(SchedulerConfig{
+		WorkerCount:      2,
+		RequestPerWorker: 1,
+		ClientPool:       pool,
+		Collector:        collector,
+		APISelector:      CreateAPISelector(cfg),
+		VarResolver:      config.NewVariableResolver(),
+		BeforeAll: []config.APIConfig{{Name: "create", URL: "http://localhost/items", Method: "POST",
+			Extractors: []config.ExtractorConfig{{Name: "id", JSONPath: "$.id"}}}},
+		AfterAll: []config.APIConfig{{Name: "delete", URL: "http://localhost/items/{{.create.id}}", Method: "DELETE"}},
+		Logger:   logger.Default,
+	})
+
+	ctx := context.Background()
+	assert.NoError(t, scheduler.RunBeforeAll(ctx))
+	assert.NoError(t, scheduler.Run(ctx))
+	assert.NoError(t, scheduler.RunAfterAll(ctx))
+
+	assert.Equal(t, []string{"/items", "/items/7", "/items/7", "/items/7"}, recorder.sent)
+	assert.Equal(t, uint64(2), collector.GetSnapshot().TotalRequests)
+}
+
+// closeTrackingClient 记录是否已关闭的客户端
+type closeTrackingClient struct {
+	Client
+	closed bool
+}
+
+func (c *closeTrackingClient) Close() error {
+	c.closed = true
+	return nil
+}
+
+// 测试全局步骤的客户端用完关闭且不放回连接池
+func TestHookClientNotPooled(t *testing.T) {
+	recorder := &hookRecorder{}
+	var created []*closeTrackingClient
+	pool := NewClientPool(func() (Client, error) {
+		client := &closeTrackingClient{Client: handlerClient(recorder.handle)}
+		created = append(created, client)
+		return client, nil
+	}, 1)
+	defer pool.Close()
+
+	scheduler := NewScheduler(SchedulerConfig{
+		ClientPool:  pool,
+		VarResolver: config.NewVariableResolver(),
+		BeforeAll:   []config.APIConfig{{Name: "seed", URL: "http://localhost/seed", Method: "POST"}},
+		Logger:      logger.Default,
+	})
+	assert.NoError(t, scheduler.RunBeforeAll(context.Background()))
+	assert.Len(t, created, 1)
+	assert.True(t, created[0].closed)
+
+	client, err := pool.Get()
+	assert.NoError(t, err)
+	assert.False(t, client.(*closeTrackingClient).closed, "worker 不会取到已关闭的客户端")
+}
+
+// 测试 setup 取得的数据行由首轮迭代沿用，不额外消耗 unique_once 数据
+func TestWorkerSetupRow(t *testing.T) {
+	collector := statistics.NewCollector(storage.NewMemoryStorage("test", logger.Default), logger.Default)
+	defer collector.Close()
+
+	feeder, err := NewDataFeeder([]config.DataSourceConfig{
+		{Name: "users", Policy: config.DataPolicyUniqueOnce, Rows: testRows("a", "b", "c")},
+	}, 1, logger.Default)
+	assert.NoError(t, err)
+
+	recorder := &hookRecorder{}
+	cfg := &config.Config{APIs: []config.APIConfig{{Name: "orders", URL: "http://localhost/orders?u={{.row.user_id}}", Method: "GET"}}}
+	cfg.SetLogger(logger.Default)
+	worker := NewWorker(WorkerConfig{
+		Client:      handlerClient(recorder.handle),
+		Collector:   collector,
+		ReqCount:    5,
+		APISelector: CreateAPISelector(cfg),
+		DataFeeder:  feeder,
+		Hooks: &WorkerHooks{
+			Setup: []config.APIConfig{{Name: "login", URL: "http://localhost/login?u={{.row.user_id}}", Method: "POST"}},
+		},
+		Logger: logger.Default,
+	}, config.NewVariableResolver())
+	assert.NoError(t, worker.Run(context.Background()))
+
+	assert.Equal(t, []string{"/login?u=a", "/orders?u=a", "/orders?u=b", "/orders?u=c"}, recorder.sent)
+}
+
+// 测试分布式模式下 slave 跳过 before_all/after_all
+func TestExecutorGlobalHooksDistributed(t *testing.T) {
+	cfg := &config.Config{BeforeAll: []config.APIConfig{{Name: "seed", URL: "http://localhost/seed"}}}
+	e := &Executor{config: cfg, logger: logger.Default}
+	assert.True(t, e.runGlobalHooks())
+
+	e.isDistributed = true
+	assert.False(t, e.runGlobalHooks())
+}
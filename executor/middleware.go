@@ -116,3 +116,14 @@ func ClientMiddleware(client Client) RequestHandler {
 		return client.Send(ctx, req)
 	}
 }
+
+// HandlerBuilder 为指定客户端构建请求处理器（每个 worker 的客户端各自构建一次）
+type HandlerBuilder func(client Client) RequestHandler
+
+// Handler 构建以指定客户端为最底层的处理器（未配置中间件时直接使用客户端）
+func (mc *MiddlewareChain) Handler(client Client) RequestHandler {
+	if mc == nil {
+		return ClientMiddleware(client)
+	}
+	return mc.Build(ClientMiddleware(client))
+}
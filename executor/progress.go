@@ -13,6 +13,7 @@
 import (
 	"context"
 	"fmt"
+	"math"
 	"strings"
 	"sync/atomic"
 	"time"
@@ -27,6 +28,7 @@
 // ProgressTracker 进度跟踪器
 type ProgressTracker struct {
 	total         uint64
+	duration      time.Duration // 计划持续时间（>0 时按时间显示进度）
 	completed     uint64
 	startTime     time.Time
 	collector     *statistics.Collector
@@ -57,15 +59,24 @@ func NewProgressTrackerWithCollector(total uint64, collector *statistics.Collect
 	}
 }
 
+// SetDuration 设置计划持续时间，切换为按时间显示进度
+func (pt *ProgressTracker) SetDuration(duration time.Duration) {
+	pt.duration = duration
+}
+
 // Increment 增加完成数
 func (pt *ProgressTracker) Increment() uint64 {
 	return atomic.AddUint64(&pt.completed, 1)
 }
 
-// GetProgress 获取当前进度
+// GetProgress 获取当前进度（按时间运行时百分比为已耗时占计划时长的比例）
 func (pt *ProgressTracker) GetProgress() (completed, total uint64, percentage float64) {
 	completed = atomic.LoadUint64(&pt.completed)
 	total = pt.total
+	if pt.duration > 0 {
+		percentage = math.Min(float64(time.Since(pt.startTime))/float64(pt.duration)*100, 100)
+		return
+	}
 	percentage = float64(completed) / float64(total) * 100
 	return
 }
@@ -116,9 +127,16 @@ func (pt *ProgressTracker) printProgress(elapsed time.Duration) {
 		pt.headerPrinted = true
 	}
 
-	// 格式化每个字段
+	// 格式化每个字段（按时间运行时显示 已耗时/计划时长）
 	timeStr := fmt.Sprintf("%-4s", fmt.Sprintf("%ds", int(seconds)))
+	if pt.duration > 0 {
+		timeStr = fmt.Sprintf("%ds/%ds", int(seconds), int(pt.duration.Seconds()))
+	}
 	concurrencyStr := fmt.Sprintf("%-6d", pt.workerCount)
+	if stage := pt.collector.GetStage(); stage != nil {
+		// 多阶段模式下展示当前活跃worker数
+		concurrencyStr = fmt.Sprintf("%-6d", stage.ActiveWorkers)
+	}
 	successStr := fmt.Sprintf("%-6d", stats.SuccessRequests)
 	failedStr := fmt.Sprintf("%-6d", stats.FailedRequests)
 	qpsStr := fmt.Sprintf("%4.2f", qps)
@@ -141,7 +159,12 @@ func (pt *ProgressTracker) printSimpleProgress(elapsed time.Duration) {
 
 	// 计算预估剩余时间
 	var eta time.Duration
-	if completed > 0 {
+	progressStr := fmt.Sprintf("%d/%d (%.2f%%)", completed, total, percentage)
+	if pt.duration > 0 {
+		// 按时间运行：剩余时间即计划时长减去已耗时
+		eta = max(pt.duration-elapsed, 0)
+		progressStr = fmt.Sprintf("%s/%s (%.2f%%)", elapsed.Round(time.Second), pt.duration, percentage)
+	} else if completed > 0 {
 		avgTimePerReq := elapsed / time.Duration(completed)
 		remaining := total - completed
 		eta = avgTimePerReq * time.Duration(remaining)
@@ -153,7 +176,7 @@ func (pt *ProgressTracker) printSimpleProgress(elapsed time.Duration) {
 	// 构建表格数据
 	tableData := []map[string]interface{}{
 		{
-			"进度":   fmt.Sprintf("%d/%d (%.2f%%)", completed, total, percentage),
+			"进度":   progressStr,
 			"耗时":   elapsed.Round(time.Second).String(),
 			"预计剩余": eta.Round(time.Second).String(),
 			"QPS":  fmt.Sprintf("%.2f", qps),
@@ -182,7 +205,7 @@ func buildStatusStr(statusCodes map[int]uint64) string {
 
 	var parts []string
 	for code, count := range statusCodes {
-		parts = append(parts, fmt.Sprintf("%d:%d", code, count))
+		parts = append(parts, fmt.Sprintf("%s:%d", StatusCodeLabel(code), count))
 	}
 	return strings.Join(parts, " ")
 }
@@ -27,14 +27,18 @@
 
 // RunOptions 任务执行选项（通用，支持独立模式和分布式模式）
 type RunOptions struct {
-	// === 配置来源（三选一） ===
-	ConfigFile string                // 配置文件路径
-	CurlFile   string                // curl 文件路径
-	ConfigFunc func() *config.Config // 从命令行构建配置的函数
+	// === 配置来源（五选一） ===
+	ConfigFile   string                // 配置文件路径
+	CurlFile     string                // curl 文件路径
+	HARFile      string                // HAR 文件路径
+	PostmanFile  string                // Postman v2.1 集合文件路径
+	ConfigFunc   func() *config.Config // 从命令行构建配置的函数
+	ImportFilter *config.ImportFilter  // HAR/Postman 导入过滤器（nil 时默认过滤静态资源）
 
 	// === 运行时参数 ===
 	Concurrency uint64        // 并发数（可覆盖配置文件）
 	Requests    uint64        // 请求数（可覆盖配置文件）
+	Duration    time.Duration // 持续时间（可覆盖配置文件，优先级高于请求数）
 	Timeout     time.Duration // 超时时间（可覆盖配置文件）
 
 	// === 存储配置 ===
@@ -51,7 +55,7 @@ type RunOptions struct {
 	ExternalCollector *statistics.Collector // 外部 Collector（Slave 模式使用）
 	NoReport          bool                  // 不生成报告文件（Slave 模式使用）
 	NoPrint           bool                  // 不打印报告（Slave 模式使用）
-	NoWait            bool                  // 不等待退出（Slave 模式使用）
+	NoWait            bool                  // 不等待退出（Slave 模式、CI 使用）
 }
 
 // RunResult 任务执行结果
@@ -106,6 +110,9 @@ func RunTask(opts RunOptions) *RunResult {
 		return result
 	}
 	result.Executor = exec
+	if opts.IsDistributed {
+		exec.isDistributed = true
+	}
 
 	// 如果提供了外部 Collector，替换掉
 	if opts.ExternalCollector != nil {
@@ -154,6 +161,11 @@ func RunTask(opts RunOptions) *RunResult {
 	// === 9. 等待退出（策略决定） ===
 	strategy.WaitForExit(exec, sigCh, ctx)
 
+	// === 10. SLO 阈值未达标时返回错误（用于 CI 判定） ===
+	if !opts.IsDistributed && report != nil && !report.ThresholdsPassed() {
+		result.Error = ErrThresholdsBreached
+	}
+
 	return result
 }
 
@@ -173,16 +185,29 @@ func loadConfig(opts RunOptions) (*config.Config, error) {
 		if err != nil {
 			return nil, fmt.Errorf("解析curl文件失败: %w", err)
 		}
-		// 命令行参数覆盖
-		if opts.Concurrency > 0 {
-			cfg.Concurrency = opts.Concurrency
+		applyCLIOverrides(cfg, opts)
+	} else if opts.HARFile != "" {
+		opts.Logger.InfoKV("📄 导入HAR文件", "file", opts.HARFile)
+		data, err := os.ReadFile(opts.HARFile)
+		if err != nil {
+			return nil, fmt.Errorf("读取HAR文件失败: %w", err)
 		}
-		if opts.Requests > 0 {
-			cfg.Requests = opts.Requests
+		cfg, err = config.NewHARParser(data, opts.ImportFilter, opts.Logger)
+		if err != nil {
+			return nil, fmt.Errorf("导入HAR文件失败: %w", err)
 		}
-		if opts.Timeout > 0 {
-			cfg.Timeout = opts.Timeout
+		applyCLIOverrides(cfg, opts)
+	} else if opts.PostmanFile != "" {
+		opts.Logger.InfoKV("📄 导入Postman集合", "file", opts.PostmanFile)
+		data, err := os.ReadFile(opts.PostmanFile)
+		if err != nil {
+			return nil, fmt.Errorf("读取Postman集合失败: %w", err)
 		}
+		cfg, err = config.NewPostmanParser(data, opts.ImportFilter, opts.Logger)
+		if err != nil {
+			return nil, fmt.Errorf("导入Postman集合失败: %w", err)
+		}
+		applyCLIOverrides(cfg, opts)
 	} else if opts.ConfigFile != "" {
 		// 从配置文件加载
 		opts.Logger.InfoKV("📄 加载配置文件", "file", opts.ConfigFile)
@@ -195,16 +220,32 @@ func loadConfig(opts RunOptions) (*config.Config, error) {
 		// 使用命令行参数
 		cfg = opts.ConfigFunc()
 	} else {
-		return nil, fmt.Errorf("必须提供配置文件、curl文件或命令行参数")
+		return nil, fmt.Errorf("必须提供配置文件、curl文件、HAR文件、Postman集合或命令行参数")
 	}
 
 	return cfg, nil
 }
 
+// applyCLIOverrides 命令行参数覆盖（curl/HAR/Postman 导入的配置不含运行参数）
+func applyCLIOverrides(cfg *config.Config, opts RunOptions) {
+	if opts.Concurrency > 0 {
+		cfg.Concurrency = opts.Concurrency
+	}
+	if opts.Requests > 0 {
+		cfg.Requests = opts.Requests
+	}
+	if opts.Duration > 0 {
+		cfg.Duration = opts.Duration
+	}
+	if opts.Timeout > 0 {
+		cfg.Timeout = opts.Timeout
+	}
+}
+
 // validateConfig 验证配置
 func validateConfig(cfg *config.Config) error {
 	// 多API模式下，URL已经在config.Loader中验证过了
-	if len(cfg.APIs) == 0 {
+	if len(cfg.APIs) == 0 && len(cfg.Scenarios) == 0 {
 		if cfg.URL == "" {
 			return fmt.Errorf("URL不能为空")
 		}
@@ -214,8 +255,8 @@ func validateConfig(cfg *config.Config) error {
 		return fmt.Errorf("并发数不能为0")
 	}
 
-	if cfg.Requests == 0 {
-		return fmt.Errorf("请求数不能为0")
+	if cfg.Requests == 0 && cfg.Duration == 0 && len(cfg.Stages) == 0 {
+		return fmt.Errorf("请求数和持续时间不能同时为0")
 	}
 
 	// gRPC特定验证
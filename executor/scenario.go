@@ -0,0 +1,117 @@
+/*
+ * @Author: kamalyes 501893067@qq.com
+ * @Date: 2026-02-21 00:00:00
+ * @LastEditors: kamalyes 501893067@qq.com
+ * @LastEditTime: 2026-02-21 00:00:00
+ * @FilePath: \go-stress\executor\scenario.go
+ * @Description: 多场景配比 - 按权重将 worker 分配到各场景
+ *
+ * Copyright (c) 2026 by kamalyes, All Rights Reserved.
+ */
+package executor
+
+import (
+	"slices"
+
+	"github.com/kamalyes/go-stress/config"
+	"github.com/kamalyes/go-toolbox/pkg/mathx"
+)
+
+// Scenario 场景（一类用户的完整流程）
+type Scenario struct {
+	Name      string
+	Weight    int
+	Selector  APISelector    // 场景内的API选择器
+	Variables map[string]any // 场景变量（模板中直接访问，覆盖同名全局变量）
+	Setup     []APIConfig    // 场景前置步骤（在全局 setup 之后执行）
+	Teardown  []APIConfig    // 场景后置步骤（在全局 teardown 之前执行）
+}
+
+// ScenarioMix 场景配比
+// 按平滑加权轮询预先生成一个周期的分配序列，worker ID 对周期取模得到场景，
+// 任意数量的连续 worker 中各场景的占比都接近权重比例
+type ScenarioMix struct {
+	scenarios []*Scenario
+	sequence  []int // 一个周期内每个位置对应的场景下标
+}
+
+// NewScenarioMix 根据配置创建场景配比（未配置场景时返回 nil）
+func NewScenarioMix(cfg *config.Config) *ScenarioMix {
+	if len(cfg.Scenarios) == 0 {
+		return nil
+	}
+
+	mix := &ScenarioMix{}
+	weights := make([]int, 0, len(cfg.Scenarios))
+	for _, sc := range cfg.Scenarios {
+		// 每个场景使用独立的配置副本创建选择器，公共配置与全局一致
+		// API 列表复制一份，选择器对配置的修改不会写回原场景
+		scenarioCfg := *cfg
+		scenarioCfg.APIs = slices.Clone(sc.APIs)
+		scenarioCfg.Scenarios = nil
+		weight := mathx.IfNotZero(sc.Weight, 1)
+		mix.scenarios = append(mix.scenarios, &Scenario{
+			Name:      sc.Name,
+			Weight:    weight,
+			Selector:  CreateAPISelector(&scenarioCfg),
+			Variables: sc.Variables,
+			Setup:     sc.Setup,
+			Teardown:  sc.Teardown,
+		})
+		weights = append(weights, weight)
+	}
+	mix.sequence = smoothWeightedSequence(weights)
+	return mix
+}
+
+// Assign 按 worker ID 分配场景（未配置场景时返回 nil）
+func (m *ScenarioMix) Assign(workerID uint64) *Scenario {
+	if m == nil {
+		return nil
+	}
+	return m.scenarios[m.sequence[workerID%uint64(len(m.sequence))]]
+}
+
+// Scenarios 所有场景
+func (m *ScenarioMix) Scenarios() []*Scenario {
+	if m == nil {
+		return nil
+	}
+	return m.scenarios
+}
+
+// smoothWeightedSequence 平滑加权轮询生成一个周期（周期长度为权重之和除以最大公约数）
+func smoothWeightedSequence(weights []int) []int {
+	divisor := 0
+	for _, w := range weights {
+		divisor = gcd(divisor, w)
+	}
+	total := 0
+	for i := range weights {
+		weights[i] /= divisor
+		total += weights[i]
+	}
+
+	sequence := make([]int, 0, total)
+	current := make([]int, len(weights))
+	for range total {
+		best := 0
+		for i, w := range weights {
+			current[i] += w
+			if current[i] > current[best] {
+				best = i
+			}
+		}
+		current[best] -= total
+		sequence = append(sequence, best)
+	}
+	return sequence
+}
+
+// gcd 最大公约数
+func gcd(a, b int) int {
+	for b != 0 {
+		a, b = b, a%b
+	}
+	return a
+}
@@ -0,0 +1,92 @@
+/*
+ * @Author: kamalyes 501893067@qq.com
+ * @Date: 2026-02-21 00:00:00
+ * @LastEditors: kamalyes 501893067@qq.com
+ * @LastEditTime: 2026-02-21 00:00:00
+ * @FilePath: \go-stress\executor\scenario_test.go
+ * @Description: 多场景配比测试
+ *
+ * Copyright (c) 2026 by kamalyes, All Rights Reserved.
+ */
+package executor
+
+import (
+	"context"
+	"testing"
+
+	"github.com/kamalyes/go-stress/config"
+	"github.com/kamalyes/go-stress/logger"
+	"github.com/kamalyes/go-stress/statistics"
+	"github.com/kamalyes/go-stress/storage"
+	"github.com/stretchr/testify/assert"
+)
+
+// 测试按权重分配 worker
+func TestScenarioMixAssign(t *testing.T) {
+	assert.Nil(t, NewScenarioMix(&config.Config{}).Assign(3))
+
+	cfg := &config.Config{Scenarios: []config.ScenarioConfig{
+		{Name: "browser", Weight: 70, APIs: []config.APIConfig{{Name: "home", URL: "http://localhost/"}}},
+		{Name: "buyer", Weight: 20, APIs: []config.APIConfig{{Name: "cart", URL: "http://localhost/cart"}}},
+		{Name: "admin", Weight: 10, APIs: []config.APIConfig{{Name: "dashboard", URL: "http://localhost/admin"}}},
+	}}
+	cfg.SetLogger(logger.Default)
+	mix := NewScenarioMix(cfg)
+
+	counts := map[string]int{}
+	for id := uint64(0); id < 10; id++ {
+		counts[mix.Assign(id).Name]++
+	}
+	assert.Equal(t, map[string]int{"browser": 7, "buyer": 2, "admin": 1}, counts, "一个周期内严格按比例分配")
+
+	// 少量 worker 时各场景也尽早分配到
+	first := map[string]bool{}
+	for id := uint64(0); id < 5; id++ {
+		first[mix.Assign(id).Name] = true
+	}
+	assert.Len(t, first, 2)
+	assert.Equal(t, mix.Assign(3), mix.Assign(13))
+	assert.Nil(t, cfg.APIs, "场景不修改全局APIs")
+
+	// 选择器持有场景API的副本，修改不会写回场景配置
+	mix.Assign(0).Selector.Next().URL = "http://localhost/changed"
+	assert.Equal(t, "http://localhost/", cfg.Scenarios[0].APIs[0].URL)
+}
+
+// 测试场景 worker 使用场景的API和变量，并按场景标记结果
+func TestWorkerScenario(t *testing.T) {
+	collector := statistics.NewCollector(storage.NewMemoryStorage("test", logger.Default), logger.Default)
+	defer collector.Close()
+
+	var urls []string
+	handler := func(ctx context.Context, req *Request) (*Response, error) {
+		urls = append(urls, req.URL)
+		return &Response{StatusCode: 200}, nil
+	}
+
+	cfg := &config.Config{Scenarios: []config.ScenarioConfig{
+		{Name: "admin", Variables: map[string]any{"role": "admin"}, APIs: []config.APIConfig{
+			{Name: "login", URL: "http://localhost/login?role={{.role}}&env={{.env}}"},
+			{Name: "audit", URL: "http://localhost/audit", DependsOn: []string{"login"}},
+		}},
+	}}
+	cfg.SetLogger(logger.Default)
+	resolver := config.NewVariableResolver()
+	resolver.SetVariables(map[string]any{"role": "guest", "env": "staging"})
+
+	worker := NewWorker(WorkerConfig{
+		Client:    handlerClient(handler),
+		Collector: collector,
+		ReqCount:  1,
+		Scenario:  NewScenarioMix(cfg).Assign(0),
+		Logger:    logger.Default,
+	}, resolver)
+	assert.NoError(t, worker.Run(context.Background()))
+
+	assert.Equal(t, []string{"http://localhost/login?role=admin&env=staging", "http://localhost/audit"}, urls)
+	report := statistics.NewReportBuilder(collector).BuildSummary(0)
+	assert.Len(t, report.ScenarioStats, 1)
+	assert.Equal(t, "admin", report.ScenarioStats[0].Name)
+	assert.Equal(t, uint64(2), report.ScenarioStats[0].SuccessRequests)
+	assert.Equal(t, int64(1), report.ScenarioStats[0].TimeSeries[0].ActiveWorkers, "worker 计入所属场景的活跃数")
+}
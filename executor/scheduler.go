@@ -24,13 +24,23 @@
 type Scheduler struct {
 	workerCount      uint64
 	requestPerWorker uint64
+	duration         time.Duration // 压测持续时间（>0 时按时间运行）
+	targetRPS        float64       // 目标到达速率（>0 时使用开放模型）
+	stagePlan        *StagePlan    // 多阶段负载计划（可选）
+	pacing           time.Duration // 每轮迭代的目标时长（闭合模型）
 	rampUpDuration   time.Duration
 	clientPool       *ClientPool
-	handler          RequestHandler
+	middlewares      *MiddlewareChain // 中间件链（每个 worker 以自己的客户端为最底层构建处理器）
 	collector        *statistics.Collector
-	apiSelector      APISelector // API选择器（统一入口）
+	apiSelector      APISelector      // API选择器（统一入口）
+	scenarios        *ScenarioMix     // 场景配比（多场景模式）
+	hooks            *WorkerHooks     // worker 生命周期步骤（Vars 在 before_all 执行后填充）
+	beforeAll        []APIConfig      // 全局前置步骤
+	afterAll         []APIConfig      // 全局后置步骤
+	apiClientFactory APIClientFactory // 单独配置了协议的API使用的客户端工厂
 	progress         *ProgressTracker
 	varResolver      *config.VariableResolver // 变量解析器
+	dataFeeder       *DataFeeder              // 数据供给器（可选）
 	controller       Controller               // 控制器
 	logger           logger.ILogger
 }
@@ -39,12 +49,23 @@ type Scheduler struct {
 type SchedulerConfig struct {
 	WorkerCount      uint64
 	RequestPerWorker uint64
+	Duration         time.Duration // 压测持续时间（优先级高于 RequestPerWorker）
+	TargetRPS        float64       // 目标RPS（可选，>0 时按固定到达速率派发请求）
+	Stages           []StageConfig // 多阶段负载（可选，设置后优先于 Duration/TargetRPS）
+	Pacing           time.Duration // 每轮迭代的目标时长（可选，开放模型下忽略）
 	RampUpDuration   time.Duration
 	ClientPool       *ClientPool
-	Handler          RequestHandler
+	Middlewares      *MiddlewareChain // 中间件链（可选，未配置时直接由客户端发送）
 	Collector        *statistics.Collector
 	APISelector      APISelector              // API选择器（必需）
+	Scenarios        *ScenarioMix             // 场景配比（可选，设置后按 worker ID 分配场景）
+	APIClientFactory APIClientFactory         // 单独配置了协议的API使用的客户端工厂（可选）
+	Setup            []APIConfig              // worker 前置步骤（可选）
+	Teardown         []APIConfig              // worker 后置步骤（可选）
+	BeforeAll        []APIConfig              // 全局前置步骤（可选，由 RunBeforeAll 执行）
+	AfterAll         []APIConfig              // 全局后置步骤（可选，由 RunAfterAll 执行）
 	VarResolver      *config.VariableResolver // 变量解析器
+	DataFeeder       *DataFeeder              // 数据供给器（可选）
 	Controller       Controller               // 控制器（可选）
 	Logger           logger.ILogger
 }
@@ -56,16 +77,36 @@ func NewScheduler(cfg SchedulerConfig) *Scheduler {
 	if ctrl == nil {
 		ctrl = &NoOpController{}
 	}
+	progress := NewProgressTrackerWithCollector(totalRequests, cfg.Collector, cfg.WorkerCount, cfg.Logger)
+	var stagePlan *StagePlan
+	if len(cfg.Stages) > 0 {
+		// 多阶段模式下总时长由阶段决定
+		stagePlan = NewStagePlan(cfg.Stages)
+		cfg.Duration = 0
+		progress.SetDuration(stagePlan.TotalDuration())
+	} else if cfg.Duration > 0 {
+		progress.SetDuration(cfg.Duration)
+	}
 	return &Scheduler{
 		workerCount:      cfg.WorkerCount,
 		requestPerWorker: cfg.RequestPerWorker,
+		duration:         cfg.Duration,
+		targetRPS:        cfg.TargetRPS,
+		stagePlan:        stagePlan,
+		pacing:           cfg.Pacing,
 		rampUpDuration:   cfg.RampUpDuration,
 		clientPool:       cfg.ClientPool,
-		handler:          cfg.Handler,
+		middlewares:      cfg.Middlewares,
 		collector:        cfg.Collector,
 		apiSelector:      cfg.APISelector,
-		progress:         NewProgressTrackerWithCollector(totalRequests, cfg.Collector, cfg.WorkerCount, cfg.Logger),
+		scenarios:        cfg.Scenarios,
+		hooks:            &WorkerHooks{Setup: cfg.Setup, Teardown: cfg.Teardown},
+		beforeAll:        cfg.BeforeAll,
+		afterAll:         cfg.AfterAll,
+		apiClientFactory: cfg.APIClientFactory,
+		progress:         progress,
 		varResolver:      cfg.VarResolver,
+		dataFeeder:       cfg.DataFeeder,
 		controller:       ctrl,
 		logger:           cfg.Logger,
 	}
@@ -73,6 +114,16 @@ func NewScheduler(cfg SchedulerConfig) *Scheduler {
 
 // Run 运行调度器
 func (s *Scheduler) Run(ctx context.Context) error {
+	// 配置了多阶段负载时按阶段运行
+	if s.stagePlan != nil {
+		return s.runStages(ctx)
+	}
+
+	// 配置了目标RPS时使用开放模型
+	if s.targetRPS > 0 {
+		return s.runArrivalRate(ctx)
+	}
+
 	var wg sync.WaitGroup
 	errChan := make(chan error, s.workerCount)
 
@@ -81,6 +132,13 @@ func (s *Scheduler) Run(ctx context.Context) error {
 	defer cancelProgress()
 	go s.progress.Start(progressCtx)
 
+	// 按时间运行时计算截止时间（零值表示按请求数运行）
+	var deadline time.Time
+	if s.duration > 0 {
+		deadline = time.Now().Add(s.duration)
+		s.logger.Infof("⏱️  按时间运行: %v，截止 %s", s.duration, deadline.Format(time.DateTime))
+	}
+
 	// 启动workers
 	for i := uint64(0); i < s.workerCount; i++ {
 		// 渐进式启动
@@ -89,11 +147,17 @@ func (s *Scheduler) Run(ctx context.Context) error {
 			time.Sleep(delay)
 		}
 
+		// 渐进启动期间已到截止时间，不再启动新的worker
+		if !deadline.IsZero() && !time.Now().Before(deadline) {
+			s.logger.Warnf("⚠️  渐进启动期间已到达截止时间，仅启动了 %d/%d 个worker", i, s.workerCount)
+			break
+		}
+
 		wg.Add(1)
 		go func(workerID uint64) {
 			defer wg.Done()
 
-			if err := s.runWorker(ctx, workerID); err != nil {
+			if err := s.runWorker(ctx, workerID, deadline); err != nil {
 				select {
 				case errChan <- err:
 				default:
@@ -102,7 +166,7 @@ func (s *Scheduler) Run(ctx context.Context) error {
 		}(i)
 	}
 
-	// 等待所有worker完成
+	// 等待所有worker完成（按时间运行时，到达截止时间后等待进行中的请求执行完毕）
 	wg.Wait()
 	close(errChan)
 
@@ -121,7 +185,7 @@ func (s *Scheduler) Run(ctx context.Context) error {
 }
 
 // runWorker 运行单个worker
-func (s *Scheduler) runWorker(ctx context.Context, workerID uint64) error {
+func (s *Scheduler) runWorker(ctx context.Context, workerID uint64, deadline time.Time) error {
 	// 从连接池获取客户端
 	client, err := s.clientPool.Get()
 	if err != nil {
@@ -132,25 +196,28 @@ func (s *Scheduler) runWorker(ctx context.Context, workerID uint64) error {
 
 	// 创建worker，传递变量解析器和控制器
 	worker := NewWorker(WorkerConfig{
-		ID:          workerID,
-		Client:      client,
-		Handler:     s.wrapHandlerWithProgress(s.handler),
-		Collector:   s.collector,
-		ReqCount:    s.requestPerWorker,
-		APISelector: s.apiSelector,
-		Controller:  s.controller,
-		Logger:      s.logger,
+		ID:               workerID,
+		Client:           client,
+		Handler:          s.middlewares.Handler,
+		Progress:         s.progress,
+		Collector:        s.collector,
+		ReqCount:         s.requestPerWorker,
+		Deadline:         deadline,
+		Pacing:           s.pacing,
+		APISelector:      s.apiSelector,
+		Scenario:         s.scenarios.Assign(workerID),
+		DataFeeder:       s.dataFeeder,
+		Controller:       s.controller,
+		Hooks:            s.hooks,
+		APIClientFactory: s.apiClientFactory,
+		Logger:           s.logger,
 	}, s.varResolver)
 
 	// 运行worker
 	return worker.Run(ctx)
 }
 
-// wrapHandlerWithProgress 包装处理器以跟踪进度
-func (s *Scheduler) wrapHandlerWithProgress(handler RequestHandler) RequestHandler {
-	return func(ctx context.Context, req *Request) (*Response, error) {
-		resp, err := handler(ctx, req)
-		s.progress.Increment()
-		return resp, err
-	}
+// isStopped 是否应停止派发（被控制器停止或数据源已用完）
+func (s *Scheduler) isStopped() bool {
+	return s.controller.IsStopped() || s.dataFeeder.Exhausted()
 }
@@ -0,0 +1,243 @@
+/*
+ * @Author: kamalyes 501893067@qq.com
+ * @Date: 2026-02-03 00:00:00
+ * @LastEditors: kamalyes 501893067@qq.com
+ * @LastEditTime: 2026-02-03 00:00:00
+ * @FilePath: \go-stress\executor\stages.go
+ * @Description: 多阶段负载调度 - 按阶段动态增减worker或调整到达速率
+ *
+ * Copyright (c) 2026 by kamalyes, All Rights Reserved.
+ */
+package executor
+
+import (
+	"context"
+	"math"
+	"sync"
+	"time"
+
+	"github.com/kamalyes/go-stress/config"
+	"github.com/kamalyes/go-stress/statistics"
+	"github.com/kamalyes/go-toolbox/pkg/syncx"
+)
+
+// stageTick 阶段调度器的调整间隔
+const stageTick = 100 * time.Millisecond
+
+// StagePlan 阶段计划：根据已运行时间计算当前阶段和目标值
+type StagePlan struct {
+	stages []StageConfig
+	total  time.Duration
+	useRPS bool
+}
+
+// NewStagePlan 创建阶段计划
+func NewStagePlan(stages []StageConfig) *StagePlan {
+	plan := &StagePlan{
+		stages: stages,
+		useRPS: config.StagesUseRPS(stages),
+	}
+	for _, stage := range stages {
+		plan.total += stage.Duration
+	}
+	return plan
+}
+
+// TotalDuration 所有阶段的总时长
+func (p *StagePlan) TotalDuration() time.Duration {
+	return p.total
+}
+
+// UseRPS 是否以RPS为目标（开放模型）
+func (p *StagePlan) UseRPS() bool {
+	return p.useRPS
+}
+
+// Peak 所有阶段中的最大目标值
+func (p *StagePlan) Peak() float64 {
+	var peak float64
+	for _, stage := range p.stages {
+		peak = max(peak, p.targetOf(stage))
+	}
+	return peak
+}
+
+// At 计算已运行 elapsed 时所处的阶段序号（从0开始）、目标值和本阶段剩余时间
+// 阶段内目标值从上一阶段目标线性过渡，超出总时长时返回 index = -1
+func (p *StagePlan) At(elapsed time.Duration) (index int, target float64, remaining time.Duration) {
+	var from float64
+	var offset time.Duration
+	for i, stage := range p.stages {
+		to := p.targetOf(stage)
+		if elapsed < offset+stage.Duration {
+			progress := float64(elapsed-offset) / float64(stage.Duration)
+			return i, from + (to-from)*progress, offset + stage.Duration - elapsed
+		}
+		from = to
+		offset += stage.Duration
+	}
+	return -1, from, 0
+}
+
+// targetOf 获取阶段的目标值
+func (p *StagePlan) targetOf(stage StageConfig) float64 {
+	if p.useRPS {
+		return stage.TargetRPS
+	}
+	return float64(stage.Target)
+}
+
+// publishStage 更新收集器中的当前阶段信息
+func (s *Scheduler) publishStage(index int, target float64, remaining time.Duration, activeWorkers uint64) {
+	stage := s.stagePlan.stages[index]
+	s.collector.SetStage(&statistics.StageInfo{
+		Index:         index + 1,
+		Total:         len(s.stagePlan.stages),
+		Name:          stage.Name,
+		Target:        math.Round(target*100) / 100,
+		IsRPS:         s.stagePlan.useRPS,
+		ActiveWorkers: activeWorkers,
+		Remaining:     int64(remaining.Seconds()),
+	})
+}
+
+// logStageChange 阶段切换时打印日志
+func (s *Scheduler) logStageChange(index int) {
+	stage := s.stagePlan.stages[index]
+	target := s.stagePlan.targetOf(stage)
+	unit := "并发"
+	if s.stagePlan.useRPS {
+		unit = "RPS"
+	}
+	s.logger.Infof("📶 进入阶段 %d/%d %s: %v 内过渡到 %.2f %s",
+		index+1, len(s.stagePlan.stages), stage.Name, stage.Duration, target, unit)
+}
+
+// runStages 按阶段运行：RPS目标交给开放模型派发器，并发目标则在运行时增减worker
+func (s *Scheduler) runStages(ctx context.Context) error {
+	if s.stagePlan.UseRPS() {
+		return s.runArrivalRate(ctx)
+	}
+
+	// 启动进度跟踪
+	progressCtx, cancelProgress := context.WithCancel(ctx)
+	defer cancelProgress()
+	go s.progress.Start(progressCtx)
+
+	var (
+		wg       sync.WaitGroup
+		errOnce  sync.Once
+		firstErr error
+		nextID   uint64
+		retires  []chan struct{} // 每个活跃worker的退役信号，按启动顺序排列
+	)
+	active := syncx.NewInt64(0)
+
+	spawn := func() {
+		retire := make(chan struct{})
+		retires = append(retires, retire)
+		workerID := nextID
+		nextID++
+
+		wg.Add(1)
+		active.Add(1)
+		go func() {
+			defer wg.Done()
+			defer active.Add(-1)
+
+			if err := s.runStageWorker(ctx, workerID, retire); err != nil {
+				errOnce.Do(func() { firstErr = err })
+			}
+		}()
+	}
+
+	// 退役最后启动的worker：worker执行完当前一轮后退出，不打断进行中的请求
+	retireLast := func() {
+		last := len(retires) - 1
+		close(retires[last])
+		retires = retires[:last]
+	}
+
+	ticker := time.NewTicker(stageTick)
+	defer ticker.Stop()
+
+	start := time.Now()
+	currentStage := -1
+	for {
+		elapsed := time.Since(start)
+		index, target, remaining := s.stagePlan.At(elapsed)
+		if index < 0 || s.isStopped() {
+			break
+		}
+		if index != currentStage {
+			currentStage = index
+			s.logStageChange(index)
+		}
+
+		// 向目标并发数调整worker数量
+		desired := int(math.Round(target))
+		for len(retires) < desired {
+			spawn()
+		}
+		for len(retires) > desired {
+			retireLast()
+		}
+		s.publishStage(index, target, remaining, uint64(len(retires)))
+
+		select {
+		case <-ctx.Done():
+		case <-ticker.C:
+			if s.controller.IsPaused() {
+				// 暂停期间阶段时钟不前进
+				pauseStart := time.Now()
+				WaitWhilePaused(s.controller)
+				start = start.Add(time.Since(pauseStart))
+			}
+			continue
+		}
+		break
+	}
+
+	// 所有阶段结束：退役全部worker并等待进行中的请求执行完毕
+	for len(retires) > 0 {
+		retireLast()
+	}
+	wg.Wait()
+
+	cancelProgress()
+	s.progress.Complete()
+
+	if firstErr != nil {
+		return firstErr
+	}
+	return ctx.Err()
+}
+
+// runStageWorker 运行多阶段模式下的单个worker，收到退役信号后退出
+func (s *Scheduler) runStageWorker(ctx context.Context, workerID uint64, retire <-chan struct{}) error {
+	client, err := s.clientPool.Get()
+	if err != nil {
+		s.logger.Errorf("❌ Worker %d: 获取客户端失败: %v", workerID, err)
+		return err
+	}
+	defer s.clientPool.Put(client)
+
+	worker := NewWorker(WorkerConfig{
+		ID:               workerID,
+		Client:           client,
+		Handler:          s.middlewares.Handler,
+		Progress:         s.progress,
+		Collector:        s.collector,
+		Retire:           retire,
+		Pacing:           s.pacing,
+		APISelector:      s.apiSelector,
+		Scenario:         s.scenarios.Assign(workerID),
+		DataFeeder:       s.dataFeeder,
+		Controller:       s.controller,
+		Hooks:            s.hooks,
+		APIClientFactory: s.apiClientFactory,
+		Logger:           s.logger,
+	}, s.varResolver)
+
+	return worker.Run(ctx)
+}
@@ -0,0 +1,157 @@
+/*
+ * @Author: kamalyes 501893067@qq.com
+ * @Date: 2026-02-24 00:00:00
+ * @LastEditors: kamalyes 501893067@qq.com
+ * @LastEditTime: 2026-02-24 00:00:00
+ * @FilePath: \go-stress\executor\stages_test.go
+ * @Description: 多阶段负载调度测试
+ *
+ * Copyright (c) 2026 by kamalyes, All Rights Reserved.
+ */
+package executor
+
+import (
+	"context"
+	"sync"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/kamalyes/go-stress/config"
+	"github.com/kamalyes/go-stress/logger"
+	"github.com/kamalyes/go-stress/statistics"
+	"github.com/kamalyes/go-stress/storage"
+	"github.com/stretchr/testify/assert"
+)
+
+// 测试阶段内目标值线性过渡及阶段边界
+func TestStagePlanAt(t *testing.T) {
+	plan := NewStagePlan([]StageConfig{
+		{Name: "ramp", Duration: 10 * time.Second, Target: 100},
+		{Name: "hold", Duration: 20 * time.Second, Target: 100},
+		{Name: "down", Duration: 10 * time.Second, Target: 0},
+	})
+
+	tests := []struct {
+		name      string
+		elapsed   time.Duration
+		index     int
+		target    float64
+		remaining time.Duration
+	}{
+		{"开始时从0过渡", 0, 0, 0, 10 * time.Second},
+		{"第一阶段中点", 5 * time.Second, 0, 50, 5 * time.Second},
+		{"第一阶段末尾", 10*time.Second - time.Millisecond, 0, 99.99, time.Millisecond},
+		{"第二阶段开始保持目标", 10 * time.Second, 1, 100, 20 * time.Second},
+		{"第二阶段保持", 25 * time.Second, 1, 100, 5 * time.Second},
+		{"第三阶段开始", 30 * time.Second, 2, 100, 10 * time.Second},
+		{"第三阶段降压", 37500 * time.Millisecond, 2, 25, 2500 * time.Millisecond},
+		{"超出总时长", 40 * time.Second, -1, 0, 0},
+		{"远超总时长", time.Hour, -1, 0, 0},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			index, target, remaining := plan.At(tt.elapsed)
+			assert.Equal(t, tt.index, index)
+			assert.InDelta(t, tt.target, target, 0.001)
+			assert.Equal(t, tt.remaining, remaining)
+		})
+	}
+
+	assert.Equal(t, 40*time.Second, plan.TotalDuration())
+	assert.Equal(t, float64(100), plan.Peak())
+	assert.False(t, plan.UseRPS())
+}
+
+// 测试按RPS分阶段时使用开放模型的目标值
+func TestStagePlanAtRPS(t *testing.T) {
+	plan := NewStagePlan([]StageConfig{
+		{Duration: 2 * time.Second, TargetRPS: 50},
+		{Duration: 2 * time.Second, TargetRPS: 10},
+	})
+
+	assert.True(t, plan.UseRPS())
+	assert.Equal(t, float64(50), plan.Peak())
+
+	_, target, _ := plan.At(time.Second)
+	assert.InDelta(t, 25, target, 0.001)
+	_, target, _ = plan.At(3 * time.Second)
+	assert.InDelta(t, 30, target, 0.001)
+}
+
+// stageClient 记录 worker 连接/关闭次数的客户端（每个 worker 启动时连接，退出时关闭）
+type stageClient struct {
+	connects *atomic.Int64
+	live     *atomic.Int64
+	send     RequestHandler
+}
+
+func (c stageClient) Connect(ctx context.Context) error {
+	c.connects.Add(1)
+	c.live.Add(1)
+	return nil
+}
+func (c stageClient) Send(ctx context.Context, req *Request) (*Response, error) {
+	return c.send(ctx, req)
+}
+func (c stageClient) Close() error {
+	c.live.Add(-1)
+	return nil
+}
+func (c stageClient) Type() ProtocolType { return ProtocolHTTP }
+
+// 测试按并发数分阶段时运行中扩容和缩容worker
+func TestSchedulerRunStagesScaling(t *testing.T) {
+	collector := statistics.NewCollector(storage.NewMemoryStorage("test", logger.Default), logger.Default)
+	defer collector.Close()
+
+	var connects, live atomic.Int64
+	var (
+		mu      sync.Mutex
+		peak    int64
+		samples []int64 // 降压阶段后半段观察到的活跃worker数
+	)
+	start := time.Now()
+	handler := func(ctx context.Context, req *Request) (*Response, error) {
+		n := live.Load()
+		mu.Lock()
+		peak = max(peak, n)
+		if time.Since(start) > 560*time.Millisecond {
+			samples = append(samples, n)
+		}
+		mu.Unlock()
+		time.Sleep(5 * time.Millisecond)
+		return &Response{StatusCode: 200}, nil
+	}
+
+	cfg := &config.Config{APIs: []config.APIConfig{{Name: "ping", URL: "http://localhost/ping"}}}
+	cfg.SetLogger(logger.Default)
+	pool := NewClientPool(func() (Client, error) { return stageClient{connects: &connects, live: &live, send: handler}, nil }, 4)
+	defer pool.Close()
+
+	// 200ms 内扩容到 4，保持 200ms，再在 200ms 内缩容到 1
+	scheduler := NewScheduler(SchedulerConfig{
+		WorkerCount: 4,
+		Stages: []StageConfig{
+			{Name: "up", Duration: 200 * time.Millisecond, Target: 4},
+			{Name: "hold", Duration: 200 * time.Millisecond, Target: 4},
+			{Name: "down", Duration: 200 * time.Millisecond, Target: 1},
+		},
+		ClientPool:  pool,
+		Collector:   collector,
+		APISelector: CreateAPISelector(cfg),
+		VarResolver: config.NewVariableResolver(),
+		Logger:      logger.Default,
+	})
+	assert.NoError(t, scheduler.Run(context.Background()))
+
+	assert.Equal(t, int64(4), connects.Load(), "扩容时只启动到目标并发数，缩容后不重复启动")
+	assert.Equal(t, int64(4), peak)
+	assert.Equal(t, int64(0), live.Load(), "所有阶段结束后worker全部退役")
+
+	assert.NotEmpty(t, samples)
+	for _, n := range samples {
+		assert.LessOrEqual(t, n, int64(3), "降压阶段运行中退役worker")
+	}
+}
@@ -0,0 +1,90 @@
+/*
+ * @Author: kamalyes 501893067@qq.com
+ * @Date: 2026-02-19 00:00:00
+ * @LastEditors: kamalyes 501893067@qq.com
+ * @LastEditTime: 2026-02-19 00:00:00
+ * @FilePath: \go-stress\executor\think_time_test.go
+ * @Description: 思考时间与迭代节奏测试
+ *
+ * Copyright (c) 2026 by kamalyes, All Rights Reserved.
+ */
+package executor
+
+import (
+	"context"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/kamalyes/go-stress/config"
+	"github.com/kamalyes/go-stress/logger"
+	"github.com/kamalyes/go-stress/statistics"
+	"github.com/kamalyes/go-stress/storage"
+	"github.com/stretchr/testify/assert"
+)
+
+// handlerClient 由函数处理请求的客户端
+type handlerClient RequestHandler
+
+func (h handlerClient) Connect(ctx context.Context) error { return nil }
+func (h handlerClient) Send(ctx context.Context, req *Request) (*Response, error) {
+	return h(ctx, req)
+}
+func (h handlerClient) Close() error       { return nil }
+func (h handlerClient) Type() ProtocolType { return ProtocolHTTP }
+
+// 测试思考时间和节奏等待计为空闲，不计入请求耗时
+func TestWorkerThinkTimeAndPacing(t *testing.T) {
+	collector := statistics.NewCollector(storage.NewMemoryStorage("test", logger.Default), logger.Default)
+	defer collector.Close()
+
+	var (
+		mu     sync.Mutex
+		starts []time.Time
+		active []int64
+	)
+	handler := func(ctx context.Context, req *Request) (*Response, error) {
+		mu.Lock()
+		starts = append(starts, time.Now())
+		active = append(active, collector.GetActiveWorkers())
+		mu.Unlock()
+		return &Response{StatusCode: 200, Duration: time.Millisecond}, nil
+	}
+
+	// 依赖链两步：login 后思考 20ms，每轮目标时长 80ms
+	apis := []config.APIConfig{
+		{Name: "login", URL: "http://localhost/login", ThinkTime: &ThinkTimeConfig{Duration: 20 * time.Millisecond}},
+		{Name: "browse", URL: "http://localhost/items", DependsOn: []string{"login"}},
+	}
+	cfg := &config.Config{APIs: apis}
+	cfg.SetLogger(logger.Default)
+	worker := NewWorker(WorkerConfig{
+		ID:          0,
+		Client:      handlerClient(handler),
+		Collector:   collector,
+		ReqCount:    3,
+		Pacing:      80 * time.Millisecond,
+		APISelector: CreateAPISelector(cfg),
+		Logger:      logger.Default,
+	}, config.NewVariableResolver())
+
+	idle := make(chan int64, 1)
+	go func() {
+		time.Sleep(40 * time.Millisecond)
+		idle <- collector.GetActiveWorkers()
+	}()
+
+	start := time.Now()
+	assert.NoError(t, worker.Run(context.Background()))
+	assert.GreaterOrEqual(t, time.Since(start), 200*time.Millisecond, "前两轮按节奏补齐")
+	assert.Equal(t, int64(0), collector.GetActiveWorkers())
+
+	assert.Len(t, starts, 6)
+	assert.Equal(t, []int64{1, 1, 1, 1, 1, 1}, active)
+	assert.Equal(t, int64(0), <-idle, "节奏等待期间不计为活跃")
+	assert.GreaterOrEqual(t, starts[1].Sub(starts[0]), 20*time.Millisecond, "login 后思考")
+	assert.GreaterOrEqual(t, starts[2].Sub(starts[0]), 80*time.Millisecond, "下一轮按节奏开始")
+
+	// 等待不计入请求耗时
+	assert.Equal(t, time.Millisecond, collector.GetSnapshot().MaxLatency)
+}
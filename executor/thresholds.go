@@ -0,0 +1,320 @@
+/*
+ * @Author: kamalyes 501893067@qq.com
+ * @Date: 2026-02-07 00:00:00
+ * @LastEditors: kamalyes 501893067@qq.com
+ * @LastEditTime: 2026-02-07 00:00:00
+ * @FilePath: \go-stress\executor\thresholds.go
+ * @Description: SLO 阈值评估 - 压测结束后判定是否达标，支持确定无法达标时提前终止
+ *
+ * Copyright (c) 2026 by kamalyes, All Rights Reserved.
+ */
+package executor
+
+import (
+	"context"
+	"errors"
+	"time"
+
+	"github.com/kamalyes/go-logger"
+	"github.com/kamalyes/go-stress/config"
+	"github.com/kamalyes/go-stress/statistics"
+	"github.com/kamalyes/go-toolbox/pkg/mathx"
+)
+
+// ExitCodeThresholdsBreached 阈值未达标时的进程退出码
+const ExitCodeThresholdsBreached = 99
+
+// thresholdCheckInterval 提前终止检查间隔
+const thresholdCheckInterval = time.Second
+
+// ErrThresholdsBreached 存在未达标的 SLO 阈值
+var ErrThresholdsBreached = errors.New("SLO 阈值未达标")
+
+// thresholdSample 评估阈值所需的指标值
+type thresholdSample struct {
+	total       uint64
+	failed      uint64
+	successRate float64
+	qps         float64
+	avg         time.Duration
+	min         time.Duration
+	max         time.Duration
+	percentiles map[config.ThresholdMetric]time.Duration
+	latencies   *statistics.Histogram // 仅运行中检查时使用
+}
+
+// ThresholdEvaluator 阈值评估器
+type ThresholdEvaluator struct {
+	thresholds []*config.Threshold
+	collector  *statistics.Collector
+	planned    func(api string) uint64 // 计划请求数，未知时返回0
+	logger     logger.ILogger
+	abortedBy  *config.Threshold
+}
+
+// NewThresholdEvaluator 创建阈值评估器，未配置阈值时返回 nil
+func NewThresholdEvaluator(cfgs []config.ThresholdConfig, collector *statistics.Collector, planned func(api string) uint64, log logger.ILogger) (*ThresholdEvaluator, error) {
+	if len(cfgs) == 0 {
+		return nil, nil
+	}
+	thresholds, err := config.ParseThresholds(cfgs)
+	if err != nil {
+		return nil, err
+	}
+	return &ThresholdEvaluator{
+		thresholds: thresholds,
+		collector:  collector,
+		planned:    planned,
+		logger:     log,
+	}, nil
+}
+
+// HasAbortThresholds 是否配置了提前终止的阈值
+func (te *ThresholdEvaluator) HasAbortThresholds() bool {
+	for _, t := range te.thresholds {
+		if t.AbortOnFail {
+			return true
+		}
+	}
+	return false
+}
+
+// AbortedBy 触发提前终止的阈值（未提前终止时返回 nil）
+func (te *ThresholdEvaluator) AbortedBy() *config.Threshold {
+	return te.abortedBy
+}
+
+// Watch 运行期间定期检查，任一提前终止阈值确定无法达标时调用 abort
+func (te *ThresholdEvaluator) Watch(ctx context.Context, abort func()) {
+	ticker := time.NewTicker(thresholdCheckInterval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+			for _, t := range te.thresholds {
+				if !t.AbortOnFail || !te.irrecoverable(t) {
+					continue
+				}
+				te.abortedBy = t
+				te.logger.Warnf("🛑 阈值 %s（%s）已确定无法达标，提前终止压测", t.Expr, t.Scope())
+				abort()
+				return
+			}
+		}
+	}
+}
+
+// Evaluate 按最终报告评估所有阈值
+func (te *ThresholdEvaluator) Evaluate(report *statistics.Report) []*statistics.ThresholdResult {
+	results := make([]*statistics.ThresholdResult, 0, len(te.thresholds))
+	for _, t := range te.thresholds {
+		result := &statistics.ThresholdResult{
+			Expr:     t.Expr,
+			Scenario: t.Scenario,
+			API:      t.API,
+			Actual:   "无数据",
+			Aborted:  t == te.abortedBy,
+		}
+		if sample := sampleFromReport(report, t); sample != nil && sample.total > 0 {
+			actual := sample.value(t.Metric)
+			result.Actual = t.FormatValue(actual)
+			result.Passed = t.Passed(actual)
+		}
+		results = append(results, result)
+	}
+	return results
+}
+
+// irrecoverable 判断阈值在剩余请求中是否已不可能达标
+// 只对单调变化的指标（最大/最小延迟、失败数）或已知计划请求数时的比率/百分位做判定，
+// 按时间运行时比率类指标始终可能回落，不会提前终止
+func (te *ThresholdEvaluator) irrecoverable(t *config.Threshold) bool {
+	sample := te.liveSample(t)
+	if sample == nil || sample.total == 0 {
+		return false
+	}
+
+	upper := t.UpperBound()
+	lower := t.Operator == ">" || t.Operator == ">="
+	switch t.Metric {
+	case config.MetricMax:
+		return upper && !t.Passed(durationMs(sample.max))
+	case config.MetricMin:
+		return lower && !t.Passed(durationMs(sample.min))
+	case config.MetricFailed:
+		return upper && !t.Passed(float64(sample.failed))
+	case config.MetricRequests:
+		return upper && !t.Passed(float64(sample.total))
+	}
+
+	planned := te.planned(t.API)
+	if planned == 0 {
+		return false
+	}
+	// 以计划请求数为分母得到的是最终结果的最好情况
+	switch t.Metric {
+	case config.MetricErrorRate:
+		return upper && !t.Passed(float64(sample.failed)/float64(planned)*100)
+	case config.MetricSuccessRate:
+		best := float64(planned-min(sample.failed, planned)) / float64(planned) * 100
+		return lower && !t.Passed(best)
+	case config.MetricAvg:
+		return upper && !t.Passed(durationMs(sample.avg)*float64(sample.total)/float64(planned))
+	}
+	if p, ok := t.Metric.Percentile(); ok && upper {
+		limit := time.Duration(t.Value * float64(time.Millisecond))
+		return float64(sample.latencies.CountAbove(limit)) > (1-p/100)*float64(planned)
+	}
+	return false
+}
+
+// liveSample 从收集器读取运行中的全局、场景或接口指标
+func (te *ThresholdEvaluator) liveSample(t *config.Threshold) *thresholdSample {
+	if t.API != "" || t.Scenario != "" {
+		var (
+			stats *statistics.BreakdownStats
+			hist  *statistics.Histogram
+		)
+		if t.API != "" {
+			stats, hist = te.collector.GetAPIStats(statistics.APIStatsKey(t.Scenario, t.API))
+		} else {
+			stats, hist = te.collector.GetScenarioStats(t.Scenario)
+		}
+		if stats == nil {
+			return nil
+		}
+		sample := sampleFromBreakdown(stats)
+		sample.latencies = hist
+		return sample
+	}
+
+	metrics := te.collector.GetMetrics()
+	snapshot := te.collector.GetSnapshot()
+	return &thresholdSample{
+		total:     metrics.TotalRequests,
+		failed:    metrics.FailedRequests,
+		avg:       snapshot.AvgLatency,
+		min:       snapshot.MinLatency,
+		max:       snapshot.MaxLatency,
+		latencies: te.collector.GetLatencyHistogram(),
+	}
+}
+
+// sampleFromReport 从报告中取全局、场景或接口的指标
+func sampleFromReport(report *statistics.Report, t *config.Threshold) *thresholdSample {
+	if report == nil {
+		return nil
+	}
+	if t.API != "" || t.Scenario != "" {
+		groups, name := report.ScenarioStats, t.Scenario
+		if t.API != "" {
+			groups, name = report.APIStats, statistics.APIStatsKey(t.Scenario, t.API)
+		}
+		for _, stats := range groups {
+			if stats.Name == name {
+				return sampleFromBreakdown(stats)
+			}
+		}
+		return nil
+	}
+	return &thresholdSample{
+		total:       report.TotalRequests,
+		failed:      report.FailedRequests,
+		successRate: report.SuccessRate,
+		qps:         report.QPS,
+		avg:         report.AvgLatency,
+		min:         report.MinLatency,
+		max:         report.MaxLatency,
+		percentiles: map[config.ThresholdMetric]time.Duration{
+			config.MetricP50:   report.P50Latency,
+			config.MetricP90:   report.P90Latency,
+			config.MetricP95:   report.P95Latency,
+			config.MetricP99:   report.P99Latency,
+			config.MetricP999:  report.P999Latency,
+			config.MetricP9999: report.P9999Latency,
+		},
+	}
+}
+
+// sampleFromBreakdown 从接口或场景分组统计中取指标
+func sampleFromBreakdown(stats *statistics.BreakdownStats) *thresholdSample {
+	return &thresholdSample{
+		total:       stats.TotalRequests,
+		failed:      stats.FailedRequests,
+		successRate: stats.SuccessRate,
+		qps:         stats.QPS,
+		avg:         stats.AvgLatency,
+		min:         stats.MinLatency,
+		max:         stats.MaxLatency,
+		percentiles: map[config.ThresholdMetric]time.Duration{
+			config.MetricP50: stats.P50Latency,
+			config.MetricP90: stats.P90Latency,
+			config.MetricP95: stats.P95Latency,
+			config.MetricP99: stats.P99Latency,
+		},
+	}
+}
+
+// value 取指标值（延迟为毫秒，比率为百分比）
+func (s *thresholdSample) value(metric config.ThresholdMetric) float64 {
+	switch metric {
+	case config.MetricAvg:
+		return durationMs(s.avg)
+	case config.MetricMin:
+		return durationMs(s.min)
+	case config.MetricMax:
+		return durationMs(s.max)
+	case config.MetricErrorRate:
+		return mathx.Percentage(s.failed, s.total)
+	case config.MetricSuccessRate:
+		return s.successRate
+	case config.MetricQPS:
+		return s.qps
+	case config.MetricRequests:
+		return float64(s.total)
+	case config.MetricFailed:
+		return float64(s.failed)
+	}
+	return durationMs(s.percentiles[metric])
+}
+
+// plannedRequests 计划请求数（按请求数运行时可确定；按时间/阶段运行、多场景、控制流或按权重随机选择API时返回0）
+func (e *Executor) plannedRequests(api string) uint64 {
+	cfg := e.config
+	if cfg.Duration > 0 || len(cfg.Stages) > 0 || cfg.Requests == 0 || len(cfg.Scenarios) > 0 {
+		return 0
+	}
+	if hasFlowSteps(cfg.APIs) {
+		return 0
+	}
+	iterations := cfg.Concurrency * cfg.Requests
+
+	// 依赖链模式：每轮按顺序执行所有API（含重复次数）
+	if selector := e.scheduler.apiSelector; selector != nil && selector.HasDependencies() {
+		resolver := selector.GetDependencyResolver()
+		var perIteration uint64
+		for _, name := range resolver.GetExecutionOrder() {
+			if api != "" && name != api {
+				continue
+			}
+			if apiCfg := resolver.GetAPI(name); apiCfg != nil {
+				perIteration += uint64(mathx.IfNotZero(apiCfg.Repeat, 1))
+			}
+		}
+		return iterations * perIteration
+	}
+
+	// 其他模式每轮只发一个请求，多API时无法确定单个API的请求数
+	if api != "" && len(cfg.APIs) > 1 {
+		return 0
+	}
+	return iterations
+}
+
+// durationMs 转换为毫秒（保留小数）
+func durationMs(d time.Duration) float64 {
+	return float64(d) / float64(time.Millisecond)
+}
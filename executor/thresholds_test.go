@@ -0,0 +1,133 @@
+/*
+ * @Author: kamalyes 501893067@qq.com
+ * @Date: 2026-02-07 00:00:00
+ * @LastEditors: kamalyes 501893067@qq.com
+ * @LastEditTime: 2026-02-07 00:00:00
+ * @FilePath: \go-stress\executor\thresholds_test.go
+ * @Description: SLO 阈值评估测试
+ *
+ * Copyright (c) 2026 by kamalyes, All Rights Reserved.
+ */
+package executor
+
+import (
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/kamalyes/go-stress/config"
+	"github.com/kamalyes/go-stress/logger"
+	"github.com/kamalyes/go-stress/statistics"
+	"github.com/kamalyes/go-stress/storage"
+	"github.com/stretchr/testify/assert"
+)
+
+// newThresholdTestCollector 创建测试用收集器：90 个 10ms 成功请求，10 个 500ms 失败请求
+func newThresholdTestCollector() *statistics.Collector {
+	c := statistics.NewCollector(storage.NewMemoryStorage("test", logger.Default), logger.Default)
+	for i := 0; i < 90; i++ {
+		c.Collect(&statistics.RequestResult{APIName: "login", Success: true, StatusCode: 200, Duration: 10 * time.Millisecond})
+	}
+	for i := 0; i < 10; i++ {
+		c.Collect(&statistics.RequestResult{APIName: "login", StatusCode: 500, Duration: 500 * time.Millisecond, Error: errors.New("boom")})
+	}
+	return c
+}
+
+// 测试按最终报告评估阈值
+func TestThresholdEvaluator_Evaluate(t *testing.T) {
+	c := newThresholdTestCollector()
+	defer c.Close()
+
+	te, err := NewThresholdEvaluator([]config.ThresholdConfig{
+		{Expr: "p50 < 20ms"},
+		{Expr: "p99 < 100ms"},
+		{Expr: "error_rate <= 10%"},
+		{Expr: "failed == 10", API: "login"},
+		{Expr: "avg < 1s", API: "missing"},
+	}, c, func(string) uint64 { return 0 }, logger.Default)
+	assert.NoError(t, err)
+
+	report := statistics.NewReportBuilder(c).BuildSummary(time.Second)
+	results := te.Evaluate(report)
+	assert.Len(t, results, 5)
+	assert.True(t, results[0].Passed)
+	assert.False(t, results[1].Passed)
+	assert.True(t, results[2].Passed)
+	assert.Equal(t, "10.00%", results[2].Actual)
+	assert.True(t, results[3].Passed)
+	assert.False(t, results[4].Passed)
+	assert.Equal(t, "无数据", results[4].Actual)
+
+	report.Thresholds = results
+	assert.False(t, report.ThresholdsPassed())
+}
+
+// 测试多场景模式下按场景和 场景/API 评估阈值，同名API不合并
+func TestThresholdEvaluator_Scenarios(t *testing.T) {
+	c := statistics.NewCollector(storage.NewMemoryStorage("test", logger.Default), logger.Default)
+	defer c.Close()
+	for i := 0; i < 10; i++ {
+		c.Collect(&statistics.RequestResult{APIName: "login", Scenario: "browser", Success: true, StatusCode: 200, Duration: 10 * time.Millisecond})
+	}
+	c.Collect(&statistics.RequestResult{APIName: "login", Scenario: "buyer", StatusCode: 500, Duration: 500 * time.Millisecond, Error: errors.New("boom")})
+
+	te, err := NewThresholdEvaluator([]config.ThresholdConfig{
+		{Expr: "failed < 1", Scenario: "browser", API: "login"},
+		{Expr: "failed < 1", Scenario: "buyer", API: "login"},
+		{Expr: "requests == 1", Scenario: "buyer"},
+		{Expr: "failed == 0", API: "login"},
+	}, c, func(string) uint64 { return 0 }, logger.Default)
+	assert.NoError(t, err)
+
+	results := te.Evaluate(statistics.NewReportBuilder(c).BuildSummary(time.Second))
+	assert.True(t, results[0].Passed)
+	assert.Equal(t, "browser/login", results[0].Scope())
+	assert.False(t, results[1].Passed)
+	assert.Equal(t, "1", results[1].Actual)
+	assert.True(t, results[2].Passed)
+	assert.Equal(t, "场景 buyer", results[2].Scope())
+	assert.Equal(t, "无数据", results[3].Actual, "多场景模式下未指定场景的API没有合并统计")
+
+	// 运行中检查同样按 场景/API 和场景读取
+	assert.True(t, te.irrecoverable(te.thresholds[1]))
+	assert.False(t, te.irrecoverable(te.thresholds[0]))
+	th, err := config.ParseThreshold(config.ThresholdConfig{Expr: "max < 100ms", Scenario: "buyer"})
+	assert.NoError(t, err)
+	assert.True(t, te.irrecoverable(th))
+}
+
+// 测试提前终止只在确定无法达标时触发
+func TestThresholdEvaluator_Irrecoverable(t *testing.T) {
+	c := newThresholdTestCollector()
+	defer c.Close()
+
+	var planned uint64
+	te, err := NewThresholdEvaluator(nil, c, func(string) uint64 { return planned }, logger.Default)
+	assert.NoError(t, err)
+	assert.Nil(t, te)
+
+	check := func(expr string) bool {
+		th, err := config.ParseThreshold(config.ThresholdConfig{Expr: expr})
+		assert.NoError(t, err)
+		te := &ThresholdEvaluator{collector: c, planned: func(string) uint64 { return planned }}
+		return te.irrecoverable(th)
+	}
+
+	// 单调指标随时可判定
+	assert.True(t, check("max < 100ms"))
+	assert.True(t, check("failed < 5"))
+	assert.False(t, check("failed < 50"))
+	// 计划请求数未知时比率和百分位不判定
+	assert.False(t, check("error_rate < 1%"))
+	assert.False(t, check("p95 < 100ms"))
+
+	// 计划 1000 个请求：10 个失败最好情况为 1%；10 个慢请求不超过 P99 的容量，但已超过 P99.9
+	planned = 1000
+	assert.True(t, check("error_rate < 1%"))
+	assert.False(t, check("error_rate <= 1%"))
+	assert.False(t, check("p99 < 100ms"))
+	assert.True(t, check("p999 < 100ms"))
+	assert.False(t, check("success_rate >= 99"))
+	assert.True(t, check("success_rate > 99"))
+}
@@ -11,6 +11,8 @@
 package executor
 
 import (
+	"maps"
+
 	"github.com/kamalyes/go-stress/config"
 )
 
@@ -18,6 +20,8 @@
 type VariableReplacer struct {
 	resolver      *config.VariableResolver // 动态变量解析器（如 {{$timestamp}}）
 	extractedVars map[string]string        // 提取的变量（如从上一个API响应中提取的）
+	row           map[string]string        // 数据源的当前行（如 {{.row.user_id}}）
+	vars          map[string]any           // 场景变量（如 {{.role}}，覆盖同名全局变量）
 }
 
 // NewVariableReplacer 创建变量替换器
@@ -31,6 +35,18 @@ func NewVariableReplacer(resolver *config.VariableResolver, extractedVars map[st
 	}
 }
 
+// WithRow 设置数据源的当前行
+func (vr *VariableReplacer) WithRow(row map[string]string) *VariableReplacer {
+	vr.row = row
+	return vr
+}
+
+// WithVars 设置场景变量
+func (vr *VariableReplacer) WithVars(vars map[string]any) *VariableReplacer {
+	vr.vars = vars
+	return vr
+}
+
 // ReplaceInAPIConfig 替换 API 配置中的所有变量
 func (vr *VariableReplacer) ReplaceInAPIConfig(apiCfg *APIConfig) *APIConfig {
 	if apiCfg == nil {
@@ -43,14 +59,59 @@ func (vr *VariableReplacer) ReplaceInAPIConfig(apiCfg *APIConfig) *APIConfig {
 		URL:        vr.ReplaceString(apiCfg.URL),
 		Method:     apiCfg.Method,
 		Headers:    vr.ReplaceHeaders(apiCfg.Headers),
+		Metadata:   vr.ReplaceHeaders(apiCfg.Metadata),
 		Body:       vr.ReplaceString(apiCfg.Body),
 		Verify:     apiCfg.Verify,
 		Extractors: apiCfg.Extractors,
+		GraphQL:    vr.ReplaceInGraphQL(apiCfg.GraphQL),
+		ThinkTime:  apiCfg.ThinkTime,
+		Protocol:   apiCfg.Protocol,
+		GRPC:       apiCfg.GRPC,
+		WebSocket:  apiCfg.WebSocket,
+		SSE:        apiCfg.SSE,
+		Socket:     apiCfg.Socket,
+		MQTT:       apiCfg.MQTT,
 	}
 
 	return newCfg
 }
 
+// ReplaceInGraphQL 替换 GraphQL 查询和变量中的字符串值（返回新的配置）
+func (vr *VariableReplacer) ReplaceInGraphQL(gql *GraphQLConfig) *GraphQLConfig {
+	if gql == nil {
+		return nil
+	}
+
+	newGQL := *gql
+	newGQL.Query = vr.ReplaceString(gql.Query)
+	if gql.Variables != nil {
+		newGQL.Variables = vr.replaceValue(gql.Variables).(map[string]any)
+	}
+	return &newGQL
+}
+
+// replaceValue 递归替换嵌套 map/数组中的字符串值
+func (vr *VariableReplacer) replaceValue(value any) any {
+	switch v := value.(type) {
+	case string:
+		return vr.ReplaceString(v)
+	case map[string]any:
+		newMap := make(map[string]any, len(v))
+		for k, item := range v {
+			newMap[k] = vr.replaceValue(item)
+		}
+		return newMap
+	case []any:
+		newSlice := make([]any, len(v))
+		for i, item := range v {
+			newSlice[i] = vr.replaceValue(item)
+		}
+		return newSlice
+	default:
+		return value
+	}
+}
+
 // ReplaceString 替换字符串中的变量（两步：1.提取变量 2.动态变量）
 func (vr *VariableReplacer) ReplaceString(s string) string {
 	if s == "" {
@@ -62,9 +123,19 @@ func (vr *VariableReplacer) ReplaceString(s string) string {
 		s = replaceVars(s, vr.extractedVars)
 	}
 
-	// 第二步：替换动态变量（如 {{$timestamp}}）
+	// 第二步：替换动态变量（如 {{$timestamp}}）、场景变量和数据行（如 {{.row.user_id}}）
 	if vr.resolver != nil {
-		if resolved, err := vr.resolver.Resolve(s); err == nil {
+		var extra map[string]any
+		if vr.row != nil || vr.vars != nil {
+			extra = maps.Clone(vr.vars)
+			if extra == nil {
+				extra = make(map[string]any, 1)
+			}
+			if vr.row != nil {
+				extra["row"] = vr.row
+			}
+		}
+		if resolved, err := vr.resolver.ResolveWith(s, extra); err == nil {
 			s = resolved
 		}
 	}
@@ -13,6 +13,8 @@
 import (
 	"context"
 	"fmt"
+	"maps"
+	"math"
 	"strings"
 	"time"
 
@@ -20,7 +22,7 @@
 	"github.com/kamalyes/go-stress/config"
 	"github.com/kamalyes/go-stress/statistics"
 	"github.com/kamalyes/go-stress/verify"
-	"github.com/kamalyes/go-toolbox/pkg/mathx"
+	"github.com/kamalyes/go-toolbox/pkg/syncx"
 )
 
 // WorkerDependencyContext 每个 worker 的本地依赖上下文
@@ -39,28 +41,51 @@ func NewWorkerDependencyContext() *WorkerDependencyContext {
 
 // Worker 工作单元
 type Worker struct {
-	id          uint64
-	client      Client
-	handler     RequestHandler
-	collector   *statistics.Collector
-	reqCount    uint64
-	apiSelector APISelector              // API选择器（统一入口）
-	varResolver *config.VariableResolver // 动态变量解析器
-	controller  Controller               // 控制器
-	depContext  *WorkerDependencyContext // 本地依赖上下文
-	logger      logger.ILogger
+	id               uint64
+	client           Client
+	handler          RequestHandler   // 以 client 为最底层的处理器
+	newHandler       HandlerBuilder   // 为客户端构建处理器（按API协议创建的客户端同样经过中间件链）
+	progress         *ProgressTracker // 进度跟踪（可选）
+	collector        *statistics.Collector
+	reqCount         uint64
+	deadline         time.Time                // 截止时间（非零时按时间运行，忽略 reqCount）
+	retire           <-chan struct{}          // 退役信号（多阶段模式，关闭后执行完当前一轮即退出）
+	pacing           time.Duration            // 每轮迭代的目标时长（闭合模型，不足时空闲等待补齐）
+	apiSelector      APISelector              // API选择器（统一入口）
+	scenario         *Scenario                // 所属场景（多场景模式）
+	varResolver      *config.VariableResolver // 动态变量解析器
+	dataFeeder       *DataFeeder              // 数据供给器（可选）
+	row              map[string]string        // 本轮使用的数据行（模板中通过 {{.row.列名}} 访问）
+	setupRow         bool                     // row 由 setup 取得且尚未被迭代使用（首轮迭代沿用该行）
+	controller       Controller               // 控制器
+	depContext       *WorkerDependencyContext // 本地依赖上下文
+	hooks            WorkerHooks              // 生命周期步骤（含场景的步骤）
+	sessionVars      map[string]string        // 会话变量（before_all 和 setup 提取的变量，跨迭代保留）
+	apiClients       map[string]apiClient     // 按API协议懒加载的客户端（键为客户端复用键）
+	apiClientFactory APIClientFactory         // 单独配置了协议的API使用的客户端工厂
+	queueDelay       time.Duration            // 开放模型下本轮请求的排队时间（计入首个请求耗时后清零）
+	groupSeq         uint64                   // 已分配的分组序号（每个 worker 独立递增）
+	logger           logger.ILogger
 }
 
 // WorkerConfig Worker配置
 type WorkerConfig struct {
-	ID          uint64
-	Client      Client
-	Handler     RequestHandler
-	Collector   *statistics.Collector
-	ReqCount    uint64
-	APISelector APISelector // API选择器（必需）
-	Controller  Controller  // 控制器（可选）
-	Logger      logger.ILogger
+	ID               uint64
+	Client           Client
+	Handler          HandlerBuilder   // 为客户端构建处理器（可选，默认直接由客户端发送）
+	Progress         *ProgressTracker // 进度跟踪（可选，每发送一个请求计数一次）
+	Collector        *statistics.Collector
+	ReqCount         uint64
+	Deadline         time.Time        // 截止时间（可选，非零时优先于 ReqCount）
+	Retire           <-chan struct{}  // 退役信号（可选，设置后运行到信号关闭为止）
+	Pacing           time.Duration    // 每轮迭代的目标时长（可选，仅 Run 生效）
+	APISelector      APISelector      // API选择器（未指定场景时必需）
+	Scenario         *Scenario        // 所属场景（可选，设置后使用场景的API选择器）
+	DataFeeder       *DataFeeder      // 数据供给器（可选）
+	Controller       Controller       // 控制器（可选）
+	Hooks            *WorkerHooks     // 生命周期步骤（可选）
+	APIClientFactory APIClientFactory // 单独配置了协议的API使用的客户端工厂（可选）
+	Logger           logger.ILogger
 }
 
 // NewWorker 创建Worker
@@ -70,18 +95,46 @@ func NewWorker(cfg WorkerConfig, varResolver *config.VariableResolver) *Worker {
 		ctrl = &NoOpController{}
 	}
 
-	return &Worker{
-		id:          cfg.ID,
-		client:      cfg.Client,
-		handler:     cfg.Handler,
-		collector:   cfg.Collector,
-		reqCount:    cfg.ReqCount,
-		apiSelector: cfg.APISelector,
-		varResolver: varResolver,
-		controller:  ctrl,
-		depContext:  NewWorkerDependencyContext(),
-		logger:      cfg.Logger,
+	apiSelector := cfg.APISelector
+	if cfg.Scenario != nil {
+		apiSelector = cfg.Scenario.Selector
 	}
+
+	var hooks WorkerHooks
+	if cfg.Hooks != nil {
+		hooks = *cfg.Hooks
+	}
+	hooks = hooks.withScenario(cfg.Scenario)
+
+	newHandler := cfg.Handler
+	if newHandler == nil {
+		newHandler = ClientMiddleware
+	}
+
+	w := &Worker{
+		id:               cfg.ID,
+		client:           cfg.Client,
+		handler:          newHandler(cfg.Client),
+		newHandler:       newHandler,
+		progress:         cfg.Progress,
+		collector:        cfg.Collector,
+		reqCount:         cfg.ReqCount,
+		deadline:         cfg.Deadline,
+		retire:           cfg.Retire,
+		pacing:           cfg.Pacing,
+		apiSelector:      apiSelector,
+		scenario:         cfg.Scenario,
+		varResolver:      varResolver,
+		dataFeeder:       cfg.DataFeeder,
+		controller:       ctrl,
+		hooks:            hooks,
+		sessionVars:      maps.Clone(hooks.Vars),
+		apiClients:       make(map[string]apiClient),
+		apiClientFactory: cfg.APIClientFactory,
+		logger:           cfg.Logger,
+	}
+	w.resetDepContext()
+	return w
 }
 
 // Run 运行Worker
@@ -92,13 +145,110 @@ func (w *Worker) Run(ctx context.Context) error {
 		return err
 	}
 	defer w.client.Close()
+	defer w.closeAPIClients()
+
+	// 前置/后置步骤不计为活跃（前置步骤失败时不再执行迭代，但仍执行后置步骤清理已创建的数据）
+	defer w.teardownSession(ctx)
+	if err := w.setupSession(ctx); err != nil {
+		return err
+	}
+
+	w.collector.AddScenarioActiveWorkers(w.scenarioName(), 1)
+	defer w.collector.AddScenarioActiveWorkers(w.scenarioName(), -1)
+
+	// 执行请求（按时间运行时只在每轮开始前检查截止时间，进行中的请求/依赖链会完整执行）
+	for i := uint64(0); w.hasNext(i); i++ {
+		select {
+		case <-ctx.Done():
+			return ctx.Err()
+		default:
+		}
+
+		start := time.Now()
+		if w.runIteration(ctx) {
+			return nil
+		}
+
+		// 按节奏补齐本轮剩余时间（本轮已超出目标时长时立即开始下一轮）
+		if w.pacing > 0 && !w.pause(ctx, w.pacing-time.Since(start)) {
+			return ctx.Err()
+		}
+	}
+
+	return nil
+}
+
+// RunArrivals 开放模型下运行Worker：每收到一个到达时间执行一轮请求，直到通道关闭
+func (w *Worker) RunArrivals(ctx context.Context, arrivals <-chan time.Time, idle *syncx.Int64) error {
+	// 建立连接
+	if err := w.client.Connect(ctx); err != nil {
+		w.logger.Errorf("❌ Worker %d: 连接失败: %v", w.id, err)
+		return err
+	}
+	defer w.client.Close()
+	defer w.closeAPIClients()
+
+	defer w.teardownSession(ctx)
+	if err := w.setupSession(ctx); err != nil {
+		return err
+	}
+
+	for i := uint64(0); ; i++ {
+		idle.Add(1)
+		var (
+			scheduledAt time.Time
+			ok          bool
+		)
+		select {
+		case <-ctx.Done():
+			idle.Add(-1)
+			return ctx.Err()
+		case scheduledAt, ok = <-arrivals:
+			idle.Add(-1)
+		}
+		if !ok {
+			return nil
+		}
+
+		// 计划发送时间到实际开始之间的排队时间计入本轮首个请求的耗时
+		w.queueDelay = max(time.Since(scheduledAt), 0)
+
+		// 开放模型下只有处理到达的worker计为活跃
+		w.collector.AddScenarioActiveWorkers(w.scenarioName(), 1)
+		done := w.runIteration(ctx)
+		w.collector.AddScenarioActiveWorkers(w.scenarioName(), -1)
+		if done {
+			return nil
+		}
+	}
+}
+
+// runIteration 执行一轮请求（单API一次或完整依赖链），返回 true 表示应该退出
+func (w *Worker) runIteration(ctx context.Context) bool {
+	// 检查控制状态
+	if w.checkControlState() {
+		return true
+	}
+
+	// 每轮取一行数据（依赖链内的所有API共用同一行），数据用完时退出
+	// 首轮沿用 setup 取得的行，unique_once 数据源不会因 setup 多消耗一行
+	if w.setupRow {
+		w.setupRow = false
+	} else if w.dataFeeder != nil {
+		row, ok := w.dataFeeder.Next(w.id)
+		if !ok {
+			return true
+		}
+		w.row = row
+	}
+
+	// 每次新的请求序列，重置本地依赖上下文（保留 setup 等步骤提取的会话变量）
+	w.resetDepContext()
 
 	// 判断是否是依赖链模式
-	isDependencyMode := w.apiSelector != nil && w.apiSelector.HasDependencies()
 	var executionOrder []string
 	var resolver *DependencyResolver
-
-	if isDependencyMode {
+	if w.apiSelector != nil && w.apiSelector.HasDependencies() {
 		// 获取依赖链的执行顺序
 		resolver = w.apiSelector.GetDependencyResolver()
 		if resolver != nil {
@@ -106,68 +256,89 @@ func (w *Worker) Run(ctx context.Context) error {
 		}
 	}
 
-	// 执行请求
-	for i := uint64(0); i < w.reqCount; i++ {
-		select {
-		case <-ctx.Done():
-			return ctx.Err()
-		default:
-		}
+	// 单API模式或其他模式，执行一次
+	if len(executionOrder) == 0 {
+		return !w.think(ctx, w.executeRequest(ctx))
+	}
 
+	// 本轮依赖链共享一个分组ID
+	groupID := w.nextGroupID()
+
+	// 在依赖链模式下，按顺序执行完整的依赖链
+	for _, apiName := range executionOrder {
 		// 检查控制状态
 		if w.checkControlState() {
-			return nil
+			return true
 		}
 
-		// 每次新的请求序列，重置本地依赖上下文
-		w.depContext = NewWorkerDependencyContext()
-
-		// 计算分组ID（(Worker ID + 1) * 100000 + 请求序号，确保全局唯一）
-		groupID := (w.id+1)*100000 + i + 1
+		api := resolver.GetAPI(apiName)
+		if api == nil {
+			w.logger.Errorf("Worker %d: 找不到 API [%s]", w.id, apiName)
+			continue
+		}
 
-		// 在依赖链模式下，按顺序执行完整的依赖链
-		if isDependencyMode && len(executionOrder) > 0 {
-			for _, apiName := range executionOrder {
-				// 检查控制状态
-				if w.checkControlState() {
-					return nil
-				}
+		// 按控制流配置执行该步骤（遍历、条件、重复和轮询）
+		if !w.runStep(ctx, api, resolver, groupID) {
+			return true
+		}
+	}
 
-				// 获取 API 配置以检查重复次数
-				api := resolver.GetAPI(apiName)
-				if api == nil {
-					w.logger.Errorf("Worker %d: 找不到 API [%s]", w.id, apiName)
-					continue
-				}
+	return false
+}
 
-				// 确定重复次数（默认为1）
-				repeatCount := mathx.IfNotZero(api.Repeat, 1)
+// nextGroupID 分配新的分组ID：高 32 位为 Worker ID + 1，低 32 位为该 worker 递增的分组序号，确保全局唯一
+func (w *Worker) nextGroupID() uint64 {
+	w.groupSeq++
+	return (w.id+1)<<32 | w.groupSeq&math.MaxUint32
+}
 
-				// 执行指定次数
-				for r := 0; r < repeatCount; r++ {
-					// 检查控制状态
-					if w.checkControlState() {
-						return nil
-					}
+// hasNext 判断是否还需要执行第 i 轮请求
+func (w *Worker) hasNext(i uint64) bool {
+	if w.retire != nil {
+		select {
+		case <-w.retire:
+			return false
+		default:
+			return true
+		}
+	}
+	if !w.deadline.IsZero() {
+		return time.Now().Before(w.deadline)
+	}
+	return i < w.reqCount
+}
 
-					// 每次执行使用不同的 groupID（对于重复执行的API）
-					currentGroupID := groupID
-					if r > 0 {
-						// 如果是重复执行，在原groupID基础上增加一个小的偏移
-						currentGroupID = groupID + uint64(r)*100
-					}
+// think 请求完成后按API配置的思考时间等待，返回 false 表示上下文已取消
+func (w *Worker) think(ctx context.Context, apiCfg *APIConfig) bool {
+	if apiCfg == nil || apiCfg.ThinkTime == nil {
+		return true
+	}
+	return w.pause(ctx, apiCfg.ThinkTime.Sample())
+}
 
-					// 直接按顺序执行指定的 API
-					w.executeRequestByName(ctx, apiName, resolver, currentGroupID)
-				}
-			}
-		} else {
-			// 单API模式或其他模式，执行一次
-			w.executeRequest(ctx)
-		}
+// pause 空闲等待（不超过截止时间，收到退役信号时提前结束），等待期间不计为活跃worker
+// 返回 false 表示上下文已取消
+func (w *Worker) pause(ctx context.Context, d time.Duration) bool {
+	if !w.deadline.IsZero() {
+		d = min(d, time.Until(w.deadline))
+	}
+	if d <= 0 {
+		return ctx.Err() == nil
 	}
 
-	return nil
+	w.collector.AddScenarioActiveWorkers(w.scenarioName(), -1)
+	defer w.collector.AddScenarioActiveWorkers(w.scenarioName(), 1)
+
+	timer := time.NewTimer(d)
+	defer timer.Stop()
+	select {
+	case <-ctx.Done():
+		return false
+	case <-w.retire:
+		return true
+	case <-timer.C:
+		return true
+	}
 }
 
 // checkControlState 检查控制状态（停止/暂停）返回 true 表示应该退出
@@ -178,13 +349,13 @@ func (w *Worker) checkControlState() bool {
 	return WaitWhilePaused(w.controller)
 }
 
-// executeRequestUnified 统一的请求执行方法（消除重复代码）
-func (w *Worker) executeRequestUnified(ctx context.Context, source RequestSource) {
+// executeRequestUnified 统一的请求执行方法（消除重复代码），返回实际发送的API配置（未发送时为 nil）
+func (w *Worker) executeRequestUnified(ctx context.Context, source RequestSource) *APIConfig {
 	// 创建请求上下文
 	reqCtx, err := NewRequestContext(source, w.id, w.depContext)
 	if err != nil {
 		w.logger.Errorf("❌ Worker %d: %v", w.id, err)
-		return
+		return nil
 	}
 
 	apiCfg := reqCtx.APIConfig
@@ -193,18 +364,25 @@ func (w *Worker) executeRequestUnified(ctx context.Context, source RequestSource
 	// 检查是否应该跳过
 	if w.shouldSkipAPI(apiCfg.Name) {
 		w.recordSkippedRequest(apiCfg, groupID)
-		return
+		return nil
 	}
 
 	// 使用统一的变量替换器（同时处理提取变量和动态变量）
-	replacer := NewVariableReplacer(w.varResolver, w.depContext.extractedVars)
-	apiCfg = replacer.ReplaceInAPIConfig(apiCfg)
-
-	// 构建请求
-	req := BuildRequest(apiCfg)
-
-	// 执行请求（通过中间件链）
-	resp, err := w.handler(ctx, req)
+	clientKey := apiCfg.ClientKey()
+	apiCfg = w.newReplacer().ReplaceInAPIConfig(apiCfg)
+
+	// 构建请求（构建失败时不发送，按请求失败记录）
+	var resp *Response
+	req, err := BuildRequest(apiCfg)
+	if err == nil {
+		// 按时间运行时，保持连接的请求（会话/流）最迟在截止时间结束
+		req.Deadline = w.deadline
+		// 执行请求（通过中间件链，最终由 Worker 持有的该API协议的客户端发送）
+		resp, err = w.send(ctx, clientKey, apiCfg, req)
+	}
+	if w.progress != nil {
+		w.progress.Increment()
+	}
 
 	// 先提取变量（无论验证是否通过都提取）
 	var extractedVars map[string]string
@@ -222,17 +400,40 @@ func (w *Worker) executeRequestUnified(ctx context.Context, source RequestSource
 
 	// 记录结果
 	result := BuildRequestResult(resp, err)
+	if w.queueDelay > 0 {
+		// 开放模型：延迟从计划发送时间开始计算，反映真实用户感受到的耗时
+		result.Duration += w.queueDelay
+		w.queueDelay = 0
+	}
 	result.ExtractedVars = extractedVars
 	result.APIName = apiCfg.Name
 	result.GroupID = groupID
+	result.Scenario = w.scenarioName()
 	w.collector.Collect(result)
+	return apiCfg
+}
+
+// newReplacer 创建变量替换器（提取变量、数据行和场景变量）
+func (w *Worker) newReplacer() *VariableReplacer {
+	replacer := NewVariableReplacer(w.varResolver, w.depContext.extractedVars).WithRow(w.row)
+	if w.scenario != nil {
+		replacer.WithVars(w.scenario.Variables)
+	}
+	return replacer
+}
+
+// scenarioName 所属场景名称（未指定场景时为空）
+func (w *Worker) scenarioName() string {
+	if w.scenario == nil {
+		return ""
+	}
+	return w.scenario.Name
 }
 
 // recordSkippedRequest 记录跳过的请求
 func (w *Worker) recordSkippedRequest(apiCfg *APIConfig, groupID uint64) {
 	// 使用统一的变量替换器
-	replacer := NewVariableReplacer(w.varResolver, w.depContext.extractedVars)
-	apiCfg = replacer.ReplaceInAPIConfig(apiCfg)
+	apiCfg = w.newReplacer().ReplaceInAPIConfig(apiCfg)
 
 	// 找出具体失败的依赖API
 	failedDeps := w.getFailedDependencies(apiCfg.Name)
@@ -245,6 +446,7 @@ func (w *Worker) recordSkippedRequest(apiCfg *APIConfig, groupID uint64) {
 		SkipReason:    skipReason,
 		GroupID:       groupID,
 		APIName:       apiCfg.Name,
+		Scenario:      w.scenarioName(),
 		StatusCode:    0,
 		Duration:      0,
 		Error:         fmt.Errorf("%s", skipReason),
@@ -298,22 +500,22 @@ func (w *Worker) handleVerificationAndErrors(apiCfg *APIConfig, resp *Response,
 }
 
 // executeRequest 执行单次请求（统一方法）
-func (w *Worker) executeRequest(ctx context.Context) {
+func (w *Worker) executeRequest(ctx context.Context) *APIConfig {
 	if w.apiSelector == nil {
 		w.logger.Error("Worker 缺少 API选择器")
-		return
+		return nil
 	}
 
 	// 统一使用 APISource
 	source := NewAPISource(w.apiSelector, w.logger)
-	w.executeRequestUnified(ctx, source)
+	return w.executeRequestUnified(ctx, source)
 }
 
 // executeRequestByName 按名称执行指定的 API（用于依赖链模式）
-func (w *Worker) executeRequestByName(ctx context.Context, apiName string, resolver *DependencyResolver, groupID uint64) {
+func (w *Worker) executeRequestByName(ctx context.Context, apiName string, resolver *DependencyResolver, groupID uint64) *APIConfig {
 	// 创建依赖链API请求源（统一执行逻辑）
 	source := NewDependencyAPISource(apiName, resolver, groupID, w.logger)
-	w.executeRequestUnified(ctx, source)
+	return w.executeRequestUnified(ctx, source)
 }
 
 // markAPIFailedLocal 标记API在本地上下文中失败
@@ -0,0 +1,229 @@
+/*
+ * @Author: kamalyes 501893067@qq.com
+ * @Date: 2026-02-24 00:00:00
+ * @LastEditors: kamalyes 501893067@qq.com
+ * @LastEditTime: 2026-02-24 00:00:00
+ * @FilePath: \go-stress\executor\worker_test.go
+ * @Description: Worker 运行模式测试
+ *
+ * Copyright (c) 2026 by kamalyes, All Rights Reserved.
+ */
+package executor
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"sync"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/gorilla/websocket"
+	"github.com/kamalyes/go-stress/config"
+	"github.com/kamalyes/go-stress/logger"
+	"github.com/kamalyes/go-stress/protocol"
+	"github.com/kamalyes/go-stress/statistics"
+	"github.com/kamalyes/go-stress/storage"
+	"github.com/stretchr/testify/assert"
+)
+
+// 测试按时间运行时不限请求数，到达截止时间后停止（截止后不再发起新请求）
+func TestWorkerStopsAtDeadline(t *testing.T) {
+	collector := statistics.NewCollector(storage.NewMemoryStorage("test", logger.Default), logger.Default)
+	defer collector.Close()
+
+	const reqTime = 5 * time.Millisecond
+	var (
+		mu     sync.Mutex
+		starts []time.Time
+	)
+	handler := func(ctx context.Context, req *Request) (*Response, error) {
+		mu.Lock()
+		starts = append(starts, time.Now())
+		mu.Unlock()
+		time.Sleep(reqTime)
+		return &Response{StatusCode: 200, Duration: reqTime}, nil
+	}
+
+	cfg := &config.Config{APIs: []config.APIConfig{{Name: "ping", URL: "http://localhost/ping"}}}
+	cfg.SetLogger(logger.Default)
+	start := time.Now()
+	deadline := start.Add(100 * time.Millisecond)
+	worker := NewWorker(WorkerConfig{
+		Client:      handlerClient(handler),
+		Collector:   collector,
+		Deadline:    deadline,
+		APISelector: CreateAPISelector(cfg),
+		Logger:      logger.Default,
+	}, config.NewVariableResolver())
+
+	done := make(chan error, 1)
+	go func() { done <- worker.Run(context.Background()) }()
+
+	select {
+	case err := <-done:
+		assert.NoError(t, err)
+	case <-time.After(5 * time.Second):
+		t.Fatal("到达截止时间后 worker 未停止")
+	}
+	assert.False(t, time.Now().Before(deadline), "未到截止时间不会停止")
+
+	// 每个请求都在截止时间前发起，且请求串行执行，数量不超过截止时间内能容纳的请求数
+	mu.Lock()
+	defer mu.Unlock()
+	total := collector.GetSnapshot().TotalRequests
+	assert.Equal(t, uint64(len(starts)), total, "ReqCount 为0时按时间持续发送")
+	assert.NotEmpty(t, starts)
+	for _, at := range starts {
+		assert.True(t, at.Before(deadline), "截止后只完成进行中的请求，不再发起新请求")
+	}
+	assert.LessOrEqual(t, total, uint64(deadline.Sub(start)/reqTime)+1)
+}
+
+// connClient 记录发送次数的客户端（未建立连接时发送失败）
+type connClient struct {
+	protocol  ProtocolType
+	connected bool
+	sent      int
+}
+
+func (c *connClient) Connect(ctx context.Context) error {
+	c.connected = true
+	return nil
+}
+func (c *connClient) Send(ctx context.Context, req *Request) (*Response, error) {
+	if !c.connected {
+		return nil, errors.New("not connected")
+	}
+	c.sent++
+	return &Response{StatusCode: 200, Protocol: c.protocol}, nil
+}
+func (c *connClient) Close() error       { return nil }
+func (c *connClient) Type() ProtocolType { return c.protocol }
+
+// 测试每个 worker 以自己已连接的客户端构建处理器，共用的中间件链同样包裹按API协议创建的客户端
+func TestWorkerHandlerPerClient(t *testing.T) {
+	collector := statistics.NewCollector(storage.NewMemoryStorage("test", logger.Default), logger.Default)
+	defer collector.Close()
+
+	var wrapped atomic.Int64
+	chain := NewMiddlewareChain().Use(func(next RequestHandler) RequestHandler {
+		return func(ctx context.Context, req *Request) (*Response, error) {
+			wrapped.Add(1)
+			return next(ctx, req)
+		}
+	})
+
+	cfg := &config.Config{APIs: []config.APIConfig{
+		{Name: "login", URL: "http://localhost/login", Method: "POST"},
+		{Name: "chat", URL: "ws://localhost/chat", Protocol: ProtocolWebSocket, DependsOn: []string{"login"}},
+	}}
+	cfg.SetLogger(logger.Default)
+	selector := CreateAPISelector(cfg)
+
+	var (
+		mu      sync.Mutex
+		created []*connClient
+	)
+	factory := func(api *APIConfig) (Client, error) {
+		mu.Lock()
+		defer mu.Unlock()
+		c := &connClient{protocol: api.Protocol}
+		created = append(created, c)
+		return c, nil
+	}
+
+	clients := []*connClient{{protocol: ProtocolHTTP}, {protocol: ProtocolHTTP}}
+	var wg sync.WaitGroup
+	for i, client := range clients {
+		worker := NewWorker(WorkerConfig{
+			ID:               uint64(i),
+			Client:           client,
+			Handler:          chain.Handler,
+			Collector:        collector,
+			ReqCount:         3,
+			APISelector:      selector,
+			APIClientFactory: factory,
+			Logger:           logger.Default,
+		}, config.NewVariableResolver())
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			assert.NoError(t, worker.Run(context.Background()))
+		}()
+	}
+	wg.Wait()
+
+	for _, client := range clients {
+		assert.Equal(t, 3, client.sent, "每个 worker 的请求由自己的客户端发送")
+	}
+	assert.Len(t, created, 2, "每个 worker 各自创建 websocket 客户端")
+	for _, client := range created {
+		assert.Equal(t, 3, client.sent)
+	}
+	assert.Equal(t, int64(12), wrapped.Load(), "所有请求都经过中间件链")
+	assert.Equal(t, uint64(0), collector.GetSnapshot().FailedRequests)
+}
+
+// 测试按时间运行时，服务端从不断开的 WebSocket 会话（hold 为0）在截止时间结束并视为正常取消
+func TestWorkerSessionEndsAtDeadline(t *testing.T) {
+	collector := statistics.NewCollector(storage.NewMemoryStorage("test", logger.Default), logger.Default)
+	defer collector.Close()
+
+	upgrader := websocket.Upgrader{}
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		conn, err := upgrader.Upgrade(w, r, nil)
+		if err != nil {
+			return
+		}
+		defer conn.Close()
+		// 只读不写，直到客户端断开
+		for {
+			if _, _, err := conn.ReadMessage(); err != nil {
+				return
+			}
+		}
+	}))
+	defer srv.Close()
+	url := "ws" + strings.TrimPrefix(srv.URL, "http")
+
+	client, err := protocol.NewWebSocketClient(&config.Config{
+		URL:       url,
+		Timeout:   time.Second,
+		WebSocket: &config.WebSocketConfig{Mode: config.WebSocketModeSession},
+	})
+	assert.NoError(t, err)
+	assert.NoError(t, client.Connect(context.Background()))
+	defer client.Close()
+
+	cfg := &config.Config{APIs: []config.APIConfig{{Name: "chat", URL: url}}}
+	cfg.SetLogger(logger.Default)
+	worker := NewWorker(WorkerConfig{
+		Client:      client,
+		Collector:   collector,
+		Deadline:    time.Now().Add(100 * time.Millisecond),
+		APISelector: CreateAPISelector(cfg),
+		Logger:      logger.Default,
+	}, config.NewVariableResolver())
+
+	done := make(chan error, 1)
+	go func() { done <- worker.Run(context.Background()) }()
+
+	select {
+	case err := <-done:
+		assert.NoError(t, err)
+	case <-time.After(2 * time.Second):
+		t.Fatal("服务端不断开时会话未在截止时间结束")
+	}
+
+	snapshot := collector.GetSnapshot()
+	assert.Equal(t, uint64(1), snapshot.TotalRequests)
+	assert.Equal(t, uint64(0), snapshot.FailedRequests, "到达截止时间断开视为正常结束")
+	report := statistics.NewReportBuilder(collector).BuildReport(time.Second, false)
+	if assert.NotNil(t, report.WebSocket) {
+		assert.Equal(t, map[string]uint64{protocol.CloseReasonCanceled: 1}, report.WebSocket.CloseReasons)
+	}
+}
@@ -11,6 +11,7 @@
 package main
 
 import (
+	"errors"
 	"flag"
 	"fmt"
 	"os"
@@ -48,9 +49,12 @@
 	// 基础参数
 	configFile  string
 	curlFile    string
+	harFile     string
+	postmanFile string
 	protocol    string
 	concurrency uint64
 	requests    uint64
+	duration    time.Duration
 	url         string
 	method      string
 	timeout     time.Duration
@@ -63,6 +67,13 @@
 	grpcReflection bool
 	grpcService    string
 	grpcMethod     string
+	grpcProto      string     // 本地 proto 文件（不使用反射时）
+	grpcImportPath arrayFlags // proto 导入路径
+	grpcDescSet    string     // 编译好的 FileDescriptorSet
+
+	// WebSocket参数
+	wsMode string        // 压测模式 request/session
+	wsHold time.Duration // 会话模式的连接保持时长
 
 	// 其他
 	body    string
@@ -81,6 +92,15 @@
 	// 内存限制
 	maxMemory string // 内存使用阈值
 
+	// 退出控制
+	noWait bool // 压测结束后直接退出
+
+	// 场景导入 (HAR/Postman)
+	importInclude    arrayFlags // 只保留匹配的URL
+	importExclude    arrayFlags // 丢弃匹配的URL
+	importKeepStatic bool       // 保留静态资源
+	importChain      bool       // 按导入顺序串联请求
+
 	// 分布式参数
 	mode         types.RunMode // 运行模式: standalone/master/slave
 	masterAddr   string        // Master 地址 (Slave 模式使用)
@@ -123,9 +143,12 @@ func init() {
 	// 基础参数
 	flag.StringVar(&configFile, "config", "", "配置文件路径 (yaml/json)")
 	flag.StringVar(&curlFile, "curl", "", "curl命令文件路径")
-	flag.StringVar(&protocol, "protocol", "http", "协议类型 (http/grpc/websocket)")
+	flag.StringVar(&harFile, "har", "", "HAR文件路径（按录制顺序导入为多API场景）")
+	flag.StringVar(&postmanFile, "postman", "", "Postman v2.1 集合文件路径（按集合顺序导入为多API场景）")
+	flag.StringVar(&protocol, "protocol", "http", "协议类型 (http/grpc/websocket/sse/tcp/udp/mqtt)")
 	flag.Uint64Var(&concurrency, "c", 1, "并发数")
 	flag.Uint64Var(&requests, "n", 1, "每个并发的请求数")
+	flag.DurationVar(&duration, "d", 0, "压测持续时间 (如: 30s, 10m, 2h)，设置后优先于 -n")
 	flag.StringVar(&url, "url", "", "目标URL")
 	flag.StringVar(&method, "method", "GET", "请求方法")
 	flag.DurationVar(&timeout, "timeout", 30*time.Second, "请求超时时间")
@@ -138,6 +161,13 @@ func init() {
 	flag.BoolVar(&grpcReflection, "grpc-reflection", false, "使用gRPC反射")
 	flag.StringVar(&grpcService, "grpc-service", "", "gRPC服务名")
 	flag.StringVar(&grpcMethod, "grpc-method", "", "gRPC方法名")
+	flag.StringVar(&grpcProto, "grpc-proto", "", "gRPC proto文件路径（不使用反射时）")
+	flag.Var(&grpcImportPath, "grpc-import-path", "proto导入路径 (可多次使用)")
+	flag.StringVar(&grpcDescSet, "grpc-descriptor-set", "", "gRPC FileDescriptorSet 文件（不使用反射时）")
+
+	// WebSocket参数
+	flag.StringVar(&wsMode, "ws-mode", "request", "WebSocket压测模式 (request:一发一收 | session:保持连接并接收推送)")
+	flag.DurationVar(&wsHold, "ws-hold", 0, "WebSocket会话模式下每个连接的保持时长 (如: 5m)，0 表示直到服务端断开")
 
 	// 其他
 	flag.StringVar(&body, "data", "", "请求体数据")
@@ -156,6 +186,15 @@ func init() {
 	// 内存限制
 	flag.StringVar(&maxMemory, "max-memory", "", "内存使用阈值，超过后自动停止测试 (如: 1GB, 512MB, 2048KB)")
 
+	// 退出控制
+	flag.BoolVar(&noWait, "no-wait", false, "压测结束后直接退出，不保留实时报告服务器 (适用于 CI)")
+
+	// 场景导入
+	flag.Var(&importInclude, "import-include", "导入时只保留URL匹配该正则的请求 (可多次使用)")
+	flag.Var(&importExclude, "import-exclude", "导入时丢弃URL匹配该正则的请求 (可多次使用)")
+	flag.BoolVar(&importKeepStatic, "import-keep-static", false, "导入时保留静态资源 (css/js/图片/字体)")
+	flag.BoolVar(&importChain, "import-chain", false, "导入时按录制/集合顺序串联请求 (前一个请求失败时跳过后续请求)")
+
 	// 分布式参数
 	flag.Var(&mode, "mode", "运行模式 (standalone/master/slave)")
 	flag.StringVar(&masterAddr, "master", "", "Master节点地址 (Slave模式必需, 如: localhost:9090)")
@@ -233,6 +272,7 @@ func buildConfigFromFlags() *config.Config {
 	cfg.Protocol = types.ProtocolType(protocol)
 	cfg.Concurrency = concurrency
 	cfg.Requests = requests
+	cfg.Duration = duration
 	cfg.URL = url
 	cfg.Method = method
 	cfg.Timeout = timeout
@@ -260,10 +300,19 @@ func buildConfigFromFlags() *config.Config {
 			UseReflection: grpcReflection,
 			Service:       grpcService,
 			Method:        grpcMethod,
+			ProtoFile:     grpcProto,
+			ImportPaths:   grpcImportPath,
+			DescriptorSet: grpcDescSet,
 			Metadata:      make(map[string]string),
 		}
 	}
 
+	// WebSocket配置
+	if cfg.Protocol == ExportProtocolWebSocket {
+		cfg.WebSocket.Mode = config.WebSocketMode(wsMode)
+		cfg.WebSocket.Hold = wsHold
+	}
+
 	return cfg
 }
 
@@ -418,12 +467,21 @@ func printExamples() {
 		"# POST请求",
 		"go-stress -url https://api.example.com/users -method POST -data '{\"name\":\"test\"}' -H \"Content-Type: application/json\" -c 5 -n 50",
 		"",
+		"# 按时间压测（持续10分钟）",
+		"go-stress -url https://example.com -c 10 -d 10m",
+		"",
 		"# 使用配置文件",
 		"go-stress -config config.yaml",
 		"",
 		"# 使用curl文件",
 		"go-stress -curl requests.txt -c 10 -n 100",
 		"",
+		"# 导入浏览器录制的HAR文件（默认过滤静态资源）",
+		"go-stress -har session.har -import-include 'api\\.example\\.com' -c 10 -n 100",
+		"",
+		"# 导入Postman集合",
+		"go-stress -postman collection.json -c 10 -d 5m",
+		"",
 		"# 自定义报告前缀",
 		"go-stress -url https://example.com -c 10 -n 100 -report-prefix my-test",
 		"",
@@ -432,6 +490,8 @@ func printExamples() {
 		"",
 		"# WebSocket压测",
 		"go-stress -protocol websocket -url ws://localhost:8080/ws -body '{\"action\":\"ping\"}' -c 10 -n 100",
+		"# WebSocket会话压测（1000个连接各保持5分钟，接收服务端推送）",
+		"go-stress -protocol websocket -ws-mode session -ws-hold 5m -url ws://localhost:8080/ws -data '{\"action\":\"subscribe\"}' -c 1000 -n 1",
 		"",
 		"# 实时监控",
 		"运行后自动打开浏览器查看实时报告（默认端口: 8088，可通过配置文件的 realtime_port 修改）",
@@ -675,18 +735,32 @@ func runSlaveMode() {
 // runStandaloneMode 运行独立模式
 func runStandaloneMode() {
 	opts := bootstrap.StandaloneOptions{
-		ConfigFile:   configFile,
-		CurlFile:     curlFile,
+		ConfigFile:  configFile,
+		CurlFile:    curlFile,
+		HARFile:     harFile,
+		PostmanFile: postmanFile,
+		ImportFilter: &config.ImportFilter{
+			KeepStatic:      importKeepStatic,
+			IncludePatterns: importInclude,
+			ExcludePatterns: importExclude,
+			Chain:           importChain,
+		},
 		Concurrency:  concurrency,
 		Requests:     requests,
+		Duration:     duration,
 		Timeout:      timeout,
 		StorageMode:  storageMode,
 		ReportPrefix: reportPrefix,
 		MaxMemory:    maxMemory,
 		Logger:       logger.Default,
 		ConfigFunc:   buildConfigFromFlags,
+		NoWait:       noWait,
 	}
 	if err := bootstrap.RunStandalone(opts); err != nil {
+		if errors.Is(err, bootstrap.ErrThresholdsBreached) {
+			logger.Default.Errorf("❌ %v", err)
+			os.Exit(bootstrap.ExitCodeThresholdsBreached)
+		}
 		logger.Default.Fatalf("❌ 运行 Standalone 失败: %v", err)
 	}
 }
@@ -22,6 +22,15 @@
 	ProtocolType       = types.ProtocolType
 	VerifyType         = types.VerifyType
 	VerificationResult = types.VerificationResult
+	StreamStats        = types.StreamStats
+	WebSocketStats     = types.WebSocketStats
+	MQTTStats          = types.MQTTStats
+	SSEEvent           = types.SSEEvent
+)
+
+// 函数别名
+var (
+	GRPCCodeName = types.GRPCCodeName
 )
 
 // 常量别名
@@ -29,6 +38,13 @@
 	ProtocolHTTP      = types.ProtocolHTTP
 	ProtocolGRPC      = types.ProtocolGRPC
 	ProtocolWebSocket = types.ProtocolWebSocket
+	ProtocolSSE       = types.ProtocolSSE
+	ProtocolTCP       = types.ProtocolTCP
+	ProtocolUDP       = types.ProtocolUDP
+	ProtocolMQTT      = types.ProtocolMQTT
+
+	MQTTConnectAccepted = types.MQTTConnectAccepted
+	GRPCMetadataKey     = types.GRPCMetadataKey
 
 	// 基础验证类型
 	VerifyTypeStatusCode = types.VerifyTypeStatusCode
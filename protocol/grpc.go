@@ -12,26 +12,26 @@
 
 import (
 	"context"
-	"crypto/tls"
-	"crypto/x509"
 	"encoding/json"
 	"fmt"
-	"os"
+	"strings"
 	"time"
 
 	"google.golang.org/grpc"
 	"google.golang.org/grpc/credentials"
 	"google.golang.org/grpc/credentials/insecure"
 	"google.golang.org/grpc/metadata"
+	"google.golang.org/grpc/status"
 
 	"github.com/kamalyes/go-stress/config"
 )
 
 // GRPCClient gRPC协议客户端
 type GRPCClient struct {
-	config    *config.Config
-	conn      *grpc.ClientConn
-	reflector *GRPCReflector // 反射辅助
+	config      *config.Config
+	conn        *grpc.ClientConn
+	reflector   *GRPCReflector        // 反射辅助
+	descriptors *GRPCDescriptorSource // 本地描述符（未启用反射时）
 }
 
 // NewGRPCClient 创建gRPC客户端
@@ -47,6 +47,12 @@ func NewGRPCClient(cfg *config.Config) (*GRPCClient, error) {
 	// 如果启用反射，创建反射辅助
 	if cfg.GRPC.UseReflection {
 		client.reflector = NewGRPCReflector()
+	} else {
+		descriptors, err := NewGRPCDescriptorSource(cfg.GRPC)
+		if err != nil {
+			return nil, err
+		}
+		client.descriptors = descriptors
 	}
 
 	return client, nil
@@ -60,47 +66,13 @@ func (g *GRPCClient) Connect(ctx context.Context) error {
 
 	// 支持 TLS 配置
 	if g.config.GRPC.TLS != nil && g.config.GRPC.TLS.Enabled {
-		var tlsConfig *tls.Config
-
-		if g.config.GRPC.TLS.InsecureSkipVerify {
-			// 不验证服务器证书（仅用于测试环境）
-			tlsConfig = &tls.Config{
-				InsecureSkipVerify: true,
-			}
-		} else {
-			// 加载 CA 证书
-			if g.config.GRPC.TLS.CAFile != "" {
-				certPool := x509.NewCertPool()
-				ca, err := os.ReadFile(g.config.GRPC.TLS.CAFile)
-				if err != nil {
-					return fmt.Errorf("读取 CA 证书失败: %w", err)
-				}
-				if !certPool.AppendCertsFromPEM(ca) {
-					return fmt.Errorf("添加 CA 证书失败")
-				}
-				tlsConfig = &tls.Config{
-					RootCAs: certPool,
-				}
-			}
-
-			// 加载客户端证书（双向认证）
-			if g.config.GRPC.TLS.CertFile != "" && g.config.GRPC.TLS.KeyFile != "" {
-				cert, err := tls.LoadX509KeyPair(g.config.GRPC.TLS.CertFile, g.config.GRPC.TLS.KeyFile)
-				if err != nil {
-					return fmt.Errorf("加载客户端证书失败: %w", err)
-				}
-				if tlsConfig == nil {
-					tlsConfig = &tls.Config{}
-				}
-				tlsConfig.Certificates = []tls.Certificate{cert}
-			}
+		tlsConfig, err := buildTLSConfig(g.config.GRPC.TLS)
+		if err != nil {
+			return err
 		}
-
-		if tlsConfig != nil {
-			// 替换为 TLS 凭证
-			opts = []grpc.DialOption{
-				grpc.WithTransportCredentials(credentials.NewTLS(tlsConfig)),
-			}
+		// 替换为 TLS 凭证
+		opts = []grpc.DialOption{
+			grpc.WithTransportCredentials(credentials.NewTLS(tlsConfig)),
 		}
 	}
 
@@ -122,55 +94,109 @@ func (g *GRPCClient) Connect(ctx context.Context) error {
 	return nil
 }
 
-// Send 发送gRPC请求
+// Send 发送gRPC请求（流式方法按 stream 配置发起流式调用）
+// 状态码为 gRPC 状态码（0 为 OK），响应 metadata 和 trailer 合并到响应头
 func (g *GRPCClient) Send(ctx context.Context, req *Request) (*Response, error) {
 	startTime := time.Now()
 
-	// 设置超时
-	if g.config.Timeout > 0 {
-		var cancel context.CancelFunc
-		ctx, cancel = context.WithTimeout(ctx, g.config.Timeout)
-		defer cancel()
+	// 设置metadata：优先使用请求携带的 metadata（已合并公共 metadata 并完成变量替换），请求头不作为 metadata 发送
+	md := metadata.New(g.config.GRPC.Metadata)
+	if m, ok := req.Metadata[GRPCMetadataKey].(map[string]string); ok {
+		md = metadata.New(m)
 	}
-
-	// 设置metadata
-	if len(g.config.GRPC.Metadata) > 0 {
-		md := metadata.New(g.config.GRPC.Metadata)
-		ctx = metadata.NewOutgoingContext(ctx, md)
+	ctx = metadata.NewOutgoingContext(ctx, md)
+
+	resp, err := g.send(ctx, req)
+	resp.Duration = time.Since(startTime)
+	resp.Protocol = ProtocolGRPC
+	resp.StatusCode = int(status.Code(err))
+	resp.RequestURL = g.config.URL
+	resp.RequestMethod = fmt.Sprintf("/%s/%s", g.config.GRPC.Service, g.config.GRPC.Method)
+	resp.RequestHeaders = metadataToHeaders(md)
+	resp.RequestBody = req.Body
+	if err != nil {
+		resp.Error = fmt.Errorf("gRPC调用失败: %w", err)
+		return resp, err
 	}
+	return resp, nil
+}
 
-	var respData []byte
-	var err error
+// send 按方法类型发起一元或流式调用
+func (g *GRPCClient) send(ctx context.Context, req *Request) (*Response, error) {
+	desc, err := g.findMethod(ctx)
+	if err != nil {
+		return &Response{}, err
+	}
 
-	// 使用反射或普通调用
-	if g.reflector != nil {
-		// 反射调用
-		respData, err = g.reflector.Invoke(
+	// 流式调用的时长由 stream.lifetime 和请求的截止时间控制，不受请求超时限制
+	if streamKind(desc.descriptor) != "" {
+		return invokeStream(
 			ctx,
 			g.conn,
+			desc,
 			g.config.GRPC.Service,
 			g.config.GRPC.Method,
 			[]byte(req.Body),
+			g.config.GRPC.Stream,
+			req.Deadline,
 		)
-	} else {
-		// 普通调用（需要生成的代码）
-		return nil, fmt.Errorf("未启用反射模式，暂不支持")
 	}
 
-	duration := time.Since(startTime)
-
-	if err != nil {
-		return &Response{
-			Duration: duration,
-			Error:    fmt.Errorf("gRPC调用失败: %w", err),
-		}, err
+	// 设置超时
+	if g.config.Timeout > 0 {
+		var cancel context.CancelFunc
+		ctx, cancel = context.WithTimeout(ctx, g.config.Timeout)
+		defer cancel()
 	}
 
+	var header, trailer metadata.MD
+	respData, err := invokeMethod(
+		ctx,
+		g.conn,
+		desc,
+		g.config.GRPC.Service,
+		g.config.GRPC.Method,
+		[]byte(req.Body),
+		grpc.Header(&header),
+		grpc.Trailer(&trailer),
+	)
 	return &Response{
-		StatusCode: 0, // gRPC没有HTTP状态码
-		Body:       respData,
-		Duration:   duration,
-	}, nil
+		Body:    respData,
+		Headers: metadataToHeaders(header, trailer),
+	}, err
+}
+
+// metadataToHeaders 将 metadata 合并为响应头（键为小写，多个值以逗号分隔，后面的 metadata 优先）
+func metadataToHeaders(mds ...metadata.MD) map[string]string {
+	headers := make(map[string]string)
+	for _, md := range mds {
+		for k, v := range md {
+			headers[k] = strings.Join(v, ", ")
+		}
+	}
+	return headers
+}
+
+// findMethod 通过反射或本地描述符查找方法描述（反射查询受请求超时限制）
+func (g *GRPCClient) findMethod(ctx context.Context) (*methodDesc, error) {
+	var (
+		desc *methodDesc
+		err  error
+	)
+	if g.reflector != nil {
+		if g.config.Timeout > 0 {
+			var cancel context.CancelFunc
+			ctx, cancel = context.WithTimeout(ctx, g.config.Timeout)
+			defer cancel()
+		}
+		desc, err = g.reflector.getMethodDescriptor(ctx, g.config.GRPC.Service, g.config.GRPC.Method)
+	} else {
+		desc, err = g.descriptors.FindMethod(g.config.GRPC.Service, g.config.GRPC.Method)
+	}
+	if err != nil {
+		return nil, fmt.Errorf("获取方法描述失败: %w", err)
+	}
+	return desc, nil
 }
 
 // Close 关闭gRPC连接
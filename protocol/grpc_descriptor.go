@@ -0,0 +1,169 @@
+/*
+ * @Author: kamalyes 501893067@qq.com
+ * @Date: 2026-02-11 00:00:00
+ * @LastEditors: kamalyes 501893067@qq.com
+ * @LastEditTime: 2026-02-11 00:00:00
+ * @FilePath: \go-stress\protocol\grpc_descriptor.go
+ * @Description: gRPC本地描述符支持 - 解析 .proto 文件或 FileDescriptorSet，无需服务端反射
+ *
+ * Copyright (c) 2026 by kamalyes, All Rights Reserved.
+ */
+package protocol
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"github.com/bufbuild/protocompile"
+	"github.com/kamalyes/go-stress/config"
+	"github.com/kamalyes/go-toolbox/pkg/syncx"
+	"google.golang.org/grpc"
+	"google.golang.org/protobuf/proto"
+	"google.golang.org/protobuf/reflect/protodesc"
+	"google.golang.org/protobuf/reflect/protoreflect"
+	"google.golang.org/protobuf/types/descriptorpb"
+)
+
+// descriptorResolver 按全名查找描述符（protoregistry.Files 和 protocompile 的 linker.Resolver 均满足）
+type descriptorResolver interface {
+	FindDescriptorByName(name protoreflect.FullName) (protoreflect.Descriptor, error)
+}
+
+// descriptorCache 已解析的描述符（每个worker各自创建客户端，相同配置只解析一次）
+var descriptorCache = syncx.NewMap[string, *GRPCDescriptorSource]()
+
+// GRPCDescriptorSource 本地描述符源（.proto 文件或编译好的 FileDescriptorSet）
+type GRPCDescriptorSource struct {
+	resolver descriptorResolver
+	methods  *syncx.Map[string, *methodDesc] // 方法描述缓存（service/method -> 描述）
+}
+
+// NewGRPCDescriptorSource 根据配置加载本地描述符（descriptor_set 优先于 proto_file）
+func NewGRPCDescriptorSource(cfg *config.GRPCConfig) (*GRPCDescriptorSource, error) {
+	key := descriptorCacheKey(cfg)
+	if source, ok := descriptorCache.Load(key); ok {
+		return source, nil
+	}
+
+	var (
+		resolver descriptorResolver
+		err      error
+	)
+	switch {
+	case cfg.DescriptorSet != "":
+		resolver, err = loadDescriptorSet(cfg.DescriptorSet)
+	case cfg.ProtoFile != "":
+		resolver, err = compileProtoFile(cfg.ProtoFile, cfg.ImportPaths)
+	default:
+		return nil, fmt.Errorf("未启用反射时必须指定 proto_file 或 descriptor_set")
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	source := &GRPCDescriptorSource{
+		resolver: resolver,
+		methods:  syncx.NewMap[string, *methodDesc](),
+	}
+	source, _ = descriptorCache.LoadOrStore(key, source)
+	return source, nil
+}
+
+// descriptorCacheKey 描述符缓存键
+func descriptorCacheKey(cfg *config.GRPCConfig) string {
+	if cfg.DescriptorSet != "" {
+		return "set:" + cfg.DescriptorSet
+	}
+	return "proto:" + cfg.ProtoFile + "|" + strings.Join(cfg.ImportPaths, ",")
+}
+
+// loadDescriptorSet 加载 FileDescriptorSet（protoc --descriptor_set_out --include_imports 生成）
+func loadDescriptorSet(path string) (descriptorResolver, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, fmt.Errorf("读取描述符集失败: %w", err)
+	}
+	var set descriptorpb.FileDescriptorSet
+	if err := proto.Unmarshal(data, &set); err != nil {
+		return nil, fmt.Errorf("解析描述符集失败: %w", err)
+	}
+	files, err := protodesc.NewFiles(&set)
+	if err != nil {
+		return nil, fmt.Errorf("构建描述符集失败（是否缺少 --include_imports）: %w", err)
+	}
+	return files, nil
+}
+
+// compileProtoFile 编译 .proto 文件（google/protobuf 标准导入无需提供）
+// 未指定导入路径时以文件所在目录作为导入路径
+func compileProtoFile(protoFile string, importPaths []string) (descriptorResolver, error) {
+	name := protoFile
+	if len(importPaths) == 0 {
+		importPaths = []string{filepath.Dir(protoFile)}
+		name = filepath.Base(protoFile)
+	} else {
+		// proto_file 位于某个导入路径下时转换为相对路径
+		for _, dir := range importPaths {
+			if rel, err := filepath.Rel(dir, protoFile); err == nil && !strings.HasPrefix(rel, "..") {
+				name = filepath.ToSlash(rel)
+				break
+			}
+		}
+	}
+
+	compiler := protocompile.Compiler{
+		Resolver: protocompile.WithStandardImports(&protocompile.SourceResolver{ImportPaths: importPaths}),
+	}
+	files, err := compiler.Compile(context.Background(), name)
+	if err != nil {
+		return nil, fmt.Errorf("编译proto文件失败: %w", err)
+	}
+	return files.AsResolver(), nil
+}
+
+// Invoke 使用本地描述符调用gRPC方法（请求和响应均为JSON）
+func (s *GRPCDescriptorSource) Invoke(
+	ctx context.Context,
+	conn *grpc.ClientConn,
+	service string,
+	method string,
+	requestJSON []byte,
+) ([]byte, error) {
+	desc, err := s.FindMethod(service, method)
+	if err != nil {
+		return nil, fmt.Errorf("获取方法描述失败: %w", err)
+	}
+	return invokeMethod(ctx, conn, desc, service, method, requestJSON)
+}
+
+// FindMethod 查找方法描述
+func (s *GRPCDescriptorSource) FindMethod(service, method string) (*methodDesc, error) {
+	key := service + "/" + method
+	if desc, ok := s.methods.Load(key); ok {
+		return desc, nil
+	}
+
+	d, err := s.resolver.FindDescriptorByName(protoreflect.FullName(service))
+	if err != nil {
+		return nil, fmt.Errorf("服务不存在: %s", service)
+	}
+	svc, ok := d.(protoreflect.ServiceDescriptor)
+	if !ok {
+		return nil, fmt.Errorf("%s 不是服务", service)
+	}
+	m := svc.Methods().ByName(protoreflect.Name(method))
+	if m == nil {
+		return nil, fmt.Errorf("方法不存在: %s", method)
+	}
+
+	desc := &methodDesc{
+		descriptor: m,
+		inputType:  m.Input(),
+		outputType: m.Output(),
+	}
+	s.methods.Store(key, desc)
+	return desc, nil
+}
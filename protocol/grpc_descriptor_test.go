@@ -0,0 +1,96 @@
+/*
+ * @Author: kamalyes 501893067@qq.com
+ * @Date: 2026-02-11 00:00:00
+ * @LastEditors: kamalyes 501893067@qq.com
+ * @LastEditTime: 2026-02-11 00:00:00
+ * @FilePath: \go-stress\protocol\grpc_descriptor_test.go
+ * @Description: gRPC本地描述符测试
+ *
+ * Copyright (c) 2026 by kamalyes, All Rights Reserved.
+ */
+package protocol
+
+import (
+	"context"
+	"net"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/kamalyes/go-stress/config"
+	"github.com/kamalyes/go-stress/types"
+	"github.com/stretchr/testify/assert"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/health"
+	healthpb "google.golang.org/grpc/health/grpc_health_v1"
+	"google.golang.org/protobuf/proto"
+	"google.golang.org/protobuf/reflect/protodesc"
+	"google.golang.org/protobuf/types/descriptorpb"
+)
+
+// startHealthServer 启动未注册反射服务的健康检查服务
+func startHealthServer(t *testing.T) string {
+	lis, err := net.Listen("tcp", "127.0.0.1:0")
+	assert.NoError(t, err)
+	srv := grpc.NewServer()
+	healthpb.RegisterHealthServer(srv, health.NewServer())
+	go func() { _ = srv.Serve(lis) }()
+	t.Cleanup(srv.Stop)
+	return lis.Addr().String()
+}
+
+// callHealthCheck 使用本地描述符调用 Health/Check
+func callHealthCheck(t *testing.T, addr string, grpcCfg *config.GRPCConfig) (*types.Response, error) {
+	grpcCfg.Service = "grpc.health.v1.Health"
+	grpcCfg.Method = "Check"
+	client, err := NewGRPCClient(&config.Config{URL: addr, GRPC: grpcCfg})
+	if err != nil {
+		return nil, err
+	}
+	assert.NoError(t, client.Connect(context.Background()))
+	defer client.Close()
+	return client.Send(context.Background(), &types.Request{Body: `{"service":""}`})
+}
+
+// 测试从 .proto 文件（含导入路径）调用
+func TestGRPCDescriptorProtoFile(t *testing.T) {
+	addr := startHealthServer(t)
+	root := filepath.Join("testdata", "proto")
+
+	resp, err := callHealthCheck(t, addr, &config.GRPCConfig{
+		ProtoFile:   filepath.Join(root, "grpc", "health", "v1", "health.proto"),
+		ImportPaths: []string{root},
+	})
+	assert.NoError(t, err)
+	assert.JSONEq(t, `{"status":"SERVING"}`, string(resp.Body))
+
+	// 缺少导入路径时无法解析 common/status.proto
+	_, err = callHealthCheck(t, addr, &config.GRPCConfig{
+		ProtoFile: filepath.Join(root, "grpc", "health", "v1", "health.proto"),
+	})
+	assert.ErrorContains(t, err, "编译proto文件失败")
+}
+
+// 测试从 FileDescriptorSet 调用
+func TestGRPCDescriptorSet(t *testing.T) {
+	addr := startHealthServer(t)
+
+	set := &descriptorpb.FileDescriptorSet{
+		File: []*descriptorpb.FileDescriptorProto{protodesc.ToFileDescriptorProto(healthpb.File_grpc_health_v1_health_proto)},
+	}
+	data, err := proto.Marshal(set)
+	assert.NoError(t, err)
+	setFile := filepath.Join(t.TempDir(), "health.pb")
+	assert.NoError(t, os.WriteFile(setFile, data, 0o600))
+
+	resp, err := callHealthCheck(t, addr, &config.GRPCConfig{DescriptorSet: setFile})
+	assert.NoError(t, err)
+	assert.JSONEq(t, `{"status":"SERVING"}`, string(resp.Body))
+
+	source, err := NewGRPCDescriptorSource(&config.GRPCConfig{DescriptorSet: setFile})
+	assert.NoError(t, err)
+	_, err = source.FindMethod("grpc.health.v1.Health", "Missing")
+	assert.ErrorContains(t, err, "方法不存在")
+	_, err = source.FindMethod("grpc.health.v1.Missing", "Check")
+	assert.ErrorContains(t, err, "服务不存在")
+}
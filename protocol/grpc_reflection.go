@@ -71,6 +71,19 @@ func (r *GRPCReflector) Invoke(
 		return nil, fmt.Errorf("获取方法描述失败: %w", err)
 	}
 
+	return invokeMethod(ctx, conn, methodDesc, service, method, requestJSON)
+}
+
+// invokeMethod 按方法描述构建动态消息并调用（反射和本地描述符共用）
+func invokeMethod(
+	ctx context.Context,
+	conn *grpc.ClientConn,
+	methodDesc *methodDesc,
+	service string,
+	method string,
+	requestJSON []byte,
+	opts ...grpc.CallOption,
+) ([]byte, error) {
 	// 创建动态请求消息
 	reqMsg := dynamicpb.NewMessage(methodDesc.inputType)
 
@@ -86,8 +99,7 @@ func (r *GRPCReflector) Invoke(
 	fullMethod := fmt.Sprintf("/%s/%s", service, method)
 
 	// 调用gRPC方法
-	err = conn.Invoke(ctx, fullMethod, reqMsg, respMsg)
-	if err != nil {
+	if err := conn.Invoke(ctx, fullMethod, reqMsg, respMsg, opts...); err != nil {
 		return nil, fmt.Errorf("调用gRPC方法失败: %w", err)
 	}
 
@@ -0,0 +1,278 @@
+/*
+ * @Author: kamalyes 501893067@qq.com
+ * @Date: 2026-02-12 00:00:00
+ * @LastEditors: kamalyes 501893067@qq.com
+ * @LastEditTime: 2026-02-12 00:00:00
+ * @FilePath: \go-stress\protocol\grpc_stream.go
+ * @Description: gRPC流式调用 - 客户端流、服务端流、双向流
+ *
+ * Copyright (c) 2026 by kamalyes, All Rights Reserved.
+ */
+package protocol
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"sync"
+	"time"
+
+	"github.com/kamalyes/go-stress/config"
+	"github.com/kamalyes/go-toolbox/pkg/mathx"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/status"
+	"google.golang.org/protobuf/encoding/protojson"
+	"google.golang.org/protobuf/reflect/protoreflect"
+	"google.golang.org/protobuf/types/dynamicpb"
+)
+
+// 流类型
+const (
+	StreamKindClient = "client" // 客户端流
+	StreamKindServer = "server" // 服务端流
+	StreamKindBidi   = "bidi"   // 双向流
+)
+
+// streamKind 根据方法描述识别流类型（一元调用返回空）
+func streamKind(md protoreflect.MethodDescriptor) string {
+	switch {
+	case md.IsStreamingClient() && md.IsStreamingServer():
+		return StreamKindBidi
+	case md.IsStreamingClient():
+		return StreamKindClient
+	case md.IsStreamingServer():
+		return StreamKindServer
+	}
+	return ""
+}
+
+// grpcCodeName 错误对应的 gRPC 状态码名称
+func grpcCodeName(err error) string {
+	return GRPCCodeName(int(status.Code(err)))
+}
+
+// parseStreamMessages 解析流消息：请求体为 JSON 数组时每个元素是一条消息，否则整个请求体为一条消息
+func parseStreamMessages(input protoreflect.MessageDescriptor, requestJSON []byte) ([]*dynamicpb.Message, error) {
+	raws := []json.RawMessage{requestJSON}
+	if trimmed := bytes.TrimSpace(requestJSON); len(trimmed) > 0 && trimmed[0] == '[' {
+		if err := json.Unmarshal(trimmed, &raws); err != nil {
+			return nil, fmt.Errorf("解析流消息数组失败: %w", err)
+		}
+		if len(raws) == 0 {
+			return nil, fmt.Errorf("流消息数组不能为空")
+		}
+	}
+
+	messages := make([]*dynamicpb.Message, 0, len(raws))
+	for _, raw := range raws {
+		msg := dynamicpb.NewMessage(input)
+		if err := protojson.Unmarshal(raw, msg); err != nil {
+			return nil, fmt.Errorf("解析请求JSON失败: %w", err)
+		}
+		messages = append(messages, msg)
+	}
+	return messages, nil
+}
+
+// streamSendResult 发送协程的结果
+type streamSendResult struct {
+	sent int
+	err  error
+}
+
+// streamSendTimes 每条消息的发送时间（发送协程写入，接收方按顺序关联响应计算单条消息延迟）
+type streamSendTimes struct {
+	mu    sync.Mutex
+	times []time.Time
+}
+
+// add 记录一条消息的发送时间（在 SendMsg 之前记录，保证对应的响应到达时已可查询）
+func (s *streamSendTimes) add(t time.Time) {
+	s.mu.Lock()
+	s.times = append(s.times, t)
+	s.mu.Unlock()
+}
+
+// at 第 i 条消息的发送时间
+func (s *streamSendTimes) at(i int) (time.Time, bool) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if i >= len(s.times) {
+		return time.Time{}, false
+	}
+	return s.times[i], true
+}
+
+// last 最后一条消息的发送时间
+func (s *streamSendTimes) last() (time.Time, bool) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if len(s.times) == 0 {
+		return time.Time{}, false
+	}
+	return s.times[len(s.times)-1], true
+}
+
+// streamEnd 流的主动结束时间：lifetime 到期和截止时间中较早者，零值表示直到服务端结束
+func streamEnd(lifetime time.Duration, deadline time.Time) time.Time {
+	if lifetime <= 0 {
+		return deadline
+	}
+	end := time.Now().Add(lifetime)
+	if !deadline.IsZero() && deadline.Before(end) {
+		return deadline
+	}
+	return end
+}
+
+// invokeStream 发起流式调用，响应体为最后一条接收消息的JSON，响应头为流的 metadata 和 trailer
+// 超过 lifetime 或到达截止时间（按时间运行的压测结束）主动结束的流视为正常结束（状态码 OK）
+func invokeStream(
+	ctx context.Context,
+	conn *grpc.ClientConn,
+	desc *methodDesc,
+	service string,
+	method string,
+	requestJSON []byte,
+	cfg *config.GRPCStreamConfig,
+	deadline time.Time,
+) (*Response, error) {
+	if cfg == nil {
+		cfg = &config.GRPCStreamConfig{}
+	}
+	md := desc.descriptor
+	stats := &StreamStats{Kind: streamKind(md)}
+	resp := &Response{Stream: stats}
+
+	messages, err := parseStreamMessages(desc.inputType, requestJSON)
+	if err != nil {
+		stats.Code = grpcCodeName(err)
+		return resp, err
+	}
+	// 服务端流只发送一条请求消息
+	count := 1
+	if md.IsStreamingClient() {
+		count = mathx.IfNotZero(cfg.Messages, len(messages))
+	}
+
+	end := streamEnd(cfg.Lifetime, deadline)
+	streamCtx, cancel := context.WithCancel(ctx)
+	if !end.IsZero() {
+		streamCtx, cancel = context.WithDeadline(ctx, end)
+	}
+	defer cancel()
+
+	startTime := time.Now()
+	stream, err := conn.NewStream(streamCtx, &grpc.StreamDesc{
+		StreamName:    method,
+		ClientStreams: md.IsStreamingClient(),
+		ServerStreams: md.IsStreamingServer(),
+	}, fmt.Sprintf("/%s/%s", service, method))
+	if err != nil {
+		stats.Code = grpcCodeName(err)
+		return resp, fmt.Errorf("创建gRPC流失败: %w", err)
+	}
+
+	// 发送协程：按间隔发送消息后关闭发送方向（双向流与接收并行）
+	sendTimes := &streamSendTimes{}
+	sendDone := make(chan streamSendResult, 1)
+	go func() {
+		sendDone <- sendStreamMessages(streamCtx, stream, messages, count, cfg.Interval, sendTimes)
+	}()
+
+	// 接收直到服务端结束流
+	var (
+		last    *dynamicpb.Message
+		recvErr error
+	)
+	prev := startTime
+	for {
+		msg := dynamicpb.NewMessage(desc.outputType)
+		if err := stream.RecvMsg(msg); err != nil {
+			if !errors.Is(err, io.EOF) {
+				recvErr = err
+			}
+			break
+		}
+		now := time.Now()
+		if stats.Received == 0 {
+			stats.FirstMessage = now.Sub(startTime)
+		}
+		// 单条消息延迟：客户端流的唯一响应对应最后一条发送，其余按顺序对应第 N 条发送（超出发送数的推送消息不计）
+		sentAt, ok := sendTimes.at(stats.Received)
+		if !md.IsStreamingServer() {
+			sentAt, ok = sendTimes.last()
+		}
+		if ok {
+			stats.MessageLatencies = append(stats.MessageLatencies, now.Sub(sentAt))
+		}
+		stats.InterArrivals = append(stats.InterArrivals, now.Sub(prev))
+		stats.Received++
+		prev = now
+		last = msg
+		// 客户端流只有一条响应
+		if !md.IsStreamingServer() {
+			break
+		}
+	}
+
+	// 流已结束，取消 context 以终止仍在等待发送间隔的协程
+	lifetimeExpired := !end.IsZero() && errors.Is(streamCtx.Err(), context.DeadlineExceeded) && ctx.Err() == nil
+	cancel()
+	sendResult := <-sendDone
+	stats.Sent = sendResult.sent
+
+	if lifetimeExpired {
+		recvErr = nil
+	} else if recvErr == nil && sendResult.err != nil {
+		recvErr = sendResult.err
+	}
+	stats.Code = grpcCodeName(recvErr)
+	// 流已结束，Header 不会阻塞；主动结束的流没有 trailer
+	header, _ := stream.Header()
+	resp.Headers = metadataToHeaders(header, stream.Trailer())
+	if recvErr != nil {
+		return resp, fmt.Errorf("gRPC流调用失败: %w", recvErr)
+	}
+
+	if last != nil {
+		if resp.Body, err = protojson.Marshal(last); err != nil {
+			return resp, fmt.Errorf("序列化响应失败: %w", err)
+		}
+	}
+	return resp, nil
+}
+
+// sendStreamMessages 按顺序循环发送 count 条消息并记录发送时间，发送完毕后关闭发送方向
+func sendStreamMessages(
+	ctx context.Context,
+	stream grpc.ClientStream,
+	messages []*dynamicpb.Message,
+	count int,
+	interval time.Duration,
+	sendTimes *streamSendTimes,
+) streamSendResult {
+	sent := 0
+	for i := 0; i < count; i++ {
+		if i > 0 && interval > 0 {
+			select {
+			case <-ctx.Done():
+				return streamSendResult{sent: sent}
+			case <-time.After(interval):
+			}
+		}
+		sendTimes.add(time.Now())
+		if err := stream.SendMsg(messages[i%len(messages)]); err != nil {
+			// io.EOF 表示服务端已结束流，真实状态由 RecvMsg 返回
+			if errors.Is(err, io.EOF) {
+				return streamSendResult{sent: sent}
+			}
+			return streamSendResult{sent: sent, err: err}
+		}
+		sent++
+	}
+	return streamSendResult{sent: sent, err: stream.CloseSend()}
+}
@@ -0,0 +1,264 @@
+/*
+ * @Author: kamalyes 501893067@qq.com
+ * @Date: 2026-02-12 00:00:00
+ * @LastEditors: kamalyes 501893067@qq.com
+ * @LastEditTime: 2026-02-12 00:00:00
+ * @FilePath: \go-stress\protocol\grpc_stream_test.go
+ * @Description: gRPC流式调用、状态码和 metadata 测试
+ *
+ * Copyright (c) 2026 by kamalyes, All Rights Reserved.
+ */
+package protocol
+
+import (
+	"context"
+	"errors"
+	"io"
+	"net"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/kamalyes/go-stress/config"
+	"github.com/kamalyes/go-stress/types"
+	"github.com/stretchr/testify/assert"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/metadata"
+	"google.golang.org/grpc/status"
+	"google.golang.org/protobuf/reflect/protoreflect"
+	"google.golang.org/protobuf/types/dynamicpb"
+)
+
+var counterProto = filepath.Join("testdata", "proto", "stream", "v1", "counter.proto")
+
+// startCounterServer 启动基于动态消息的流式测试服务
+func startCounterServer(t *testing.T) string {
+	source, err := NewGRPCDescriptorSource(&config.GRPCConfig{ProtoFile: counterProto})
+	assert.NoError(t, err)
+	desc, err := source.FindMethod("stream.v1.Counter", "Ticks")
+	assert.NoError(t, err)
+	numType := desc.inputType
+	valueField := numType.Fields().ByName("value")
+
+	newNum := func(v int64) *dynamicpb.Message {
+		msg := dynamicpb.NewMessage(numType)
+		msg.Set(valueField, protoreflect.ValueOfInt64(v))
+		return msg
+	}
+	recvNum := func(ss grpc.ServerStream) (int64, error) {
+		msg := dynamicpb.NewMessage(numType)
+		if err := ss.RecvMsg(msg); err != nil {
+			return 0, err
+		}
+		return msg.Get(valueField).Int(), nil
+	}
+
+	serviceDesc := &grpc.ServiceDesc{
+		ServiceName: "stream.v1.Counter",
+		HandlerType: (*any)(nil),
+		Methods: []grpc.MethodDesc{
+			{MethodName: "Echo", Handler: func(_ any, ctx context.Context, dec func(any) error, _ grpc.UnaryServerInterceptor) (any, error) {
+				msg := dynamicpb.NewMessage(numType)
+				if err := dec(msg); err != nil {
+					return nil, err
+				}
+				md, _ := metadata.FromIncomingContext(ctx)
+				user := strings.Join(md.Get("x-user"), ",")
+				_ = grpc.SetHeader(ctx, metadata.Pairs("x-user", user))
+				_ = grpc.SetTrailer(ctx, metadata.Pairs("x-trace", "t-"+user))
+				if msg.Get(valueField).Int() < 0 {
+					return nil, status.Error(codes.NotFound, "no such value")
+				}
+				return msg, nil
+			}},
+		},
+		Streams: []grpc.StreamDesc{
+			{StreamName: "Ticks", ServerStreams: true, Handler: func(_ any, ss grpc.ServerStream) error {
+				n, err := recvNum(ss)
+				if err != nil {
+					return err
+				}
+				for i := int64(1); n == 0 || i <= n; i++ {
+					if err := ss.SendMsg(newNum(i)); err != nil {
+						return err
+					}
+					time.Sleep(5 * time.Millisecond)
+				}
+				return nil
+			}},
+			{StreamName: "Sum", ClientStreams: true, Handler: func(_ any, ss grpc.ServerStream) error {
+				var sum int64
+				for {
+					n, err := recvNum(ss)
+					if errors.Is(err, io.EOF) {
+						return ss.SendMsg(newNum(sum))
+					}
+					if err != nil {
+						return err
+					}
+					sum += n
+				}
+			}},
+			{StreamName: "Double", ClientStreams: true, ServerStreams: true, Handler: func(_ any, ss grpc.ServerStream) error {
+				for {
+					n, err := recvNum(ss)
+					if errors.Is(err, io.EOF) {
+						return nil
+					}
+					if err != nil {
+						return err
+					}
+					if err := ss.SendMsg(newNum(n * 2)); err != nil {
+						return err
+					}
+				}
+			}},
+			{StreamName: "Fail", ServerStreams: true, Handler: func(_ any, ss grpc.ServerStream) error {
+				if _, err := recvNum(ss); err != nil {
+					return err
+				}
+				if err := ss.SendMsg(newNum(1)); err != nil {
+					return err
+				}
+				return status.Error(codes.Unavailable, "backend down")
+			}},
+		},
+	}
+
+	lis, err := net.Listen("tcp", "127.0.0.1:0")
+	assert.NoError(t, err)
+	srv := grpc.NewServer()
+	srv.RegisterService(serviceDesc, struct{}{})
+	go func() { _ = srv.Serve(lis) }()
+	t.Cleanup(srv.Stop)
+	return lis.Addr().String()
+}
+
+// callCounter 调用 Counter 服务的指定方法
+func callCounter(t *testing.T, addr, method, body string, stream *config.GRPCStreamConfig) (*types.Response, error) {
+	return callCounterWithRequest(t, addr, method, stream, &types.Request{Body: body})
+}
+
+// callCounterWithRequest 发送指定请求（可携带 metadata）调用 Counter 服务
+func callCounterWithRequest(t *testing.T, addr, method string, stream *config.GRPCStreamConfig, req *types.Request) (*types.Response, error) {
+	client, err := NewGRPCClient(&config.Config{URL: addr, Timeout: 20 * time.Millisecond, GRPC: &config.GRPCConfig{
+		ProtoFile: counterProto,
+		Service:   "stream.v1.Counter",
+		Method:    method,
+		Stream:    stream,
+		Metadata:  map[string]string{"x-user": "static", "x-tenant": "t1"},
+	}})
+	assert.NoError(t, err)
+	assert.NoError(t, client.Connect(context.Background()))
+	defer client.Close()
+	return client.Send(context.Background(), req)
+}
+
+// 测试服务端流：统计接收消息数和首条消息耗时，不受请求超时限制
+func TestGRPCServerStream(t *testing.T) {
+	addr := startCounterServer(t)
+
+	resp, err := callCounter(t, addr, "Ticks", `{"value":"10"}`, nil)
+	assert.NoError(t, err)
+	assert.JSONEq(t, `{"value":"10"}`, string(resp.Body), "响应体为最后一条消息")
+	assert.Equal(t, StreamKindServer, resp.Stream.Kind)
+	assert.Equal(t, 1, resp.Stream.Sent)
+	assert.Equal(t, 10, resp.Stream.Received)
+	assert.Len(t, resp.Stream.InterArrivals, 10)
+	assert.Len(t, resp.Stream.MessageLatencies, 1, "只有首条消息对应发送的请求")
+	assert.Equal(t, resp.Stream.FirstMessage, resp.Stream.InterArrivals[0])
+	assert.Greater(t, resp.Stream.FirstMessage, time.Duration(0))
+	assert.Equal(t, "OK", resp.Stream.Code)
+	assert.Greater(t, resp.Duration, 20*time.Millisecond)
+
+	// 无限流到达 lifetime 后主动结束，视为正常
+	resp, err = callCounter(t, addr, "Ticks", `{"value":"0"}`, &config.GRPCStreamConfig{Lifetime: 50 * time.Millisecond})
+	assert.NoError(t, err)
+	assert.Equal(t, "OK", resp.Stream.Code)
+	assert.Greater(t, resp.Stream.Received, 1)
+
+	// 未设置 lifetime 的无限流在请求截止时间（按时间运行的压测结束）主动结束，同样视为正常
+	start := time.Now()
+	resp, err = callCounterWithRequest(t, addr, "Ticks", nil, &types.Request{Body: `{"value":"0"}`, Deadline: start.Add(50 * time.Millisecond)})
+	assert.NoError(t, err)
+	assert.Equal(t, "OK", resp.Stream.Code)
+	assert.Greater(t, resp.Stream.Received, 1)
+	assert.Less(t, time.Since(start), time.Second)
+}
+
+// 测试客户端流：循环发送请求体中的消息
+func TestGRPCClientStream(t *testing.T) {
+	addr := startCounterServer(t)
+
+	resp, err := callCounter(t, addr, "Sum", `[{"value":"1"},{"value":"2"}]`, &config.GRPCStreamConfig{Messages: 5, Interval: time.Millisecond})
+	assert.NoError(t, err)
+	assert.JSONEq(t, `{"value":"7"}`, string(resp.Body)) // 1+2+1+2+1
+	assert.Equal(t, StreamKindClient, resp.Stream.Kind)
+	assert.Equal(t, 5, resp.Stream.Sent)
+	assert.Equal(t, 1, resp.Stream.Received)
+	assert.Len(t, resp.Stream.MessageLatencies, 1, "响应距最后一条发送")
+	assert.Less(t, resp.Stream.MessageLatencies[0], resp.Stream.FirstMessage)
+}
+
+// 测试双向流
+func TestGRPCBidiStream(t *testing.T) {
+	addr := startCounterServer(t)
+
+	resp, err := callCounter(t, addr, "Double", `[{"value":"1"},{"value":"2"},{"value":"3"}]`, nil)
+	assert.NoError(t, err)
+	assert.JSONEq(t, `{"value":"6"}`, string(resp.Body))
+	assert.Equal(t, StreamKindBidi, resp.Stream.Kind)
+	assert.Equal(t, 3, resp.Stream.Sent)
+	assert.Equal(t, 3, resp.Stream.Received)
+
+	// 按间隔发送时，单条消息延迟从各自的发送时间算起，不包含发送间隔
+	resp, err = callCounter(t, addr, "Double", `[{"value":"1"},{"value":"2"},{"value":"3"}]`, &config.GRPCStreamConfig{Interval: 30 * time.Millisecond})
+	assert.NoError(t, err)
+	assert.Len(t, resp.Stream.MessageLatencies, 3)
+	assert.Len(t, resp.Stream.InterArrivals, 3)
+	for i := 1; i < 3; i++ {
+		// 接收间隔 = 发送间隔(>=30ms) + 本条延迟 - 上一条延迟
+		assert.GreaterOrEqual(t, resp.Stream.InterArrivals[i], 30*time.Millisecond-resp.Stream.MessageLatencies[i-1])
+		assert.Less(t, resp.Stream.MessageLatencies[i], 30*time.Millisecond)
+	}
+}
+
+// 测试流错误状态码
+func TestGRPCStreamError(t *testing.T) {
+	addr := startCounterServer(t)
+
+	resp, err := callCounter(t, addr, "Fail", `{}`, nil)
+	assert.ErrorContains(t, err, "backend down")
+	assert.Equal(t, "UNAVAILABLE", resp.Stream.Code)
+	assert.Equal(t, int(codes.Unavailable), resp.StatusCode)
+	assert.Equal(t, 1, resp.Stream.Received)
+}
+
+// 测试一元调用的状态码、请求 metadata 和响应 metadata/trailer
+func TestGRPCUnaryStatusAndMetadata(t *testing.T) {
+	addr := startCounterServer(t)
+
+	// 请求携带的 metadata（已合并公共 metadata）替代客户端配置的 metadata，请求头不作为 metadata 发送
+	resp, err := callCounterWithRequest(t, addr, "Echo", nil, &types.Request{
+		Body:     `{"value":"3"}`,
+		Headers:  map[string]string{"Content-Type": "application/json"},
+		Metadata: map[string]any{GRPCMetadataKey: map[string]string{"x-user": "alice", "x-tenant": "t1"}},
+	})
+	assert.NoError(t, err)
+	assert.Equal(t, ProtocolGRPC, resp.Protocol)
+	assert.Equal(t, int(codes.OK), resp.StatusCode)
+	assert.True(t, resp.IsSuccessStatus())
+	assert.Equal(t, "alice", resp.Headers["x-user"])
+	assert.Equal(t, "t-alice", resp.Headers["x-trace"])
+	assert.Equal(t, map[string]string{"x-user": "alice", "x-tenant": "t1"}, resp.RequestHeaders)
+	assert.Equal(t, "/stream.v1.Counter/Echo", resp.RequestMethod)
+
+	resp, err = callCounter(t, addr, "Echo", `{"value":"-1"}`, nil)
+	assert.Error(t, err)
+	assert.Equal(t, int(codes.NotFound), resp.StatusCode)
+	assert.False(t, resp.IsSuccessStatus())
+	assert.Equal(t, "static", resp.Headers["x-user"], "失败的调用同样返回 metadata")
+	assert.Equal(t, "NOT_FOUND", GRPCCodeName(resp.StatusCode))
+}
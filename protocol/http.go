@@ -13,6 +13,8 @@
 import (
 	"context"
 	"fmt"
+	"net/http"
+	"net/http/httptrace"
 	"net/url"
 	"time"
 
@@ -23,8 +25,9 @@
 
 // HTTPClient HTTP协议客户端
 type HTTPClient struct {
-	config *config.Config
-	client *httpx.Client
+	config    *config.Config
+	client    *httpx.Client
+	stdClient *http.Client // 底层标准库客户端（持有连接池）
 }
 
 // NewHTTPClient 创建HTTP客户端
@@ -36,19 +39,17 @@ func NewHTTPClient(cfg *config.Config) (*HTTPClient, error) {
 		}
 	}
 
-	// 使用 go-toolbox 的 httpx 客户端
-	var client *httpx.Client
-	if cfg.HTTP.KeepAlive {
-		// 自定义配置的HTTP客户端，支持长连接
-		client = httpx.NewCustomDefaultClient()
-	} else {
-		// 默认客户端
-		client = httpx.NewDefaultHttpClient()
+	// 基于可配置的传输层创建 go-toolbox 的 httpx 客户端
+	stdClient, err := newStdHTTPClient(cfg.HTTP)
+	if err != nil {
+		return nil, fmt.Errorf("创建HTTP客户端失败: %w", err)
 	}
+	client := httpx.NewHttpClient(stdClient)
 
 	return &HTTPClient{
-		config: cfg,
-		client: client,
+		config:    cfg,
+		client:    client,
+		stdClient: stdClient,
 	}, nil
 }
 
@@ -71,6 +72,10 @@ func (h *HTTPClient) Send(ctx context.Context, req *types.Request) (*types.Respo
 		defer cancel()
 	}
 
+	// 注入阶段耗时追踪
+	tracer := newPhaseTracer()
+	httpReq = httpReq.WithContext(httptrace.WithClientTrace(ctx, tracer.clientTrace()))
+
 	// 设置Headers
 	for k, v := range req.Headers {
 		httpReq.SetHeader(k, v)
@@ -83,7 +88,6 @@ func (h *HTTPClient) Send(ctx context.Context, req *types.Request) (*types.Respo
 
 	// 执行请求
 	httpResp, err := httpReq.Send()
-	duration := time.Since(startTime)
 
 	// 解析Query参数
 	var queryString string
@@ -93,7 +97,8 @@ func (h *HTTPClient) Send(ctx context.Context, req *types.Request) (*types.Respo
 
 	if err != nil {
 		return &types.Response{
-			Duration:       duration,
+			Duration:       time.Since(startTime),
+			Phases:         tracer.timings(time.Now()),
 			Error:          fmt.Errorf("HTTP请求失败: %w", err),
 			RequestURL:     req.URL,
 			RequestMethod:  req.Method,
@@ -104,11 +109,16 @@ func (h *HTTPClient) Send(ctx context.Context, req *types.Request) (*types.Respo
 	}
 
 	// 读取响应体 - httpx.Response.Body() 返回 ([]byte, error)
+	// 耗时包含响应体传输，与阶段耗时之和保持一致
 	body, err := httpResp.Body()
+	doneTime := time.Now()
+	duration := doneTime.Sub(startTime)
+	phases := tracer.timings(doneTime)
 	if err != nil {
 		return &types.Response{
 			StatusCode:     httpResp.StatusCode,
 			Duration:       duration,
+			Phases:         phases,
 			Error:          fmt.Errorf("读取响应失败: %w", err),
 			RequestURL:     req.URL,
 			RequestMethod:  req.Method,
@@ -131,6 +141,7 @@ func (h *HTTPClient) Send(ctx context.Context, req *types.Request) (*types.Respo
 		Headers:        headers,
 		Body:           body,
 		Duration:       duration,
+		Phases:         phases,
 		RequestURL:     req.URL,
 		RequestMethod:  req.Method,
 		RequestHeaders: req.Headers,
@@ -141,9 +152,9 @@ func (h *HTTPClient) Send(ctx context.Context, req *types.Request) (*types.Respo
 	return response, nil
 }
 
-// Close 关闭HTTP客户端
+// Close 关闭HTTP客户端（释放空闲连接）
 func (h *HTTPClient) Close() error {
-	// HTTP客户端无需显式关闭
+	h.stdClient.CloseIdleConnections()
 	return nil
 }
 
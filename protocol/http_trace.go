@@ -0,0 +1,91 @@
+/*
+ * @Author: kamalyes 501893067@qq.com
+ * @Date: 2026-02-10 00:00:00
+ * @LastEditors: kamalyes 501893067@qq.com
+ * @LastEditTime: 2026-02-10 00:00:00
+ * @FilePath: \go-stress\protocol\http_trace.go
+ * @Description: HTTP 请求阶段耗时采集（DNS、连接、TLS、首字节、传输）
+ *
+ * Copyright (c) 2026 by kamalyes, All Rights Reserved.
+ */
+package protocol
+
+import (
+	"crypto/tls"
+	"net/http/httptrace"
+	"sync"
+	"time"
+
+	"github.com/kamalyes/go-stress/types"
+)
+
+// phaseTracer 记录单次请求各阶段的时间点
+// 双栈拨号时连接回调可能并发触发，因此需要加锁
+type phaseTracer struct {
+	mu           sync.Mutex
+	dnsStart     time.Time
+	dnsDone      time.Time
+	connectStart time.Time
+	connectDone  time.Time
+	tlsStart     time.Time
+	tlsDone      time.Time
+	wroteRequest time.Time
+	firstByte    time.Time
+	reused       bool
+}
+
+// newPhaseTracer 创建阶段耗时采集器
+func newPhaseTracer() *phaseTracer {
+	return &phaseTracer{}
+}
+
+// clientTrace 返回注入请求 context 的追踪回调
+func (t *phaseTracer) clientTrace() *httptrace.ClientTrace {
+	mark := func(field *time.Time, keepFirst bool) {
+		t.mu.Lock()
+		defer t.mu.Unlock()
+		if keepFirst && !field.IsZero() {
+			return
+		}
+		*field = time.Now()
+	}
+
+	return &httptrace.ClientTrace{
+		DNSStart:          func(httptrace.DNSStartInfo) { mark(&t.dnsStart, true) },
+		DNSDone:           func(httptrace.DNSDoneInfo) { mark(&t.dnsDone, false) },
+		ConnectStart:      func(string, string) { mark(&t.connectStart, true) },
+		ConnectDone:       func(string, string, error) { mark(&t.connectDone, false) },
+		TLSHandshakeStart: func() { mark(&t.tlsStart, true) },
+		TLSHandshakeDone:  func(tls.ConnectionState, error) { mark(&t.tlsDone, false) },
+		GotConn: func(info httptrace.GotConnInfo) {
+			t.mu.Lock()
+			t.reused = info.Reused
+			t.mu.Unlock()
+		},
+		WroteRequest:         func(httptrace.WroteRequestInfo) { mark(&t.wroteRequest, false) },
+		GotFirstResponseByte: func() { mark(&t.firstByte, true) },
+	}
+}
+
+// timings 计算各阶段耗时（done 为读取完响应体的时间，未到达的阶段记为 0）
+func (t *phaseTracer) timings(done time.Time) *types.PhaseTimings {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+
+	return &types.PhaseTimings{
+		DNS:        phaseDuration(t.dnsStart, t.dnsDone),
+		Connect:    phaseDuration(t.connectStart, t.connectDone),
+		TLS:        phaseDuration(t.tlsStart, t.tlsDone),
+		TTFB:       phaseDuration(t.wroteRequest, t.firstByte),
+		Transfer:   phaseDuration(t.firstByte, done),
+		ConnReused: t.reused,
+	}
+}
+
+// phaseDuration 计算两个时间点的间隔（任一时间点缺失时返回 0）
+func phaseDuration(start, end time.Time) time.Duration {
+	if start.IsZero() || end.IsZero() || end.Before(start) {
+		return 0
+	}
+	return end.Sub(start)
+}
@@ -0,0 +1,115 @@
+/*
+ * @Author: kamalyes 501893067@qq.com
+ * @Date: 2026-02-10 00:00:00
+ * @LastEditors: kamalyes 501893067@qq.com
+ * @LastEditTime: 2026-02-10 00:00:00
+ * @FilePath: \go-stress\protocol\http_transport.go
+ * @Description: HTTP 传输层构建 - TLS/mTLS、HTTP/2(h2c)、连接池、代理、源IP绑定、重定向
+ *
+ * Copyright (c) 2026 by kamalyes, All Rights Reserved.
+ */
+package protocol
+
+import (
+	"fmt"
+	"net"
+	"net/http"
+	"net/url"
+	"time"
+
+	"github.com/kamalyes/go-stress/config"
+	"github.com/kamalyes/go-toolbox/pkg/mathx"
+)
+
+const (
+	defaultDialTimeout         = 30 * time.Second
+	defaultDialKeepAlive       = 60 * time.Second
+	defaultIdleConnTimeout     = 90 * time.Second
+	defaultTLSHandshakeTimeout = 10 * time.Second
+	defaultMaxRedirects        = 10
+	keepAliveIdleConnsPerHost  = 1000 // keepalive 模式下每个host的空闲连接数
+)
+
+// newHTTPTransport 根据配置构建传输层
+func newHTTPTransport(cfg *config.HTTPConfig) (*http.Transport, error) {
+	dialer := &net.Dialer{
+		Timeout:   mathx.IfNotZero(cfg.DialTimeout, defaultDialTimeout),
+		KeepAlive: defaultDialKeepAlive,
+	}
+	if cfg.LocalAddr != "" {
+		ip := net.ParseIP(cfg.LocalAddr)
+		if ip == nil {
+			return nil, fmt.Errorf("无效的本地源IP: %s", cfg.LocalAddr)
+		}
+		dialer.LocalAddr = &net.TCPAddr{IP: ip}
+	}
+
+	proxy := http.ProxyFromEnvironment
+	if cfg.Proxy != "" {
+		proxyURL, err := url.Parse(cfg.Proxy)
+		if err != nil || proxyURL.Host == "" {
+			return nil, fmt.Errorf("无效的代理地址: %s", cfg.Proxy)
+		}
+		proxy = http.ProxyURL(proxyURL)
+	}
+
+	tlsConfig, err := buildTLSConfig(cfg.TLS)
+	if err != nil {
+		return nil, err
+	}
+
+	transport := &http.Transport{
+		Proxy:               proxy,
+		DialContext:         dialer.DialContext,
+		TLSClientConfig:     tlsConfig,
+		TLSHandshakeTimeout: defaultTLSHandshakeTimeout,
+		MaxIdleConns:        cfg.MaxIdleConns,
+		MaxIdleConnsPerHost: cfg.MaxIdleConnsPerHost,
+		MaxConnsPerHost:     cfg.MaxConnsPerHost,
+		IdleConnTimeout:     mathx.IfNotZero(cfg.IdleConnTimeout, defaultIdleConnTimeout),
+		DisableKeepAlives:   cfg.DisableKeepAlives,
+		DisableCompression:  cfg.DisableCompression,
+		// 100-continue 超时
+		ExpectContinueTimeout: time.Second,
+	}
+	if cfg.KeepAlive && transport.MaxIdleConnsPerHost == 0 {
+		transport.MaxIdleConnsPerHost = keepAliveIdleConnsPerHost
+	}
+
+	// 协议：默认 https 通过 ALPN 协商 HTTP/2，不支持时回退 HTTP/1.1；
+	// 强制 HTTP/2 时不回退 HTTP/1.1，http:// 使用 h2c（prior knowledge）
+	protocols := new(http.Protocols)
+	if cfg.HTTP2 {
+		protocols.SetHTTP2(true)
+		protocols.SetUnencryptedHTTP2(true)
+	} else {
+		protocols.SetHTTP1(true)
+		protocols.SetHTTP2(true)
+	}
+	transport.Protocols = protocols
+
+	return transport, nil
+}
+
+// newStdHTTPClient 根据配置构建标准库客户端（超时由请求 context 控制）
+func newStdHTTPClient(cfg *config.HTTPConfig) (*http.Client, error) {
+	transport, err := newHTTPTransport(cfg)
+	if err != nil {
+		return nil, err
+	}
+
+	maxRedirects := mathx.IfNotZero(cfg.MaxRedirects, defaultMaxRedirects)
+	return &http.Client{
+		Transport: transport,
+		CheckRedirect: func(req *http.Request, via []*http.Request) error {
+			// 不跟随重定向时直接返回 3xx 响应
+			if !cfg.FollowRedirects {
+				return http.ErrUseLastResponse
+			}
+			if len(via) >= maxRedirects {
+				return fmt.Errorf("重定向次数超过 %d 次", maxRedirects)
+			}
+			return nil
+		},
+	}, nil
+}
@@ -0,0 +1,173 @@
+/*
+ * @Author: kamalyes 501893067@qq.com
+ * @Date: 2026-02-10 00:00:00
+ * @LastEditors: kamalyes 501893067@qq.com
+ * @LastEditTime: 2026-02-10 00:00:00
+ * @FilePath: \go-stress\protocol\http_transport_test.go
+ * @Description: HTTP 传输层测试
+ *
+ * Copyright (c) 2026 by kamalyes, All Rights Reserved.
+ */
+package protocol
+
+import (
+	"context"
+	"crypto/ecdsa"
+	"crypto/elliptic"
+	"crypto/rand"
+	"crypto/tls"
+	"crypto/x509"
+	"crypto/x509/pkix"
+	"encoding/pem"
+	"math/big"
+	"net"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/kamalyes/go-stress/config"
+	"github.com/kamalyes/go-stress/types"
+	"github.com/stretchr/testify/assert"
+)
+
+// writeClientCert 生成自签名客户端证书并写入临时目录
+func writeClientCert(t *testing.T) (certFile, keyFile string, cert *x509.Certificate) {
+	key, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	assert.NoError(t, err)
+	tmpl := &x509.Certificate{
+		SerialNumber: big.NewInt(1),
+		Subject:      pkix.Name{CommonName: "go-stress-client"},
+		NotBefore:    time.Now().Add(-time.Hour),
+		NotAfter:     time.Now().Add(time.Hour),
+		KeyUsage:     x509.KeyUsageDigitalSignature,
+		ExtKeyUsage:  []x509.ExtKeyUsage{x509.ExtKeyUsageClientAuth},
+	}
+	der, err := x509.CreateCertificate(rand.Reader, tmpl, tmpl, &key.PublicKey, key)
+	assert.NoError(t, err)
+	cert, err = x509.ParseCertificate(der)
+	assert.NoError(t, err)
+	keyDER, err := x509.MarshalECPrivateKey(key)
+	assert.NoError(t, err)
+
+	dir := t.TempDir()
+	certFile = filepath.Join(dir, "client.pem")
+	keyFile = filepath.Join(dir, "client-key.pem")
+	assert.NoError(t, os.WriteFile(certFile, pem.EncodeToMemory(&pem.Block{Type: "CERTIFICATE", Bytes: der}), 0o600))
+	assert.NoError(t, os.WriteFile(keyFile, pem.EncodeToMemory(&pem.Block{Type: "EC PRIVATE KEY", Bytes: keyDER}), 0o600))
+	return certFile, keyFile, cert
+}
+
+// sendGet 使用指定配置发送 GET 请求
+func sendGet(t *testing.T, httpCfg *config.HTTPConfig, url string) (*types.Response, error) {
+	client, err := NewHTTPClient(&config.Config{HTTP: httpCfg})
+	assert.NoError(t, err)
+	defer client.Close()
+	return client.Send(context.Background(), &types.Request{Method: "GET", URL: url})
+}
+
+// 测试自定义CA和客户端证书（mTLS）
+func TestHTTPTransportMTLS(t *testing.T) {
+	certFile, keyFile, clientCert := writeClientCert(t)
+
+	srv := httptest.NewUnstartedServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		_, _ = w.Write([]byte(r.TLS.PeerCertificates[0].Subject.CommonName))
+	}))
+	clientCAs := x509.NewCertPool()
+	clientCAs.AddCert(clientCert)
+	srv.TLS = &tls.Config{ClientAuth: tls.RequireAndVerifyClientCert, ClientCAs: clientCAs}
+	srv.StartTLS()
+	defer srv.Close()
+
+	caFile := filepath.Join(t.TempDir(), "ca.pem")
+	assert.NoError(t, os.WriteFile(caFile, pem.EncodeToMemory(&pem.Block{Type: "CERTIFICATE", Bytes: srv.Certificate().Raw}), 0o600))
+
+	// 未配置客户端证书时握手失败
+	_, err := sendGet(t, &config.HTTPConfig{TLS: &config.TLSConfig{CAFile: caFile}}, srv.URL)
+	assert.Error(t, err)
+
+	resp, err := sendGet(t, &config.HTTPConfig{TLS: &config.TLSConfig{
+		CAFile:     caFile,
+		CertFile:   certFile,
+		KeyFile:    keyFile,
+		ServerName: "example.com", // httptest 证书包含 example.com
+	}}, srv.URL)
+	assert.NoError(t, err)
+	assert.Equal(t, "go-stress-client", string(resp.Body))
+	assert.Greater(t, resp.Phases.TLS, time.Duration(0))
+}
+
+// 测试强制 HTTP/2（h2c）
+func TestHTTPTransportH2C(t *testing.T) {
+	srv := httptest.NewUnstartedServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		_, _ = w.Write([]byte(r.Proto))
+	}))
+	srv.Config.Protocols = new(http.Protocols)
+	srv.Config.Protocols.SetHTTP1(true)
+	srv.Config.Protocols.SetUnencryptedHTTP2(true)
+	srv.Start()
+	defer srv.Close()
+
+	resp, err := sendGet(t, &config.HTTPConfig{}, srv.URL)
+	assert.NoError(t, err)
+	assert.Equal(t, "HTTP/1.1", string(resp.Body))
+
+	resp, err = sendGet(t, &config.HTTPConfig{HTTP2: true}, srv.URL)
+	assert.NoError(t, err)
+	assert.Equal(t, "HTTP/2.0", string(resp.Body))
+}
+
+// 测试默认通过 ALPN 协商 HTTP/2，服务端不支持时回退 HTTP/1.1
+func TestHTTPTransportALPN(t *testing.T) {
+	handler := http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		_, _ = w.Write([]byte(r.Proto))
+	})
+	insecure := &config.HTTPConfig{TLS: &config.TLSConfig{InsecureSkipVerify: true}}
+
+	h2 := httptest.NewUnstartedServer(handler)
+	h2.EnableHTTP2 = true
+	h2.StartTLS()
+	defer h2.Close()
+
+	resp, err := sendGet(t, insecure, h2.URL)
+	assert.NoError(t, err)
+	assert.Equal(t, "HTTP/2.0", string(resp.Body))
+
+	h1 := httptest.NewTLSServer(handler)
+	defer h1.Close()
+
+	resp, err = sendGet(t, insecure, h1.URL)
+	assert.NoError(t, err)
+	assert.Equal(t, "HTTP/1.1", string(resp.Body))
+}
+
+// 测试重定向控制和源IP绑定
+func TestHTTPTransportRedirectAndLocalAddr(t *testing.T) {
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path == "/loop" {
+			http.Redirect(w, r, "/loop", http.StatusFound)
+			return
+		}
+		host, _, _ := net.SplitHostPort(r.RemoteAddr)
+		_, _ = w.Write([]byte(host))
+	}))
+	defer srv.Close()
+
+	resp, err := sendGet(t, &config.HTTPConfig{FollowRedirects: false}, srv.URL+"/loop")
+	assert.NoError(t, err)
+	assert.Equal(t, http.StatusFound, resp.StatusCode)
+
+	_, err = sendGet(t, &config.HTTPConfig{FollowRedirects: true, MaxRedirects: 3}, srv.URL+"/loop")
+	assert.ErrorContains(t, err, "重定向次数超过 3 次")
+
+	resp, err = sendGet(t, &config.HTTPConfig{LocalAddr: "127.0.0.1"}, srv.URL)
+	assert.NoError(t, err)
+	assert.Equal(t, "127.0.0.1", string(resp.Body))
+
+	_, err = NewHTTPClient(&config.Config{HTTP: &config.HTTPConfig{LocalAddr: "not-an-ip"}})
+	assert.Error(t, err)
+	_, err = NewHTTPClient(&config.Config{HTTP: &config.HTTPConfig{Proxy: "::bad"}})
+	assert.Error(t, err)
+}
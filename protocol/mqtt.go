@@ -0,0 +1,362 @@
+/*
+ * @Author: kamalyes 501893067@qq.com
+ * @Date: 2026-02-18 00:00:00
+ * @LastEditors: kamalyes 501893067@qq.com
+ * @LastEditTime: 2026-02-18 00:00:00
+ * @FilePath: \go-stress\protocol\mqtt.go
+ * @Description: MQTT 协议客户端 - 连接认证、QoS 0/1/2 发布、订阅和端到端延迟
+ *
+ * Copyright (c) 2026 by kamalyes, All Rights Reserved.
+ */
+package protocol
+
+import (
+	"bytes"
+	"context"
+	"crypto/tls"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"math/rand/v2"
+	"net/url"
+	"strings"
+	"sync"
+	"time"
+
+	paho "github.com/eclipse/paho.mqtt.golang"
+	"github.com/eclipse/paho.mqtt.golang/packets"
+	"github.com/kamalyes/go-stress/config"
+	"github.com/kamalyes/go-toolbox/pkg/mathx"
+)
+
+const (
+	mqttDefaultClientIDPrefix = "go-stress"      // 默认客户端ID前缀
+	mqttDefaultKeepAlive      = 30 * time.Second // 默认心跳间隔
+	mqttDisconnectQuiesce     = 250              // 断开时等待未完成操作的毫秒数
+	mqttMethodPublish         = "PUBLISH"
+	mqttMethodSubscribe       = "SUBSCRIBE"
+)
+
+// MQTT 建立连接失败的结果（CONNACK 拒绝时为 mqttConnackCodes 中的原因，成功为 MQTTConnectAccepted）
+const (
+	MQTTConnectTimeout = "timeout" // 等待 CONNACK 超时
+	MQTTConnectError   = "error"   // 网络等其他错误
+)
+
+// mqttConnackCodes CONNACK 拒绝码对应的连接结果
+var mqttConnackCodes = map[byte]string{
+	packets.ErrRefusedBadProtocolVersion:    "bad_protocol_version",
+	packets.ErrRefusedIDRejected:            "id_rejected",
+	packets.ErrRefusedServerUnavailable:     "server_unavailable",
+	packets.ErrRefusedBadUsernameOrPassword: "bad_credentials",
+	packets.ErrRefusedNotAuthorised:         "not_authorized",
+}
+
+var errMQTTTimeout = errors.New("等待超时")
+
+// MQTTClient MQTT 客户端
+// 首次请求时建立连接（建连耗时和结果计入统计），连接断开后下次请求重新建立；
+// 配置了订阅主题时，每次请求带回上次请求以来收到的消息数和端到端延迟
+type MQTTClient struct {
+	config *config.Config
+	mqtt   *config.MQTTConfig
+	tlsCfg *tls.Config // TLS 地址使用的配置（未配置时为系统默认）
+	client paho.Client
+	inbox  *mqttInbox
+	mu     sync.Mutex // 保护连接
+}
+
+// mqttInbox 订阅收到的消息（两次请求之间累积）
+type mqttInbox struct {
+	mu        sync.Mutex
+	received  int
+	retained  int
+	latencies []time.Duration
+	last      []byte
+	notify    chan struct{}
+}
+
+// NewMQTTClient 创建MQTT客户端
+func NewMQTTClient(cfg *config.Config) (*MQTTClient, error) {
+	mqttConfig := cfg.MQTT
+	if mqttConfig == nil {
+		mqttConfig = &config.MQTTConfig{}
+	}
+	tlsConfig, err := buildTLSConfig(mqttConfig.TLS)
+	if err != nil {
+		return nil, fmt.Errorf("MQTT TLS配置错误: %w", err)
+	}
+	return &MQTTClient{
+		config: cfg,
+		mqtt:   mqttConfig,
+		tlsCfg: tlsConfig,
+		inbox:  &mqttInbox{notify: make(chan struct{}, 1)},
+	}, nil
+}
+
+// Connect MQTT在首次请求时建立连接
+func (c *MQTTClient) Connect(ctx context.Context) error {
+	return nil
+}
+
+// isSubscribe 是否为订阅模式
+func (c *MQTTClient) isSubscribe() bool {
+	return c.mqtt.Mode == config.MQTTModeSubscribe
+}
+
+// Send 发布一条消息（订阅模式下等待收到消息），响应中带回本次建连和订阅收到消息的统计
+func (c *MQTTClient) Send(ctx context.Context, req *Request) (*Response, error) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	startTime := time.Now()
+	stats := &MQTTStats{}
+	resp := &Response{
+		RequestURL:     req.URL,
+		RequestMethod:  mathx.IF(c.isSubscribe(), mqttMethodSubscribe, mqttMethodPublish),
+		RequestHeaders: req.Headers,
+		RequestBody:    req.Body,
+		Protocol:       ProtocolMQTT,
+		MQTT:           stats,
+	}
+	fail := func(err error) (*Response, error) {
+		c.inbox.drain(stats)
+		resp.Duration = time.Since(startTime)
+		resp.Error = fmt.Errorf("MQTT请求失败: %w", err)
+		return resp, resp.Error
+	}
+
+	u, err := url.Parse(mathx.IfEmpty(req.URL, c.config.URL))
+	if err != nil {
+		return fail(fmt.Errorf("无效的MQTT地址: %w", err))
+	}
+	if err := c.ensureConnected(ctx, u, stats); err != nil {
+		return fail(err)
+	}
+
+	if c.isSubscribe() {
+		if err := c.inbox.wait(ctx, c.config.Timeout); err != nil {
+			return fail(fmt.Errorf("等待消息失败: %w", err))
+		}
+	} else {
+		topic := strings.TrimPrefix(u.Path, "/")
+		if topic == "" {
+			return fail(fmt.Errorf("发布主题不能为空（url 路径即主题）"))
+		}
+		token := c.client.Publish(topic, c.mqtt.QoS, c.mqtt.Retain, c.embedTimestamp([]byte(req.Body)))
+		if err := waitToken(ctx, token, c.config.Timeout); err != nil {
+			return fail(fmt.Errorf("发布失败: %w", err))
+		}
+		stats.Published = 1
+	}
+
+	last := c.inbox.drain(stats)
+	if c.isSubscribe() {
+		resp.Body = last
+	}
+	resp.StatusCode = 200 // 成功时状态码固定为 200
+	resp.Duration = time.Since(startTime)
+	return resp, nil
+}
+
+// ensureConnected 连接未建立或已断开时建立连接并订阅，建连耗时和结果记入统计（调用方持有锁）
+func (c *MQTTClient) ensureConnected(ctx context.Context, u *url.URL, stats *MQTTStats) error {
+	if c.client != nil {
+		if c.client.IsConnectionOpen() {
+			return nil
+		}
+		c.client.Disconnect(0)
+		c.client = nil
+	}
+
+	opts := paho.NewClientOptions().
+		AddBroker(u.Scheme + "://" + u.Host).
+		SetClientID(fmt.Sprintf("%s-%016x", mathx.IfEmpty(c.mqtt.ClientIDPrefix, mqttDefaultClientIDPrefix), rand.Uint64())).
+		SetUsername(c.mqtt.Username).
+		SetPassword(c.mqtt.Password).
+		SetCleanSession(!c.mqtt.PersistSession).
+		SetKeepAlive(mathx.IfNotZero(c.mqtt.KeepAlive, mqttDefaultKeepAlive)).
+		SetConnectTimeout(c.config.Timeout).
+		SetAutoReconnect(false).
+		SetOrderMatters(false)
+	if c.tlsCfg != nil {
+		opts.SetTLSConfig(c.tlsCfg)
+	}
+
+	connectStart := time.Now()
+	client := paho.NewClient(opts)
+	token := client.Connect()
+	err := waitToken(ctx, token, c.config.Timeout)
+	stats.Connect = time.Since(connectStart)
+	stats.ConnectCode = mqttConnectCode(token, err)
+	if err != nil {
+		client.Disconnect(0)
+		return fmt.Errorf("连接失败(%s): %w", stats.ConnectCode, err)
+	}
+
+	if len(c.mqtt.Subscribe) > 0 {
+		filters := make(map[string]byte, len(c.mqtt.Subscribe))
+		for _, topic := range c.mqtt.Subscribe {
+			filters[topic] = c.mqtt.SubscribeQoS
+		}
+		if err := waitToken(ctx, client.SubscribeMultiple(filters, c.onMessage), c.config.Timeout); err != nil {
+			client.Disconnect(0)
+			return fmt.Errorf("订阅失败: %w", err)
+		}
+	}
+	c.client = client
+	return nil
+}
+
+// mqttConnectCode 建立连接的结果
+func mqttConnectCode(token paho.Token, err error) string {
+	switch {
+	case err == nil:
+		return MQTTConnectAccepted
+	case errors.Is(err, errMQTTTimeout):
+		return MQTTConnectTimeout
+	}
+	if connectToken, ok := token.(*paho.ConnectToken); ok {
+		if code, ok := mqttConnackCodes[connectToken.ReturnCode()]; ok {
+			return code
+		}
+	}
+	return MQTTConnectError
+}
+
+// waitToken 等待操作完成（受 timeout 和 ctx 限制）
+func waitToken(ctx context.Context, token paho.Token, timeout time.Duration) error {
+	var timer <-chan time.Time
+	if timeout > 0 {
+		t := time.NewTimer(timeout)
+		defer t.Stop()
+		timer = t.C
+	}
+	select {
+	case <-token.Done():
+		return token.Error()
+	case <-ctx.Done():
+		return ctx.Err()
+	case <-timer:
+		return errMQTTTimeout
+	}
+}
+
+// embedTimestamp 向 JSON 对象消息写入发布时间戳（未配置字段或非 JSON 对象时原样发送）
+func (c *MQTTClient) embedTimestamp(payload []byte) []byte {
+	if c.mqtt.TimestampField == "" {
+		return payload
+	}
+	fields, ok := decodeJSONObject(payload)
+	if !ok {
+		return payload
+	}
+	fields[c.mqtt.TimestampField] = time.Now().UnixNano()
+	data, err := json.Marshal(fields)
+	if err != nil {
+		return payload
+	}
+	return data
+}
+
+// onMessage 记录订阅收到的消息，按嵌入的发布时间戳计算端到端延迟（保留消息是历史消息，不计延迟）
+func (c *MQTTClient) onMessage(_ paho.Client, msg paho.Message) {
+	receivedAt := time.Now()
+	var latency time.Duration
+	if c.mqtt.TimestampField != "" && !msg.Retained() {
+		if fields, ok := decodeJSONObject(msg.Payload()); ok {
+			if ts, ok := fields[c.mqtt.TimestampField].(json.Number); ok {
+				if nanos, err := ts.Int64(); err == nil {
+					latency = receivedAt.Sub(time.Unix(0, nanos))
+				}
+			}
+		}
+	}
+	c.inbox.add(msg.Payload(), msg.Retained(), latency)
+}
+
+// decodeJSONObject 解析 JSON 对象（数字保留为 json.Number）
+func decodeJSONObject(data []byte) (map[string]any, bool) {
+	decoder := json.NewDecoder(bytes.NewReader(data))
+	decoder.UseNumber()
+	var fields map[string]any
+	if err := decoder.Decode(&fields); err != nil || fields == nil {
+		return nil, false
+	}
+	return fields, true
+}
+
+// add 记录一条消息并唤醒等待的请求
+func (b *mqttInbox) add(payload []byte, retained bool, latency time.Duration) {
+	b.mu.Lock()
+	b.received++
+	if retained {
+		b.retained++
+	}
+	if latency > 0 {
+		b.latencies = append(b.latencies, latency)
+	}
+	b.last = payload
+	b.mu.Unlock()
+
+	select {
+	case b.notify <- struct{}{}:
+	default:
+	}
+}
+
+// wait 等待至少收到一条消息
+func (b *mqttInbox) wait(ctx context.Context, timeout time.Duration) error {
+	var timer <-chan time.Time
+	if timeout > 0 {
+		t := time.NewTimer(timeout)
+		defer t.Stop()
+		timer = t.C
+	}
+	for {
+		b.mu.Lock()
+		pending := b.received > 0
+		b.mu.Unlock()
+		if pending {
+			return nil
+		}
+		// 通知可能由已被取出的消息触发，唤醒后重新检查
+		select {
+		case <-b.notify:
+		case <-ctx.Done():
+			return ctx.Err()
+		case <-timer:
+			return errMQTTTimeout
+		}
+	}
+}
+
+// drain 取出累积的消息统计写入 stats，返回最后一条消息
+func (b *mqttInbox) drain(stats *MQTTStats) []byte {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	stats.Received = b.received
+	stats.Retained = b.retained
+	stats.Latencies = b.latencies
+	last := b.last
+	b.received, b.retained, b.latencies, b.last = 0, 0, nil, nil
+	return last
+}
+
+// Type 返回协议类型
+func (c *MQTTClient) Type() ProtocolType {
+	return ProtocolMQTT
+}
+
+// Close 断开连接
+func (c *MQTTClient) Close() error {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	if c.client != nil {
+		c.client.Disconnect(mqttDisconnectQuiesce)
+		c.client = nil
+	}
+	return nil
+}
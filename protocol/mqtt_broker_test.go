@@ -0,0 +1,246 @@
+/*
+ * @Author: kamalyes 501893067@qq.com
+ * @Date: 2026-02-18 00:00:00
+ * @LastEditors: kamalyes 501893067@qq.com
+ * @LastEditTime: 2026-02-18 00:00:00
+ * @FilePath: \go-stress\protocol\mqtt_broker_test.go
+ * @Description: 测试用进程内 MQTT 3.1.1 代理（连接认证、QoS 0/1/2、订阅通配符、保留消息）
+ *
+ * Copyright (c) 2026 by kamalyes, All Rights Reserved.
+ */
+package protocol
+
+import (
+	"crypto/ecdsa"
+	"crypto/elliptic"
+	"crypto/rand"
+	"crypto/tls"
+	"crypto/x509"
+	"crypto/x509/pkix"
+	"encoding/pem"
+	"math/big"
+	"net"
+	"os"
+	"path/filepath"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/eclipse/paho.mqtt.golang/packets"
+	"github.com/stretchr/testify/assert"
+)
+
+// testBroker 进程内 MQTT 代理，订阅者统一以 QoS 0 投递
+type testBroker struct {
+	scheme   string
+	addr     string
+	username string
+	password string
+
+	mu       sync.Mutex
+	subs     map[*brokerConn][]string // 连接 -> 订阅的主题过滤器
+	retained map[string][]byte
+	connects int
+}
+
+// brokerConn 代理的一个客户端连接
+type brokerConn struct {
+	conn net.Conn
+	mu   sync.Mutex // 保护并发写
+}
+
+// write 写入一个报文
+func (c *brokerConn) write(p packets.ControlPacket) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	_ = p.Write(c.conn)
+}
+
+// startTestBroker 启动代理，username 非空时校验用户名和密码
+func startTestBroker(t *testing.T, username, password string) *testBroker {
+	ln, err := net.Listen("tcp", "127.0.0.1:0")
+	assert.NoError(t, err)
+	return serveTestBroker(t, ln, "mqtt", username, password)
+}
+
+// startTLSTestBroker 启动 TLS 代理（自签名证书），返回代理和 CA 证书文件
+func startTLSTestBroker(t *testing.T) (*testBroker, string) {
+	key, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	assert.NoError(t, err)
+	tmpl := &x509.Certificate{
+		SerialNumber: big.NewInt(1),
+		Subject:      pkix.Name{CommonName: "go-stress-broker"},
+		NotBefore:    time.Now().Add(-time.Hour),
+		NotAfter:     time.Now().Add(time.Hour),
+		KeyUsage:     x509.KeyUsageDigitalSignature | x509.KeyUsageCertSign,
+		ExtKeyUsage:  []x509.ExtKeyUsage{x509.ExtKeyUsageServerAuth},
+		IPAddresses:  []net.IP{net.IPv4(127, 0, 0, 1)},
+		IsCA:         true,
+
+		BasicConstraintsValid: true,
+	}
+	der, err := x509.CreateCertificate(rand.Reader, tmpl, tmpl, &key.PublicKey, key)
+	assert.NoError(t, err)
+
+	caFile := filepath.Join(t.TempDir(), "ca.pem")
+	assert.NoError(t, os.WriteFile(caFile, pem.EncodeToMemory(&pem.Block{Type: "CERTIFICATE", Bytes: der}), 0o600))
+
+	ln, err := tls.Listen("tcp", "127.0.0.1:0", &tls.Config{
+		Certificates: []tls.Certificate{{Certificate: [][]byte{der}, PrivateKey: key}},
+	})
+	assert.NoError(t, err)
+	return serveTestBroker(t, ln, "mqtts", "", ""), caFile
+}
+
+// serveTestBroker 在监听器上运行代理
+func serveTestBroker(t *testing.T, ln net.Listener, scheme, username, password string) *testBroker {
+	b := &testBroker{
+		scheme:   scheme,
+		addr:     ln.Addr().String(),
+		username: username,
+		password: password,
+		subs:     make(map[*brokerConn][]string),
+		retained: make(map[string][]byte),
+	}
+	t.Cleanup(func() { ln.Close() })
+	go func() {
+		for {
+			conn, err := ln.Accept()
+			if err != nil {
+				return
+			}
+			go b.serve(&brokerConn{conn: conn})
+		}
+	}()
+	return b
+}
+
+// url 代理地址，topic 为发布主题
+func (b *testBroker) url(topic string) string {
+	return b.scheme + "://" + b.addr + "/" + topic
+}
+
+// serve 处理一个连接的报文直到断开
+func (b *testBroker) serve(c *brokerConn) {
+	defer func() {
+		b.mu.Lock()
+		delete(b.subs, c)
+		b.mu.Unlock()
+		c.conn.Close()
+	}()
+
+	for {
+		packet, err := packets.ReadPacket(c.conn)
+		if err != nil {
+			return
+		}
+		switch p := packet.(type) {
+		case *packets.ConnectPacket:
+			ack := packets.NewControlPacket(packets.Connack).(*packets.ConnackPacket)
+			if b.username != "" && (p.Username != b.username || string(p.Password) != b.password) {
+				ack.ReturnCode = packets.ErrRefusedBadUsernameOrPassword
+				c.write(ack)
+				return
+			}
+			b.mu.Lock()
+			b.connects++
+			b.mu.Unlock()
+			c.write(ack)
+		case *packets.SubscribePacket:
+			ack := packets.NewControlPacket(packets.Suback).(*packets.SubackPacket)
+			ack.MessageID = p.MessageID
+			ack.ReturnCodes = p.Qoss
+			b.mu.Lock()
+			b.subs[c] = append(b.subs[c], p.Topics...)
+			var retained []*packets.PublishPacket
+			for topic, payload := range b.retained {
+				if matchAny(p.Topics, topic) {
+					retained = append(retained, newTestPublish(topic, payload, true))
+				}
+			}
+			b.mu.Unlock()
+			c.write(ack)
+			for _, msg := range retained {
+				c.write(msg)
+			}
+		case *packets.PublishPacket:
+			b.publish(p)
+			switch p.Qos {
+			case 1:
+				ack := packets.NewControlPacket(packets.Puback).(*packets.PubackPacket)
+				ack.MessageID = p.MessageID
+				c.write(ack)
+			case 2:
+				rec := packets.NewControlPacket(packets.Pubrec).(*packets.PubrecPacket)
+				rec.MessageID = p.MessageID
+				c.write(rec)
+			}
+		case *packets.PubrelPacket:
+			comp := packets.NewControlPacket(packets.Pubcomp).(*packets.PubcompPacket)
+			comp.MessageID = p.MessageID
+			c.write(comp)
+		case *packets.PingreqPacket:
+			c.write(packets.NewControlPacket(packets.Pingresp))
+		case *packets.DisconnectPacket:
+			return
+		}
+	}
+}
+
+// publish 保存保留消息并投递给匹配的订阅者
+func (b *testBroker) publish(p *packets.PublishPacket) {
+	b.mu.Lock()
+	if p.Retain {
+		if len(p.Payload) == 0 {
+			delete(b.retained, p.TopicName)
+		} else {
+			b.retained[p.TopicName] = p.Payload
+		}
+	}
+	var targets []*brokerConn
+	for conn, filters := range b.subs {
+		if matchAny(filters, p.TopicName) {
+			targets = append(targets, conn)
+		}
+	}
+	b.mu.Unlock()
+
+	for _, conn := range targets {
+		conn.write(newTestPublish(p.TopicName, p.Payload, false))
+	}
+}
+
+// newTestPublish 创建 QoS 0 的投递报文
+func newTestPublish(topic string, payload []byte, retain bool) *packets.PublishPacket {
+	msg := packets.NewControlPacket(packets.Publish).(*packets.PublishPacket)
+	msg.TopicName = topic
+	msg.Payload = payload
+	msg.Retain = retain
+	return msg
+}
+
+// matchAny 主题是否匹配任一过滤器（支持 + 和 # 通配符）
+func matchAny(filters []string, topic string) bool {
+	for _, filter := range filters {
+		if matchTopic(filter, topic) {
+			return true
+		}
+	}
+	return false
+}
+
+// matchTopic 主题是否匹配过滤器
+func matchTopic(filter, topic string) bool {
+	filterLevels := strings.Split(filter, "/")
+	topicLevels := strings.Split(topic, "/")
+	for i, level := range filterLevels {
+		if level == "#" {
+			return true
+		}
+		if i >= len(topicLevels) || (level != "+" && level != topicLevels[i]) {
+			return false
+		}
+	}
+	return len(filterLevels) == len(topicLevels)
+}
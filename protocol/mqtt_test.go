@@ -0,0 +1,134 @@
+/*
+ * @Author: kamalyes 501893067@qq.com
+ * @Date: 2026-02-18 00:00:00
+ * @LastEditors: kamalyes 501893067@qq.com
+ * @LastEditTime: 2026-02-18 00:00:00
+ * @FilePath: \go-stress\protocol\mqtt_test.go
+ * @Description: MQTT 协议客户端测试
+ *
+ * Copyright (c) 2026 by kamalyes, All Rights Reserved.
+ */
+package protocol
+
+import (
+	"context"
+	"fmt"
+	"testing"
+	"time"
+
+	"github.com/kamalyes/go-stress/config"
+	"github.com/stretchr/testify/assert"
+)
+
+// newTestMQTTClient 创建MQTT客户端
+func newTestMQTTClient(t *testing.T, timeout time.Duration, mqtt *config.MQTTConfig) *MQTTClient {
+	client, err := NewMQTTClient(&config.Config{Timeout: timeout, MQTT: mqtt})
+	assert.NoError(t, err)
+	assert.NoError(t, client.Connect(context.Background()))
+	t.Cleanup(func() { client.Close() })
+	return client
+}
+
+// 测试各 QoS 发布，以及订阅自身主题得到的端到端延迟
+func TestMQTTPublish(t *testing.T) {
+	broker := startTestBroker(t, "", "")
+
+	for qos := byte(0); qos <= 2; qos++ {
+		t.Run(fmt.Sprintf("qos%d", qos), func(t *testing.T) {
+			topic := fmt.Sprintf("devices/%d/telemetry", qos)
+			client := newTestMQTTClient(t, time.Second, &config.MQTTConfig{
+				QoS:            qos,
+				Subscribe:      []string{"devices/+/telemetry"},
+				TimestampField: "ts",
+			})
+
+			resp, err := client.Send(context.Background(), &Request{URL: broker.url(topic), Body: `{"v":1}`})
+			assert.NoError(t, err)
+			assert.Equal(t, 200, resp.StatusCode)
+			assert.Equal(t, "PUBLISH", resp.RequestMethod)
+			assert.Equal(t, MQTTConnectAccepted, resp.MQTT.ConnectCode)
+			assert.Greater(t, resp.MQTT.Connect, time.Duration(0))
+			assert.Equal(t, 1, resp.MQTT.Published)
+
+			// 订阅收到的消息在后续请求中带回，复用连接时不再记录建连
+			received, latencies := resp.MQTT.Received, len(resp.MQTT.Latencies)
+			for i := 0; i < 50 && received < 3; i++ {
+				time.Sleep(5 * time.Millisecond)
+				resp, err = client.Send(context.Background(), &Request{URL: broker.url(topic), Body: `{"v":1}`})
+				assert.NoError(t, err)
+				assert.Empty(t, resp.MQTT.ConnectCode)
+				received += resp.MQTT.Received
+				latencies += len(resp.MQTT.Latencies)
+			}
+			assert.GreaterOrEqual(t, received, 3)
+			assert.Equal(t, received, latencies, "每条消息都带有发布时间戳")
+		})
+	}
+}
+
+// 测试订阅模式接收保留消息，以及等待消息超时
+func TestMQTTSubscribeRetained(t *testing.T) {
+	broker := startTestBroker(t, "", "")
+
+	publisher := newTestMQTTClient(t, time.Second, &config.MQTTConfig{QoS: 1, Retain: true, TimestampField: "ts"})
+	_, err := publisher.Send(context.Background(), &Request{URL: broker.url("status/1"), Body: `{"online":true}`})
+	assert.NoError(t, err)
+
+	subscriber := newTestMQTTClient(t, 100*time.Millisecond, &config.MQTTConfig{
+		Mode:           config.MQTTModeSubscribe,
+		Subscribe:      []string{"status/#"},
+		TimestampField: "ts",
+	})
+	resp, err := subscriber.Send(context.Background(), &Request{URL: broker.url("")})
+	assert.NoError(t, err)
+	assert.Equal(t, "SUBSCRIBE", resp.RequestMethod)
+	assert.Equal(t, 1, resp.MQTT.Received)
+	assert.Equal(t, 1, resp.MQTT.Retained)
+	assert.Empty(t, resp.MQTT.Latencies, "保留消息不计端到端延迟")
+	assert.Contains(t, string(resp.Body), `"online":true`)
+
+	resp, err = subscriber.Send(context.Background(), &Request{URL: broker.url("")})
+	assert.Error(t, err)
+	assert.Equal(t, 0, resp.MQTT.Received)
+}
+
+// 测试连接认证结果
+func TestMQTTConnectAuth(t *testing.T) {
+	broker := startTestBroker(t, "device", "secret")
+
+	client := newTestMQTTClient(t, time.Second, &config.MQTTConfig{Username: "device", Password: "wrong"})
+	resp, err := client.Send(context.Background(), &Request{URL: broker.url("t")})
+	assert.Error(t, err)
+	assert.Equal(t, "bad_credentials", resp.MQTT.ConnectCode)
+
+	client = newTestMQTTClient(t, time.Second, &config.MQTTConfig{Username: "device", Password: "secret"})
+	resp, err = client.Send(context.Background(), &Request{URL: broker.url("t")})
+	assert.NoError(t, err)
+	assert.Equal(t, MQTTConnectAccepted, resp.MQTT.ConnectCode)
+
+	// 发布主题为空时失败
+	_, err = client.Send(context.Background(), &Request{URL: broker.url("")})
+	assert.Error(t, err)
+}
+
+// 测试 mqtts:// 地址按 mqtt.tls 配置校验代理证书
+func TestMQTTTLS(t *testing.T) {
+	broker, caFile := startTLSTestBroker(t)
+
+	// 自签名证书未配置 CA 时握手失败
+	client := newTestMQTTClient(t, time.Second, &config.MQTTConfig{})
+	_, err := client.Send(context.Background(), &Request{URL: broker.url("t")})
+	assert.Error(t, err)
+
+	client = newTestMQTTClient(t, time.Second, &config.MQTTConfig{TLS: &config.TLSConfig{CAFile: caFile}})
+	resp, err := client.Send(context.Background(), &Request{URL: broker.url("t")})
+	assert.NoError(t, err)
+	assert.Equal(t, MQTTConnectAccepted, resp.MQTT.ConnectCode)
+
+	client = newTestMQTTClient(t, time.Second, &config.MQTTConfig{TLS: &config.TLSConfig{InsecureSkipVerify: true}})
+	_, err = client.Send(context.Background(), &Request{URL: broker.url("t")})
+	assert.NoError(t, err)
+
+	_, err = NewMQTTClient(&config.Config{MQTT: &config.MQTTConfig{TLS: &config.TLSConfig{CAFile: "missing.pem"}}})
+	assert.Error(t, err)
+}
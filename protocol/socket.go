@@ -0,0 +1,217 @@
+/*
+ * @Author: kamalyes 501893067@qq.com
+ * @Date: 2026-02-16 00:00:00
+ * @LastEditors: kamalyes 501893067@qq.com
+ * @LastEditTime: 2026-02-16 00:00:00
+ * @FilePath: \go-stress\protocol\socket.go
+ * @Description: TCP/UDP 原始套接字协议客户端
+ *
+ * Copyright (c) 2026 by kamalyes, All Rights Reserved.
+ */
+package protocol
+
+import (
+	"bufio"
+	"context"
+	"fmt"
+	"net"
+	"net/url"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/kamalyes/go-stress/config"
+	"github.com/kamalyes/go-toolbox/pkg/mathx"
+)
+
+// SocketClient TCP/UDP 客户端
+// 每个 worker 保持一条连接（UDP 为已连接的套接字），请求体解码、分帧后发送，可选读取一帧响应
+type SocketClient struct {
+	config   *config.Config
+	socket   *config.SocketConfig
+	protocol ProtocolType
+	network  string // tcp | udp
+	framer   *framer
+	dialer   *net.Dialer
+	conn     net.Conn
+	reader   *bufio.Reader // TCP 连接的缓冲读取器（跨请求保留未消费的数据）
+	addr     string        // 当前连接的地址
+	mu       sync.Mutex
+}
+
+// NewTCPClient 创建TCP客户端
+func NewTCPClient(cfg *config.Config) (*SocketClient, error) {
+	return newSocketClient(cfg, ProtocolTCP)
+}
+
+// NewUDPClient 创建UDP客户端
+func NewUDPClient(cfg *config.Config) (*SocketClient, error) {
+	return newSocketClient(cfg, ProtocolUDP)
+}
+
+// newSocketClient 创建TCP/UDP客户端
+func newSocketClient(cfg *config.Config, protocol ProtocolType) (*SocketClient, error) {
+	socketConfig := cfg.Socket
+	if socketConfig == nil {
+		socketConfig = &config.SocketConfig{}
+	}
+	f, err := newFramer(socketConfig)
+	if err != nil {
+		return nil, fmt.Errorf("创建%s客户端失败: %w", strings.ToUpper(string(protocol)), err)
+	}
+
+	return &SocketClient{
+		config:   cfg,
+		socket:   socketConfig,
+		protocol: protocol,
+		network:  string(protocol),
+		framer:   f,
+		dialer:   &net.Dialer{Timeout: cfg.Timeout},
+	}, nil
+}
+
+// socketAddress 从 URL 中取出 host:port（支持 tcp://host:port、udp://host:port 和 host:port）
+func socketAddress(rawURL string) string {
+	if strings.Contains(rawURL, "://") {
+		if u, err := url.Parse(rawURL); err == nil {
+			return u.Host
+		}
+	}
+	return strings.TrimSuffix(rawURL, "/")
+}
+
+// Connect 建立连接（地址取自 url）
+func (c *SocketClient) Connect(ctx context.Context) error {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	if c.conn != nil {
+		return nil // 已连接
+	}
+	return c.dial(ctx, socketAddress(c.config.URL))
+}
+
+// dial 建立到指定地址的连接（调用方持有锁）
+func (c *SocketClient) dial(ctx context.Context, addr string) error {
+	conn, err := c.dialer.DialContext(ctx, c.network, addr)
+	if err != nil {
+		return fmt.Errorf("%s dial failed: %w", c.network, err)
+	}
+	c.conn = conn
+	c.addr = addr
+	if c.network == string(ProtocolTCP) {
+		c.reader = bufio.NewReader(conn)
+	}
+	return nil
+}
+
+// closeConn 关闭当前连接（调用方持有锁）
+func (c *SocketClient) closeConn() error {
+	if c.conn == nil {
+		return nil
+	}
+	err := c.conn.Close()
+	c.conn, c.reader, c.addr = nil, nil, ""
+	return err
+}
+
+// Send 发送一帧报文并按配置读取一帧响应
+// 响应体按请求的编码（hex/base64）转为文本，读写出错时关闭连接，下次请求重新建立
+func (c *SocketClient) Send(ctx context.Context, req *Request) (*Response, error) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	startTime := time.Now()
+	resp := &Response{
+		RequestURL:     req.URL,
+		RequestMethod:  strings.ToUpper(c.network),
+		RequestHeaders: req.Headers,
+		RequestBody:    req.Body,
+		Protocol:       c.protocol,
+	}
+	fail := func(err error) (*Response, error) {
+		resp.Duration = time.Since(startTime)
+		resp.Error = err
+		return resp, err
+	}
+
+	payload, err := decodePayload(req.Body, c.socket.Encoding)
+	if err != nil {
+		return fail(err)
+	}
+	frame, err := c.framer.encode(payload)
+	if err != nil {
+		return fail(err)
+	}
+
+	// 多API时地址可能不同，地址变化时重新建立连接
+	addr := socketAddress(mathx.IfEmpty(req.URL, c.config.URL))
+	if c.conn != nil && c.addr != addr {
+		c.closeConn()
+	}
+	if c.conn == nil {
+		if err := c.dial(ctx, addr); err != nil {
+			return fail(err)
+		}
+	}
+
+	if err := c.conn.SetDeadline(c.deadline(ctx)); err != nil {
+		c.closeConn()
+		return fail(fmt.Errorf("%s set deadline failed: %w", c.network, err))
+	}
+	if _, err := c.conn.Write(frame); err != nil {
+		c.closeConn()
+		return fail(fmt.Errorf("%s write failed: %w", c.network, err))
+	}
+
+	if !c.socket.WriteOnly {
+		data, err := c.readFrame()
+		if err != nil {
+			c.closeConn()
+			return fail(fmt.Errorf("%s read failed: %w", c.network, err))
+		}
+		resp.Body = encodePayload(data, c.socket.Encoding)
+	}
+
+	resp.StatusCode = 200 // 成功时状态码固定为 200
+	resp.Duration = time.Since(startTime)
+	return resp, nil
+}
+
+// deadline 本次请求的读写截止时间：read_timeout（默认 timeout）与 ctx 截止时间取较早者
+func (c *SocketClient) deadline(ctx context.Context) time.Time {
+	var deadline time.Time
+	if timeout := mathx.IfNotZero(c.socket.ReadTimeout, c.config.Timeout); timeout > 0 {
+		deadline = time.Now().Add(timeout)
+	}
+	if ctxDeadline, ok := ctx.Deadline(); ok && (deadline.IsZero() || ctxDeadline.Before(deadline)) {
+		deadline = ctxDeadline
+	}
+	return deadline
+}
+
+// readFrame 读取一帧响应：TCP 按分帧方式从流中读取，UDP 读取一个数据报后去掉帧结构
+func (c *SocketClient) readFrame() ([]byte, error) {
+	if c.reader != nil {
+		return c.framer.read(c.reader)
+	}
+	buf := make([]byte, socketReadBufferSize)
+	n, err := c.conn.Read(buf)
+	if err != nil {
+		return nil, err
+	}
+	return c.framer.unwrap(buf[:n])
+}
+
+// Type 返回协议类型
+func (c *SocketClient) Type() ProtocolType {
+	return c.protocol
+}
+
+// Close 关闭连接
+func (c *SocketClient) Close() error {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	return c.closeConn()
+}
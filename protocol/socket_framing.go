@@ -0,0 +1,252 @@
+/*
+ * @Author: kamalyes 501893067@qq.com
+ * @Date: 2026-02-16 00:00:00
+ * @LastEditors: kamalyes 501893067@qq.com
+ * @LastEditTime: 2026-02-16 00:00:00
+ * @FilePath: \go-stress\protocol\socket_framing.go
+ * @Description: TCP/UDP 报文编码与分帧（长度前缀、分隔符、定长）
+ *
+ * Copyright (c) 2026 by kamalyes, All Rights Reserved.
+ */
+package protocol
+
+import (
+	"bufio"
+	"bytes"
+	"encoding/base64"
+	"encoding/binary"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"io"
+	"strconv"
+	"strings"
+
+	"github.com/kamalyes/go-stress/config"
+	"github.com/kamalyes/go-toolbox/pkg/mathx"
+)
+
+const (
+	socketDefaultLengthBytes  = 4                // 默认长度前缀字节数
+	socketDefaultDelimiter    = `\n`             // 默认分隔符（支持转义）
+	socketDefaultMaxFrameSize = 16 * 1024 * 1024 // 默认读取单个帧的最大字节数
+	socketReadBufferSize      = 64 * 1024        // 不分帧时单次读取/UDP 数据报的最大字节数
+)
+
+// decodePayload 按编码将请求体模板渲染结果解码为原始字节
+func decodePayload(body string, encoding config.PayloadEncoding) ([]byte, error) {
+	switch encoding {
+	case config.PayloadEncodingHex:
+		// 允许使用空白分隔字节，如 "01 02 ff"
+		data, err := hex.DecodeString(strings.Join(strings.Fields(body), ""))
+		if err != nil {
+			return nil, fmt.Errorf("hex 解码失败: %w", err)
+		}
+		return data, nil
+	case config.PayloadEncodingBase64:
+		data, err := base64.StdEncoding.DecodeString(strings.TrimSpace(body))
+		if err != nil {
+			return nil, fmt.Errorf("base64 解码失败: %w", err)
+		}
+		return data, nil
+	default:
+		return []byte(body), nil
+	}
+}
+
+// encodePayload 将响应原始字节按请求的编码转为可读文本，便于验证器和提取器处理
+func encodePayload(data []byte, encoding config.PayloadEncoding) []byte {
+	switch encoding {
+	case config.PayloadEncodingHex:
+		return []byte(hex.EncodeToString(data))
+	case config.PayloadEncodingBase64:
+		return []byte(base64.StdEncoding.EncodeToString(data))
+	default:
+		return data
+	}
+}
+
+// framer 报文分帧器
+type framer struct {
+	framing      config.FramingType
+	lengthBytes  int
+	littleEndian bool
+	delimiter    []byte
+	fixedSize    int
+	maxFrameSize int // 读取单个帧的最大字节数（防止异常的长度前缀或缺失的分隔符占用大量内存）
+}
+
+// newFramer 根据配置创建分帧器，分隔符支持 \n、\r\n、\x00 等转义写法
+func newFramer(cfg *config.SocketConfig) (*framer, error) {
+	f := &framer{
+		framing:      mathx.IfEmpty(cfg.Framing, config.FramingNone),
+		lengthBytes:  mathx.IfNotZero(cfg.LengthBytes, socketDefaultLengthBytes),
+		littleEndian: cfg.LittleEndian,
+		fixedSize:    cfg.FixedSize,
+		maxFrameSize: mathx.IfNotZero(cfg.MaxFrameSize, socketDefaultMaxFrameSize),
+	}
+	if f.maxFrameSize < 0 {
+		return nil, fmt.Errorf("max_frame_size 不能为负数: %d", cfg.MaxFrameSize)
+	}
+	switch f.framing {
+	case config.FramingLength:
+		switch f.lengthBytes {
+		case 1, 2, 4:
+		default:
+			return nil, fmt.Errorf("length_bytes 只能为 1/2/4: %d", f.lengthBytes)
+		}
+	case config.FramingDelimiter:
+		delimiter, err := strconv.Unquote(`"` + mathx.IfEmpty(cfg.Delimiter, socketDefaultDelimiter) + `"`)
+		if err != nil || delimiter == "" {
+			return nil, fmt.Errorf("无效的分隔符: %q", cfg.Delimiter)
+		}
+		f.delimiter = []byte(delimiter)
+	case config.FramingFixed:
+		if f.fixedSize <= 0 {
+			return nil, fmt.Errorf("定长分帧时 fixed_size 必须大于0")
+		}
+	}
+	return f, nil
+}
+
+// byteOrder 长度前缀字节序
+func (f *framer) byteOrder() binary.ByteOrder {
+	if f.littleEndian {
+		return binary.LittleEndian
+	}
+	return binary.BigEndian
+}
+
+// encode 为报文添加帧结构
+func (f *framer) encode(payload []byte) ([]byte, error) {
+	switch f.framing {
+	case config.FramingLength:
+		if limit := uint64(1)<<(8*f.lengthBytes) - 1; uint64(len(payload)) > limit {
+			return nil, fmt.Errorf("报文长度 %d 超过 %d 字节长度前缀的上限 %d", len(payload), f.lengthBytes, limit)
+		}
+		frame := make([]byte, f.lengthBytes+len(payload))
+		f.putLength(frame[:f.lengthBytes], len(payload))
+		copy(frame[f.lengthBytes:], payload)
+		return frame, nil
+	case config.FramingDelimiter:
+		return append(append([]byte{}, payload...), f.delimiter...), nil
+	case config.FramingFixed:
+		if len(payload) > f.fixedSize {
+			return nil, fmt.Errorf("报文长度 %d 超过定长 %d", len(payload), f.fixedSize)
+		}
+		frame := make([]byte, f.fixedSize)
+		copy(frame, payload)
+		return frame, nil
+	default:
+		return payload, nil
+	}
+}
+
+// putLength 写入长度前缀
+func (f *framer) putLength(buf []byte, n int) {
+	switch f.lengthBytes {
+	case 1:
+		buf[0] = byte(n)
+	case 2:
+		f.byteOrder().PutUint16(buf, uint16(n))
+	default:
+		f.byteOrder().PutUint32(buf, uint32(n))
+	}
+}
+
+// readLength 读取长度前缀
+func (f *framer) readLength(buf []byte) int {
+	switch f.lengthBytes {
+	case 1:
+		return int(buf[0])
+	case 2:
+		return int(f.byteOrder().Uint16(buf))
+	default:
+		return int(f.byteOrder().Uint32(buf))
+	}
+}
+
+// read 从流中读取一个完整的帧并返回去掉帧结构后的报文（TCP）
+func (f *framer) read(r *bufio.Reader) ([]byte, error) {
+	switch f.framing {
+	case config.FramingLength:
+		header := make([]byte, f.lengthBytes)
+		if _, err := io.ReadFull(r, header); err != nil {
+			return nil, err
+		}
+		n := f.readLength(header)
+		if n > f.maxFrameSize {
+			return nil, fmt.Errorf("帧长度 %d 超过 max_frame_size %d", n, f.maxFrameSize)
+		}
+		payload := make([]byte, n)
+		if _, err := io.ReadFull(r, payload); err != nil {
+			return nil, err
+		}
+		return payload, nil
+	case config.FramingDelimiter:
+		return f.readDelimited(r)
+	case config.FramingFixed:
+		payload := make([]byte, f.fixedSize)
+		if _, err := io.ReadFull(r, payload); err != nil {
+			return nil, err
+		}
+		return payload, nil
+	default:
+		// 不分帧时读取一次到达的数据
+		buf := make([]byte, socketReadBufferSize)
+		n, err := r.Read(buf)
+		if n > 0 {
+			return buf[:n], nil
+		}
+		return nil, err
+	}
+}
+
+// readDelimited 读取直到分隔符（支持多字节分隔符），超过 max_frame_size 仍未读到分隔符时失败
+func (f *framer) readDelimited(r *bufio.Reader) ([]byte, error) {
+	last := f.delimiter[len(f.delimiter)-1]
+	var frame []byte
+	for {
+		chunk, err := r.ReadSlice(last)
+		frame = append(frame, chunk...)
+		if len(frame) > f.maxFrameSize+len(f.delimiter) {
+			return nil, fmt.Errorf("超过 max_frame_size %d 字节未读到分隔符", f.maxFrameSize)
+		}
+		if errors.Is(err, bufio.ErrBufferFull) {
+			continue
+		}
+		if err != nil {
+			return nil, err
+		}
+		if bytes.HasSuffix(frame, f.delimiter) {
+			return frame[:len(frame)-len(f.delimiter)], nil
+		}
+	}
+}
+
+// unwrap 去掉单个数据报的帧结构（UDP）
+func (f *framer) unwrap(datagram []byte) ([]byte, error) {
+	switch f.framing {
+	case config.FramingLength:
+		if len(datagram) < f.lengthBytes {
+			return nil, fmt.Errorf("数据报长度 %d 小于长度前缀 %d 字节", len(datagram), f.lengthBytes)
+		}
+		n := f.readLength(datagram[:f.lengthBytes])
+		if n > len(datagram)-f.lengthBytes {
+			return nil, fmt.Errorf("数据报不完整: 期望 %d 字节，实际 %d 字节", n, len(datagram)-f.lengthBytes)
+		}
+		return datagram[f.lengthBytes : f.lengthBytes+n], nil
+	case config.FramingDelimiter:
+		if i := bytes.Index(datagram, f.delimiter); i >= 0 {
+			return datagram[:i], nil
+		}
+		return datagram, nil
+	case config.FramingFixed:
+		if len(datagram) < f.fixedSize {
+			return nil, fmt.Errorf("数据报长度 %d 小于定长 %d", len(datagram), f.fixedSize)
+		}
+		return datagram[:f.fixedSize], nil
+	default:
+		return datagram, nil
+	}
+}
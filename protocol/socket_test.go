@@ -0,0 +1,200 @@
+/*
+ * @Author: kamalyes 501893067@qq.com
+ * @Date: 2026-02-16 00:00:00
+ * @LastEditors: kamalyes 501893067@qq.com
+ * @LastEditTime: 2026-02-16 00:00:00
+ * @FilePath: \go-stress\protocol\socket_test.go
+ * @Description: TCP/UDP 协议客户端测试
+ *
+ * Copyright (c) 2026 by kamalyes, All Rights Reserved.
+ */
+package protocol
+
+import (
+	"bufio"
+	"bytes"
+	"context"
+	"io"
+	"net"
+	"testing"
+	"time"
+
+	"github.com/kamalyes/go-stress/config"
+	"github.com/stretchr/testify/assert"
+)
+
+// startTCPEchoServer 启动TCP回显服务：原样回写收到的字节
+func startTCPEchoServer(t *testing.T) string {
+	ln, err := net.Listen("tcp", "127.0.0.1:0")
+	assert.NoError(t, err)
+	t.Cleanup(func() { ln.Close() })
+	go func() {
+		for {
+			conn, err := ln.Accept()
+			if err != nil {
+				return
+			}
+			go func() {
+				defer conn.Close()
+				_, _ = io.Copy(conn, conn)
+			}()
+		}
+	}()
+	return ln.Addr().String()
+}
+
+// startUDPEchoServer 启动UDP回显服务：原样回写每个数据报
+func startUDPEchoServer(t *testing.T) string {
+	conn, err := net.ListenPacket("udp", "127.0.0.1:0")
+	assert.NoError(t, err)
+	t.Cleanup(func() { conn.Close() })
+	go func() {
+		buf := make([]byte, 2048)
+		for {
+			n, addr, err := conn.ReadFrom(buf)
+			if err != nil {
+				return
+			}
+			_, _ = conn.WriteTo(buf[:n], addr)
+		}
+	}()
+	return conn.LocalAddr().String()
+}
+
+// 测试TCP在一条连接上按各种分帧方式收发
+func TestTCPClientFraming(t *testing.T) {
+	addr := startTCPEchoServer(t)
+
+	cases := []struct {
+		name   string
+		socket *config.SocketConfig
+		body   string
+		want   string
+	}{
+		{"text", nil, "hello", "hello"},
+		{"delimiter", &config.SocketConfig{Framing: config.FramingDelimiter, Delimiter: `\r\n`}, "ping", "ping"},
+		{"length", &config.SocketConfig{Framing: config.FramingLength, LengthBytes: 2, Encoding: config.PayloadEncodingHex}, "01 02 ff", "0102ff"},
+		{"fixed", &config.SocketConfig{Framing: config.FramingFixed, FixedSize: 4, Encoding: config.PayloadEncodingBase64}, "AQI=", "AQIAAA=="},
+	}
+	for _, tc := range cases {
+		t.Run(tc.name, func(t *testing.T) {
+			client, err := NewTCPClient(&config.Config{URL: "tcp://" + addr, Timeout: time.Second, Socket: tc.socket})
+			assert.NoError(t, err)
+			defer client.Close()
+			assert.NoError(t, client.Connect(context.Background()))
+
+			for i := 0; i < 3; i++ {
+				resp, err := client.Send(context.Background(), &Request{URL: "tcp://" + addr, Body: tc.body})
+				assert.NoError(t, err)
+				assert.Equal(t, 200, resp.StatusCode)
+				assert.Equal(t, "TCP", resp.RequestMethod)
+				assert.Equal(t, ProtocolTCP, resp.Protocol)
+				assert.Equal(t, tc.want, string(resp.Body))
+			}
+		})
+	}
+}
+
+// 测试UDP数据报收发、只写模式和读取超时
+func TestUDPClient(t *testing.T) {
+	addr := startUDPEchoServer(t)
+
+	client, err := NewUDPClient(&config.Config{Timeout: time.Second, Socket: &config.SocketConfig{Framing: config.FramingLength, LengthBytes: 1}})
+	assert.NoError(t, err)
+	defer client.Close()
+	resp, err := client.Send(context.Background(), &Request{URL: addr, Body: "hi"})
+	assert.NoError(t, err)
+	assert.Equal(t, "hi", string(resp.Body))
+	assert.Equal(t, ProtocolUDP, client.Type())
+
+	writeOnly, err := NewUDPClient(&config.Config{Timeout: time.Second, Socket: &config.SocketConfig{WriteOnly: true}})
+	assert.NoError(t, err)
+	defer writeOnly.Close()
+	resp, err = writeOnly.Send(context.Background(), &Request{URL: addr, Body: "fire"})
+	assert.NoError(t, err)
+	assert.Empty(t, resp.Body)
+
+	// 无人监听的端口读取超时
+	silent, err := net.ListenPacket("udp", "127.0.0.1:0")
+	assert.NoError(t, err)
+	defer silent.Close()
+	timeoutClient, err := NewUDPClient(&config.Config{Timeout: time.Second, Socket: &config.SocketConfig{ReadTimeout: 50 * time.Millisecond}})
+	assert.NoError(t, err)
+	defer timeoutClient.Close()
+	resp, err = timeoutClient.Send(context.Background(), &Request{URL: silent.LocalAddr().String(), Body: "x"})
+	assert.Error(t, err)
+	assert.Less(t, resp.Duration, time.Second)
+}
+
+// 测试分帧编码和解析
+func TestFramer(t *testing.T) {
+	f, err := newFramer(&config.SocketConfig{Framing: config.FramingLength, LengthBytes: 2, LittleEndian: true})
+	assert.NoError(t, err)
+	frame, err := f.encode([]byte("abc"))
+	assert.NoError(t, err)
+	assert.Equal(t, []byte{3, 0, 'a', 'b', 'c'}, frame)
+	payload, err := f.read(bufio.NewReader(bytes.NewReader(append(frame, 1, 0, 'x'))))
+	assert.NoError(t, err)
+	assert.Equal(t, "abc", string(payload))
+	_, err = f.unwrap([]byte{5, 0, 'a'})
+	assert.Error(t, err, "数据报不完整")
+
+	f, err = newFramer(&config.SocketConfig{Framing: config.FramingLength, LengthBytes: 1})
+	assert.NoError(t, err)
+	_, err = f.encode(make([]byte, 256))
+	assert.Error(t, err, "超过1字节长度前缀上限")
+
+	f, err = newFramer(&config.SocketConfig{Framing: config.FramingDelimiter, Delimiter: `\r\n`})
+	assert.NoError(t, err)
+	payload, err = f.read(bufio.NewReader(bytes.NewReader([]byte("a\nb\r\nc"))))
+	assert.NoError(t, err)
+	assert.Equal(t, "a\nb", string(payload), "多字节分隔符中的单个字节不截断")
+
+	_, err = decodePayload("zz", config.PayloadEncodingHex)
+	assert.Error(t, err)
+}
+
+// 测试分帧参数在创建分帧器时校验，读取的帧超过 max_frame_size 时失败
+func TestFramerLimits(t *testing.T) {
+	for _, cfg := range []config.SocketConfig{
+		{Framing: config.FramingLength, LengthBytes: 3},
+		{Framing: config.FramingLength, LengthBytes: 8},
+		{Framing: config.FramingFixed},
+		{Framing: config.FramingLength, MaxFrameSize: -1},
+	} {
+		_, err := newFramer(&cfg)
+		assert.Error(t, err, "%+v", cfg)
+	}
+	_, err := NewTCPClient(&config.Config{Socket: &config.SocketConfig{Framing: config.FramingLength, LengthBytes: 3}})
+	assert.Error(t, err)
+
+	// 默认上限 16MB：4 字节长度前缀声明 4GB 的帧不分配内存直接失败
+	f, err := newFramer(&config.SocketConfig{Framing: config.FramingLength})
+	assert.NoError(t, err)
+	_, err = f.read(bufio.NewReader(bytes.NewReader([]byte{0xff, 0xff, 0xff, 0xff, 'a'})))
+	assert.ErrorContains(t, err, "max_frame_size")
+
+	f, err = newFramer(&config.SocketConfig{Framing: config.FramingLength, LengthBytes: 2, MaxFrameSize: 3})
+	assert.NoError(t, err)
+	payload, err := f.read(bufio.NewReader(bytes.NewReader([]byte{0, 3, 'a', 'b', 'c'})))
+	assert.NoError(t, err)
+	assert.Equal(t, "abc", string(payload))
+	_, err = f.read(bufio.NewReader(bytes.NewReader([]byte{0, 4, 'a', 'b', 'c', 'd'})))
+	assert.ErrorContains(t, err, "max_frame_size")
+
+	// 分隔符分帧：超过上限仍未读到分隔符时失败（包括超过读缓冲区的长帧）
+	f, err = newFramer(&config.SocketConfig{Framing: config.FramingDelimiter, MaxFrameSize: 3})
+	assert.NoError(t, err)
+	payload, err = f.read(bufio.NewReader(bytes.NewReader([]byte("abc\n"))))
+	assert.NoError(t, err)
+	assert.Equal(t, "abc", string(payload))
+	_, err = f.read(bufio.NewReader(bytes.NewReader([]byte("abcd\n"))))
+	assert.ErrorContains(t, err, "max_frame_size")
+
+	f, err = newFramer(&config.SocketConfig{Framing: config.FramingDelimiter})
+	assert.NoError(t, err)
+	long := append(bytes.Repeat([]byte("x"), 10000), '\n')
+	payload, err = f.read(bufio.NewReaderSize(bytes.NewReader(long), 16))
+	assert.NoError(t, err)
+	assert.Len(t, payload, 10000)
+}
@@ -0,0 +1,287 @@
+/*
+ * @Author: kamalyes 501893067@qq.com
+ * @Date: 2026-02-15 00:00:00
+ * @LastEditors: kamalyes 501893067@qq.com
+ * @LastEditTime: 2026-02-15 00:00:00
+ * @FilePath: \go-stress\protocol\sse.go
+ * @Description: Server-Sent Events 协议客户端 - 保持事件流、解析事件、Last-Event-ID 重连
+ *
+ * Copyright (c) 2026 by kamalyes, All Rights Reserved.
+ */
+package protocol
+
+import (
+	"bufio"
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/kamalyes/go-stress/config"
+	"github.com/kamalyes/go-toolbox/pkg/mathx"
+)
+
+const (
+	sseStreamKind         = "sse"           // 流统计中的流类型
+	sseDefaultEvent       = "message"       // 未指定 event 字段时的事件类型
+	sseDefaultRetryDelay  = time.Second     // 默认重连等待时间
+	sseMaxLineSize        = 1024 * 1024     // 单行最大长度
+	sseLastEventIDHeader  = "Last-Event-ID" // 重连时携带的最后事件ID请求头
+	sseCodeOK             = "OK"            // 正常结束
+	sseCodeError          = "ERROR"         // 网络或读取错误
+	sseStatusCodeTemplate = "HTTP_%d"       // 非 2xx 响应
+)
+
+// SSEClient Server-Sent Events 客户端
+type SSEClient struct {
+	config *config.Config
+	sse    *config.SSEConfig
+	client *http.Client
+}
+
+// NewSSEClient 创建SSE客户端（复用 http 配置的传输层，响应头等待时间受 timeout 限制）
+func NewSSEClient(cfg *config.Config) (*SSEClient, error) {
+	httpCfg := cfg.HTTP
+	if httpCfg == nil {
+		httpCfg = &config.HTTPConfig{
+			MaxConnsPerHost: 100,
+			FollowRedirects: true,
+		}
+	}
+	stdClient, err := newStdHTTPClient(httpCfg)
+	if err != nil {
+		return nil, fmt.Errorf("创建SSE客户端失败: %w", err)
+	}
+	// 事件流时长不受 timeout 限制，只限制等待响应头的时间
+	if transport, ok := stdClient.Transport.(*http.Transport); ok {
+		transport.ResponseHeaderTimeout = cfg.Timeout
+	}
+
+	sseConfig := cfg.SSE
+	if sseConfig == nil {
+		sseConfig = &config.SSEConfig{}
+	}
+
+	return &SSEClient{
+		config: cfg,
+		sse:    sseConfig,
+		client: stdClient,
+	}, nil
+}
+
+// Connect SSE在每次请求时建立事件流
+func (c *SSEClient) Connect(ctx context.Context) error {
+	return nil
+}
+
+// sseStream 一次 SSE 请求的事件流状态（跨重连保持）
+type sseStream struct {
+	startTime   time.Time
+	prev        time.Time // 上一条事件的接收时间
+	lastEventID string
+	retryDelay  time.Duration
+	stats       *StreamStats
+	events      []SSEEvent
+}
+
+// Send 打开事件流并持续接收事件，直到服务端结束、到达 lifetime/max_events/请求截止时间或重连次数用尽
+// 响应体为最后一个事件的数据，Events 为全部事件；流统计中 Received 为事件数，InterArrivals 为事件间隔
+func (c *SSEClient) Send(ctx context.Context, req *Request) (*Response, error) {
+	stream := &sseStream{
+		startTime:  time.Now(),
+		retryDelay: mathx.IfNotZero(c.sse.RetryDelay, sseDefaultRetryDelay),
+		stats:      &StreamStats{Kind: sseStreamKind},
+	}
+	stream.prev = stream.startTime
+	resp := &Response{
+		RequestURL:     req.URL,
+		RequestMethod:  mathx.IfEmpty(req.Method, http.MethodGet),
+		RequestHeaders: req.Headers,
+		RequestBody:    req.Body,
+		Protocol:       ProtocolSSE,
+		Stream:         stream.stats,
+	}
+
+	streamCtx, cancel := context.WithCancel(ctx)
+	if end := streamEnd(c.sse.Lifetime, req.Deadline); !end.IsZero() {
+		streamCtx, cancel = context.WithDeadline(ctx, end)
+	}
+	defer cancel()
+
+	err := c.receive(streamCtx, req, resp, stream)
+	// 到达 lifetime、max_events 或截止时间（按时间运行的压测结束）主动断开视为正常结束
+	if err != nil && streamCtx.Err() != nil && ctx.Err() == nil {
+		err = nil
+	}
+
+	resp.Duration = time.Since(stream.startTime)
+	resp.Events = stream.events
+	if n := len(stream.events); n > 0 {
+		resp.Body = []byte(stream.events[n-1].Data)
+	}
+	if err != nil {
+		stream.stats.Code = mathx.IF(stream.stats.Code == "", sseCodeError, stream.stats.Code)
+		resp.Error = fmt.Errorf("SSE请求失败: %w", err)
+		return resp, resp.Error
+	}
+	stream.stats.Code = sseCodeOK
+	return resp, nil
+}
+
+// receive 建立连接并接收事件，连接中断时按 max_reconnects 携带 Last-Event-ID 重连
+func (c *SSEClient) receive(ctx context.Context, req *Request, resp *Response, stream *sseStream) error {
+	for {
+		reachedMax, err := c.connectOnce(ctx, req, resp, stream)
+		if reachedMax || ctx.Err() != nil {
+			return err
+		}
+		var statusErr *sseStatusError
+		if errors.As(err, &statusErr) {
+			return err
+		}
+		// 服务端正常结束且没有设置存活时间/事件数目标时，视为流已完成
+		if err == nil && c.sse.Lifetime == 0 && c.sse.MaxEvents == 0 {
+			return nil
+		}
+		if stream.stats.Reconnects >= c.sse.MaxReconnects {
+			return err
+		}
+
+		select {
+		case <-ctx.Done():
+			return err
+		case <-time.After(stream.retryDelay):
+		}
+		stream.stats.Reconnects++
+	}
+}
+
+// sseStatusError 非 2xx 响应（不重连）
+type sseStatusError struct {
+	statusCode int
+}
+
+func (e *sseStatusError) Error() string {
+	return fmt.Sprintf("非预期的状态码: %d", e.statusCode)
+}
+
+// connectOnce 建立一次连接并读取事件直到流结束，reachedMax 表示已收到 max_events 个事件
+func (c *SSEClient) connectOnce(ctx context.Context, req *Request, resp *Response, stream *sseStream) (reachedMax bool, err error) {
+	var body io.Reader
+	if req.Body != "" {
+		body = strings.NewReader(req.Body)
+	}
+	httpReq, err := http.NewRequestWithContext(ctx, resp.RequestMethod, req.URL, body)
+	if err != nil {
+		return false, fmt.Errorf("创建请求失败: %w", err)
+	}
+	for k, v := range req.Headers {
+		httpReq.Header.Set(k, v)
+	}
+	httpReq.Header.Set("Accept", "text/event-stream")
+	httpReq.Header.Set("Cache-Control", "no-cache")
+	if stream.lastEventID != "" {
+		httpReq.Header.Set(sseLastEventIDHeader, stream.lastEventID)
+	}
+
+	httpResp, err := c.client.Do(httpReq)
+	if err != nil {
+		return false, err
+	}
+	defer httpResp.Body.Close()
+
+	resp.StatusCode = httpResp.StatusCode
+	resp.Headers = firstHeaderValues(httpResp.Header)
+	// 204 表示服务端要求客户端停止重连
+	if httpResp.StatusCode == http.StatusNoContent {
+		return true, nil
+	}
+	if httpResp.StatusCode < 200 || httpResp.StatusCode >= 300 {
+		stream.stats.Code = fmt.Sprintf(sseStatusCodeTemplate, httpResp.StatusCode)
+		return false, &sseStatusError{statusCode: httpResp.StatusCode}
+	}
+
+	return c.readEvents(httpResp.Body, stream)
+}
+
+// readEvents 按 SSE 规范逐行解析事件：空行分发事件，以冒号开头的行为注释
+func (c *SSEClient) readEvents(r io.Reader, stream *sseStream) (reachedMax bool, err error) {
+	scanner := bufio.NewScanner(r)
+	scanner.Buffer(make([]byte, 0, 4096), sseMaxLineSize)
+
+	var (
+		event   SSEEvent
+		data    strings.Builder
+		hasData bool
+	)
+	for scanner.Scan() {
+		line := strings.TrimSuffix(scanner.Text(), "\r")
+		if line == "" {
+			if hasData {
+				event.Data = data.String()
+				event.Event = mathx.IfEmpty(event.Event, sseDefaultEvent)
+				event.ID = stream.lastEventID
+				stream.dispatch(event)
+				if c.sse.MaxEvents > 0 && stream.stats.Received >= c.sse.MaxEvents {
+					return true, nil
+				}
+			}
+			event, hasData = SSEEvent{}, false
+			data.Reset()
+			continue
+		}
+		if strings.HasPrefix(line, ":") {
+			continue
+		}
+
+		field, value, _ := strings.Cut(line, ":")
+		value = strings.TrimPrefix(value, " ")
+		switch field {
+		case "event":
+			event.Event = value
+		case "data":
+			if hasData {
+				data.WriteByte('\n')
+			}
+			data.WriteString(value)
+			hasData = true
+		case "id":
+			// 事件ID在流内持续生效，用于重连时的 Last-Event-ID
+			if !strings.ContainsRune(value, 0) {
+				stream.lastEventID = value
+			}
+		case "retry":
+			if ms, err := strconv.Atoi(value); err == nil && ms >= 0 {
+				stream.retryDelay = time.Duration(ms) * time.Millisecond
+			}
+		}
+	}
+	return false, scanner.Err()
+}
+
+// dispatch 记录一个事件及其与上一事件的间隔（首个事件为距请求开始的耗时）
+func (s *sseStream) dispatch(event SSEEvent) {
+	now := time.Now()
+	if s.stats.Received == 0 {
+		s.stats.FirstMessage = now.Sub(s.startTime)
+	}
+	s.stats.InterArrivals = append(s.stats.InterArrivals, now.Sub(s.prev))
+	s.stats.Received++
+	s.prev = now
+	s.events = append(s.events, event)
+}
+
+// Type 返回协议类型
+func (c *SSEClient) Type() ProtocolType {
+	return ProtocolSSE
+}
+
+// Close 关闭客户端的空闲连接
+func (c *SSEClient) Close() error {
+	c.client.CloseIdleConnections()
+	return nil
+}
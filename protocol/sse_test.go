@@ -0,0 +1,139 @@
+/*
+ * @Author: kamalyes 501893067@qq.com
+ * @Date: 2026-02-15 00:00:00
+ * @LastEditors: kamalyes 501893067@qq.com
+ * @LastEditTime: 2026-02-15 00:00:00
+ * @FilePath: \go-stress\protocol\sse_test.go
+ * @Description: SSE 协议客户端测试
+ *
+ * Copyright (c) 2026 by kamalyes, All Rights Reserved.
+ */
+package protocol
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"strconv"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/kamalyes/go-stress/config"
+	"github.com/stretchr/testify/assert"
+)
+
+// sendSSE 以 SSE 客户端发送一次请求
+func sendSSE(t *testing.T, url string, sse *config.SSEConfig) (*Response, error) {
+	client, err := NewSSEClient(&config.Config{Timeout: time.Second, SSE: sse})
+	assert.NoError(t, err)
+	defer client.Close()
+	return client.Send(context.Background(), &Request{URL: url, Headers: map[string]string{"X-User": "alice"}})
+}
+
+// 测试事件解析：多行 data、事件类型、注释和事件ID
+func TestSSEEvents(t *testing.T) {
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		assert.Equal(t, "text/event-stream", r.Header.Get("Accept"))
+		assert.Equal(t, "alice", r.Header.Get("X-User"))
+		w.Header().Set("Content-Type", "text/event-stream")
+		fmt.Fprint(w, ": keepalive\n\n")
+		fmt.Fprint(w, "id: 1\ndata: {\"step\":1}\n\n")
+		w.(http.Flusher).Flush()
+		time.Sleep(20 * time.Millisecond)
+		fmt.Fprint(w, "event: done\r\ndata: line1\r\ndata: line2\r\n\r\n")
+	}))
+	defer srv.Close()
+
+	resp, err := sendSSE(t, srv.URL, nil)
+	assert.NoError(t, err)
+	assert.Equal(t, http.StatusOK, resp.StatusCode)
+	assert.Equal(t, ProtocolSSE, resp.Protocol)
+	assert.Equal(t, []SSEEvent{
+		{ID: "1", Event: "message", Data: `{"step":1}`},
+		{ID: "1", Event: "done", Data: "line1\nline2"},
+	}, resp.Events)
+	assert.Equal(t, "line1\nline2", string(resp.Body))
+
+	stats := resp.Stream
+	assert.Equal(t, "sse", stats.Kind)
+	assert.Equal(t, "OK", stats.Code)
+	assert.Equal(t, 2, stats.Received)
+	assert.Len(t, stats.InterArrivals, 2)
+	assert.GreaterOrEqual(t, stats.InterArrivals[1], 20*time.Millisecond, "事件间隔")
+	assert.Equal(t, stats.FirstMessage, stats.InterArrivals[0])
+	assert.Empty(t, stats.MessageLatencies, "SSE 没有发送的消息")
+}
+
+// 测试连接中断后携带 Last-Event-ID 重连，直到收到 max_events 个事件
+func TestSSEReconnect(t *testing.T) {
+	var (
+		mu           sync.Mutex
+		lastEventIDs []string
+	)
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		mu.Lock()
+		lastEventIDs = append(lastEventIDs, r.Header.Get("Last-Event-ID"))
+		mu.Unlock()
+
+		// 每个连接从上次的ID继续发送两个事件后断开
+		next, _ := strconv.Atoi(r.Header.Get("Last-Event-ID"))
+		fmt.Fprint(w, "retry: 10\n\n")
+		for i := next + 1; i <= next+2; i++ {
+			fmt.Fprintf(w, "id: %d\ndata: %d\n\n", i, i)
+		}
+	}))
+	defer srv.Close()
+
+	resp, err := sendSSE(t, srv.URL, &config.SSEConfig{MaxEvents: 5, MaxReconnects: 5, RetryDelay: time.Hour})
+	assert.NoError(t, err)
+	assert.Equal(t, 5, resp.Stream.Received)
+	assert.Equal(t, 2, resp.Stream.Reconnects)
+	assert.Equal(t, "5", string(resp.Body))
+	assert.Equal(t, []string{"", "2", "4"}, lastEventIDs, "服务端 retry 字段覆盖重连等待时间")
+
+	// 重连次数用尽且未达到目标时，服务端正常结束视为成功
+	resp, err = sendSSE(t, srv.URL, &config.SSEConfig{MaxEvents: 5, MaxReconnects: 1})
+	assert.NoError(t, err)
+	assert.Equal(t, 4, resp.Stream.Received)
+	assert.Equal(t, 1, resp.Stream.Reconnects)
+}
+
+// 测试 lifetime 到期或到达请求截止时间主动断开，以及非 2xx 响应
+func TestSSELifetimeAndStatus(t *testing.T) {
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path == "/down" {
+			w.WriteHeader(http.StatusServiceUnavailable)
+			return
+		}
+		for i := 0; r.Context().Err() == nil; i++ {
+			fmt.Fprintf(w, "data: %d\n\n", i)
+			w.(http.Flusher).Flush()
+			time.Sleep(10 * time.Millisecond)
+		}
+	}))
+	defer srv.Close()
+
+	resp, err := sendSSE(t, srv.URL, &config.SSEConfig{Lifetime: 100 * time.Millisecond})
+	assert.NoError(t, err)
+	assert.Equal(t, "OK", resp.Stream.Code)
+	assert.Greater(t, resp.Stream.Received, 3)
+	assert.Less(t, resp.Duration, time.Second)
+
+	// 未设置 lifetime 时，按时间运行的压测在截止时间结束事件流，同样视为正常
+	client, err := NewSSEClient(&config.Config{Timeout: time.Second, SSE: &config.SSEConfig{}})
+	assert.NoError(t, err)
+	defer client.Close()
+	resp, err = client.Send(context.Background(), &Request{URL: srv.URL, Deadline: time.Now().Add(100 * time.Millisecond)})
+	assert.NoError(t, err)
+	assert.Equal(t, "OK", resp.Stream.Code)
+	assert.Greater(t, resp.Stream.Received, 3)
+	assert.Less(t, resp.Duration, time.Second)
+
+	resp, err = sendSSE(t, srv.URL+"/down", &config.SSEConfig{MaxReconnects: 3})
+	assert.Error(t, err)
+	assert.Equal(t, http.StatusServiceUnavailable, resp.StatusCode)
+	assert.Equal(t, "HTTP_503", resp.Stream.Code)
+	assert.Equal(t, 0, resp.Stream.Reconnects, "非 2xx 响应不重连")
+}
@@ -0,0 +1,59 @@
+/*
+ * @Author: kamalyes 501893067@qq.com
+ * @Date: 2026-02-10 00:00:00
+ * @LastEditors: kamalyes 501893067@qq.com
+ * @LastEditTime: 2026-02-10 00:00:00
+ * @FilePath: \go-stress\protocol\tls.go
+ * @Description: TLS 配置构建 - 自定义CA、客户端证书(mTLS)、SNI，HTTP 与 gRPC 共用
+ *
+ * Copyright (c) 2026 by kamalyes, All Rights Reserved.
+ */
+package protocol
+
+import (
+	"crypto/tls"
+	"crypto/x509"
+	"fmt"
+	"os"
+
+	"github.com/kamalyes/go-stress/config"
+)
+
+// buildTLSConfig 根据配置构建 tls.Config（cfg 为 nil 时返回 nil，使用系统默认配置）
+func buildTLSConfig(cfg *config.TLSConfig) (*tls.Config, error) {
+	if cfg == nil {
+		return nil, nil
+	}
+
+	tlsConfig := &tls.Config{
+		ServerName:         cfg.ServerName,
+		InsecureSkipVerify: cfg.InsecureSkipVerify, // 不验证服务器证书（仅用于测试环境）
+	}
+
+	// 加载 CA 证书
+	if cfg.CAFile != "" {
+		ca, err := os.ReadFile(cfg.CAFile)
+		if err != nil {
+			return nil, fmt.Errorf("读取 CA 证书失败: %w", err)
+		}
+		certPool := x509.NewCertPool()
+		if !certPool.AppendCertsFromPEM(ca) {
+			return nil, fmt.Errorf("添加 CA 证书失败: %s 中没有有效的 PEM 证书", cfg.CAFile)
+		}
+		tlsConfig.RootCAs = certPool
+	}
+
+	// 加载客户端证书（双向认证）
+	if cfg.CertFile != "" || cfg.KeyFile != "" {
+		if cfg.CertFile == "" || cfg.KeyFile == "" {
+			return nil, fmt.Errorf("客户端证书和私钥必须同时配置")
+		}
+		cert, err := tls.LoadX509KeyPair(cfg.CertFile, cfg.KeyFile)
+		if err != nil {
+			return nil, fmt.Errorf("加载客户端证书失败: %w", err)
+		}
+		tlsConfig.Certificates = []tls.Certificate{cert}
+	}
+
+	return tlsConfig, nil
+}
@@ -20,15 +20,21 @@
 
 	"github.com/gorilla/websocket"
 	"github.com/kamalyes/go-stress/config"
+	"github.com/kamalyes/go-toolbox/pkg/mathx"
+	"github.com/oliveagle/jsonpath"
 )
 
 // WebSocketClient WebSocket 客户端
+// 请求模式在长连接上一发一收；会话模式每次请求建立新连接并持续接收服务端推送（见 websocket_session.go）
 type WebSocketClient struct {
-	config  *config.Config
-	conn    *websocket.Conn
-	dialer  *websocket.Dialer
-	headers http.Header
-	mu      sync.Mutex // 保护并发读写
+	config      *config.Config
+	wsConfig    *config.WebSocketConfig
+	conn        *websocket.Conn
+	dialer      *websocket.Dialer
+	headers     http.Header
+	correlation *jsonpath.Compiled // 会话模式的关联ID路径
+	stopPing    chan struct{}      // 停止请求模式的心跳协程
+	mu          sync.Mutex         // 保护并发读写
 }
 
 // NewWebSocketClient 创建 WebSocket 客户端
@@ -64,21 +70,87 @@ func NewWebSocketClient(cfg *config.Config) (Client, error) {
 	}
 
 	dialer := &websocket.Dialer{
-		HandshakeTimeout: time.Duration(cfg.Timeout) * time.Second,
+		HandshakeTimeout: cfg.Timeout,
 		Proxy:            http.ProxyFromEnvironment,
 	}
 
+	wsConfig := cfg.WebSocket
+	if wsConfig == nil {
+		wsConfig = &config.WebSocketConfig{}
+	}
+
 	client := &WebSocketClient{
-		config:  cfg,
-		dialer:  dialer,
-		headers: headers,
+		config:   cfg,
+		wsConfig: wsConfig,
+		dialer:   dialer,
+		headers:  headers,
+	}
+
+	if wsConfig.CorrelationField != "" {
+		client.correlation, err = jsonpath.Compile(wsConfig.CorrelationField)
+		if err != nil {
+			return nil, fmt.Errorf("invalid websocket correlation_field: %w", err)
+		}
 	}
 
 	return client, nil
 }
 
-// Connect 建立 WebSocket 连接
+// isSession 是否为会话模式
+func (c *WebSocketClient) isSession() bool {
+	return c.wsConfig.Mode == config.WebSocketModeSession
+}
+
+// setConn 保存请求模式的长连接并启动心跳（调用方持有锁）
+func (c *WebSocketClient) setConn(conn *websocket.Conn) {
+	c.conn = conn
+	if c.wsConfig.PingInterval > 0 {
+		c.stopPing = make(chan struct{})
+		go keepalive(conn, c.wsConfig.PingInterval, c.wsConfig.PingTimeout, c.stopPing)
+	}
+}
+
+// closeConn 停止心跳并关闭请求模式的长连接（调用方持有锁）
+func (c *WebSocketClient) closeConn() error {
+	if c.stopPing != nil {
+		close(c.stopPing)
+		c.stopPing = nil
+	}
+	if c.conn == nil {
+		return nil
+	}
+	err := c.conn.Close()
+	c.conn = nil
+	return err
+}
+
+// keepalive 按间隔发送 ping，直到 done 关闭或发送失败，返回发送的 ping 数
+// WriteControl 可与其他写操作并发调用
+func keepalive(conn *websocket.Conn, interval, timeout time.Duration, done <-chan struct{}) int {
+	timeout = mathx.IfNotZero(timeout, interval)
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+
+	pings := 0
+	for {
+		select {
+		case <-done:
+			return pings
+		case <-ticker.C:
+			if err := conn.WriteControl(websocket.PingMessage, nil, time.Now().Add(timeout)); err != nil {
+				return pings
+			}
+			pings++
+		}
+	}
+}
+
+// Connect 建立 WebSocket 连接（会话模式在每次请求时建立连接）
 func (c *WebSocketClient) Connect(ctx context.Context) error {
+	if c.isSession() {
+		return nil
+	}
+
 	c.mu.Lock()
 	defer c.mu.Unlock()
 
@@ -90,7 +162,7 @@ func (c *WebSocketClient) Connect(ctx context.Context) error {
 	if err != nil {
 		return fmt.Errorf("websocket dial failed: %w", err)
 	}
-	c.conn = conn
+	c.setConn(conn)
 
 	if httpResp != nil {
 		httpResp.Body.Close()
@@ -101,6 +173,10 @@ func (c *WebSocketClient) Connect(ctx context.Context) error {
 
 // Send 发送 WebSocket 请求
 func (c *WebSocketClient) Send(ctx context.Context, req *Request) (*Response, error) {
+	if c.isSession() {
+		return c.runSession(ctx, req)
+	}
+
 	c.mu.Lock()
 	defer c.mu.Unlock()
 
@@ -112,7 +188,7 @@ func (c *WebSocketClient) Send(ctx context.Context, req *Request) (*Response, er
 		if err != nil {
 			return nil, fmt.Errorf("websocket dial failed: %w", err)
 		}
-		c.conn = conn
+		c.setConn(conn)
 		if httpResp != nil {
 			httpResp.Body.Close()
 		}
@@ -128,10 +204,7 @@ func (c *WebSocketClient) Send(ctx context.Context, req *Request) (*Response, er
 
 	if err := c.conn.WriteMessage(messageType, []byte(req.Body)); err != nil {
 		// 连接断开,关闭并返回错误
-		if c.conn != nil {
-			c.conn.Close()
-			c.conn = nil
-		}
+		c.closeConn()
 		return nil, fmt.Errorf("websocket write failed: %w", err)
 	}
 
@@ -141,10 +214,7 @@ func (c *WebSocketClient) Send(ctx context.Context, req *Request) (*Response, er
 		// 检查是否为正常关闭或异常关闭
 		if websocket.IsCloseError(err, websocket.CloseNormalClosure, websocket.CloseGoingAway, websocket.CloseAbnormalClosure) {
 			// WebSocket 连接已关闭，这是正常情况（特别是在长连接测试中）
-			if c.conn != nil {
-				c.conn.Close()
-				c.conn = nil
-			}
+			c.closeConn()
 
 			duration := time.Since(startTime)
 			headers := make(map[string]string)
@@ -167,10 +237,7 @@ func (c *WebSocketClient) Send(ctx context.Context, req *Request) (*Response, er
 		}
 
 		// 其他错误才视为失败
-		if c.conn != nil {
-			c.conn.Close()
-			c.conn = nil
-		}
+		c.closeConn()
 		return nil, fmt.Errorf("websocket read failed: %w", err)
 	}
 
@@ -208,10 +275,5 @@ func (c *WebSocketClient) Close() error {
 	c.mu.Lock()
 	defer c.mu.Unlock()
 
-	if c.conn != nil {
-		err := c.conn.Close()
-		c.conn = nil
-		return err
-	}
-	return nil
+	return c.closeConn()
 }
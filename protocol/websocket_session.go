@@ -0,0 +1,330 @@
+/*
+ * @Author: kamalyes 501893067@qq.com
+ * @Date: 2026-02-14 00:00:00
+ * @LastEditors: kamalyes 501893067@qq.com
+ * @LastEditTime: 2026-02-14 00:00:00
+ * @FilePath: \go-stress\protocol\websocket_session.go
+ * @Description: WebSocket 会话模式 - 保持连接、心跳、脚本发送、接收推送消息和关联ID往返延迟
+ *
+ * Copyright (c) 2026 by kamalyes, All Rights Reserved.
+ */
+package protocol
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net"
+	"net/http"
+	"sync"
+	"time"
+
+	"github.com/gorilla/websocket"
+	"github.com/kamalyes/go-toolbox/pkg/convert"
+	"github.com/kamalyes/go-toolbox/pkg/mathx"
+)
+
+// 会话断开原因（服务端关闭时为 server_close_<关闭码>）
+const (
+	CloseReasonHold        = "hold_expired" // 到达保持时长主动断开
+	CloseReasonCanceled    = "canceled"     // 压测结束或被取消
+	CloseReasonPingTimeout = "ping_timeout" // 心跳超时未收到任何数据
+	CloseReasonReadError   = "read_error"   // 读取失败（连接被重置等）
+	CloseReasonWriteError  = "write_error"  // 发送脚本消息失败
+	CloseReasonDialFailed  = "dial_failed"  // 建立连接失败
+)
+
+// isNormalClose 断开原因是否视为正常结束
+func isNormalClose(reason string) bool {
+	switch reason {
+	case CloseReasonHold, CloseReasonCanceled,
+		serverCloseReason(websocket.CloseNormalClosure), serverCloseReason(websocket.CloseGoingAway):
+		return true
+	}
+	return false
+}
+
+// serverCloseReason 服务端关闭的断开原因
+func serverCloseReason(code int) string {
+	return fmt.Sprintf("server_close_%d", code)
+}
+
+// closeReasonOf 根据读取错误判断断开原因
+func closeReasonOf(err error) string {
+	var closeErr *websocket.CloseError
+	if errors.As(err, &closeErr) {
+		return serverCloseReason(closeErr.Code)
+	}
+	var netErr net.Error
+	if errors.As(err, &netErr) && netErr.Timeout() {
+		return CloseReasonPingTimeout
+	}
+	return CloseReasonReadError
+}
+
+// parseWebSocketMessages 解析会话脚本消息：请求体为 JSON 数组时每个元素是一条消息（字符串元素按原文发送），否则整个请求体为一条消息
+func parseWebSocketMessages(body string) [][]byte {
+	trimmed := bytes.TrimSpace([]byte(body))
+	if len(trimmed) == 0 {
+		return nil
+	}
+	var raws []json.RawMessage
+	if trimmed[0] != '[' || json.Unmarshal(trimmed, &raws) != nil || len(raws) == 0 {
+		return [][]byte{[]byte(body)}
+	}
+
+	messages := make([][]byte, 0, len(raws))
+	for _, raw := range raws {
+		var text string
+		if json.Unmarshal(raw, &text) == nil {
+			messages = append(messages, []byte(text))
+			continue
+		}
+		messages = append(messages, raw)
+	}
+	return messages
+}
+
+// wsSession 单个 WebSocket 会话
+type wsSession struct {
+	client  *WebSocketClient
+	conn    *websocket.Conn
+	mu      sync.Mutex             // 保护 pending 和 reason
+	pending map[string][]time.Time // 关联ID -> 未匹配的发送时间
+	reason  string                 // 主动断开的原因
+}
+
+// closeWith 记录断开原因（仅首次生效）并关闭连接，使接收循环结束
+func (s *wsSession) closeWith(reason string) {
+	s.mu.Lock()
+	if s.reason != "" {
+		s.mu.Unlock()
+		return
+	}
+	s.reason = reason
+	s.mu.Unlock()
+
+	msg := websocket.FormatCloseMessage(websocket.CloseNormalClosure, "")
+	_ = s.conn.WriteControl(websocket.CloseMessage, msg, time.Now().Add(time.Second))
+	_ = s.conn.Close()
+}
+
+// closeReason 主动断开的原因（未主动断开时为空）
+func (s *wsSession) closeReason() string {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return s.reason
+}
+
+// correlationID 提取消息中的关联ID
+func (s *wsSession) correlationID(data []byte) (string, bool) {
+	if s.client.correlation == nil {
+		return "", false
+	}
+	var v any
+	if err := json.Unmarshal(data, &v); err != nil {
+		return "", false
+	}
+	id, err := s.client.correlation.Lookup(v)
+	if err != nil || id == nil {
+		return "", false
+	}
+	return convert.MustString(id), true
+}
+
+// markSent 记录带关联ID消息的发送时间
+func (s *wsSession) markSent(data []byte) {
+	id, ok := s.correlationID(data)
+	if !ok {
+		return
+	}
+	s.mu.Lock()
+	s.pending[id] = append(s.pending[id], time.Now())
+	s.mu.Unlock()
+}
+
+// matchReceived 匹配最早发送的同ID消息，返回往返延迟
+func (s *wsSession) matchReceived(data []byte) (time.Duration, bool) {
+	id, ok := s.correlationID(data)
+	if !ok {
+		return 0, false
+	}
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	sentAt := s.pending[id]
+	if len(sentAt) == 0 {
+		return 0, false
+	}
+	if len(sentAt) == 1 {
+		delete(s.pending, id)
+	} else {
+		s.pending[id] = sentAt[1:]
+	}
+	return time.Since(sentAt[0]), true
+}
+
+// send 按间隔循环发送 count 条脚本消息，done 关闭时提前结束，返回发送成功的消息数
+func (s *wsSession) send(messages [][]byte, count int, interval time.Duration, done <-chan struct{}) int {
+	sent := 0
+	for i := 0; i < count; i++ {
+		if i > 0 && interval > 0 {
+			select {
+			case <-done:
+				return sent
+			case <-time.After(interval):
+			}
+		}
+		msg := messages[i%len(messages)]
+		s.markSent(msg)
+		if err := s.conn.WriteMessage(websocket.TextMessage, msg); err != nil {
+			s.closeWith(CloseReasonWriteError)
+			return sent
+		}
+		sent++
+	}
+	return sent
+}
+
+// runSession 运行一个会话：建立连接 -> 心跳 + 脚本发送 -> 持续接收直到保持时长到期/服务端断开/心跳超时
+// 到达请求的截止时间（按时间运行的压测结束）时与取消一样主动断开
+// 响应体为最后一条接收消息，响应头为握手响应头，耗时为建立连接到断开的总时长
+func (c *WebSocketClient) runSession(ctx context.Context, req *Request) (*Response, error) {
+	if !req.Deadline.IsZero() {
+		var cancel context.CancelFunc
+		ctx, cancel = context.WithDeadline(ctx, req.Deadline)
+		defer cancel()
+	}
+	cfg := c.wsConfig
+	stats := &WebSocketStats{}
+	headers := c.headers.Clone()
+	for k, v := range req.Headers {
+		headers.Set(k, v)
+	}
+	resp := &Response{
+		RequestURL:     c.config.URL,
+		RequestMethod:  "WEBSOCKET",
+		RequestHeaders: firstHeaderValues(headers),
+		RequestBody:    req.Body,
+		Protocol:       ProtocolWebSocket,
+		WebSocket:      stats,
+	}
+
+	startTime := time.Now()
+	conn, httpResp, err := c.dialer.DialContext(ctx, c.config.URL, headers)
+	stats.Connect = time.Since(startTime)
+	if httpResp != nil {
+		resp.StatusCode = httpResp.StatusCode
+		resp.Headers = firstHeaderValues(httpResp.Header)
+		httpResp.Body.Close()
+	}
+	if err != nil {
+		stats.CloseReason = CloseReasonDialFailed
+		resp.Duration = time.Since(startTime)
+		return resp, fmt.Errorf("websocket dial failed: %w", err)
+	}
+	// 与请求模式一致，握手成功的会话状态码为 200
+	resp.StatusCode = 200
+
+	session := &wsSession{client: c, conn: conn, pending: make(map[string][]time.Time)}
+	done := make(chan struct{})
+	var wg sync.WaitGroup
+
+	// 心跳：超过 ping间隔+ping超时 未收到任何数据（含 pong）判定连接失效
+	var pings int
+	idle := cfg.PingInterval + mathx.IfNotZero(cfg.PingTimeout, cfg.PingInterval)
+	if cfg.PingInterval > 0 {
+		_ = conn.SetReadDeadline(time.Now().Add(idle))
+		conn.SetPongHandler(func(string) error {
+			return conn.SetReadDeadline(time.Now().Add(idle))
+		})
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			pings = keepalive(conn, cfg.PingInterval, cfg.PingTimeout, done)
+		}()
+	}
+
+	// 脚本发送
+	var sent int
+	if messages := parseWebSocketMessages(req.Body); len(messages) > 0 {
+		count := mathx.IfNotZero(cfg.Messages, len(messages))
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			sent = session.send(messages, count, cfg.Interval, done)
+		}()
+	}
+
+	// 保持时长到期、压测取消或到达截止时间时主动断开
+	var hold <-chan time.Time
+	if cfg.Hold > 0 {
+		timer := time.NewTimer(cfg.Hold)
+		defer timer.Stop()
+		hold = timer.C
+	}
+	wg.Add(1)
+	go func() {
+		defer wg.Done()
+		select {
+		case <-hold:
+			session.closeWith(CloseReasonHold)
+		case <-ctx.Done():
+			session.closeWith(CloseReasonCanceled)
+		case <-done:
+		}
+	}()
+
+	// 接收直到连接断开
+	var (
+		last    []byte
+		readErr error
+	)
+	for {
+		_, data, err := conn.ReadMessage()
+		if err != nil {
+			readErr = err
+			break
+		}
+		if cfg.PingInterval > 0 {
+			_ = conn.SetReadDeadline(time.Now().Add(idle))
+		}
+		stats.Received++
+		if rtt, ok := session.matchReceived(data); ok {
+			stats.RoundTrips = append(stats.RoundTrips, rtt)
+		}
+		last = data
+	}
+	stats.Lifetime = time.Since(startTime) - stats.Connect
+
+	reason := session.closeReason()
+	if reason == "" {
+		reason = closeReasonOf(readErr)
+		_ = conn.Close()
+	}
+	close(done)
+	wg.Wait()
+	stats.Sent = sent
+	stats.Pings = pings
+	stats.CloseReason = reason
+
+	resp.Body = last
+	resp.Duration = time.Since(startTime)
+	if !isNormalClose(reason) {
+		resp.Error = fmt.Errorf("websocket会话异常断开(%s): %w", reason, readErr)
+		return resp, resp.Error
+	}
+	return resp, nil
+}
+
+// firstHeaderValues 将 http.Header 转换为 map（每个头取第一个值）
+func firstHeaderValues(header http.Header) map[string]string {
+	headers := make(map[string]string, len(header))
+	for k, v := range header {
+		if len(v) > 0 {
+			headers[k] = v[0]
+		}
+	}
+	return headers
+}
@@ -0,0 +1,142 @@
+/*
+ * @Author: kamalyes 501893067@qq.com
+ * @Date: 2026-02-14 00:00:00
+ * @LastEditors: kamalyes 501893067@qq.com
+ * @LastEditTime: 2026-02-14 00:00:00
+ * @FilePath: \go-stress\protocol\websocket_session_test.go
+ * @Description: WebSocket 会话模式测试
+ *
+ * Copyright (c) 2026 by kamalyes, All Rights Reserved.
+ */
+package protocol
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/gorilla/websocket"
+	"github.com/kamalyes/go-stress/config"
+	"github.com/stretchr/testify/assert"
+)
+
+// startWebSocketServer 启动测试服务：按 handler 处理每个连接
+func startWebSocketServer(t *testing.T, handler func(conn *websocket.Conn)) string {
+	upgrader := websocket.Upgrader{}
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		conn, err := upgrader.Upgrade(w, r, http.Header{"X-Session": {r.Header.Get("X-User")}})
+		if err != nil {
+			return
+		}
+		defer conn.Close()
+		handler(conn)
+	}))
+	t.Cleanup(srv.Close)
+	return "ws" + strings.TrimPrefix(srv.URL, "http")
+}
+
+// runWebSocketSession 以会话模式发送一次请求
+func runWebSocketSession(t *testing.T, url string, ws *config.WebSocketConfig, req *Request) (*Response, error) {
+	ws.Mode = config.WebSocketModeSession
+	client, err := NewWebSocketClient(&config.Config{URL: url, Timeout: time.Second, WebSocket: ws})
+	assert.NoError(t, err)
+	assert.NoError(t, client.Connect(context.Background()))
+	defer client.Close()
+	return client.Send(context.Background(), req)
+}
+
+// 测试会话模式：推送消息计数、关联ID往返延迟、心跳和保持时长
+func TestWebSocketSession(t *testing.T) {
+	url := startWebSocketServer(t, func(conn *websocket.Conn) {
+		var mu sync.Mutex
+		write := func(data []byte) error {
+			mu.Lock()
+			defer mu.Unlock()
+			return conn.WriteMessage(websocket.TextMessage, data)
+		}
+		// 服务端推送
+		go func() {
+			for write([]byte(`{"type":"push"}`)) == nil {
+				time.Sleep(10 * time.Millisecond)
+			}
+		}()
+		// 原样回显（读取时自动回复 pong）
+		for {
+			_, data, err := conn.ReadMessage()
+			if err != nil {
+				return
+			}
+			_ = write(data)
+		}
+	})
+
+	resp, err := runWebSocketSession(t, url, &config.WebSocketConfig{
+		Hold:             150 * time.Millisecond,
+		Interval:         10 * time.Millisecond,
+		PingInterval:     20 * time.Millisecond,
+		CorrelationField: "$.id",
+	}, &Request{Body: `[{"id":1,"op":"sub"},{"id":2,"op":"sub"}]`, Headers: map[string]string{"X-User": "alice"}})
+	assert.NoError(t, err)
+	assert.Equal(t, 200, resp.StatusCode)
+	assert.Equal(t, "alice", resp.Headers["X-Session"], "响应头为握手响应头")
+
+	stats := resp.WebSocket
+	assert.Equal(t, CloseReasonHold, stats.CloseReason)
+	assert.Equal(t, 2, stats.Sent)
+	assert.Len(t, stats.RoundTrips, 2)
+	assert.Greater(t, stats.Received, 5, "推送消息和回显都计入接收数")
+	assert.Greater(t, stats.Pings, 0)
+	assert.Greater(t, stats.Connect, time.Duration(0))
+	assert.GreaterOrEqual(t, stats.Lifetime, 150*time.Millisecond)
+	assert.GreaterOrEqual(t, resp.Duration, stats.Connect+stats.Lifetime)
+}
+
+// 测试会话模式的异常断开原因
+func TestWebSocketSessionCloseReasons(t *testing.T) {
+	// 服务端主动以 1011 关闭
+	url := startWebSocketServer(t, func(conn *websocket.Conn) {
+		_ = conn.WriteMessage(websocket.TextMessage, []byte("hello"))
+		msg := websocket.FormatCloseMessage(websocket.CloseInternalServerErr, "boom")
+		_ = conn.WriteControl(websocket.CloseMessage, msg, time.Now().Add(time.Second))
+		_, _, _ = conn.ReadMessage()
+	})
+	resp, err := runWebSocketSession(t, url, &config.WebSocketConfig{}, &Request{})
+	assert.Error(t, err)
+	assert.Equal(t, "server_close_1011", resp.WebSocket.CloseReason)
+	assert.Equal(t, 1, resp.WebSocket.Received)
+	assert.Equal(t, "hello", string(resp.Body))
+
+	// 服务端正常关闭视为成功
+	url = startWebSocketServer(t, func(conn *websocket.Conn) {
+		msg := websocket.FormatCloseMessage(websocket.CloseNormalClosure, "")
+		_ = conn.WriteControl(websocket.CloseMessage, msg, time.Now().Add(time.Second))
+		_, _, _ = conn.ReadMessage()
+	})
+	resp, err = runWebSocketSession(t, url, &config.WebSocketConfig{}, &Request{})
+	assert.NoError(t, err)
+	assert.Equal(t, "server_close_1000", resp.WebSocket.CloseReason)
+
+	// 服务端不读取数据（不回复 pong）导致心跳超时
+	url = startWebSocketServer(t, func(conn *websocket.Conn) {
+		time.Sleep(time.Second)
+	})
+	resp, err = runWebSocketSession(t, url, &config.WebSocketConfig{
+		PingInterval: 20 * time.Millisecond,
+		PingTimeout:  20 * time.Millisecond,
+	}, &Request{})
+	assert.Error(t, err)
+	assert.Equal(t, CloseReasonPingTimeout, resp.WebSocket.CloseReason)
+	assert.Less(t, resp.Duration, time.Second)
+}
+
+// 测试会话脚本消息解析
+func TestParseWebSocketMessages(t *testing.T) {
+	assert.Nil(t, parseWebSocketMessages("  "))
+	assert.Equal(t, [][]byte{[]byte("ping")}, parseWebSocketMessages("ping"))
+	assert.Equal(t, [][]byte{[]byte(`[1,`)}, parseWebSocketMessages(`[1,`), "非法数组按原文发送")
+	assert.Equal(t, [][]byte{[]byte(`{"a":1}`), []byte("text")}, parseWebSocketMessages(`[{"a":1}, "text"]`))
+}
@@ -18,6 +18,10 @@
 // 类型别名 - 从 types 包导入
 type (
 	RequestResult      = types.RequestResult
+	PhaseTimings       = types.PhaseTimings
+	StreamStats        = types.StreamStats
+	WebSocketStats     = types.WebSocketStats
+	MQTTStats          = types.MQTTStats
 	Statistics         = types.Statistics
 	VerificationResult = types.VerificationResult
 	RunMode            = types.RunMode
@@ -31,10 +35,14 @@
 // 函数别名
 var (
 	ParseStatusFilter = storage.ParseStatusFilter
+	StatusCodeLabel   = types.StatusCodeLabel
+	APIStatsKey       = types.APIStatsKey
 )
 
 // 常量别名
 const (
+	MQTTConnectAccepted = types.MQTTConnectAccepted
+
 	// 存储模式
 	StorageModeMemory = types.StorageModeMemory
 	StorageModeSQLite = types.StorageModeSQLite
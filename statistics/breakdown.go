@@ -0,0 +1,211 @@
+/*
+ * @Author: kamalyes 501893067@qq.com
+ * @Date: 2026-02-05 00:00:00
+ * @LastEditors: kamalyes 501893067@qq.com
+ * @LastEditTime: 2026-02-05 00:00:00
+ * @FilePath: \go-stress\statistics\breakdown.go
+ * @Description: 分组统计 - 按接口(API)、场景和负载阶段拆分的计数、延迟分布、错误和状态码
+ *
+ * Copyright (c) 2026 by kamalyes, All Rights Reserved.
+ */
+package statistics
+
+import (
+	"encoding/json"
+	"sort"
+	"time"
+
+	"github.com/kamalyes/go-toolbox/pkg/mathx"
+)
+
+// defaultAPIName 未命名接口（单URL模式）的分组名称
+const defaultAPIName = "default"
+
+// breakdownStats 单个分组的累计数据（由 Collector 在写锁内更新）
+type breakdownStats struct {
+	name          string
+	index         int // 分组序号（阶段序号，接口分组不使用）
+	total         uint64
+	success       uint64
+	failed        uint64
+	skipped       uint64
+	totalDuration time.Duration
+	totalSize     float64
+	latencies     *Histogram
+	errors        map[string]uint64
+	statusCodes   map[int]uint64
+	firstSeen     time.Time
+	lastSeen      time.Time
+	details       *scenarioDetails // 场景明细（仅场景分组）
+}
+
+// newBreakdownStats 创建分组累计数据
+func newBreakdownStats(name string, index int) *breakdownStats {
+	return &breakdownStats{
+		name:        name,
+		index:       index,
+		latencies:   NewHistogram(),
+		errors:      make(map[string]uint64),
+		statusCodes: make(map[int]uint64),
+	}
+}
+
+// record 记录一次请求结果
+func (b *breakdownStats) record(result *RequestResult, now time.Time) {
+	b.total++
+	if b.firstSeen.IsZero() {
+		b.firstSeen = now
+	}
+	b.lastSeen = now
+
+	switch {
+	case result.Skipped:
+		b.skipped++
+	case result.Success:
+		b.success++
+	default:
+		b.failed++
+		if result.Error != nil {
+			b.errors[result.Error.Error()]++
+		}
+	}
+	if result.HasStatusCode() {
+		b.statusCodes[result.StatusCode]++
+	}
+
+	// 跳过的请求没有实际耗时，不计入延迟分布
+	if !result.Skipped {
+		b.totalDuration += result.Duration
+		b.latencies.Record(result.Duration)
+	}
+	b.totalSize += result.Size
+}
+
+// snapshot 生成分组统计快照（调用方持有读锁）
+// elapsed 为整体运行时长，接口和场景分组按其计算 QPS；阶段分组按阶段内首末请求时间计算
+func (b *breakdownStats) snapshot(elapsed time.Duration) *BreakdownStats {
+	stats := &BreakdownStats{
+		Name:            b.name,
+		Index:           b.index,
+		TotalRequests:   b.total,
+		SuccessRequests: b.success,
+		FailedRequests:  b.failed,
+		SkippedRequests: b.skipped,
+		TotalSize:       b.totalSize,
+		Errors:          mapCopy(b.errors),
+		StatusCodes:     mapCopy(b.statusCodes),
+	}
+	if b.details != nil {
+		b.details.apply(stats, elapsed)
+	}
+	if b.total == 0 {
+		return stats
+	}
+
+	stats.SuccessRate = mathx.Percentage(b.success, b.total)
+	if elapsed > 0 {
+		stats.QPS = float64(b.total) / elapsed.Seconds()
+	}
+
+	if count := b.latencies.Count(); count > 0 {
+		stats.AvgLatency = b.totalDuration / time.Duration(count)
+		stats.MinLatency = b.latencies.Min()
+		stats.MaxLatency = b.latencies.Max()
+		percentiles := b.latencies.Percentiles(50, 90, 95, 99)
+		stats.P50Latency = percentiles[50]
+		stats.P90Latency = percentiles[90]
+		stats.P95Latency = percentiles[95]
+		stats.P99Latency = percentiles[99]
+	}
+	return stats
+}
+
+// BreakdownStats 分组统计（按接口、场景或负载阶段）
+type BreakdownStats struct {
+	Name            string            `json:"name"`
+	Index           int               `json:"index,omitempty"` // 阶段序号（从1开始，仅阶段分组）
+	TotalRequests   uint64            `json:"total_requests"`
+	SuccessRequests uint64            `json:"success_requests"`
+	FailedRequests  uint64            `json:"failed_requests"`
+	SkippedRequests uint64            `json:"skipped_requests"`
+	SuccessRate     float64           `json:"success_rate"` // 百分比 0-100
+	QPS             float64           `json:"qps"`
+	AvgLatency      time.Duration     `json:"avg_latency"`
+	MinLatency      time.Duration     `json:"min_latency"`
+	MaxLatency      time.Duration     `json:"max_latency"`
+	P50Latency      time.Duration     `json:"p50_latency"`
+	P90Latency      time.Duration     `json:"p90_latency"`
+	P95Latency      time.Duration     `json:"p95_latency"`
+	P99Latency      time.Duration     `json:"p99_latency"`
+	TotalSize       float64           `json:"total_size"` // 字节数
+	Errors          map[string]uint64 `json:"errors,omitempty"`
+	StatusCodes     map[int]uint64    `json:"status_codes,omitempty"`
+
+	// 场景分组额外输出与全局报告相同的时间序列、请求阶段耗时和协议统计（多场景模式）
+	TimeSeries []*TimePoint        `json:"time_series,omitempty"`
+	Phases     *PhaseBreakdown     `json:"phases,omitempty"`
+	Streams    *StreamBreakdown    `json:"streams,omitempty"`
+	WebSocket  *WebSocketBreakdown `json:"websocket,omitempty"`
+	MQTT       *MQTTBreakdown      `json:"mqtt,omitempty"`
+}
+
+// MarshalJSON 自定义JSON序列化，将time.Duration转换为毫秒（与 Report 保持一致）
+func (b *BreakdownStats) MarshalJSON() ([]byte, error) {
+	type Alias BreakdownStats
+	return json.Marshal(&struct {
+		*Alias
+		AvgLatency float64 `json:"avg_latency"`
+		MinLatency float64 `json:"min_latency"`
+		MaxLatency float64 `json:"max_latency"`
+		P50Latency float64 `json:"p50_latency"`
+		P90Latency float64 `json:"p90_latency"`
+		P95Latency float64 `json:"p95_latency"`
+		P99Latency float64 `json:"p99_latency"`
+	}{
+		Alias:      (*Alias)(b),
+		AvgLatency: float64(b.AvgLatency.Microseconds()) / 1000.0,
+		MinLatency: float64(b.MinLatency.Microseconds()) / 1000.0,
+		MaxLatency: float64(b.MaxLatency.Microseconds()) / 1000.0,
+		P50Latency: float64(b.P50Latency.Microseconds()) / 1000.0,
+		P90Latency: float64(b.P90Latency.Microseconds()) / 1000.0,
+		P95Latency: float64(b.P95Latency.Microseconds()) / 1000.0,
+		P99Latency: float64(b.P99Latency.Microseconds()) / 1000.0,
+	})
+}
+
+// buildNamedBreakdown 构建按接口或场景分组的统计（按名称排序，调用方持有读锁）
+func buildNamedBreakdown(groups map[string]*breakdownStats, elapsed time.Duration) []*BreakdownStats {
+	if len(groups) == 0 {
+		return nil
+	}
+	result := make([]*BreakdownStats, 0, len(groups))
+	for _, b := range groups {
+		result = append(result, b.snapshot(elapsed))
+	}
+	sort.Slice(result, func(i, j int) bool { return result[i].Name < result[j].Name })
+	return result
+}
+
+// buildStageBreakdown 构建按阶段分组的统计（按阶段顺序，调用方持有读锁）
+func buildStageBreakdown(stages []*breakdownStats) []*BreakdownStats {
+	if len(stages) == 0 {
+		return nil
+	}
+	result := make([]*BreakdownStats, 0, len(stages))
+	for _, b := range stages {
+		result = append(result, b.snapshot(b.lastSeen.Sub(b.firstSeen)))
+	}
+	return result
+}
+
+// mapCopy 复制 map（避免报告与收集器共享可变数据）
+func mapCopy[K comparable, V any](m map[K]V) map[K]V {
+	if len(m) == 0 {
+		return nil
+	}
+	result := make(map[K]V, len(m))
+	for k, v := range m {
+		result[k] = v
+	}
+	return result
+}
@@ -0,0 +1,162 @@
+/*
+ * @Author: kamalyes 501893067@qq.com
+ * @Date: 2026-02-05 00:00:00
+ * @LastEditors: kamalyes 501893067@qq.com
+ * @LastEditTime: 2026-02-05 00:00:00
+ * @FilePath: \go-stress\statistics\breakdown_test.go
+ * @Description: 分组统计测试
+ *
+ * Copyright (c) 2026 by kamalyes, All Rights Reserved.
+ */
+package statistics
+
+import (
+	"encoding/json"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/kamalyes/go-stress/logger"
+	"github.com/kamalyes/go-stress/storage"
+	"github.com/kamalyes/go-stress/types"
+	"github.com/stretchr/testify/assert"
+)
+
+// 测试按接口、场景和阶段拆分统计
+func TestCollectorBreakdown(t *testing.T) {
+	c := NewCollector(storage.NewMemoryStorage("test", logger.Default), logger.Default)
+	defer c.Close()
+
+	c.SetStage(&StageInfo{Index: 1, Total: 2, Name: "warmup"})
+	for i := 0; i < 10; i++ {
+		c.Collect(&RequestResult{APIName: "login", Scenario: "browser", Success: true, StatusCode: 200, Duration: 10 * time.Millisecond})
+	}
+
+	c.SetStage(&StageInfo{Index: 2, Total: 2})
+	for i := 0; i < 5; i++ {
+		c.Collect(&RequestResult{APIName: "search", Scenario: "buyer", Success: false, StatusCode: 500, Duration: 200 * time.Millisecond, Error: errors.New("boom")})
+	}
+	c.Collect(&RequestResult{APIName: "search", Scenario: "buyer", Skipped: true})
+	c.Collect(&RequestResult{APIName: "login", Scenario: "buyer", Success: true, StatusCode: 200, Duration: 10 * time.Millisecond})
+
+	report := NewReportBuilder(c).BuildSummary(time.Second)
+
+	// 多场景模式下同名接口按 场景/接口 分别统计
+	assert.Len(t, report.APIStats, 3)
+	login, buyerLogin, search := report.APIStats[0], report.APIStats[1], report.APIStats[2]
+	assert.Equal(t, "browser/login", login.Name)
+	assert.Equal(t, uint64(10), login.SuccessRequests)
+	assert.InDelta(t, 10*time.Millisecond, login.P99Latency, float64(time.Millisecond))
+	assert.Equal(t, "buyer/login", buyerLogin.Name)
+	assert.Equal(t, uint64(1), buyerLogin.SuccessRequests)
+
+	stats, hist := c.GetAPIStats(APIStatsKey("buyer", "login"))
+	assert.Equal(t, uint64(1), stats.TotalRequests)
+	assert.Equal(t, uint64(1), hist.Count())
+	stats, _ = c.GetAPIStats("login")
+	assert.Nil(t, stats)
+	stats, _ = c.GetScenarioStats("buyer")
+	assert.Equal(t, uint64(7), stats.TotalRequests)
+
+	assert.Equal(t, "buyer/search", search.Name)
+	assert.Equal(t, uint64(6), search.TotalRequests)
+	assert.Equal(t, uint64(5), search.FailedRequests)
+	assert.Equal(t, uint64(1), search.SkippedRequests)
+	assert.Equal(t, uint64(5), search.Errors["boom"])
+	assert.Equal(t, uint64(5), search.StatusCodes[500])
+	assert.InDelta(t, 200*time.Millisecond, search.P50Latency, float64(2*time.Millisecond))
+
+	assert.Len(t, report.StageStats, 2)
+	assert.Equal(t, "warmup", report.StageStats[0].Name)
+	assert.Equal(t, "阶段 2", report.StageStats[1].Name)
+	assert.Equal(t, uint64(7), report.StageStats[1].TotalRequests)
+
+	assert.Len(t, report.ScenarioStats, 2)
+	browser, buyer := report.ScenarioStats[0], report.ScenarioStats[1]
+	assert.Equal(t, "browser", browser.Name)
+	assert.Equal(t, uint64(10), browser.TotalRequests)
+	assert.Equal(t, 100.0, browser.SuccessRate)
+	assert.Equal(t, "buyer", buyer.Name)
+	assert.Equal(t, uint64(7), buyer.TotalRequests)
+	assert.Equal(t, uint64(5), buyer.FailedRequests)
+	assert.Equal(t, uint64(1), buyer.SkippedRequests)
+	assert.Equal(t, uint64(5), buyer.Errors["boom"])
+	assert.InDelta(t, 7.0, buyer.QPS, 0.01)
+
+	// JSON 中的延迟以毫秒输出
+	data, err := json.Marshal(login)
+	assert.NoError(t, err)
+	assert.Contains(t, string(data), `"p99_latency":10`)
+}
+
+// 测试场景分组输出时间序列、请求阶段耗时和协议统计
+func TestCollectorScenarioDetails(t *testing.T) {
+	c := NewCollector(storage.NewMemoryStorage("test", logger.Default), logger.Default)
+	defer c.Close()
+
+	c.AddScenarioActiveWorkers("browser", 2)
+	c.AddScenarioActiveWorkers("mqtt", 1)
+	for i := 0; i < 4; i++ {
+		c.Collect(&RequestResult{APIName: "home", Scenario: "browser", Success: true, Duration: 20 * time.Millisecond, Phases: &PhaseTimings{
+			TTFB: 15 * time.Millisecond, Transfer: 5 * time.Millisecond, ConnReused: i > 0,
+		}})
+	}
+	c.Collect(&RequestResult{APIName: "pub", Scenario: "mqtt", Success: true, Protocol: types.ProtocolMQTT, Duration: time.Millisecond, MQTT: &MQTTStats{
+		ConnectCode: MQTTConnectAccepted, Published: 3,
+	}})
+	assert.Equal(t, int64(3), c.GetActiveWorkers())
+
+	report := NewReportBuilder(c).BuildSummary(time.Second)
+	assert.Len(t, report.ScenarioStats, 2)
+	browser, mqtt := report.ScenarioStats[0], report.ScenarioStats[1]
+
+	assert.Equal(t, uint64(4), browser.Phases.TracedRequests)
+	assert.Equal(t, 75.0, browser.Phases.ReuseRate)
+	assert.Nil(t, browser.MQTT)
+	assert.Len(t, browser.TimeSeries, 1)
+	assert.Equal(t, uint64(4), browser.TimeSeries[0].Requests)
+	assert.Equal(t, int64(2), browser.TimeSeries[0].ActiveWorkers)
+
+	assert.Nil(t, mqtt.Phases)
+	assert.Equal(t, uint64(3), mqtt.MQTT.Published)
+	assert.Equal(t, int64(1), mqtt.TimeSeries[0].ActiveWorkers)
+
+	// 全局统计仍包含所有场景，接口分组不输出场景明细
+	assert.Equal(t, uint64(4), report.Phases.TracedRequests)
+	assert.Equal(t, uint64(3), report.MQTT.Published)
+	assert.Equal(t, int64(3), report.TimeSeries[0].ActiveWorkers)
+	assert.Nil(t, report.APIStats[0].TimeSeries)
+
+	data, err := json.Marshal(browser)
+	assert.NoError(t, err)
+	assert.Contains(t, string(data), `"time_series":[`)
+	assert.Contains(t, string(data), `"phases":{`)
+
+	c.AddScenarioActiveWorkers("browser", -2)
+	c.AddScenarioActiveWorkers("mqtt", -1)
+	assert.Equal(t, int64(0), c.GetActiveWorkers())
+}
+
+// 测试 gRPC 状态码统计（OK 为 0 时同样计入）
+func TestCollectorGRPCStatusCodes(t *testing.T) {
+	c := NewCollector(storage.NewMemoryStorage("test", logger.Default), logger.Default)
+	defer c.Close()
+
+	for i := 0; i < 3; i++ {
+		c.Collect(&RequestResult{APIName: "rpc", Success: true, Protocol: types.ProtocolGRPC, Duration: time.Millisecond})
+	}
+	c.Collect(&RequestResult{APIName: "rpc", Success: false, Protocol: types.ProtocolGRPC, StatusCode: 14, Error: errors.New("unavailable")})
+	c.Collect(&RequestResult{APIName: "rpc", Skipped: true, Protocol: types.ProtocolGRPC})
+	// HTTP 未收到响应时状态码为 0，不计入
+	c.Collect(&RequestResult{APIName: "http", Success: false, Error: errors.New("dial failed")})
+
+	report := NewReportBuilder(c).BuildSummary(time.Second)
+	assert.Equal(t, map[int]uint64{0: 3, 14: 1}, report.StatusCodes)
+	for _, api := range report.APIStats {
+		if api.Name == "rpc" {
+			assert.Equal(t, map[int]uint64{0: 3, 14: 1}, api.StatusCodes)
+		}
+	}
+	assert.Equal(t, "UNAVAILABLE", StatusCodeLabel(14))
+	assert.Equal(t, "404", StatusCodeLabel(404))
+}
@@ -11,6 +11,7 @@
 package statistics
 
 import (
+	"fmt"
 	"time"
 
 	"github.com/kamalyes/go-logger"
@@ -19,6 +20,9 @@
 	"github.com/kamalyes/go-toolbox/pkg/syncx"
 )
 
+// recentDurationsSize 实时图表展示的最近响应时间数量
+const recentDurationsSize = 20
+
 // Collector 统计收集器
 type Collector struct {
 	// 使用 syncx 原子类型
@@ -26,13 +30,39 @@ type Collector struct {
 	successRequests *syncx.Uint64
 	failedRequests  *syncx.Uint64
 	skippedRequests *syncx.Uint64 // 跳过请求计数器
+	delayedRequests *syncx.Uint64 // 开放模型：无空闲worker而排队的请求数
+	droppedRequests *syncx.Uint64 // 开放模型：队列已满而丢弃的请求数
 
 	// 时长统计（需要加锁）
 	mu            *syncx.RWLock
 	totalDuration time.Duration
 	minDuration   time.Duration
 	maxDuration   time.Duration
-	durations     []float64 // 用于计算百分位（转为秒）
+	latencies     *Histogram      // 延迟直方图（固定内存，用于计算百分位）
+	recent        []time.Duration // 最近的响应时间（环形缓冲，用于实时图表）
+	recentPos     int
+	// 协调遗漏校正的期望请求间隔（>0 时启用校正）
+	expectedInterval time.Duration
+
+	// 分组统计：按接口名称、场景和负载阶段拆分（与上面的时长统计共用写锁）
+	apis      map[string]*breakdownStats // 按 APIStatsKey（多场景模式下为 场景/接口）
+	scenarios map[string]*breakdownStats // 按场景名称（多场景模式）
+	stages    []*breakdownStats          // 按阶段顺序排列（多阶段模式）
+
+	// 时间序列：按固定间隔分桶（与上面的时长统计共用写锁）
+	timeSeries *timeSeries
+	// HTTP 请求阶段耗时（与上面的时长统计共用写锁）
+	phases *phaseStats
+	// gRPC 流式调用统计（与上面的时长统计共用写锁）
+	streams *streamStats
+	// WebSocket 会话统计（与上面的时长统计共用写锁）
+	webSocket *webSocketStats
+	// MQTT 统计（与上面的时长统计共用写锁）
+	mqtt *mqttStats
+	// 当前正在执行请求的worker数（用于时间序列）
+	activeWorkers *syncx.Int64
+	// 各场景正在执行请求的worker数（用于场景时间序列）
+	scenarioWorkers *syncx.Map[string, *syncx.Int64]
 
 	totalSize float64
 
@@ -57,6 +87,13 @@ type Collector struct {
 	protocol    string
 	concurrency uint64
 	totalReqs   uint64
+	duration    time.Duration // 计划持续时间（按时间运行时报告展示实际请求数）
+
+	// 当前负载阶段（多阶段模式）
+	stage *syncx.AtomicValue[*StageInfo]
+
+	// SLO 阈值评估结果（压测结束后设置）
+	thresholds *syncx.AtomicValue[[]*ThresholdResult]
 
 	// 关闭标志
 	closed *syncx.Bool
@@ -72,14 +109,28 @@ func NewCollector(strg StorageInterface, log logger.ILogger) *Collector {
 		successRequests: syncx.NewUint64(0),
 		failedRequests:  syncx.NewUint64(0),
 		skippedRequests: syncx.NewUint64(0),
+		delayedRequests: syncx.NewUint64(0),
+		droppedRequests: syncx.NewUint64(0),
 		mu:              syncx.NewRWLock(),
 		reporterMu:      syncx.NewRWLock(),
-		durations:       make([]float64, 0, 10000),
+		latencies:       NewHistogram(),
+		recent:          make([]time.Duration, 0, recentDurationsSize),
+		apis:            make(map[string]*breakdownStats),
+		scenarios:       make(map[string]*breakdownStats),
+		timeSeries:      newTimeSeries(DefaultTimeSeriesInterval),
+		phases:          newPhaseStats(),
+		streams:         newStreamStats(),
+		webSocket:       newWebSocketStats(),
+		mqtt:            newMQTTStats(),
+		activeWorkers:   syncx.NewInt64(0),
+		scenarioWorkers: syncx.NewMap[string, *syncx.Int64](),
 		errors:          syncx.NewMap[string, uint64](),
 		statusCodes:     syncx.NewMap[int, uint64](),
 		storage:         strg,
 		idGenerator:     idgen.NewSnowflakeGenerator(1, 1),
 		minDuration:     time.Hour,
+		stage:           syncx.NewAtomicValue[*StageInfo](nil),
+		thresholds:      syncx.NewAtomicValue[[]*ThresholdResult](nil),
 		closed:          syncx.NewBool(false),
 		logger:          log,
 	}
@@ -121,7 +172,7 @@ func (c *Collector) Collect(result *RequestResult) {
 	}
 
 	// 统计状态码 - 使用 syncx.Map
-	if result.StatusCode > 0 {
+	if result.HasStatusCode() {
 		old, _ := c.statusCodes.LoadOrStore(result.StatusCode, 0)
 		c.statusCodes.Store(result.StatusCode, old+1)
 	}
@@ -129,12 +180,24 @@ func (c *Collector) Collect(result *RequestResult) {
 	// 统计耗时 - 使用 syncx.WithLock 包装
 	syncx.WithLock(c.mu, func() {
 		c.totalDuration += result.Duration
-		c.durations = append(c.durations, result.Duration.Seconds())
+		if c.expectedInterval > 0 {
+			c.latencies.RecordCorrected(result.Duration, c.expectedInterval)
+		} else {
+			c.latencies.Record(result.Duration)
+		}
+		c.appendRecent(result.Duration)
 
 		c.minDuration = mathx.Min(c.minDuration, result.Duration)
 		c.maxDuration = mathx.Max(c.maxDuration, result.Duration)
 
 		c.totalSize += result.Size
+
+		c.recordBreakdown(result)
+		c.phases.record(result)
+		c.streams.record(result)
+		c.webSocket.record(result)
+		c.mqtt.record(result)
+		c.timeSeries.record(time.Now(), result, c.activeWorkers.Load())
 	})
 
 	// 生成唯一ID和错误消息
@@ -147,6 +210,114 @@ func (c *Collector) Collect(result *RequestResult) {
 	c.storage.Write(result)
 }
 
+// recordBreakdown 记录接口、场景和阶段分组统计（调用方持有写锁）
+func (c *Collector) recordBreakdown(result *RequestResult) {
+	now := time.Now()
+
+	name := APIStatsKey(result.Scenario, mathx.IfEmpty(result.APIName, defaultAPIName))
+	api, ok := c.apis[name]
+	if !ok {
+		api = newBreakdownStats(name, 0)
+		c.apis[name] = api
+	}
+	api.record(result, now)
+
+	if result.Scenario != "" {
+		scenario, ok := c.scenarios[result.Scenario]
+		if !ok {
+			scenario = newBreakdownStats(result.Scenario, 0)
+			scenario.details = newScenarioDetails(c.timeSeries.interval)
+			c.scenarios[result.Scenario] = scenario
+		}
+		scenario.record(result, now)
+		scenario.details.record(now, result, c.scenarioActiveWorkers(result.Scenario).Load())
+	}
+
+	stage := c.stage.Load()
+	if stage == nil {
+		return
+	}
+	// 阶段只会向前推进，最后一个分组不是当前阶段时新建
+	if n := len(c.stages); n == 0 || c.stages[n-1].index != stage.Index {
+		label := mathx.IfEmpty(stage.Name, fmt.Sprintf("阶段 %d", stage.Index))
+		c.stages = append(c.stages, newBreakdownStats(label, stage.Index))
+	}
+	c.stages[len(c.stages)-1].record(result, now)
+}
+
+// appendRecent 追加最近响应时间（调用方持有写锁）
+func (c *Collector) appendRecent(d time.Duration) {
+	if len(c.recent) < recentDurationsSize {
+		c.recent = append(c.recent, d)
+		return
+	}
+	c.recent[c.recentPos] = d
+	c.recentPos = (c.recentPos + 1) % recentDurationsSize
+}
+
+// recentDurations 按时间顺序返回最近响应时间（调用方持有读锁）
+func (c *Collector) recentDurations() []time.Duration {
+	if len(c.recent) < recentDurationsSize {
+		return append([]time.Duration(nil), c.recent...)
+	}
+	return append(append([]time.Duration(nil), c.recent[c.recentPos:]...), c.recent[:c.recentPos]...)
+}
+
+// SetLatencyCorrection 设置协调遗漏校正的期望请求间隔（0 表示关闭校正）
+// 耗时超过该间隔的请求会按间隔回填样本，使百分位反映被阻塞请求的真实延迟
+func (c *Collector) SetLatencyCorrection(expectedInterval time.Duration) {
+	syncx.WithLock(c.mu, func() {
+		c.expectedInterval = expectedInterval
+	})
+}
+
+// GetLatencyHistogram 获取延迟直方图副本（可用于序列化或合并）
+func (c *Collector) GetLatencyHistogram() *Histogram {
+	return syncx.WithRLockReturnValue(c.mu, func() *Histogram {
+		return c.latencies.Clone()
+	})
+}
+
+// SetTimeSeriesInterval 设置时间序列分桶间隔（需在收集数据前设置）
+func (c *Collector) SetTimeSeriesInterval(interval time.Duration) {
+	syncx.WithLock(c.mu, func() {
+		c.timeSeries = newTimeSeries(interval)
+	})
+}
+
+// AddActiveWorkers 调整正在执行请求的worker数
+func (c *Collector) AddActiveWorkers(delta int64) {
+	c.activeWorkers.Add(delta)
+}
+
+// AddScenarioActiveWorkers 调整正在执行请求的worker数，并计入所属场景（多场景模式）
+func (c *Collector) AddScenarioActiveWorkers(scenario string, delta int64) {
+	c.activeWorkers.Add(delta)
+	if scenario != "" {
+		c.scenarioActiveWorkers(scenario).Add(delta)
+	}
+}
+
+// scenarioActiveWorkers 场景正在执行请求的worker计数器
+func (c *Collector) scenarioActiveWorkers(scenario string) *syncx.Int64 {
+	return c.scenarioWorkers.GetOrCompute(scenario, func() *syncx.Int64 { return syncx.NewInt64(0) })
+}
+
+// GetActiveWorkers 获取正在执行请求的worker数
+func (c *Collector) GetActiveWorkers() int64 {
+	return c.activeWorkers.Load()
+}
+
+// RecordDelayed 记录一次因无空闲worker而排队的请求（开放模型）
+func (c *Collector) RecordDelayed() {
+	c.delayedRequests.Add(1)
+}
+
+// RecordDropped 记录一次因队列已满而丢弃的请求（开放模型）
+func (c *Collector) RecordDropped() {
+	c.droppedRequests.Add(1)
+}
+
 // GetMetrics 获取实时指标
 func (c *Collector) GetMetrics() *Metrics {
 	return &Metrics{
@@ -285,6 +456,56 @@ func (c *Collector) SetConfig(protocol string, concurrency, totalReqs uint64) {
 	c.totalReqs = totalReqs
 }
 
+// SetDuration 设置计划持续时间（按时间运行模式）
+func (c *Collector) SetDuration(duration time.Duration) {
+	c.duration = duration
+}
+
+// SetStage 更新当前负载阶段（多阶段模式，供实时报告展示）
+func (c *Collector) SetStage(stage *StageInfo) {
+	c.stage.Store(stage)
+}
+
+// GetStage 获取当前负载阶段，非多阶段模式返回 nil
+func (c *Collector) GetStage() *StageInfo {
+	return c.stage.Load()
+}
+
+// SetThresholdResults 设置 SLO 阈值评估结果（写入后续导出的报告）
+func (c *Collector) SetThresholdResults(results []*ThresholdResult) {
+	c.thresholds.Store(results)
+}
+
+// GetThresholdResults 获取 SLO 阈值评估结果
+func (c *Collector) GetThresholdResults() []*ThresholdResult {
+	return c.thresholds.Load()
+}
+
+// GetAPIStats 获取指定接口的分组统计和延迟直方图副本，接口没有请求时返回 nil
+// 多场景模式下 name 为 APIStatsKey 生成的 场景/接口 名称
+func (c *Collector) GetAPIStats(name string) (*BreakdownStats, *Histogram) {
+	c.mu.RLock()
+	defer c.mu.RUnlock()
+
+	api, ok := c.apis[mathx.IfEmpty(name, defaultAPIName)]
+	if !ok {
+		return nil, nil
+	}
+	return api.snapshot(0), api.latencies.Clone()
+}
+
+// GetScenarioStats 获取指定场景的分组统计和延迟直方图副本，场景没有请求时返回 nil
+func (c *Collector) GetScenarioStats(name string) (*BreakdownStats, *Histogram) {
+	c.mu.RLock()
+	defer c.mu.RUnlock()
+
+	scenario, ok := c.scenarios[name]
+	if !ok {
+		return nil, nil
+	}
+	return scenario.snapshot(0), scenario.latencies.Clone()
+}
+
 // ClearExternalReporter 清除外部上报器
 func (c *Collector) ClearExternalReporter() {
 	c.reporterMu.Lock()
@@ -195,8 +195,15 @@ func (f *TextFormatter) Format(report *Report) ([]byte, error) {
 	buf.WriteString(fmt.Sprintf("P90: %s\n", report.P90Latency))
 	buf.WriteString(fmt.Sprintf("P95: %s\n", report.P95Latency))
 	buf.WriteString(fmt.Sprintf("P99: %s\n", report.P99Latency))
+	buf.WriteString(fmt.Sprintf("P99.9: %s\n", report.P999Latency))
+	buf.WriteString(fmt.Sprintf("P99.99: %s\n", report.P9999Latency))
 	buf.WriteString(fmt.Sprintf("总数据量: %s\n", units.BytesSize(report.TotalSize)))
 
+	// 分组统计
+	writeBreakdownText(&buf, "接口统计", report.APIStats)
+	writeBreakdownText(&buf, "场景统计", report.ScenarioStats)
+	writeBreakdownText(&buf, "阶段统计", report.StageStats)
+
 	// 错误统计
 	if len(report.Errors) > 0 {
 		buf.WriteString("\n错误统计:\n")
@@ -211,13 +218,25 @@ func (f *TextFormatter) Format(report *Report) ([]byte, error) {
 		buf.WriteString("\n状态码统计:\n")
 		for code, count := range report.StatusCodes {
 			percentage := mathx.Percentage(count, report.TotalRequests)
-			buf.WriteString(fmt.Sprintf("  %d: %d (%.2f%%)\n", code, count, percentage))
+			buf.WriteString(fmt.Sprintf("  %s: %d (%.2f%%)\n", StatusCodeLabel(code), count, percentage))
 		}
 	}
 
 	return buf.Bytes(), nil
 }
 
+// writeBreakdownText 写入分组统计（每个分组一行）
+func writeBreakdownText(buf *bytes.Buffer, title string, groups []*BreakdownStats) {
+	if len(groups) == 0 {
+		return
+	}
+	buf.WriteString(fmt.Sprintf("\n%s:\n", title))
+	for _, g := range groups {
+		buf.WriteString(fmt.Sprintf("  %s: 请求 %d | 失败 %d | 成功率 %.2f%% | QPS %.2f | 平均 %s | P95 %s | P99 %s\n",
+			g.Name, g.TotalRequests, g.FailedRequests, g.SuccessRate, g.QPS, g.AvgLatency, g.P95Latency, g.P99Latency))
+	}
+}
+
 // ContentType 返回文本内容类型
 func (f *TextFormatter) ContentType() string {
 	return "text/plain; charset=utf-8"
@@ -0,0 +1,324 @@
+/*
+ * @Author: kamalyes 501893067@qq.com
+ * @Date: 2026-02-04 00:00:00
+ * @LastEditors: kamalyes 501893067@qq.com
+ * @LastEditTime: 2026-02-04 00:00:00
+ * @FilePath: \go-stress\statistics\histogram.go
+ * @Description: HDR 风格延迟直方图 - 固定内存、可合并、支持协调遗漏校正
+ *
+ * Copyright (c) 2026 by kamalyes, All Rights Reserved.
+ */
+package statistics
+
+import (
+	"encoding/binary"
+	"errors"
+	"fmt"
+	"math"
+	"math/bits"
+	"time"
+)
+
+const (
+	// histSubBucketBits 每个数量级的子桶位数：128 个子桶，相对误差 < 1%
+	histSubBucketBits = 7
+	// histSubBucketCount 子桶数量
+	histSubBucketCount = 1 << histSubBucketBits
+	// histSubBucketHalf 第一个数量级之后，每个数量级只使用上半部分子桶
+	histSubBucketHalf = histSubBucketCount / 2
+	// histMaxValueBits 可记录的最大值位数（微秒）：2^36µs ≈ 19 小时
+	histMaxValueBits = 36
+	// histBucketCount 桶总数（固定内存约 14KB）
+	histBucketCount = histSubBucketCount + (histMaxValueBits-histSubBucketBits)*histSubBucketHalf
+	// histMaxValue 可记录的最大值（微秒），超出部分记入最后一个桶
+	histMaxValue = int64(1)<<histMaxValueBits - 1
+	// histEncodingVersion 序列化格式版本
+	histEncodingVersion = 1
+)
+
+// Histogram 延迟直方图（HDR 风格的对数-线性分桶，单位微秒）
+// 内存固定，与样本数无关；相同结构的直方图可直接按桶相加合并，
+// 因此分布式场景下合并后的百分位依然准确。非并发安全，由调用方加锁
+type Histogram struct {
+	counts     []uint64
+	totalCount uint64
+	min        time.Duration
+	max        time.Duration
+}
+
+// NewHistogram 创建直方图
+func NewHistogram() *Histogram {
+	return &Histogram{
+		counts: make([]uint64, histBucketCount),
+	}
+}
+
+// Record 记录一个延迟样本
+func (h *Histogram) Record(d time.Duration) {
+	h.RecordN(d, 1)
+}
+
+// RecordN 记录 n 个相同的延迟样本
+func (h *Histogram) RecordN(d time.Duration, n uint64) {
+	if n == 0 {
+		return
+	}
+	if d < 0 {
+		d = 0
+	}
+	h.counts[bucketIndex(d.Microseconds())] += n
+	if h.totalCount == 0 || d < h.min {
+		h.min = d
+	}
+	if d > h.max {
+		h.max = d
+	}
+	h.totalCount += n
+}
+
+// RecordCorrected 记录延迟样本，并进行协调遗漏校正
+// 当耗时超过期望间隔时，说明这段时间内本应发出的请求被阻塞了，
+// 按 耗时-间隔、耗时-2*间隔 ... 回填这些"未发出请求"应有的延迟
+func (h *Histogram) RecordCorrected(d, expectedInterval time.Duration) {
+	h.Record(d)
+	if expectedInterval <= 0 || d <= expectedInterval {
+		return
+	}
+	for missing := d - expectedInterval; missing >= expectedInterval; missing -= expectedInterval {
+		h.Record(missing)
+	}
+}
+
+// Merge 合并另一个直方图
+func (h *Histogram) Merge(other *Histogram) {
+	if other == nil || other.totalCount == 0 {
+		return
+	}
+	for i, c := range other.counts {
+		h.counts[i] += c
+	}
+	if h.totalCount == 0 || other.min < h.min {
+		h.min = other.min
+	}
+	if other.max > h.max {
+		h.max = other.max
+	}
+	h.totalCount += other.totalCount
+}
+
+// Reset 清空直方图
+func (h *Histogram) Reset() {
+	clear(h.counts)
+	h.totalCount = 0
+	h.min = 0
+	h.max = 0
+}
+
+// Clone 复制直方图
+func (h *Histogram) Clone() *Histogram {
+	clone := &Histogram{
+		counts:     make([]uint64, histBucketCount),
+		totalCount: h.totalCount,
+		min:        h.min,
+		max:        h.max,
+	}
+	copy(clone.counts, h.counts)
+	return clone
+}
+
+// Count 样本总数（包含校正回填的样本）
+func (h *Histogram) Count() uint64 {
+	return h.totalCount
+}
+
+// Min 最小值
+func (h *Histogram) Min() time.Duration {
+	return h.min
+}
+
+// Max 最大值
+func (h *Histogram) Max() time.Duration {
+	return h.max
+}
+
+// Mean 平均值（按桶中值估算）
+func (h *Histogram) Mean() time.Duration {
+	if h.totalCount == 0 {
+		return 0
+	}
+	var sum float64
+	for i, c := range h.counts {
+		if c > 0 {
+			sum += float64(bucketMidValue(i)) * float64(c)
+		}
+	}
+	return time.Duration(sum/float64(h.totalCount)) * time.Microsecond
+}
+
+// Percentile 计算百分位（p 取值 0-100）
+func (h *Histogram) Percentile(p float64) time.Duration {
+	if h.totalCount == 0 {
+		return 0
+	}
+	if p <= 0 {
+		return h.min
+	}
+	if p >= 100 {
+		return h.max
+	}
+
+	target := uint64(math.Ceil(p / 100 * float64(h.totalCount)))
+	var cumulative uint64
+	for i, c := range h.counts {
+		cumulative += c
+		if cumulative >= target {
+			// 取桶中值，并限制在实际最小/最大值之间
+			value := time.Duration(bucketMidValue(i)) * time.Microsecond
+			return min(max(value, h.min), h.max)
+		}
+	}
+	return h.max
+}
+
+// CountAbove 统计大于 d 的样本数（只计入整个桶都大于 d 的样本，结果偏保守）
+func (h *Histogram) CountAbove(d time.Duration) uint64 {
+	if h.totalCount == 0 || d >= h.max {
+		return 0
+	}
+	var count uint64
+	for i := bucketIndex(d.Microseconds()) + 1; i < len(h.counts); i++ {
+		count += h.counts[i]
+	}
+	return count
+}
+
+// Percentiles 批量计算百分位
+func (h *Histogram) Percentiles(ps ...float64) map[float64]time.Duration {
+	result := make(map[float64]time.Duration, len(ps))
+	for _, p := range ps {
+		result[p] = h.Percentile(p)
+	}
+	return result
+}
+
+// MarshalBinary 序列化为紧凑的二进制格式（只编码非空桶）
+// 格式: 版本 | min | max | 非空桶数 | (桶序号增量, 计数)...，均为 uvarint
+func (h *Histogram) MarshalBinary() ([]byte, error) {
+	buf := make([]byte, 0, 64)
+	buf = binary.AppendUvarint(buf, histEncodingVersion)
+	buf = binary.AppendUvarint(buf, uint64(h.min))
+	buf = binary.AppendUvarint(buf, uint64(h.max))
+
+	var nonEmpty uint64
+	for _, c := range h.counts {
+		if c > 0 {
+			nonEmpty++
+		}
+	}
+	buf = binary.AppendUvarint(buf, nonEmpty)
+
+	last := 0
+	for i, c := range h.counts {
+		if c == 0 {
+			continue
+		}
+		buf = binary.AppendUvarint(buf, uint64(i-last))
+		buf = binary.AppendUvarint(buf, c)
+		last = i
+	}
+	return buf, nil
+}
+
+// UnmarshalBinary 从二进制格式反序列化（覆盖当前内容）
+func (h *Histogram) UnmarshalBinary(data []byte) error {
+	h.counts = make([]uint64, histBucketCount)
+	h.totalCount = 0
+
+	readUvarint := func() (uint64, error) {
+		v, n := binary.Uvarint(data)
+		if n <= 0 {
+			return 0, errors.New("直方图数据已损坏")
+		}
+		data = data[n:]
+		return v, nil
+	}
+
+	version, err := readUvarint()
+	if err != nil {
+		return err
+	}
+	if version != histEncodingVersion {
+		return fmt.Errorf("不支持的直方图版本: %d", version)
+	}
+
+	var header [3]uint64
+	for i := range header {
+		if header[i], err = readUvarint(); err != nil {
+			return err
+		}
+	}
+	h.min, h.max = time.Duration(header[0]), time.Duration(header[1])
+
+	index := uint64(0)
+	for i := uint64(0); i < header[2]; i++ {
+		delta, err := readUvarint()
+		if err != nil {
+			return err
+		}
+		count, err := readUvarint()
+		if err != nil {
+			return err
+		}
+		index += delta
+		if index >= histBucketCount {
+			return fmt.Errorf("直方图桶序号越界: %d", index)
+		}
+		h.counts[index] += count
+		h.totalCount += count
+	}
+	return nil
+}
+
+// DecodeHistogram 从二进制数据创建直方图
+func DecodeHistogram(data []byte) (*Histogram, error) {
+	h := NewHistogram()
+	if len(data) == 0 {
+		return h, nil
+	}
+	if err := h.UnmarshalBinary(data); err != nil {
+		return nil, err
+	}
+	return h, nil
+}
+
+// bucketIndex 计算值（微秒）所在的桶序号
+func bucketIndex(value int64) int {
+	value = min(max(value, 0), histMaxValue)
+	if value < histSubBucketCount {
+		return int(value)
+	}
+	shift := bits.Len64(uint64(value)) - histSubBucketBits
+	sub := int(value >> shift)
+	return histSubBucketCount + (shift-1)*histSubBucketHalf + (sub - histSubBucketHalf)
+}
+
+// bucketLowValue 桶的下界（微秒）
+func bucketLowValue(index int) int64 {
+	if index < histSubBucketCount {
+		return int64(index)
+	}
+	offset := index - histSubBucketCount
+	shift := offset/histSubBucketHalf + 1
+	sub := offset%histSubBucketHalf + histSubBucketHalf
+	return int64(sub) << shift
+}
+
+// bucketMidValue 桶的中值（微秒），用于估算百分位
+func bucketMidValue(index int) int64 {
+	low := bucketLowValue(index)
+	if index < histSubBucketCount {
+		return low
+	}
+	shift := (index-histSubBucketCount)/histSubBucketHalf + 1
+	return low + (int64(1)<<shift)/2
+}
@@ -0,0 +1,92 @@
+/*
+ * @Author: kamalyes 501893067@qq.com
+ * @Date: 2026-02-04 00:00:00
+ * @LastEditors: kamalyes 501893067@qq.com
+ * @LastEditTime: 2026-02-04 00:00:00
+ * @FilePath: \go-stress\statistics\histogram_test.go
+ * @Description: 延迟直方图测试
+ *
+ * Copyright (c) 2026 by kamalyes, All Rights Reserved.
+ */
+package statistics
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+// 测试百分位精度（相对误差 < 1%）
+func TestHistogramPercentile(t *testing.T) {
+	h := NewHistogram()
+	for i := 1; i <= 10000; i++ {
+		h.Record(time.Duration(i) * time.Millisecond)
+	}
+
+	assert.Equal(t, uint64(10000), h.Count())
+	assert.Equal(t, time.Millisecond, h.Min())
+	assert.Equal(t, 10*time.Second, h.Max())
+
+	for p, expected := range map[float64]time.Duration{
+		50:    5000 * time.Millisecond,
+		99:    9900 * time.Millisecond,
+		99.9:  9990 * time.Millisecond,
+		99.99: 9999 * time.Millisecond,
+	} {
+		actual := h.Percentile(p)
+		assert.InEpsilon(t, float64(expected), float64(actual), 0.01, "P%v 误差过大: %v", p, actual)
+	}
+}
+
+// 测试合并后的百分位与整体记录一致
+func TestHistogramMerge(t *testing.T) {
+	whole := NewHistogram()
+	a, b := NewHistogram(), NewHistogram()
+	for i := 1; i <= 1000; i++ {
+		d := time.Duration(i) * time.Millisecond
+		whole.Record(d)
+		if i%2 == 0 {
+			a.Record(d)
+		} else {
+			b.Record(d)
+		}
+	}
+
+	a.Merge(b)
+	assert.Equal(t, whole.Count(), a.Count())
+	assert.Equal(t, whole.Min(), a.Min())
+	assert.Equal(t, whole.Max(), a.Max())
+	assert.Equal(t, whole.Percentile(99), a.Percentile(99))
+}
+
+// 测试序列化往返
+func TestHistogramEncoding(t *testing.T) {
+	h := NewHistogram()
+	h.Record(150 * time.Microsecond)
+	h.RecordN(3*time.Second, 5)
+
+	data, err := h.MarshalBinary()
+	assert.NoError(t, err)
+
+	decoded, err := DecodeHistogram(data)
+	assert.NoError(t, err)
+	assert.Equal(t, h.Count(), decoded.Count())
+	assert.Equal(t, h.Min(), decoded.Min())
+	assert.Equal(t, h.Max(), decoded.Max())
+	assert.Equal(t, h.Percentile(90), decoded.Percentile(90))
+
+	_, err = DecodeHistogram([]byte{0xff})
+	assert.Error(t, err, "损坏的数据应返回错误")
+}
+
+// 测试协调遗漏校正回填样本
+func TestHistogramRecordCorrected(t *testing.T) {
+	h := NewHistogram()
+	h.RecordCorrected(100*time.Millisecond, 10*time.Millisecond)
+
+	// 100ms 的请求按 10ms 间隔回填 90ms, 80ms ... 10ms 共 9 个样本
+	assert.Equal(t, uint64(10), h.Count())
+	assert.Equal(t, 10*time.Millisecond, h.Min())
+	assert.Equal(t, 100*time.Millisecond, h.Max())
+}
@@ -0,0 +1,119 @@
+/*
+ * @Author: kamalyes 501893067@qq.com
+ * @Date: 2026-02-18 00:00:00
+ * @LastEditors: kamalyes 501893067@qq.com
+ * @LastEditTime: 2026-02-18 00:00:00
+ * @FilePath: \go-stress\statistics\mqtt.go
+ * @Description: MQTT 统计 - 建连结果与连接风暴峰值、收发消息数、保留消息和端到端延迟
+ *
+ * Copyright (c) 2026 by kamalyes, All Rights Reserved.
+ */
+package statistics
+
+import (
+	"maps"
+	"time"
+
+	"github.com/kamalyes/go-toolbox/pkg/mathx"
+)
+
+// mqttStats MQTT 累计数据（由 Collector 在写锁内更新）
+type mqttStats struct {
+	requests      uint64
+	connects      uint64
+	failures      uint64 // 建连失败次数
+	connectTotal  time.Duration
+	connectHist   *Histogram
+	perSecond     map[int64]uint64 // 秒级时间戳 -> 建连次数
+	peakPerSecond uint64
+	results       map[string]uint64
+	published     uint64
+	received      uint64
+	retained      uint64
+	endToEndTotal time.Duration
+	endToEndHist  *Histogram
+}
+
+// newMQTTStats 创建 MQTT 累计数据
+func newMQTTStats() *mqttStats {
+	return &mqttStats{
+		connectHist:  NewHistogram(),
+		perSecond:    make(map[int64]uint64),
+		results:      make(map[string]uint64),
+		endToEndHist: NewHistogram(),
+	}
+}
+
+// record 记录一次 MQTT 请求的统计（非 MQTT 请求忽略）
+func (s *mqttStats) record(result *RequestResult) {
+	if result.Skipped || result.MQTT == nil {
+		return
+	}
+	m := result.MQTT
+	s.requests++
+	s.published += uint64(m.Published)
+	s.received += uint64(m.Received)
+	s.retained += uint64(m.Retained)
+	for _, d := range m.Latencies {
+		s.endToEndTotal += d
+		s.endToEndHist.Record(d)
+	}
+	if m.ConnectCode == "" {
+		return
+	}
+
+	// 本次请求建立了连接
+	s.connects++
+	s.results[m.ConnectCode]++
+	if m.ConnectCode != MQTTConnectAccepted {
+		s.failures++
+	}
+	s.connectTotal += m.Connect
+	s.connectHist.Record(m.Connect)
+	second := result.Timestamp.Add(-result.Duration).Unix()
+	s.perSecond[second]++
+	s.peakPerSecond = mathx.Max(s.peakPerSecond, s.perSecond[second])
+}
+
+// snapshot 生成 MQTT 报告（调用方持有读锁，没有 MQTT 请求时返回 nil）
+func (s *mqttStats) snapshot(totalTime time.Duration) *MQTTBreakdown {
+	if s.requests == 0 {
+		return nil
+	}
+	breakdown := &MQTTBreakdown{
+		Connects:           s.connects,
+		ConnectFailures:    s.failures,
+		PeakConnectsPerSec: s.peakPerSecond,
+		ConnectResults:     maps.Clone(s.results),
+		Published:          s.published,
+		Received:           s.received,
+		Retained:           s.retained,
+	}
+	if totalTime > 0 {
+		breakdown.PublishedPerSec = float64(s.published) / totalTime.Seconds()
+		breakdown.ReceivedPerSec = float64(s.received) / totalTime.Seconds()
+	}
+	if s.connects > 0 {
+		breakdown.Latencies = append(breakdown.Latencies,
+			newPhaseStat("connect", "建立连接", s.connectTotal, s.connects, s.connectHist))
+	}
+	if count := s.endToEndHist.Count(); count > 0 {
+		breakdown.Latencies = append(breakdown.Latencies,
+			newPhaseStat("end_to_end", "端到端延迟", s.endToEndTotal, count, s.endToEndHist))
+	}
+	return breakdown
+}
+
+// MQTTBreakdown MQTT 统计
+type MQTTBreakdown struct {
+	Connects           uint64            `json:"connects"`              // 建立连接次数（含失败和重连）
+	ConnectFailures    uint64            `json:"connect_failures"`      // 建连失败次数
+	PeakConnectsPerSec uint64            `json:"peak_connects_per_sec"` // 单秒最多建连次数（连接风暴峰值）
+	ConnectResults     map[string]uint64 `json:"connect_results"`       // 建连结果 -> 次数
+	Published          uint64            `json:"published"`             // 发布消息总数
+	Received           uint64            `json:"received"`              // 订阅接收消息总数
+	Retained           uint64            `json:"retained"`              // 接收的保留消息数
+	PublishedPerSec    float64           `json:"published_per_sec"`     // 每秒发布消息数
+	ReceivedPerSec     float64           `json:"received_per_sec"`      // 每秒接收消息数
+	Latencies          []*PhaseStat      `json:"latencies"`             // 建立连接、端到端延迟分布
+}
@@ -0,0 +1,61 @@
+/*
+ * @Author: kamalyes 501893067@qq.com
+ * @Date: 2026-02-18 00:00:00
+ * @LastEditors: kamalyes 501893067@qq.com
+ * @LastEditTime: 2026-02-18 00:00:00
+ * @FilePath: \go-stress\statistics\mqtt_test.go
+ * @Description: MQTT 统计测试
+ *
+ * Copyright (c) 2026 by kamalyes, All Rights Reserved.
+ */
+package statistics
+
+import (
+	"testing"
+	"time"
+
+	"github.com/kamalyes/go-stress/logger"
+	"github.com/kamalyes/go-stress/storage"
+	"github.com/stretchr/testify/assert"
+)
+
+// 测试 MQTT 统计聚合
+func TestCollectorMQTT(t *testing.T) {
+	c := NewCollector(storage.NewMemoryStorage("test", logger.Default), logger.Default)
+	defer c.Close()
+
+	// 非 MQTT 请求不产生统计
+	c.Collect(&RequestResult{Success: true, Duration: time.Millisecond})
+	assert.Nil(t, NewReportBuilder(c).BuildSummary(time.Second).MQTT)
+
+	base := time.Now().Truncate(time.Second)
+	// 同一秒内两次建连成功、一次被拒绝，下一秒一次建连
+	for i, code := range []string{"accepted", "accepted", "bad_credentials"} {
+		c.Collect(&RequestResult{Success: code == "accepted", Timestamp: base.Add(time.Duration(i+1) * 100 * time.Millisecond),
+			Duration: 50 * time.Millisecond, MQTT: &MQTTStats{Connect: 40 * time.Millisecond, ConnectCode: code, Published: 1}})
+	}
+	c.Collect(&RequestResult{Success: true, Timestamp: base.Add(1500 * time.Millisecond), Duration: 50 * time.Millisecond,
+		MQTT: &MQTTStats{Connect: 80 * time.Millisecond, ConnectCode: "accepted"}})
+	// 复用连接的请求只计入收发消息
+	c.Collect(&RequestResult{Success: true, Timestamp: base.Add(2 * time.Second), Duration: time.Millisecond, MQTT: &MQTTStats{
+		Published: 1, Received: 3, Retained: 1, Latencies: []time.Duration{2 * time.Millisecond, 4 * time.Millisecond},
+	}})
+
+	m := NewReportBuilder(c).BuildSummary(2 * time.Second).MQTT
+	assert.NotNil(t, m)
+	assert.Equal(t, uint64(4), m.Connects)
+	assert.Equal(t, uint64(1), m.ConnectFailures)
+	assert.Equal(t, uint64(3), m.PeakConnectsPerSec)
+	assert.Equal(t, map[string]uint64{"accepted": 3, "bad_credentials": 1}, m.ConnectResults)
+	assert.Equal(t, uint64(4), m.Published)
+	assert.Equal(t, uint64(3), m.Received)
+	assert.Equal(t, uint64(1), m.Retained)
+	assert.InDelta(t, 2.0, m.PublishedPerSec, 0.01)
+
+	assert.Len(t, m.Latencies, 2)
+	connect, endToEnd := m.Latencies[0], m.Latencies[1]
+	assert.Equal(t, "connect", connect.Name)
+	assert.Equal(t, 50*time.Millisecond, connect.AvgLatency)
+	assert.Equal(t, "end_to_end", endToEnd.Name)
+	assert.Equal(t, 3*time.Millisecond, endToEnd.AvgLatency)
+}
@@ -0,0 +1,168 @@
+/*
+ * @Author: kamalyes 501893067@qq.com
+ * @Date: 2026-02-10 00:00:00
+ * @LastEditors: kamalyes 501893067@qq.com
+ * @LastEditTime: 2026-02-10 00:00:00
+ * @FilePath: \go-stress\statistics\phases.go
+ * @Description: 请求阶段耗时统计 - DNS、连接、TLS、首字节、传输各自的延迟分布和连接复用率
+ *
+ * Copyright (c) 2026 by kamalyes, All Rights Reserved.
+ */
+package statistics
+
+import (
+	"encoding/json"
+	"time"
+
+	"github.com/kamalyes/go-toolbox/pkg/mathx"
+)
+
+// phase 请求阶段
+type phase int
+
+const (
+	phaseDNS phase = iota
+	phaseConnect
+	phaseTLS
+	phaseTTFB
+	phaseTransfer
+	phaseCount
+)
+
+// phaseMeta 阶段名称（JSON 字段名）和展示名称，按请求先后顺序排列
+var phaseMeta = [phaseCount]struct {
+	name  string
+	label string
+}{
+	phaseDNS:      {"dns", "DNS解析"},
+	phaseConnect:  {"connect", "TCP连接"},
+	phaseTLS:      {"tls", "TLS握手"},
+	phaseTTFB:     {"ttfb", "等待首字节"},
+	phaseTransfer: {"transfer", "响应传输"},
+}
+
+// value 取出指定阶段的耗时
+func (p phase) value(t *PhaseTimings) time.Duration {
+	switch p {
+	case phaseDNS:
+		return t.DNS
+	case phaseConnect:
+		return t.Connect
+	case phaseTLS:
+		return t.TLS
+	case phaseTTFB:
+		return t.TTFB
+	case phaseTransfer:
+		return t.Transfer
+	}
+	return 0
+}
+
+// phaseStats 阶段耗时累计数据（由 Collector 在写锁内更新）
+type phaseStats struct {
+	traced     uint64
+	reused     uint64
+	totals     [phaseCount]time.Duration
+	histograms [phaseCount]*Histogram
+}
+
+// newPhaseStats 创建阶段耗时累计数据
+func newPhaseStats() *phaseStats {
+	s := &phaseStats{}
+	for i := range s.histograms {
+		s.histograms[i] = NewHistogram()
+	}
+	return s
+}
+
+// record 记录一次请求的阶段耗时（未采集阶段耗时的请求忽略）
+// 复用连接的请求 DNS/连接/TLS 记为 0，平均值因此反映各阶段对整体耗时的实际贡献
+func (s *phaseStats) record(result *RequestResult) {
+	if result.Skipped || result.Phases == nil {
+		return
+	}
+	s.traced++
+	if result.Phases.ConnReused {
+		s.reused++
+	}
+	for p := phase(0); p < phaseCount; p++ {
+		d := p.value(result.Phases)
+		s.totals[p] += d
+		s.histograms[p].Record(d)
+	}
+}
+
+// snapshot 生成阶段耗时报告（调用方持有读锁，没有采集数据时返回 nil）
+func (s *phaseStats) snapshot() *PhaseBreakdown {
+	if s.traced == 0 {
+		return nil
+	}
+	breakdown := &PhaseBreakdown{
+		TracedRequests:    s.traced,
+		ReusedConnections: s.reused,
+		ReuseRate:         mathx.Percentage(s.reused, s.traced),
+		Phases:            make([]*PhaseStat, 0, phaseCount),
+	}
+	for p := phase(0); p < phaseCount; p++ {
+		breakdown.Phases = append(breakdown.Phases,
+			newPhaseStat(phaseMeta[p].name, phaseMeta[p].label, s.totals[p], s.traced, s.histograms[p]))
+	}
+	return breakdown
+}
+
+// newPhaseStat 根据累计耗时和直方图生成耗时分布
+func newPhaseStat(name, label string, total time.Duration, count uint64, hist *Histogram) *PhaseStat {
+	percentiles := hist.Percentiles(50, 90, 95, 99)
+	return &PhaseStat{
+		Name:       name,
+		Label:      label,
+		AvgLatency: total / time.Duration(count),
+		P50Latency: percentiles[50],
+		P90Latency: percentiles[90],
+		P95Latency: percentiles[95],
+		P99Latency: percentiles[99],
+		MaxLatency: hist.Max(),
+	}
+}
+
+// PhaseBreakdown 请求阶段耗时分解
+type PhaseBreakdown struct {
+	TracedRequests    uint64       `json:"traced_requests"`    // 采集了阶段耗时的请求数
+	ReusedConnections uint64       `json:"reused_connections"` // 复用连接的请求数
+	ReuseRate         float64      `json:"reuse_rate"`         // 连接复用率，百分比 0-100
+	Phases            []*PhaseStat `json:"phases"`             // 按请求先后顺序排列
+}
+
+// PhaseStat 单个阶段的耗时分布
+type PhaseStat struct {
+	Name       string        `json:"name"`  // dns/connect/tls/ttfb/transfer
+	Label      string        `json:"label"` // 展示名称
+	AvgLatency time.Duration `json:"avg_latency"`
+	P50Latency time.Duration `json:"p50_latency"`
+	P90Latency time.Duration `json:"p90_latency"`
+	P95Latency time.Duration `json:"p95_latency"`
+	P99Latency time.Duration `json:"p99_latency"`
+	MaxLatency time.Duration `json:"max_latency"`
+}
+
+// MarshalJSON 自定义JSON序列化，将time.Duration转换为毫秒（与 Report 保持一致）
+func (p *PhaseStat) MarshalJSON() ([]byte, error) {
+	type Alias PhaseStat
+	return json.Marshal(&struct {
+		*Alias
+		AvgLatency float64 `json:"avg_latency"`
+		P50Latency float64 `json:"p50_latency"`
+		P90Latency float64 `json:"p90_latency"`
+		P95Latency float64 `json:"p95_latency"`
+		P99Latency float64 `json:"p99_latency"`
+		MaxLatency float64 `json:"max_latency"`
+	}{
+		Alias:      (*Alias)(p),
+		AvgLatency: float64(p.AvgLatency.Microseconds()) / 1000.0,
+		P50Latency: float64(p.P50Latency.Microseconds()) / 1000.0,
+		P90Latency: float64(p.P90Latency.Microseconds()) / 1000.0,
+		P95Latency: float64(p.P95Latency.Microseconds()) / 1000.0,
+		P99Latency: float64(p.P99Latency.Microseconds()) / 1000.0,
+		MaxLatency: float64(p.MaxLatency.Microseconds()) / 1000.0,
+	})
+}
@@ -0,0 +1,67 @@
+/*
+ * @Author: kamalyes 501893067@qq.com
+ * @Date: 2026-02-10 00:00:00
+ * @LastEditors: kamalyes 501893067@qq.com
+ * @LastEditTime: 2026-02-10 00:00:00
+ * @FilePath: \go-stress\statistics\phases_test.go
+ * @Description: 请求阶段耗时统计测试
+ *
+ * Copyright (c) 2026 by kamalyes, All Rights Reserved.
+ */
+package statistics
+
+import (
+	"encoding/json"
+	"testing"
+	"time"
+
+	"github.com/kamalyes/go-stress/logger"
+	"github.com/kamalyes/go-stress/storage"
+	"github.com/stretchr/testify/assert"
+)
+
+// 测试阶段耗时按阶段聚合并计算连接复用率
+func TestCollectorPhases(t *testing.T) {
+	c := NewCollector(storage.NewMemoryStorage("test", logger.Default), logger.Default)
+	defer c.Close()
+
+	// 未采集阶段耗时时报告不包含阶段分解
+	c.Collect(&RequestResult{Success: true, Duration: time.Millisecond})
+	assert.Nil(t, NewReportBuilder(c).BuildSummary(time.Second).Phases)
+
+	// 1 个新建连接 + 3 个复用连接
+	c.Collect(&RequestResult{Success: true, Duration: 40 * time.Millisecond, Phases: &PhaseTimings{
+		DNS: 4 * time.Millisecond, Connect: 8 * time.Millisecond, TLS: 12 * time.Millisecond,
+		TTFB: 10 * time.Millisecond, Transfer: 2 * time.Millisecond,
+	}})
+	for i := 0; i < 3; i++ {
+		c.Collect(&RequestResult{Success: true, Duration: 12 * time.Millisecond, Phases: &PhaseTimings{
+			TTFB: 10 * time.Millisecond, Transfer: 2 * time.Millisecond, ConnReused: true,
+		}})
+	}
+	// 跳过的请求不计入
+	c.Collect(&RequestResult{Skipped: true, Phases: &PhaseTimings{TTFB: time.Second}})
+
+	phases := NewReportBuilder(c).BuildSummary(time.Second).Phases
+	assert.NotNil(t, phases)
+	assert.Equal(t, uint64(4), phases.TracedRequests)
+	assert.Equal(t, uint64(3), phases.ReusedConnections)
+	assert.InDelta(t, 75.0, phases.ReuseRate, 0.01)
+
+	assert.Len(t, phases.Phases, 5)
+	names := make([]string, 0, len(phases.Phases))
+	for _, p := range phases.Phases {
+		names = append(names, p.Name)
+	}
+	assert.Equal(t, []string{"dns", "connect", "tls", "ttfb", "transfer"}, names)
+
+	tlsPhase, ttfb := phases.Phases[2], phases.Phases[3]
+	assert.Equal(t, 3*time.Millisecond, tlsPhase.AvgLatency, "复用连接的握手耗时记为 0")
+	assert.Equal(t, 12*time.Millisecond, tlsPhase.MaxLatency)
+	assert.Equal(t, 10*time.Millisecond, ttfb.AvgLatency)
+
+	// JSON 中的延迟以毫秒输出
+	data, err := json.Marshal(ttfb)
+	assert.NoError(t, err)
+	assert.Contains(t, string(data), `"avg_latency":10`)
+}
@@ -13,6 +13,8 @@
 import (
 	"encoding/json"
 	"fmt"
+	"maps"
+	"slices"
 	"time"
 
 	"github.com/kamalyes/go-logger"
@@ -26,6 +28,8 @@ type Report struct {
 	SuccessRequests uint64  `json:"success_requests"`
 	FailedRequests  uint64  `json:"failed_requests"`
 	SkippedRequests uint64  `json:"skipped_requests"` // 跳过请求数
+	DelayedRequests uint64  `json:"delayed_requests"` // 开放模型：因无空闲worker而排队的请求数
+	DroppedRequests uint64  `json:"dropped_requests"` // 开放模型：因队列已满而丢弃的请求数
 	SuccessRate     float64 `json:"success_rate"`     // 百分比 0-100
 
 	// 时间统计
@@ -35,10 +39,15 @@ type Report struct {
 	AvgLatency time.Duration `json:"avg_latency"`
 
 	// 百分位统计
-	P50Latency time.Duration `json:"p50_latency"`
-	P90Latency time.Duration `json:"p90_latency"`
-	P95Latency time.Duration `json:"p95_latency"`
-	P99Latency time.Duration `json:"p99_latency"`
+	P50Latency   time.Duration `json:"p50_latency"`
+	P90Latency   time.Duration `json:"p90_latency"`
+	P95Latency   time.Duration `json:"p95_latency"`
+	P99Latency   time.Duration `json:"p99_latency"`
+	P999Latency  time.Duration `json:"p999_latency"`  // P99.9
+	P9999Latency time.Duration `json:"p9999_latency"` // P99.99
+
+	// 协调遗漏校正回填的样本数（仅启用校正时非零）
+	CorrectedSamples uint64 `json:"corrected_samples,omitempty"`
 
 	// 性能指标
 	QPS       float64 `json:"qps"`
@@ -50,16 +59,40 @@ type Report struct {
 	// 状态码统计
 	StatusCodes map[int]uint64 `json:"status_codes,omitempty"`
 
+	// 分组统计：按接口（多API配置）、场景（多场景模式）和负载阶段（多阶段模式）拆分
+	APIStats      []*BreakdownStats `json:"api_stats,omitempty"`
+	ScenarioStats []*BreakdownStats `json:"scenario_stats,omitempty"`
+	StageStats    []*BreakdownStats `json:"stage_stats,omitempty"`
+
+	// 时间序列（按固定间隔分桶的吞吐、错误、延迟百分位和活跃worker数）
+	TimeSeries []*TimePoint `json:"time_series,omitempty"`
+
+	// HTTP 请求阶段耗时分解（DNS/连接/TLS/首字节/传输）和连接复用率
+	Phases *PhaseBreakdown `json:"phases,omitempty"`
+
+	// gRPC 流式调用统计（首条消息耗时、单条消息延迟、消息间隔、消息吞吐、流结束状态码）
+	Streams *StreamBreakdown `json:"streams,omitempty"`
+
+	// WebSocket 会话统计（建连耗时、单连接消息速率、关联ID往返延迟、断开原因）
+	WebSocket *WebSocketBreakdown `json:"websocket,omitempty"`
+
+	// MQTT 统计（建连结果与连接风暴峰值、收发和保留消息数、端到端延迟）
+	MQTT *MQTTBreakdown `json:"mqtt,omitempty"`
+
+	// SLO 阈值评估结果
+	Thresholds []*ThresholdResult `json:"thresholds,omitempty"`
+
 	// 请求明细（静态报告用，实时报告不加载）
 	RequestDetails []*RequestResult `json:"request_details,omitempty"`
 
 	// === 实时报告专用字段 ===
-	Timestamp       int64   `json:"timestamp,omitempty"`        // Unix时间戳
-	Elapsed         int64   `json:"elapsed_seconds"`            // 已耗时（秒）- 移除omitempty确保始终输出
-	IsCompleted     bool    `json:"is_completed,omitempty"`     // 是否完成
-	IsPaused        bool    `json:"is_paused,omitempty"`        // 是否暂停
-	IsStopped       bool    `json:"is_stopped,omitempty"`       // 是否停止
-	RecentDurations []int64 `json:"recent_durations,omitempty"` // 最近响应时间（毫秒）用于实时图表
+	Timestamp       int64      `json:"timestamp,omitempty"`        // Unix时间戳
+	Elapsed         int64      `json:"elapsed_seconds"`            // 已耗时（秒）- 移除omitempty确保始终输出
+	IsCompleted     bool       `json:"is_completed,omitempty"`     // 是否完成
+	IsPaused        bool       `json:"is_paused,omitempty"`        // 是否暂停
+	IsStopped       bool       `json:"is_stopped,omitempty"`       // 是否停止
+	RecentDurations []int64    `json:"recent_durations,omitempty"` // 最近响应时间（毫秒）用于实时图表
+	CurrentStage    *StageInfo `json:"current_stage,omitempty"`    // 当前负载阶段（多阶段模式）
 
 	// 运行模式标识
 	RunMode RunMode `json:"run_mode,omitempty"`
@@ -67,10 +100,52 @@ type Report struct {
 	// 配置信息（用于报告显示）
 	Protocol    string `json:"protocol,omitempty"`    // 协议类型: http/grpc/websocket
 	Concurrency uint64 `json:"concurrency,omitempty"` // 并发数
-	TotalReqs   uint64 `json:"total_reqs,omitempty"`  // 计划请求数
+	TotalReqs   uint64 `json:"total_reqs,omitempty"`  // 计划请求数（按时间运行时为实际请求数）
 	logger      logger.ILogger
 }
 
+// StageInfo 当前负载阶段信息（多阶段模式）
+type StageInfo struct {
+	Index         int     `json:"index"`             // 阶段序号（从1开始）
+	Total         int     `json:"total"`             // 阶段总数
+	Name          string  `json:"name,omitempty"`    // 阶段名称
+	Target        float64 `json:"target"`            // 当前目标值（并发数或RPS，阶段内线性过渡）
+	IsRPS         bool    `json:"is_rps,omitempty"`  // 目标是否为RPS
+	ActiveWorkers uint64  `json:"active_workers"`    // 当前活跃worker数
+	Remaining     int64   `json:"remaining_seconds"` // 本阶段剩余时间（秒）
+}
+
+// ThresholdResult 单个阈值的评估结果
+type ThresholdResult struct {
+	Expr     string `json:"expr"`               // 阈值表达式
+	Scenario string `json:"scenario,omitempty"` // 作用的场景（多场景模式）
+	API      string `json:"api,omitempty"`      // 作用的API（场景和API均为空表示全局）
+	Actual   string `json:"actual"`             // 实际值
+	Passed   bool   `json:"passed"`             // 是否达标
+	Aborted  bool   `json:"aborted,omitempty"`  // 是否因该阈值提前终止压测
+}
+
+// Scope 阈值作用范围：全局、场景、API 或 场景/API
+func (t *ThresholdResult) Scope() string {
+	switch {
+	case t.API != "":
+		return APIStatsKey(t.Scenario, t.API)
+	case t.Scenario != "":
+		return "场景 " + t.Scenario
+	}
+	return "全局"
+}
+
+// ThresholdsPassed 所有阈值是否达标（未配置阈值时返回 true）
+func (r *Report) ThresholdsPassed() bool {
+	for _, t := range r.Thresholds {
+		if !t.Passed {
+			return false
+		}
+	}
+	return true
+}
+
 // Print 打印报告（使用单个多列表格）
 func (r *Report) Print() {
 	r.logger.Info("📊 压测统计报告")
@@ -133,10 +208,60 @@ func (r *Report) Print() {
 			"指标2": "P99",
 			"值2":  r.P99Latency.String(),
 		},
+		{
+			"分类":  "📈 基础统计",
+			"指标":  "跳过请求",
+			"值":   fmt.Sprintf("%d", r.SkippedRequests),
+			"分类2": "⏱️  响应时间",
+			"指标2": "P99.9",
+			"值2":  r.P999Latency.String(),
+		},
+		{
+			"分类":  "⚡ 性能指标",
+			"指标":  "校正样本",
+			"值":   fmt.Sprintf("%d", r.CorrectedSamples),
+			"分类2": "⏱️  响应时间",
+			"指标2": "P99.99",
+			"值2":  r.P9999Latency.String(),
+		},
 	}
 
 	r.logger.ConsoleTable(reportData)
 
+	// 开放模型调度统计（如果有排队或丢弃）
+	if r.DelayedRequests > 0 || r.DroppedRequests > 0 {
+		r.logger.ConsoleTable([]map[string]interface{}{
+			{
+				"指标": "排队请求（无空闲worker）",
+				"值":  fmt.Sprintf("%d", r.DelayedRequests),
+			},
+			{
+				"指标": "丢弃请求（队列已满）",
+				"值":  fmt.Sprintf("%d", r.DroppedRequests),
+			},
+		})
+	}
+
+	// 接口和阶段分组统计（如果有）
+	r.printBreakdown("接口", r.APIStats)
+	r.printBreakdown("场景", r.ScenarioStats)
+	r.printBreakdown("阶段", r.StageStats)
+
+	// 请求阶段耗时（仅HTTP）
+	r.printPhases()
+
+	// 流式调用统计（仅gRPC流）
+	r.printStreams()
+
+	// WebSocket 会话统计（仅会话模式）
+	r.printWebSocket()
+
+	// MQTT 统计（仅MQTT）
+	r.printMQTT()
+
+	// 各场景的请求阶段耗时和协议统计（多场景模式）
+	r.printScenarioDetails()
+
 	// 错误统计（如果有）
 	if len(r.Errors) > 0 {
 		errorStats := make([]map[string]interface{}, 0, len(r.Errors))
@@ -152,6 +277,195 @@ func (r *Report) Print() {
 		}
 		r.logger.ConsoleTable(errorStats)
 	}
+
+	// 阈值评估结果（放在最后，便于在 CI 日志中查看）
+	r.printThresholds()
+}
+
+// printThresholds 打印阈值评估结果
+func (r *Report) printThresholds() {
+	if len(r.Thresholds) == 0 {
+		return
+	}
+	rows := make([]map[string]interface{}, 0, len(r.Thresholds))
+	for _, t := range r.Thresholds {
+		status := "✅ 通过"
+		if !t.Passed {
+			status = "❌ 未达标"
+		}
+		if t.Aborted {
+			status += "（提前终止）"
+		}
+		rows = append(rows, map[string]interface{}{
+			"阈值":  t.Expr,
+			"范围":  t.Scope(),
+			"实际值": t.Actual,
+			"结果":  status,
+		})
+	}
+	r.logger.Info("🎯 SLO 阈值")
+	r.logger.ConsoleTable(rows)
+}
+
+// printScenarioDetails 按场景打印请求阶段耗时和协议统计
+func (r *Report) printScenarioDetails() {
+	for _, scenario := range r.ScenarioStats {
+		if scenario.Phases == nil && scenario.Streams == nil && scenario.WebSocket == nil && scenario.MQTT == nil {
+			continue
+		}
+		r.logger.Infof("🎭 场景 [%s]", scenario.Name)
+		view := &Report{
+			Phases:    scenario.Phases,
+			Streams:   scenario.Streams,
+			WebSocket: scenario.WebSocket,
+			MQTT:      scenario.MQTT,
+			logger:    r.logger,
+		}
+		view.printPhases()
+		view.printStreams()
+		view.printWebSocket()
+		view.printMQTT()
+	}
+}
+
+// printPhases 打印请求阶段耗时分解
+func (r *Report) printPhases() {
+	if r.Phases == nil {
+		return
+	}
+	rows := make([]map[string]interface{}, 0, len(r.Phases.Phases))
+	for _, p := range r.Phases.Phases {
+		rows = append(rows, map[string]interface{}{
+			"阶段":   p.Label,
+			"平均耗时": p.AvgLatency.String(),
+			"P50":  p.P50Latency.String(),
+			"P95":  p.P95Latency.String(),
+			"P99":  p.P99Latency.String(),
+			"最大耗时": p.MaxLatency.String(),
+		})
+	}
+	r.logger.Infof("🔬 请求阶段耗时（连接复用率 %.2f%%，%d/%d）", r.Phases.ReuseRate, r.Phases.ReusedConnections, r.Phases.TracedRequests)
+	r.logger.ConsoleTable(rows)
+}
+
+// printStreams 打印流式调用统计
+func (r *Report) printStreams() {
+	if r.Streams == nil {
+		return
+	}
+	s := r.Streams
+	r.logger.Infof("📡 流式调用：%d 个流，发送 %d 条（%.2f/s），接收 %d 条（%.2f/s），重连 %d 次",
+		s.Streams, s.MessagesSent, s.SentPerSec, s.MessagesReceived, s.ReceivedPerSec, s.Reconnects)
+
+	rows := make([]map[string]interface{}, 0, len(s.Latencies))
+	for _, l := range s.Latencies {
+		rows = append(rows, map[string]interface{}{
+			"指标":   l.Label,
+			"平均耗时": l.AvgLatency.String(),
+			"P50":  l.P50Latency.String(),
+			"P95":  l.P95Latency.String(),
+			"P99":  l.P99Latency.String(),
+			"最大耗时": l.MaxLatency.String(),
+		})
+	}
+	if len(rows) > 0 {
+		r.logger.ConsoleTable(rows)
+	}
+
+	codes := make([]map[string]interface{}, 0, len(s.Codes))
+	for _, code := range slices.Sorted(maps.Keys(s.Codes)) {
+		codes = append(codes, map[string]interface{}{
+			"状态码": code,
+			"流数量": s.Codes[code],
+		})
+	}
+	r.logger.ConsoleTable(codes)
+}
+
+// printWebSocket 打印 WebSocket 会话统计
+func (r *Report) printWebSocket() {
+	if r.WebSocket == nil {
+		return
+	}
+	ws := r.WebSocket
+	r.logger.Infof("🔌 WebSocket 会话：%d 个连接，发送 %d 条（%.2f/s），接收 %d 条（%.2f/s），单连接接收速率 平均 %.2f/s 最大 %.2f/s，心跳 %d 次",
+		ws.Sessions, ws.MessagesSent, ws.SentPerSec, ws.MessagesReceived, ws.ReceivedPerSec, ws.AvgConnReceiveRate, ws.MaxConnReceiveRate, ws.Pings)
+
+	rows := make([]map[string]interface{}, 0, len(ws.Latencies))
+	for _, l := range ws.Latencies {
+		rows = append(rows, map[string]interface{}{
+			"指标":   l.Label,
+			"平均耗时": l.AvgLatency.String(),
+			"P50":  l.P50Latency.String(),
+			"P95":  l.P95Latency.String(),
+			"P99":  l.P99Latency.String(),
+			"最大耗时": l.MaxLatency.String(),
+		})
+	}
+	r.logger.ConsoleTable(rows)
+
+	reasons := make([]map[string]interface{}, 0, len(ws.CloseReasons))
+	for _, reason := range slices.Sorted(maps.Keys(ws.CloseReasons)) {
+		reasons = append(reasons, map[string]interface{}{
+			"断开原因": reason,
+			"连接数":  ws.CloseReasons[reason],
+		})
+	}
+	r.logger.ConsoleTable(reasons)
+}
+
+// printMQTT 打印 MQTT 统计
+func (r *Report) printMQTT() {
+	if r.MQTT == nil {
+		return
+	}
+	m := r.MQTT
+	r.logger.Infof("📡 MQTT：建连 %d 次（失败 %d，峰值 %d/s），发布 %d 条（%.2f/s），接收 %d 条（%.2f/s，保留消息 %d 条）",
+		m.Connects, m.ConnectFailures, m.PeakConnectsPerSec, m.Published, m.PublishedPerSec, m.Received, m.ReceivedPerSec, m.Retained)
+
+	rows := make([]map[string]interface{}, 0, len(m.Latencies))
+	for _, l := range m.Latencies {
+		rows = append(rows, map[string]interface{}{
+			"指标":   l.Label,
+			"平均耗时": l.AvgLatency.String(),
+			"P50":  l.P50Latency.String(),
+			"P95":  l.P95Latency.String(),
+			"P99":  l.P99Latency.String(),
+			"最大耗时": l.MaxLatency.String(),
+		})
+	}
+	r.logger.ConsoleTable(rows)
+
+	results := make([]map[string]interface{}, 0, len(m.ConnectResults))
+	for _, result := range slices.Sorted(maps.Keys(m.ConnectResults)) {
+		results = append(results, map[string]interface{}{
+			"建连结果": result,
+			"次数":   m.ConnectResults[result],
+		})
+	}
+	r.logger.ConsoleTable(results)
+}
+
+// printBreakdown 打印分组统计表格（只有一个分组时与总体数据相同，不重复打印）
+func (r *Report) printBreakdown(title string, groups []*BreakdownStats) {
+	if len(groups) < 2 {
+		return
+	}
+	rows := make([]map[string]interface{}, 0, len(groups))
+	for _, g := range groups {
+		rows = append(rows, map[string]interface{}{
+			title:  g.Name,
+			"请求数":  fmt.Sprintf("%d", g.TotalRequests),
+			"失败":   fmt.Sprintf("%d", g.FailedRequests),
+			"成功率":  fmt.Sprintf("%.2f%%", g.SuccessRate),
+			"QPS":  fmt.Sprintf("%.2f", g.QPS),
+			"平均耗时": g.AvgLatency.String(),
+			"P95":  g.P95Latency.String(),
+			"P99":  g.P99Latency.String(),
+			"最大耗时": g.MaxLatency.String(),
+		})
+	}
+	r.logger.ConsoleTable(rows)
 }
 
 // Summary 返回简短摘要
@@ -171,23 +485,27 @@ func (r *Report) MarshalJSON() ([]byte, error) {
 	return json.Marshal(&struct {
 		*Alias
 		// 添加毫秒格式的字段供前端使用
-		AvgLatency  float64 `json:"avg_latency"`
-		MinLatency  float64 `json:"min_latency"`
-		MaxLatency  float64 `json:"max_latency"`
-		P50Latency  float64 `json:"p50_latency"`
-		P90Latency  float64 `json:"p90_latency"`
-		P95Latency  float64 `json:"p95_latency"`
-		P99Latency  float64 `json:"p99_latency"`
-		TotalTimeMs float64 `json:"total_time_ms"`
+		AvgLatency   float64 `json:"avg_latency"`
+		MinLatency   float64 `json:"min_latency"`
+		MaxLatency   float64 `json:"max_latency"`
+		P50Latency   float64 `json:"p50_latency"`
+		P90Latency   float64 `json:"p90_latency"`
+		P95Latency   float64 `json:"p95_latency"`
+		P99Latency   float64 `json:"p99_latency"`
+		P999Latency  float64 `json:"p999_latency"`
+		P9999Latency float64 `json:"p9999_latency"`
+		TotalTimeMs  float64 `json:"total_time_ms"`
 	}{
-		Alias:       (*Alias)(r),
-		AvgLatency:  float64(r.AvgLatency.Microseconds()) / 1000.0,
-		MinLatency:  float64(r.MinLatency.Microseconds()) / 1000.0,
-		MaxLatency:  float64(r.MaxLatency.Microseconds()) / 1000.0,
-		P50Latency:  float64(r.P50Latency.Microseconds()) / 1000.0,
-		P90Latency:  float64(r.P90Latency.Microseconds()) / 1000.0,
-		P95Latency:  float64(r.P95Latency.Microseconds()) / 1000.0,
-		P99Latency:  float64(r.P99Latency.Microseconds()) / 1000.0,
-		TotalTimeMs: float64(r.TotalTime.Microseconds()) / 1000.0,
+		Alias:        (*Alias)(r),
+		AvgLatency:   float64(r.AvgLatency.Microseconds()) / 1000.0,
+		MinLatency:   float64(r.MinLatency.Microseconds()) / 1000.0,
+		MaxLatency:   float64(r.MaxLatency.Microseconds()) / 1000.0,
+		P50Latency:   float64(r.P50Latency.Microseconds()) / 1000.0,
+		P90Latency:   float64(r.P90Latency.Microseconds()) / 1000.0,
+		P95Latency:   float64(r.P95Latency.Microseconds()) / 1000.0,
+		P99Latency:   float64(r.P99Latency.Microseconds()) / 1000.0,
+		P999Latency:  float64(r.P999Latency.Microseconds()) / 1000.0,
+		P9999Latency: float64(r.P9999Latency.Microseconds()) / 1000.0,
+		TotalTimeMs:  float64(r.TotalTime.Microseconds()) / 1000.0,
 	})
 }
@@ -18,14 +18,42 @@ const ELEMENT_IDS = {
   P90: 'p90',
   P95: 'p95',
   P99: 'p99',
+  P999: 'p999',
+  P9999: 'p9999',
   ELAPSED: 'elapsed',
   TEST_DURATION: 'test-duration',
+  STAGE_CARD: 'stage-card',
+  CURRENT_STAGE: 'current-stage',
   
   // 容器元素
   FILE_LOADER: 'fileLoader',
   METRICS_GRID: 'metricsGrid',
   FILE_NAME: 'fileName',
   DETAILS_TBODY: 'details-tbody',
+  API_STATS_SECTION: 'apiStatsSection',
+  API_STATS_TBODY: 'api-stats-tbody',
+  SCENARIO_STATS_SECTION: 'scenarioStatsSection',
+  SCENARIO_STATS_TBODY: 'scenario-stats-tbody',
+  SCENARIO_VIEW: 'scenario-view',
+  STAGE_STATS_SECTION: 'stageStatsSection',
+  STAGE_STATS_TBODY: 'stage-stats-tbody',
+  THRESHOLDS_SECTION: 'thresholdsSection',
+  THRESHOLDS_TBODY: 'thresholds-tbody',
+  PHASES_SECTION: 'phasesSection',
+  PHASES_TBODY: 'phases-tbody',
+  PHASE_REUSE: 'phase-reuse',
+  STREAMS_SECTION: 'streamsSection',
+  STREAMS_TBODY: 'streams-tbody',
+  STREAM_SUMMARY: 'stream-summary',
+  STREAM_CODES: 'stream-codes',
+  WEBSOCKET_SECTION: 'websocketSection',
+  WEBSOCKET_TBODY: 'websocket-tbody',
+  WEBSOCKET_SUMMARY: 'websocket-summary',
+  WEBSOCKET_REASONS: 'websocket-reasons',
+  MQTT_SECTION: 'mqttSection',
+  MQTT_TBODY: 'mqtt-tbody',
+  MQTT_SUMMARY: 'mqtt-summary',
+  MQTT_CONNECT_RESULTS: 'mqtt-connect-results',
   
   // Tab标签
   TAB_ALL: 'tab-all',
@@ -62,6 +90,9 @@ const ELEMENT_IDS = {
   
   // 图表
   DURATION_CHART: 'durationChart',
+  QPS_CHART: 'qpsChart',
+  LATENCY_CHART: 'latencyChart',
+  PHASE_CHART: 'phaseChart',
   STATUS_CHART: 'statusChart',
   ERROR_CHART: 'errorChart'
 };
@@ -169,7 +200,7 @@ function formatHttpMethod(method) {
   return '<span class="http-method ' + className + '">' + upperMethod + '</span>';
 }
 
-let durationChart, statusChart, errorChart;
+let durationChart, statusChart, errorChart, qpsChart, latencyChart, phaseChart;
 const isRealtime = (typeof IS_REALTIME_PLACEHOLDER !== 'undefined' && IS_REALTIME_PLACEHOLDER) || false;
 const jsonFilename = "JSON_FILENAME_PLACEHOLDER" || "index.json";
 let serverTotal = 0; // 服务器返回的真实总数（用于实时模式分页显示）
@@ -387,13 +418,88 @@ function initCharts() {
     console.error("errorChartDom not found!");
   }
 
+  initTimeSeriesCharts();
+
   window.addEventListener("resize", () => {
     if (durationChart) durationChart.resize();
     if (statusChart) statusChart.resize();
     if (errorChart) errorChart.resize();
+    if (qpsChart) qpsChart.resize();
+    if (latencyChart) latencyChart.resize();
+    if (phaseChart) phaseChart.resize();
   });
 }
 
+// ============ 时间序列图表 ============
+function initTimeSeriesCharts() {
+  const qpsChartDom = document.getElementById(ELEMENT_IDS.QPS_CHART);
+  const latencyChartDom = document.getElementById(ELEMENT_IDS.LATENCY_CHART);
+
+  if (qpsChartDom) {
+    qpsChart = echarts.init(qpsChartDom);
+    qpsChart.setOption({
+      title: { text: "吞吐趋势", left: "center" },
+      tooltip: { trigger: "axis" },
+      legend: { top: 28, data: ["QPS", "错误数", "活跃worker"] },
+      grid: { top: 70 },
+      xAxis: { type: "category", name: "秒", data: [] },
+      yAxis: [
+        { type: "value", name: "请求/s" },
+        { type: "value", name: "worker", splitLine: { show: false } },
+      ],
+      series: [
+        { name: "QPS", type: "line", smooth: true, showSymbol: false, data: [], lineStyle: { color: "#667eea" }, itemStyle: { color: "#667eea" } },
+        { name: "错误数", type: "line", smooth: true, showSymbol: false, data: [], lineStyle: { color: "#f45c43" }, itemStyle: { color: "#f45c43" } },
+        { name: "活跃worker", type: "line", step: "end", showSymbol: false, yAxisIndex: 1, data: [], lineStyle: { color: "#ffc107", type: "dashed" }, itemStyle: { color: "#ffc107" } },
+      ],
+    });
+  }
+
+  if (latencyChartDom) {
+    latencyChart = echarts.init(latencyChartDom);
+    latencyChart.setOption({
+      title: { text: "延迟趋势", left: "center" },
+      tooltip: { trigger: "axis" },
+      legend: { top: 28, data: ["P50", "P95", "P99"] },
+      grid: { top: 70 },
+      xAxis: { type: "category", name: "秒", data: [] },
+      yAxis: { type: "value", name: "响应时间 (ms)" },
+      series: [
+        { name: "P50", type: "line", smooth: true, showSymbol: false, data: [], itemStyle: { color: "#38ef7d" } },
+        { name: "P95", type: "line", smooth: true, showSymbol: false, data: [], itemStyle: { color: "#ffc107" } },
+        { name: "P99", type: "line", smooth: true, showSymbol: false, data: [], itemStyle: { color: "#f45c43" } },
+      ],
+    });
+  }
+}
+
+function updateTimeSeriesCharts(series) {
+  if (!series || series.length === 0) return;
+  const offsets = series.map((p) => p.offset);
+
+  if (qpsChart) {
+    qpsChart.setOption({
+      xAxis: { data: offsets },
+      series: [
+        { data: series.map((p) => +(p.qps || 0).toFixed(2)) },
+        { data: series.map((p) => p.errors || 0) },
+        { data: series.map((p) => p.active_workers || 0) },
+      ],
+    });
+  }
+
+  if (latencyChart) {
+    latencyChart.setOption({
+      xAxis: { data: offsets },
+      series: [
+        { data: series.map((p) => p.p50_latency || 0) },
+        { data: series.map((p) => p.p95_latency || 0) },
+        { data: series.map((p) => p.p99_latency || 0) },
+      ],
+    });
+  }
+}
+
 // ============ 静态模式数据更新 ============
 function updateStaticMetrics(data) {
   const setTextContent = (id, value) => {
@@ -419,13 +525,310 @@ function updateStaticMetrics(data) {
   setTextContent(ELEMENT_IDS.P90, (data.p90_latency || 0).toFixed(2) + "ms");
   setTextContent(ELEMENT_IDS.P95, (data.p95_latency || 0).toFixed(2) + "ms");
   setTextContent(ELEMENT_IDS.P99, (data.p99_latency || 0).toFixed(2) + "ms");
+  setTextContent(ELEMENT_IDS.P999, (data.p999_latency || 0).toFixed(2) + "ms");
+  setTextContent(ELEMENT_IDS.P9999, (data.p9999_latency || 0).toFixed(2) + "ms");
   
   // 静态报告特有的：测试时长（使用total_time）
   const totalTimeSec = data.total_time_ms ? (data.total_time_ms / 1000).toFixed(2) : 0;
   setTextContent(ELEMENT_IDS.TEST_DURATION, totalTimeSec + "s");
+
+  renderBreakdowns(data);
+}
+
+// ============ 分组统计（按接口/场景/阶段） ============
+function renderBreakdowns(data) {
+  renderBreakdownTable(ELEMENT_IDS.API_STATS_SECTION, ELEMENT_IDS.API_STATS_TBODY, data.api_stats);
+  renderBreakdownTable(ELEMENT_IDS.SCENARIO_STATS_SECTION, ELEMENT_IDS.SCENARIO_STATS_TBODY, data.scenario_stats);
+  renderBreakdownTable(ELEMENT_IDS.STAGE_STATS_SECTION, ELEMENT_IDS.STAGE_STATS_TBODY, data.stage_stats);
+  renderThresholds(data.thresholds);
+  renderScenarioSelector(data.scenario_stats);
+  scenarioViewData = data;
+  renderScenarioDetails(data);
+}
+
+// ============ 场景视图（多场景模式） ============
+let selectedScenario = '';
+let scenarioViewData = null;
+
+// 选中场景时返回该场景的时间序列、阶段耗时和协议统计，否则返回全局数据
+function scenarioView(data) {
+  if (!selectedScenario || !data) return data;
+  return (data.scenario_stats || []).find((s) => s.name === selectedScenario) || data;
+}
+
+function renderScenarioDetails(data) {
+  const view = scenarioView(data);
+  renderPhases(view.phases);
+  renderStreams(view.streams);
+  renderWebSocket(view.websocket);
+  renderMQTT(view.mqtt);
+}
+
+// 同步场景下拉框选项（场景列表变化时才重建）
+function renderScenarioSelector(scenarios) {
+  const select = document.getElementById(ELEMENT_IDS.SCENARIO_VIEW);
+  if (!select) return;
+  const names = (scenarios || []).map((s) => s.name);
+  const current = Array.from(select.options).slice(1).map((o) => o.value);
+  if (names.join('\n') === current.join('\n')) return;
+
+  select.length = 0;
+  select.add(new Option('全部场景', ''));
+  names.forEach((name) => select.add(new Option(name, name)));
+  if (!names.includes(selectedScenario)) selectedScenario = '';
+  select.value = selectedScenario;
+  select.onchange = () => {
+    selectedScenario = select.value;
+    if (!scenarioViewData) return;
+    renderScenarioDetails(scenarioViewData);
+    updateTimeSeriesCharts(scenarioView(scenarioViewData).time_series);
+  };
+}
+
+// ============ 请求阶段耗时 ============
+const PHASE_COLORS = ['#8e44ad', '#3498db', '#1abc9c', '#f39c12', '#e74c3c'];
+
+function renderPhases(phases) {
+  const section = document.getElementById(ELEMENT_IDS.PHASES_SECTION);
+  const tbody = document.getElementById(ELEMENT_IDS.PHASES_TBODY);
+  if (!section || !tbody) return;
+  if (!phases || !phases.phases || phases.phases.length === 0) {
+    section.style.display = 'none';
+    return;
+  }
+  section.style.display = '';
+
+  const reuse = document.getElementById(ELEMENT_IDS.PHASE_REUSE);
+  if (reuse) {
+    reuse.textContent = '连接复用率 ' + (phases.reuse_rate || 0).toFixed(2) + '% (' +
+      (phases.reused_connections || 0) + '/' + (phases.traced_requests || 0) + ')';
+  }
+
+  const ms = (v) => (v || 0).toFixed(2) + 'ms';
+  tbody.innerHTML = phases.phases.map((p) => '<tr>' +
+    '<td><strong>' + escapeHtml(p.label) + '</strong></td>' +
+    '<td>' + ms(p.avg_latency) + '</td>' +
+    '<td>' + ms(p.p50_latency) + '</td>' +
+    '<td>' + ms(p.p90_latency) + '</td>' +
+    '<td>' + ms(p.p95_latency) + '</td>' +
+    '<td>' + ms(p.p99_latency) + '</td>' +
+    '<td>' + ms(p.max_latency) + '</td>' +
+    '</tr>').join('');
+
+  // 区块初始隐藏，显示后再初始化图表以获得正确尺寸
+  const chartDom = document.getElementById(ELEMENT_IDS.PHASE_CHART);
+  if (!chartDom || typeof echarts === 'undefined') return;
+  if (!phaseChart) phaseChart = echarts.init(chartDom);
+
+  // 每行为一个统计口径，按阶段堆叠
+  const rows = [
+    { label: '平均', key: 'avg_latency' },
+    { label: 'P50', key: 'p50_latency' },
+    { label: 'P90', key: 'p90_latency' },
+    { label: 'P95', key: 'p95_latency' },
+    { label: 'P99', key: 'p99_latency' },
+  ];
+  phaseChart.setOption({
+    title: { text: '阶段耗时分解', left: 'center' },
+    tooltip: { trigger: 'axis', axisPointer: { type: 'shadow' } },
+    legend: { top: 28, data: phases.phases.map((p) => p.label) },
+    grid: { top: 70, left: 60 },
+    xAxis: { type: 'value', name: 'ms' },
+    yAxis: { type: 'category', data: rows.map((r) => r.label), inverse: true },
+    series: phases.phases.map((p, i) => ({
+      name: p.label,
+      type: 'bar',
+      stack: 'total',
+      itemStyle: { color: PHASE_COLORS[i % PHASE_COLORS.length] },
+      data: rows.map((r) => +(p[r.key] || 0).toFixed(3)),
+    })),
+  });
+  phaseChart.resize();
+}
+
+// ============ 流式调用 ============
+function renderStreams(streams) {
+  const section = document.getElementById(ELEMENT_IDS.STREAMS_SECTION);
+  const tbody = document.getElementById(ELEMENT_IDS.STREAMS_TBODY);
+  if (!section || !tbody) return;
+  if (!streams || !streams.streams) {
+    section.style.display = 'none';
+    return;
+  }
+  section.style.display = '';
+
+  const summary = document.getElementById(ELEMENT_IDS.STREAM_SUMMARY);
+  if (summary) {
+    summary.textContent = streams.streams + ' 个流，发送 ' + (streams.messages_sent || 0) +
+      ' 条 (' + (streams.sent_per_sec || 0).toFixed(2) + '/s)，接收 ' + (streams.messages_received || 0) +
+      ' 条 (' + (streams.received_per_sec || 0).toFixed(2) + '/s)' +
+      (streams.reconnects ? '，重连 ' + streams.reconnects + ' 次' : '');
+  }
+
+  const ms = (v) => (v || 0).toFixed(2) + 'ms';
+  tbody.innerHTML = (streams.latencies || []).map((l) => '<tr>' +
+    '<td><strong>' + escapeHtml(l.label) + '</strong></td>' +
+    '<td>' + ms(l.avg_latency) + '</td>' +
+    '<td>' + ms(l.p50_latency) + '</td>' +
+    '<td>' + ms(l.p90_latency) + '</td>' +
+    '<td>' + ms(l.p95_latency) + '</td>' +
+    '<td>' + ms(l.p99_latency) + '</td>' +
+    '<td>' + ms(l.max_latency) + '</td>' +
+    '</tr>').join('');
+
+  const codes = document.getElementById(ELEMENT_IDS.STREAM_CODES);
+  if (codes) {
+    codes.innerHTML = '<strong>流结束状态码：</strong>' + Object.keys(streams.codes || {}).sort().map((code) =>
+      '<span class="' + (code === 'OK' ? 'status-success' : 'status-error') + '" style="margin-right: 12px;">' +
+      escapeHtml(code) + ' × ' + streams.codes[code] + '</span>').join('');
+  }
+}
+
+// ============ WebSocket 会话 ============
+const WS_NORMAL_CLOSE_REASONS = ['hold_expired', 'canceled', 'server_close_1000', 'server_close_1001'];
+
+function renderWebSocket(ws) {
+  const section = document.getElementById(ELEMENT_IDS.WEBSOCKET_SECTION);
+  const tbody = document.getElementById(ELEMENT_IDS.WEBSOCKET_TBODY);
+  if (!section || !tbody) return;
+  if (!ws || !ws.sessions) {
+    section.style.display = 'none';
+    return;
+  }
+  section.style.display = '';
+
+  const rate = (v) => (v || 0).toFixed(2) + '/s';
+  const summary = document.getElementById(ELEMENT_IDS.WEBSOCKET_SUMMARY);
+  if (summary) {
+    summary.textContent = ws.sessions + ' 个连接，发送 ' + (ws.messages_sent || 0) + ' 条 (' + rate(ws.sent_per_sec) +
+      ')，接收 ' + (ws.messages_received || 0) + ' 条 (' + rate(ws.received_per_sec) + ')，单连接接收速率 平均 ' +
+      rate(ws.avg_conn_receive_rate) + ' 最大 ' + rate(ws.max_conn_receive_rate) + '，心跳 ' + (ws.pings || 0) + ' 次';
+  }
+
+  const ms = (v) => (v || 0).toFixed(2) + 'ms';
+  tbody.innerHTML = (ws.latencies || []).map((l) => '<tr>' +
+    '<td><strong>' + escapeHtml(l.label) + '</strong></td>' +
+    '<td>' + ms(l.avg_latency) + '</td>' +
+    '<td>' + ms(l.p50_latency) + '</td>' +
+    '<td>' + ms(l.p90_latency) + '</td>' +
+    '<td>' + ms(l.p95_latency) + '</td>' +
+    '<td>' + ms(l.p99_latency) + '</td>' +
+    '<td>' + ms(l.max_latency) + '</td>' +
+    '</tr>').join('');
+
+  const reasons = document.getElementById(ELEMENT_IDS.WEBSOCKET_REASONS);
+  if (reasons) {
+    reasons.innerHTML = '<strong>断开原因：</strong>' + Object.keys(ws.close_reasons || {}).sort().map((reason) =>
+      '<span class="' + (WS_NORMAL_CLOSE_REASONS.includes(reason) ? 'status-success' : 'status-error') + '" style="margin-right: 12px;">' +
+      escapeHtml(reason) + ' × ' + ws.close_reasons[reason] + '</span>').join('');
+  }
+}
+
+// ============ MQTT ============
+function renderMQTT(mqtt) {
+  const section = document.getElementById(ELEMENT_IDS.MQTT_SECTION);
+  const tbody = document.getElementById(ELEMENT_IDS.MQTT_TBODY);
+  if (!section || !tbody) return;
+  if (!mqtt) {
+    section.style.display = 'none';
+    return;
+  }
+  section.style.display = '';
+
+  const rate = (v) => (v || 0).toFixed(2) + '/s';
+  const summary = document.getElementById(ELEMENT_IDS.MQTT_SUMMARY);
+  if (summary) {
+    summary.textContent = '建连 ' + (mqtt.connects || 0) + ' 次（失败 ' + (mqtt.connect_failures || 0) + '，峰值 ' +
+      (mqtt.peak_connects_per_sec || 0) + '/s），发布 ' + (mqtt.published || 0) + ' 条 (' + rate(mqtt.published_per_sec) +
+      ')，接收 ' + (mqtt.received || 0) + ' 条 (' + rate(mqtt.received_per_sec) + '，保留消息 ' + (mqtt.retained || 0) + ' 条)';
+  }
+
+  const ms = (v) => (v || 0).toFixed(2) + 'ms';
+  tbody.innerHTML = (mqtt.latencies || []).map((l) => '<tr>' +
+    '<td><strong>' + escapeHtml(l.label) + '</strong></td>' +
+    '<td>' + ms(l.avg_latency) + '</td>' +
+    '<td>' + ms(l.p50_latency) + '</td>' +
+    '<td>' + ms(l.p90_latency) + '</td>' +
+    '<td>' + ms(l.p95_latency) + '</td>' +
+    '<td>' + ms(l.p99_latency) + '</td>' +
+    '<td>' + ms(l.max_latency) + '</td>' +
+    '</tr>').join('');
+
+  const results = document.getElementById(ELEMENT_IDS.MQTT_CONNECT_RESULTS);
+  if (results) {
+    results.innerHTML = '<strong>建连结果：</strong>' + Object.keys(mqtt.connect_results || {}).sort().map((result) =>
+      '<span class="' + (result === 'accepted' ? 'status-success' : 'status-error') + '" style="margin-right: 12px;">' +
+      escapeHtml(result) + ' × ' + mqtt.connect_results[result] + '</span>').join('');
+  }
+}
+
+// ============ SLO 阈值 ============
+function renderThresholds(thresholds) {
+  const section = document.getElementById(ELEMENT_IDS.THRESHOLDS_SECTION);
+  const tbody = document.getElementById(ELEMENT_IDS.THRESHOLDS_TBODY);
+  if (!section || !tbody) return;
+  if (!thresholds || thresholds.length === 0) {
+    section.style.display = 'none';
+    return;
+  }
+  section.style.display = '';
+
+  tbody.innerHTML = thresholds.map((t) => {
+    let result = t.passed ? '✅ 通过' : '❌ 未达标';
+    if (t.aborted) result += '（提前终止）';
+    const color = t.passed ? '#28a745' : '#dc3545';
+    return '<tr>' +
+      '<td style="color: ' + color + ';"><strong>' + result + '</strong></td>' +
+      '<td>' + escapeHtml(t.expr) + '</td>' +
+      '<td>' + escapeHtml(thresholdScope(t)) + '</td>' +
+      '<td>' + escapeHtml(t.actual) + '</td>' +
+      '</tr>';
+  }).join('');
+}
+
+// 阈值作用范围：全局、场景、API 或 场景/API
+function thresholdScope(t) {
+  if (t.api) return t.scenario ? t.scenario + '/' + t.api : t.api;
+  if (t.scenario) return '场景 ' + t.scenario;
+  return '全局';
+}
+
+function renderBreakdownTable(sectionId, tbodyId, groups) {
+  const section = document.getElementById(sectionId);
+  const tbody = document.getElementById(tbodyId);
+  if (!section || !tbody) return;
+  if (!groups || groups.length === 0) {
+    section.style.display = 'none';
+    return;
+  }
+  section.style.display = '';
+
+  const ms = (v) => (v || 0).toFixed(2) + 'ms';
+  tbody.innerHTML = groups.map((g) => {
+    const codes = Object.entries(g.status_codes || {})
+      .map(([code, count]) => statusLabel(code) + ': ' + count)
+      .join(', ');
+    const rateColor = (g.success_rate || 0) >= 99 ? '#28a745' : '#dc3545';
+    return '<tr>' +
+      '<td><strong>' + escapeHtml(g.name) + '</strong></td>' +
+      '<td>' + (g.total_requests || 0) + '</td>' +
+      '<td>' + (g.success_requests || 0) + '</td>' +
+      '<td>' + (g.failed_requests || 0) + '</td>' +
+      '<td style="color: ' + rateColor + ';">' + (g.success_rate || 0).toFixed(2) + '%</td>' +
+      '<td>' + (g.qps || 0).toFixed(2) + '</td>' +
+      '<td>' + ms(g.avg_latency) + '</td>' +
+      '<td>' + ms(g.p50_latency) + '</td>' +
+      '<td>' + ms(g.p95_latency) + '</td>' +
+      '<td>' + ms(g.p99_latency) + '</td>' +
+      '<td>' + ms(g.max_latency) + '</td>' +
+      '<td>' + escapeHtml(codes || '-') + '</td>' +
+      '</tr>';
+  }).join('');
 }
 
 function updateChartsFromData(data) {
+  updateTimeSeriesCharts(scenarioView(data).time_series);
+
   if (data.request_details && data.request_details.length > 0 && durationChart) {
     const recentDetails = data.request_details.slice(-1000);
     const durations = recentDetails.map((d) => d.duration / 1000000);
@@ -442,7 +845,7 @@ function updateChartsFromData(data) {
     const statusCounts = statusCodes.map((code) => data.status_codes[code]);
 
     statusChart.setOption({
-      xAxis: { data: statusCodes },
+      xAxis: { data: statusCodes.map(statusLabel) },
       series: [{ data: statusCounts }],
     });
   }
@@ -511,7 +914,7 @@ function renderStaticDetails(details) {
         "</td>";
       html += "<td>" + formatHttpMethod(req.method || req.request_method) + "</td>";
       html += "<td>" + ((req.duration ? req.duration / 1000000 : req.duration_ms) || 0).toFixed(2) + "ms</td>";
-      html += "<td>" + (req.skipped ? '-' : (req.status_code || 0)) + "</td>";
+      html += "<td>" + (req.skipped ? '-' : detailStatusLabel(req)) + "</td>";
       html +=
         '<td class="' +
         statusClass +
@@ -795,6 +1198,24 @@ function changePageSize() {
 }
 
 // ============ 工具函数 ============
+// gRPC 状态码名称（下标即状态码）
+const GRPC_CODE_NAMES = [
+  'OK', 'CANCELLED', 'UNKNOWN', 'INVALID_ARGUMENT', 'DEADLINE_EXCEEDED', 'NOT_FOUND',
+  'ALREADY_EXISTS', 'PERMISSION_DENIED', 'RESOURCE_EXHAUSTED', 'FAILED_PRECONDITION', 'ABORTED',
+  'OUT_OF_RANGE', 'UNIMPLEMENTED', 'INTERNAL', 'UNAVAILABLE', 'DATA_LOSS', 'UNAUTHENTICATED',
+];
+
+// 统计中的状态码展示文本：HTTP 状态码（>=100）显示数字，gRPC 状态码（<100）显示名称
+function statusLabel(code) {
+  const n = Number(code);
+  return n < 100 && GRPC_CODE_NAMES[n] ? GRPC_CODE_NAMES[n] : String(code);
+}
+
+// 请求明细的状态码展示文本（HTTP 未收到响应时为 0）
+function detailStatusLabel(req) {
+  return req.protocol === 'grpc' ? statusLabel(req.status_code || 0) : (req.status_code || 0);
+}
+
 function escapeHtml(text) {
   if (!text) return "";
   const div = document.createElement("div");
@@ -977,7 +1398,7 @@ function generateDetailContent(req) {
   }
   html += '<div class="detail-section"><strong>请求方法:</strong> ' + formatHttpMethod(req.method || req.request_method) + '</div>';
   html += '<div class="detail-section"><strong>响应时间:</strong><pre>' + ((req.duration ? req.duration / 1000000 : req.duration_ms) || 0).toFixed(2) + 'ms</pre></div>';
-  html += '<div class="detail-section"><strong>状态码:</strong><pre>' + (req.status_code || 0) + '</pre></div>';
+  html += '<div class="detail-section"><strong>状态码:</strong><pre>' + detailStatusLabel(req) + '</pre></div>';
   html += '</div>';
   
   // Headers Tab
@@ -1208,8 +1629,12 @@ if (isRealtime) {
     document.getElementById(ELEMENT_IDS.P90).textContent = (data.p90_latency || 0).toFixed(2) + "ms";
     document.getElementById(ELEMENT_IDS.P95).textContent = (data.p95_latency || 0).toFixed(2) + "ms";
     document.getElementById(ELEMENT_IDS.P99).textContent = (data.p99_latency || 0).toFixed(2) + "ms";
+    document.getElementById(ELEMENT_IDS.P999).textContent = (data.p999_latency || 0).toFixed(2) + "ms";
+    document.getElementById(ELEMENT_IDS.P9999).textContent = (data.p9999_latency || 0).toFixed(2) + "ms";
     
     document.getElementById(ELEMENT_IDS.ELAPSED).textContent = (data.elapsed_seconds || 0) + "s";
+    updateStageInfo(data.current_stage);
+    renderBreakdowns(data);
     
     // 检查任务状态并更新按钮
     const pauseBtn = document.getElementById(ELEMENT_IDS.PAUSE_BTN);
@@ -1266,6 +1691,11 @@ if (isRealtime) {
 
   // 更新实时图表
   window.updateCharts = function (data) {
+    const view = scenarioView(data);
+    updateTimeSeriesCharts(view.time_series);
+    renderPhases(view.phases);
+    renderStreams(view.streams);
+
     if (data.recent_durations && data.recent_durations.length > 0 && durationChart) {
       const indices = data.recent_durations.map((_, i) => i + 1);
       durationChart.setOption({
@@ -1278,13 +1708,13 @@ if (isRealtime) {
       const codes = Object.keys(data.status_codes).sort();
       const values = codes.map((code) => data.status_codes[code]);
       statusChart.setOption({
-        xAxis: { data: codes },
+        xAxis: { data: codes.map(statusLabel) },
         series: [
           {
             data: values.map((v, i) => ({
               value: v,
               itemStyle: {
-                color: codes[i].startsWith("2")
+                color: codes[i].startsWith("2") || codes[i] === "0"
                   ? "#38ef7d"
                   : codes[i].startsWith("4")
                   ? "#f45c43"
@@ -1311,6 +1741,24 @@ if (isRealtime) {
     }
   };
 
+  // 实时模式 - 更新当前负载阶段（多阶段模式）
+  window.updateStageInfo = function (stage) {
+    const card = document.getElementById(ELEMENT_IDS.STAGE_CARD);
+    const el = document.getElementById(ELEMENT_IDS.CURRENT_STAGE);
+    if (!card || !el) return;
+    if (!stage) {
+      card.style.display = 'none';
+      return;
+    }
+    card.style.display = '';
+    const unit = stage.is_rps ? ' RPS' : ' 并发';
+    const name = stage.name ? ' ' + stage.name : '';
+    el.textContent = stage.index + '/' + stage.total + name;
+    el.title = '目标: ' + stage.target + unit +
+      ' | 活跃worker: ' + stage.active_workers +
+      ' | 剩余: ' + stage.remaining_seconds + 's';
+  };
+
   let lastDetailsCount = 0;
   const openDetails = new Set();
 
@@ -1411,7 +1859,7 @@ if (isRealtime) {
                     }">${detail.url || "-"}</td>
                     <td>${formatHttpMethod(detail.method)}</td>
                     <td>${(detail.duration / 1000000).toFixed(2)}ms</td>
-                    <td>${detail.skipped ? '-' : (detail.status_code || "-")}</td>
+                    <td>${detail.skipped ? '-' : (detail.protocol === 'grpc' ? detailStatusLabel(detail) : (detail.status_code || "-"))}</td>
                     <td class="${
                       detail.skipped ? "status-warning" : (detail.success ? "status-success" : "status-error")
                     }">${detail.skipped ? "⏭ 跳过" : (detail.success ? "✓ 成功" : "✗ 失败")}</td>
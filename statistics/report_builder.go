@@ -41,18 +41,18 @@ func (rb *ReportBuilder) BuildReport(totalTime time.Duration, includeDetails boo
 	errors := c.errors.ToMap()
 	statusCodes := c.statusCodes.ToMap()
 
-	// 第三步：读取时长数据（需要锁，但使用深拷贝快速复制）
+	// 第三步：读取时长数据（需要锁，直方图固定大小，复制开销恒定）
+	var latencies *Histogram
 	report := syncx.WithRLockReturnValue(c.mu, func() *Report {
-		var durationsCopy []float64
-
-		// 使用 syncx.DeepCopy 深拷贝切片
-		if err := syncx.DeepCopy(&durationsCopy, &c.durations); err == nil {
-			// 深拷贝成功
-		} else {
-			// 降级为手动复制
-			durationsCopy = make([]float64, len(c.durations))
-			copy(durationsCopy, c.durations)
-		}
+		latencies = c.latencies.Clone()
+		apiStats := buildNamedBreakdown(c.apis, totalTime)
+		scenarioStats := buildNamedBreakdown(c.scenarios, totalTime)
+		stageStats := buildStageBreakdown(c.stages)
+		timeSeries := c.timeSeries.snapshot(0)
+		phases := c.phases.snapshot()
+		streams := c.streams.snapshot(totalTime)
+		webSocket := c.webSocket.snapshot(totalTime)
+		mqtt := c.mqtt.snapshot(totalTime)
 
 		// 在锁内快速构建报告
 		return &Report{
@@ -60,17 +60,28 @@ func (rb *ReportBuilder) BuildReport(totalTime time.Duration, includeDetails boo
 			SuccessRequests: successReqs,
 			FailedRequests:  failedReqs,
 			SkippedRequests: skippedReqs,
+			DelayedRequests: c.delayedRequests.Load(),
+			DroppedRequests: c.droppedRequests.Load(),
 			TotalTime:       totalTime,
 			MinLatency:      c.minDuration,
 			MaxLatency:      c.maxDuration,
 			TotalSize:       c.totalSize,
 			Errors:          errors,
 			StatusCodes:     statusCodes,
+			APIStats:        apiStats,
+			ScenarioStats:   scenarioStats,
+			StageStats:      stageStats,
+			TimeSeries:      timeSeries,
+			Phases:          phases,
+			Streams:         streams,
+			WebSocket:       webSocket,
+			MQTT:            mqtt,
+			Thresholds:      c.GetThresholdResults(),
 			RequestDetails:  nil,       // 详情数据从SQLite按需加载
 			RunMode:         c.runMode, // 传递运行模式
 			Protocol:        c.protocol,
 			Concurrency:     c.concurrency,
-			TotalReqs:       c.totalReqs,
+			TotalReqs:       mathx.IF(c.duration > 0, totalReqs, c.totalReqs),
 			logger:          c.logger,
 		}
 	})
@@ -81,13 +92,13 @@ func (rb *ReportBuilder) BuildReport(totalTime time.Duration, includeDetails boo
 	}
 
 	// 第四步：在锁外计算统计数据
-	rb.calculateStats(report, totalReqs, totalTime)
+	rb.calculateStats(report, totalReqs, totalTime, latencies)
 
 	return report
 }
 
 // calculateStats 计算统计数据（提取公共逻辑）
-func (rb *ReportBuilder) calculateStats(report *Report, totalReqs uint64, totalTime time.Duration) {
+func (rb *ReportBuilder) calculateStats(report *Report, totalReqs uint64, totalTime time.Duration, latencies *Histogram) {
 	if totalReqs == 0 {
 		return
 	}
@@ -97,16 +108,27 @@ func (rb *ReportBuilder) calculateStats(report *Report, totalReqs uint64, totalT
 	report.AvgLatency = rb.collector.totalDuration / time.Duration(totalReqs)
 	report.QPS = float64(totalReqs) / totalTime.Seconds()
 
-	// 计算百分位
-	if len(rb.collector.durations) > 0 {
-		percentiles := mathx.Percentiles(rb.collector.durations, 50, 90, 95, 99)
-		report.P50Latency = time.Duration(percentiles[50] * float64(time.Second))
-		report.P90Latency = time.Duration(percentiles[90] * float64(time.Second))
-		report.P95Latency = time.Duration(percentiles[95] * float64(time.Second))
-		report.P99Latency = time.Duration(percentiles[99] * float64(time.Second))
+	// 从直方图计算百分位
+	applyPercentiles(report, latencies)
+	if latencies.Count() > totalReqs {
+		report.CorrectedSamples = latencies.Count() - totalReqs
 	}
 }
 
+// applyPercentiles 从直方图填充报告的百分位字段
+func applyPercentiles(report *Report, latencies *Histogram) {
+	if latencies == nil || latencies.Count() == 0 {
+		return
+	}
+	percentiles := latencies.Percentiles(50, 90, 95, 99, 99.9, 99.99)
+	report.P50Latency = percentiles[50]
+	report.P90Latency = percentiles[90]
+	report.P95Latency = percentiles[95]
+	report.P99Latency = percentiles[99]
+	report.P999Latency = percentiles[99.9]
+	report.P9999Latency = percentiles[99.99]
+}
+
 // BuildSummary 构建摘要（不包含明细，最快）
 func (rb *ReportBuilder) BuildSummary(totalTime time.Duration) *Report {
 	return rb.BuildReport(totalTime, false)
@@ -157,27 +179,35 @@ func (rb *ReportBuilder) BuildRealtimeReport(startTime time.Time, endTime time.T
 	report.IsCompleted = isCompleted
 	report.IsPaused = isPaused
 	report.IsStopped = isStopped
+	report.CurrentStage = rb.collector.GetStage()
+
+	// 实时推送只保留最近的时间点，完整序列在最终报告中输出
+	report.TimeSeries = recentTimePoints(report.TimeSeries)
+	for _, scenario := range report.ScenarioStats {
+		scenario.TimeSeries = recentTimePoints(scenario.TimeSeries)
+	}
 
-	// 获取最近20个响应时间用于实时图表
+	// 获取最近的响应时间用于实时图表
 	c := rb.collector
 	report.RecentDurations = syncx.WithRLockReturnValue(c.mu, func() []int64 {
-		durationsLen := len(c.durations)
-		if durationsLen == 0 {
+		recent := c.recentDurations()
+		if len(recent) == 0 {
 			return nil
 		}
-
-		start := 0
-		if durationsLen > 20 {
-			start = durationsLen - 20
-		}
-
-		recent := make([]int64, 0, durationsLen-start)
-		for i := start; i < durationsLen; i++ {
-			// durations 是 float64 (秒)，转换为毫秒
-			recent = append(recent, int64(c.durations[i]*1000))
+		result := make([]int64, 0, len(recent))
+		for _, d := range recent {
+			result = append(result, d.Milliseconds())
 		}
-		return recent
+		return result
 	})
 
 	return report
 }
+
+// recentTimePoints 实时推送保留的最近时间点
+func recentTimePoints(points []*TimePoint) []*TimePoint {
+	if n := len(points); n > realtimeTimeSeriesPoints {
+		return points[n-realtimeTimeSeriesPoints:]
+	}
+	return points
+}
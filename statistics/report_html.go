@@ -131,11 +131,23 @@
                 <div class="metric-label">P99</div>
                 <div class="metric-value" id="p99">0ms</div>
             </div>
+            <div class="metric-card">
+                <div class="metric-label">P99.9</div>
+                <div class="metric-value" id="p999">0ms</div>
+            </div>
+            <div class="metric-card">
+                <div class="metric-label">P99.99</div>
+                <div class="metric-value" id="p9999">0ms</div>
+            </div>
             {{if .IsRealtime}}
             <div class="metric-card">
                 <div class="metric-label">运行时间</div>
                 <div class="metric-value" id="elapsed">0s</div>
             </div>
+            <div class="metric-card" id="stage-card" style="display: none;">
+                <div class="metric-label">当前阶段</div>
+                <div class="metric-value" id="current-stage">-</div>
+            </div>
             {{else}}
             <div class="metric-card">
                 <div class="metric-label">测试时长</div>
@@ -150,6 +162,14 @@
                 <div class="chart-container">
                     <div id="durationChart" class="chart"></div>
                 </div>
+                <div style="display: grid; grid-template-columns: 1fr 1fr; gap: 20px;">
+                    <div class="chart-container">
+                        <div id="qpsChart" class="chart"></div>
+                    </div>
+                    <div class="chart-container">
+                        <div id="latencyChart" class="chart"></div>
+                    </div>
+                </div>
                 <div style="display: grid; grid-template-columns: 1fr 1fr; gap: 20px;">
                     <div class="chart-container">
                         <div id="statusChart" class="chart"></div>
@@ -160,6 +180,184 @@
                 </div>
             </div>
             
+            <div class="section" id="thresholdsSection" style="display: none;">
+                <div class="section-title">🎯 SLO 阈值</div>
+                <div style="overflow-x: auto;">
+                    <table>
+                        <thead>
+                            <tr>
+                                <th>结果</th>
+                                <th>阈值</th>
+                                <th>范围</th>
+                                <th>实际值</th>
+                            </tr>
+                        </thead>
+                        <tbody id="thresholds-tbody"></tbody>
+                    </table>
+                </div>
+            </div>
+            
+            <div class="section" id="apiStatsSection" style="display: none;">
+                <div class="section-title">🔀 接口统计</div>
+                <div style="overflow-x: auto;">
+                    <table>
+                        <thead>
+                            <tr>
+                                <th>接口</th>
+                                <th>请求数</th>
+                                <th>成功</th>
+                                <th>失败</th>
+                                <th>成功率</th>
+                                <th>QPS</th>
+                                <th>平均</th>
+                                <th>P50</th>
+                                <th>P95</th>
+                                <th>P99</th>
+                                <th>最大</th>
+                                <th>状态码</th>
+                            </tr>
+                        </thead>
+                        <tbody id="api-stats-tbody"></tbody>
+                    </table>
+                </div>
+            </div>
+            
+            <div class="section" id="scenarioStatsSection" style="display: none;">
+                <div class="section-title">🎭 场景统计 <span style="font-size: 14px; font-weight: normal; color: #666;">图表与耗时明细：<select id="scenario-view"></select></span></div>
+                <div style="overflow-x: auto;">
+                    <table>
+                        <thead>
+                            <tr>
+                                <th>场景</th>
+                                <th>请求数</th>
+                                <th>成功</th>
+                                <th>失败</th>
+                                <th>成功率</th>
+                                <th>QPS</th>
+                                <th>平均</th>
+                                <th>P50</th>
+                                <th>P95</th>
+                                <th>P99</th>
+                                <th>最大</th>
+                                <th>状态码</th>
+                            </tr>
+                        </thead>
+                        <tbody id="scenario-stats-tbody"></tbody>
+                    </table>
+                </div>
+            </div>
+            
+            <div class="section" id="stageStatsSection" style="display: none;">
+                <div class="section-title">📶 阶段统计</div>
+                <div style="overflow-x: auto;">
+                    <table>
+                        <thead>
+                            <tr>
+                                <th>阶段</th>
+                                <th>请求数</th>
+                                <th>成功</th>
+                                <th>失败</th>
+                                <th>成功率</th>
+                                <th>QPS</th>
+                                <th>平均</th>
+                                <th>P50</th>
+                                <th>P95</th>
+                                <th>P99</th>
+                                <th>最大</th>
+                                <th>状态码</th>
+                            </tr>
+                        </thead>
+                        <tbody id="stage-stats-tbody"></tbody>
+                    </table>
+                </div>
+            </div>
+            
+            <div class="section" id="phasesSection" style="display: none;">
+                <div class="section-title">🔬 请求阶段耗时 <span id="phase-reuse" style="font-size: 14px; font-weight: normal; color: #666;"></span></div>
+                <div class="chart-container">
+                    <div id="phaseChart" class="chart"></div>
+                </div>
+                <div style="overflow-x: auto;">
+                    <table>
+                        <thead>
+                            <tr>
+                                <th>阶段</th>
+                                <th>平均</th>
+                                <th>P50</th>
+                                <th>P90</th>
+                                <th>P95</th>
+                                <th>P99</th>
+                                <th>最大</th>
+                            </tr>
+                        </thead>
+                        <tbody id="phases-tbody"></tbody>
+                    </table>
+                </div>
+            </div>
+            
+            <div class="section" id="streamsSection" style="display: none;">
+                <div class="section-title">📡 流式调用 <span id="stream-summary" style="font-size: 14px; font-weight: normal; color: #666;"></span></div>
+                <div style="overflow-x: auto;">
+                    <table>
+                        <thead>
+                            <tr>
+                                <th>指标</th>
+                                <th>平均</th>
+                                <th>P50</th>
+                                <th>P90</th>
+                                <th>P95</th>
+                                <th>P99</th>
+                                <th>最大</th>
+                            </tr>
+                        </thead>
+                        <tbody id="streams-tbody"></tbody>
+                    </table>
+                </div>
+                <div id="stream-codes" style="margin-top: 12px;"></div>
+            </div>
+            
+            <div class="section" id="websocketSection" style="display: none;">
+                <div class="section-title">🔌 WebSocket 会话 <span id="websocket-summary" style="font-size: 14px; font-weight: normal; color: #666;"></span></div>
+                <div style="overflow-x: auto;">
+                    <table>
+                        <thead>
+                            <tr>
+                                <th>指标</th>
+                                <th>平均</th>
+                                <th>P50</th>
+                                <th>P90</th>
+                                <th>P95</th>
+                                <th>P99</th>
+                                <th>最大</th>
+                            </tr>
+                        </thead>
+                        <tbody id="websocket-tbody"></tbody>
+                    </table>
+                </div>
+                <div id="websocket-reasons" style="margin-top: 12px;"></div>
+            </div>
+            
+            <div class="section" id="mqttSection" style="display: none;">
+                <div class="section-title">📡 MQTT <span id="mqtt-summary" style="font-size: 14px; font-weight: normal; color: #666;"></span></div>
+                <div style="overflow-x: auto;">
+                    <table>
+                        <thead>
+                            <tr>
+                                <th>指标</th>
+                                <th>平均</th>
+                                <th>P50</th>
+                                <th>P90</th>
+                                <th>P95</th>
+                                <th>P99</th>
+                                <th>最大</th>
+                            </tr>
+                        </thead>
+                        <tbody id="mqtt-tbody"></tbody>
+                    </table>
+                </div>
+                <div id="mqtt-connect-results" style="margin-top: 12px;"></div>
+            </div>
+            
             <div class="section">
                 <div class="section-title">
                     <span>📋 请求明细</span>
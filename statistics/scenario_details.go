@@ -0,0 +1,54 @@
+/*
+ * @Author: kamalyes 501893067@qq.com
+ * @Date: 2026-02-21 00:00:00
+ * @LastEditors: kamalyes 501893067@qq.com
+ * @LastEditTime: 2026-02-21 00:00:00
+ * @FilePath: \go-stress\statistics\scenario_details.go
+ * @Description: 场景明细统计 - 多场景模式下按场景拆分时间序列、请求阶段耗时和协议统计
+ *
+ * Copyright (c) 2026 by kamalyes, All Rights Reserved.
+ */
+package statistics
+
+import (
+	"time"
+)
+
+// scenarioDetails 单个场景的时间序列、请求阶段耗时和协议统计（由 Collector 在写锁内更新）
+// 与全局统计使用相同的累计结构，场景分组因此输出与全局报告一致的指标
+type scenarioDetails struct {
+	timeSeries *timeSeries
+	phases     *phaseStats
+	streams    *streamStats
+	webSocket  *webSocketStats
+	mqtt       *mqttStats
+}
+
+// newScenarioDetails 创建场景明细统计，时间序列与全局使用相同的分桶间隔
+func newScenarioDetails(interval time.Duration) *scenarioDetails {
+	return &scenarioDetails{
+		timeSeries: newTimeSeries(interval),
+		phases:     newPhaseStats(),
+		streams:    newStreamStats(),
+		webSocket:  newWebSocketStats(),
+		mqtt:       newMQTTStats(),
+	}
+}
+
+// record 记录一次请求结果，activeWorkers 为该场景正在执行请求的worker数
+func (d *scenarioDetails) record(now time.Time, result *RequestResult, activeWorkers int64) {
+	d.timeSeries.record(now, result, activeWorkers)
+	d.phases.record(result)
+	d.streams.record(result)
+	d.webSocket.record(result)
+	d.mqtt.record(result)
+}
+
+// apply 将场景明细写入分组统计快照（调用方持有读锁）
+func (d *scenarioDetails) apply(stats *BreakdownStats, elapsed time.Duration) {
+	stats.TimeSeries = d.timeSeries.snapshot(0)
+	stats.Phases = d.phases.snapshot()
+	stats.Streams = d.streams.snapshot(elapsed)
+	stats.WebSocket = d.webSocket.snapshot(elapsed)
+	stats.MQTT = d.mqtt.snapshot(elapsed)
+}
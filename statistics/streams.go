@@ -0,0 +1,114 @@
+/*
+ * @Author: kamalyes 501893067@qq.com
+ * @Date: 2026-02-12 00:00:00
+ * @LastEditors: kamalyes 501893067@qq.com
+ * @LastEditTime: 2026-02-12 00:00:00
+ * @FilePath: \go-stress\statistics\streams.go
+ * @Description: 流式调用统计（gRPC 流、SSE 事件流） - 首条消息耗时、单条消息延迟、消息间隔、消息吞吐和流结束状态码
+ *
+ * Copyright (c) 2026 by kamalyes, All Rights Reserved.
+ */
+package statistics
+
+import (
+	"time"
+)
+
+// streamStats 流式调用累计数据（由 Collector 在写锁内更新）
+type streamStats struct {
+	streams       uint64
+	sent          uint64
+	received      uint64
+	reconnects    uint64
+	firstTotal    time.Duration
+	firstCount    uint64 // 收到过消息的流数（首条消息耗时的样本数）
+	firstMessages *Histogram
+	messageTotal  time.Duration
+	messages      *Histogram
+	arrivalTotal  time.Duration
+	arrivals      *Histogram
+	codes         map[string]uint64
+}
+
+// newStreamStats 创建流式调用累计数据
+func newStreamStats() *streamStats {
+	return &streamStats{
+		firstMessages: NewHistogram(),
+		messages:      NewHistogram(),
+		arrivals:      NewHistogram(),
+		codes:         make(map[string]uint64),
+	}
+}
+
+// record 记录一个流的统计（非流式请求忽略）
+func (s *streamStats) record(result *RequestResult) {
+	if result.Skipped || result.Stream == nil {
+		return
+	}
+	stream := result.Stream
+	s.streams++
+	s.sent += uint64(stream.Sent)
+	s.received += uint64(stream.Received)
+	s.reconnects += uint64(stream.Reconnects)
+	s.codes[stream.Code]++
+	if stream.Received > 0 {
+		s.firstCount++
+		s.firstTotal += stream.FirstMessage
+		s.firstMessages.Record(stream.FirstMessage)
+	}
+	for _, d := range stream.MessageLatencies {
+		s.messageTotal += d
+		s.messages.Record(d)
+	}
+	for _, d := range stream.InterArrivals {
+		s.arrivalTotal += d
+		s.arrivals.Record(d)
+	}
+}
+
+// snapshot 生成流式调用报告（调用方持有读锁，没有流式请求时返回 nil）
+func (s *streamStats) snapshot(totalTime time.Duration) *StreamBreakdown {
+	if s.streams == 0 {
+		return nil
+	}
+	codes := make(map[string]uint64, len(s.codes))
+	for code, count := range s.codes {
+		codes[code] = count
+	}
+	breakdown := &StreamBreakdown{
+		Streams:          s.streams,
+		MessagesSent:     s.sent,
+		MessagesReceived: s.received,
+		Reconnects:       s.reconnects,
+		Codes:            codes,
+	}
+	if totalTime > 0 {
+		breakdown.SentPerSec = float64(s.sent) / totalTime.Seconds()
+		breakdown.ReceivedPerSec = float64(s.received) / totalTime.Seconds()
+	}
+	if s.firstCount > 0 {
+		breakdown.Latencies = append(breakdown.Latencies,
+			newPhaseStat("first_message", "首条消息", s.firstTotal, s.firstCount, s.firstMessages))
+	}
+	if count := s.messages.Count(); count > 0 {
+		breakdown.Latencies = append(breakdown.Latencies,
+			newPhaseStat("message", "单条消息", s.messageTotal, count, s.messages))
+	}
+	if count := s.arrivals.Count(); count > 0 {
+		breakdown.Latencies = append(breakdown.Latencies,
+			newPhaseStat("inter_arrival", "消息间隔", s.arrivalTotal, count, s.arrivals))
+	}
+	return breakdown
+}
+
+// StreamBreakdown 流式调用统计
+type StreamBreakdown struct {
+	Streams          uint64            `json:"streams"`           // 流数量
+	MessagesSent     uint64            `json:"messages_sent"`     // 发送消息总数
+	MessagesReceived uint64            `json:"messages_received"` // 接收消息总数
+	Reconnects       uint64            `json:"reconnects"`        // 重连总次数（仅SSE）
+	SentPerSec       float64           `json:"sent_per_sec"`      // 每秒发送消息数
+	ReceivedPerSec   float64           `json:"received_per_sec"`  // 每秒接收消息数
+	Latencies        []*PhaseStat      `json:"latencies"`         // 首条消息耗时、单条消息延迟、消息间隔分布
+	Codes            map[string]uint64 `json:"codes"`             // 流结束状态码 -> 流数量
+}
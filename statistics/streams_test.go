@@ -0,0 +1,69 @@
+/*
+ * @Author: kamalyes 501893067@qq.com
+ * @Date: 2026-02-12 00:00:00
+ * @LastEditors: kamalyes 501893067@qq.com
+ * @LastEditTime: 2026-02-12 00:00:00
+ * @FilePath: \go-stress\statistics\streams_test.go
+ * @Description: 流式调用统计测试
+ *
+ * Copyright (c) 2026 by kamalyes, All Rights Reserved.
+ */
+package statistics
+
+import (
+	"encoding/json"
+	"testing"
+	"time"
+
+	"github.com/kamalyes/go-stress/logger"
+	"github.com/kamalyes/go-stress/storage"
+	"github.com/stretchr/testify/assert"
+)
+
+// 测试流式调用统计聚合
+func TestCollectorStreams(t *testing.T) {
+	c := NewCollector(storage.NewMemoryStorage("test", logger.Default), logger.Default)
+	defer c.Close()
+
+	// 一元调用不产生流统计
+	c.Collect(&RequestResult{Success: true, Duration: time.Millisecond})
+	assert.Nil(t, NewReportBuilder(c).BuildSummary(time.Second).Streams)
+
+	c.Collect(&RequestResult{Success: true, Duration: 30 * time.Millisecond, Stream: &StreamStats{
+		Kind: "server", Sent: 1, Received: 3, FirstMessage: 10 * time.Millisecond, Code: "OK",
+		MessageLatencies: []time.Duration{10 * time.Millisecond, 10 * time.Millisecond, 10 * time.Millisecond},
+		InterArrivals:    []time.Duration{10 * time.Millisecond, 5 * time.Millisecond, 15 * time.Millisecond},
+	}})
+	c.Collect(&RequestResult{Success: true, Duration: 20 * time.Millisecond, Stream: &StreamStats{
+		Kind: "server", Sent: 1, Received: 1, FirstMessage: 20 * time.Millisecond, Code: "OK",
+		MessageLatencies: []time.Duration{20 * time.Millisecond},
+		InterArrivals:    []time.Duration{20 * time.Millisecond},
+	}})
+	// 未收到任何消息的失败流不计入首条消息耗时
+	c.Collect(&RequestResult{Success: false, Duration: 5 * time.Millisecond, Stream: &StreamStats{
+		Kind: "server", Sent: 1, Code: "UNAVAILABLE",
+	}})
+
+	streams := NewReportBuilder(c).BuildSummary(2 * time.Second).Streams
+	assert.NotNil(t, streams)
+	assert.Equal(t, uint64(3), streams.Streams)
+	assert.Equal(t, uint64(3), streams.MessagesSent)
+	assert.Equal(t, uint64(4), streams.MessagesReceived)
+	assert.InDelta(t, 2.0, streams.ReceivedPerSec, 0.01)
+	assert.Equal(t, map[string]uint64{"OK": 2, "UNAVAILABLE": 1}, streams.Codes)
+
+	assert.Len(t, streams.Latencies, 3)
+	first, message, arrival := streams.Latencies[0], streams.Latencies[1], streams.Latencies[2]
+	assert.Equal(t, "first_message", first.Name)
+	assert.Equal(t, 15*time.Millisecond, first.AvgLatency)
+	assert.Equal(t, "message", message.Name)
+	assert.Equal(t, 12500*time.Microsecond, message.AvgLatency)
+	assert.Equal(t, 20*time.Millisecond, message.MaxLatency)
+	assert.Equal(t, "inter_arrival", arrival.Name)
+	assert.Equal(t, 12500*time.Microsecond, arrival.AvgLatency)
+	assert.Equal(t, 20*time.Millisecond, arrival.MaxLatency)
+
+	data, err := json.Marshal(streams)
+	assert.NoError(t, err)
+	assert.Contains(t, string(data), `"avg_latency":15`)
+}
@@ -0,0 +1,168 @@
+/*
+ * @Author: kamalyes 501893067@qq.com
+ * @Date: 2026-02-06 00:00:00
+ * @LastEditors: kamalyes 501893067@qq.com
+ * @LastEditTime: 2026-02-06 00:00:00
+ * @FilePath: \go-stress\statistics\timeseries.go
+ * @Description: 时间序列统计 - 按固定间隔分桶记录吞吐、错误、延迟百分位和活跃worker数
+ *
+ * Copyright (c) 2026 by kamalyes, All Rights Reserved.
+ */
+package statistics
+
+import (
+	"time"
+)
+
+const (
+	// DefaultTimeSeriesInterval 默认时间序列分桶间隔
+	DefaultTimeSeriesInterval = time.Second
+	// realtimeTimeSeriesPoints 实时报告推送的最近时间点数量（避免长时间运行时推送数据过大）
+	realtimeTimeSeriesPoints = 300
+)
+
+// TimePoint 时间序列中的一个时间桶
+type TimePoint struct {
+	Timestamp     int64   `json:"timestamp"`      // 桶开始时间（Unix 毫秒）
+	Offset        float64 `json:"offset"`         // 相对首个请求的秒数
+	Requests      uint64  `json:"requests"`       // 本桶完成的请求数
+	Errors        uint64  `json:"errors"`         // 本桶失败的请求数（不含跳过）
+	QPS           float64 `json:"qps"`            // 本桶吞吐
+	P50Latency    float64 `json:"p50_latency"`    // 毫秒
+	P95Latency    float64 `json:"p95_latency"`    // 毫秒
+	P99Latency    float64 `json:"p99_latency"`    // 毫秒
+	ActiveWorkers int64   `json:"active_workers"` // 本桶内观测到的最大活跃worker数
+}
+
+// timeSeries 时间序列累计数据（由 Collector 在写锁内更新）
+// 只有当前桶保留直方图，桶结束时计算百分位后复用，内存与运行时长无关
+type timeSeries struct {
+	interval     time.Duration
+	start        time.Time
+	points       []*TimePoint // 已结束的桶，下标即桶序号
+	current      *TimePoint
+	currentIndex int
+	latencies    *Histogram
+}
+
+// newTimeSeries 创建时间序列
+func newTimeSeries(interval time.Duration) *timeSeries {
+	if interval <= 0 {
+		interval = DefaultTimeSeriesInterval
+	}
+	return &timeSeries{
+		interval:  interval,
+		latencies: NewHistogram(),
+	}
+}
+
+// record 记录一次请求结果
+func (ts *timeSeries) record(now time.Time, result *RequestResult, activeWorkers int64) {
+	if ts.start.IsZero() {
+		ts.start = now
+	}
+
+	index := int(now.Sub(ts.start) / ts.interval)
+	switch {
+	case ts.current == nil:
+		ts.open(index, activeWorkers)
+	case index > ts.currentIndex:
+		// 结束当前桶，期间没有请求完成的桶补零
+		ts.closeCurrent()
+		for i := len(ts.points); i < index; i++ {
+			ts.points = append(ts.points, ts.newPoint(i, activeWorkers))
+		}
+		ts.open(index, activeWorkers)
+	case index < ts.currentIndex:
+		// 迟到的结果（时钟抖动）：只计入已结束桶的计数，百分位不再变化
+		if index >= 0 && index < len(ts.points) {
+			ts.points[index].Requests++
+			if isErrorResult(result) {
+				ts.points[index].Errors++
+			}
+		}
+		return
+	}
+
+	ts.current.Requests++
+	if isErrorResult(result) {
+		ts.current.Errors++
+	}
+	if !result.Skipped {
+		ts.latencies.Record(result.Duration)
+	}
+	ts.current.ActiveWorkers = max(ts.current.ActiveWorkers, activeWorkers)
+}
+
+// open 开始新的当前桶
+func (ts *timeSeries) open(index int, activeWorkers int64) {
+	ts.current = ts.newPoint(index, activeWorkers)
+	ts.currentIndex = index
+	ts.latencies.Reset()
+}
+
+// closeCurrent 结束当前桶并计算百分位
+func (ts *timeSeries) closeCurrent() {
+	ts.fill(ts.current, ts.interval)
+	ts.points = append(ts.points, ts.current)
+	ts.current = nil
+}
+
+// fill 根据当前桶直方图填充吞吐和百分位
+func (ts *timeSeries) fill(point *TimePoint, elapsed time.Duration) {
+	if elapsed > 0 {
+		point.QPS = float64(point.Requests) / elapsed.Seconds()
+	}
+	if ts.latencies.Count() == 0 {
+		return
+	}
+	percentiles := ts.latencies.Percentiles(50, 95, 99)
+	point.P50Latency = float64(percentiles[50].Microseconds()) / 1000.0
+	point.P95Latency = float64(percentiles[95].Microseconds()) / 1000.0
+	point.P99Latency = float64(percentiles[99].Microseconds()) / 1000.0
+}
+
+// newPoint 创建指定序号的空桶
+func (ts *timeSeries) newPoint(index int, activeWorkers int64) *TimePoint {
+	offset := time.Duration(index) * ts.interval
+	return &TimePoint{
+		Timestamp:     ts.start.Add(offset).UnixMilli(),
+		Offset:        offset.Seconds(),
+		ActiveWorkers: activeWorkers,
+	}
+}
+
+// snapshot 复制时间序列（包含进行中的当前桶），limit > 0 时只返回最近 limit 个点（调用方持有读锁）
+func (ts *timeSeries) snapshot(limit int) []*TimePoint {
+	total := len(ts.points)
+	if ts.current != nil {
+		total++
+	}
+	if total == 0 {
+		return nil
+	}
+
+	from := 0
+	if limit > 0 && total > limit {
+		from = total - limit
+	}
+	result := make([]*TimePoint, 0, total-from)
+	for i := from; i < len(ts.points); i++ {
+		point := *ts.points[i]
+		result = append(result, &point)
+	}
+
+	if ts.current != nil {
+		// 进行中的桶按已经过的时间计算吞吐，避免末尾出现虚假的下跌
+		point := *ts.current
+		bucketStart := ts.start.Add(time.Duration(ts.currentIndex) * ts.interval)
+		ts.fill(&point, min(max(time.Since(bucketStart), time.Millisecond), ts.interval))
+		result = append(result, &point)
+	}
+	return result
+}
+
+// isErrorResult 是否计为错误（跳过的请求单独统计，不计入错误）
+func isErrorResult(result *RequestResult) bool {
+	return !result.Success && !result.Skipped
+}
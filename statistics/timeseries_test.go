@@ -0,0 +1,51 @@
+/*
+ * @Author: kamalyes 501893067@qq.com
+ * @Date: 2026-02-06 00:00:00
+ * @LastEditors: kamalyes 501893067@qq.com
+ * @LastEditTime: 2026-02-06 00:00:00
+ * @FilePath: \go-stress\statistics\timeseries_test.go
+ * @Description: 时间序列统计测试
+ *
+ * Copyright (c) 2026 by kamalyes, All Rights Reserved.
+ */
+package statistics
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+// 测试按间隔分桶、空桶补零和迟到结果
+func TestTimeSeriesBuckets(t *testing.T) {
+	ts := newTimeSeries(time.Second)
+	start := time.Now()
+
+	for i := 0; i < 10; i++ {
+		ts.record(start.Add(time.Duration(i)*10*time.Millisecond), &RequestResult{Success: true, Duration: 10 * time.Millisecond}, 4)
+	}
+	// 第 2 秒没有请求，第 3 秒有一个失败请求
+	ts.record(start.Add(2500*time.Millisecond), &RequestResult{Duration: 500 * time.Millisecond}, 2)
+	// 迟到的第 1 秒结果只计数
+	ts.record(start.Add(900*time.Millisecond), &RequestResult{Success: true, Duration: time.Second}, 2)
+
+	points := ts.snapshot(0)
+	assert.Len(t, points, 3)
+
+	assert.Equal(t, uint64(11), points[0].Requests)
+	assert.Equal(t, uint64(0), points[0].Errors)
+	assert.InDelta(t, 10, points[0].QPS, 0.01)
+	assert.InDelta(t, 10, points[0].P99Latency, 0.5)
+	assert.Equal(t, int64(4), points[0].ActiveWorkers)
+
+	assert.Equal(t, uint64(0), points[1].Requests)
+	assert.Equal(t, float64(1), points[1].Offset)
+
+	assert.Equal(t, uint64(1), points[2].Requests)
+	assert.Equal(t, uint64(1), points[2].Errors)
+	assert.InDelta(t, 500, points[2].P50Latency, 5)
+
+	// 只取最近的点
+	assert.Len(t, ts.snapshot(2), 2)
+}
@@ -0,0 +1,119 @@
+/*
+ * @Author: kamalyes 501893067@qq.com
+ * @Date: 2026-02-14 00:00:00
+ * @LastEditors: kamalyes 501893067@qq.com
+ * @LastEditTime: 2026-02-14 00:00:00
+ * @FilePath: \go-stress\statistics\websocket.go
+ * @Description: WebSocket 会话统计 - 建连耗时、单连接消息速率、关联ID往返延迟和断开原因
+ *
+ * Copyright (c) 2026 by kamalyes, All Rights Reserved.
+ */
+package statistics
+
+import (
+	"maps"
+	"time"
+
+	"github.com/kamalyes/go-toolbox/pkg/mathx"
+)
+
+// webSocketStats WebSocket 会话累计数据（由 Collector 在写锁内更新）
+type webSocketStats struct {
+	sessions       uint64
+	connected      uint64 // 建立连接成功的会话数
+	sent           uint64
+	received       uint64
+	pings          uint64
+	connectTotal   time.Duration
+	connects       *Histogram
+	lifetimeTotal  time.Duration
+	lifetimes      *Histogram
+	roundTripTotal time.Duration
+	roundTrips     *Histogram
+	rateTotal      float64 // 各连接每秒接收消息数之和
+	rateMax        float64
+	reasons        map[string]uint64
+}
+
+// newWebSocketStats 创建 WebSocket 会话累计数据
+func newWebSocketStats() *webSocketStats {
+	return &webSocketStats{
+		connects:   NewHistogram(),
+		lifetimes:  NewHistogram(),
+		roundTrips: NewHistogram(),
+		reasons:    make(map[string]uint64),
+	}
+}
+
+// record 记录一个会话的统计（非会话请求忽略）
+func (s *webSocketStats) record(result *RequestResult) {
+	if result.Skipped || result.WebSocket == nil {
+		return
+	}
+	ws := result.WebSocket
+	s.sessions++
+	s.sent += uint64(ws.Sent)
+	s.received += uint64(ws.Received)
+	s.pings += uint64(ws.Pings)
+	s.reasons[ws.CloseReason]++
+	s.connectTotal += ws.Connect
+	s.connects.Record(ws.Connect)
+	for _, d := range ws.RoundTrips {
+		s.roundTripTotal += d
+		s.roundTrips.Record(d)
+	}
+	if ws.Lifetime <= 0 {
+		return
+	}
+	s.connected++
+	s.lifetimeTotal += ws.Lifetime
+	s.lifetimes.Record(ws.Lifetime)
+	rate := float64(ws.Received) / ws.Lifetime.Seconds()
+	s.rateTotal += rate
+	s.rateMax = mathx.Max(s.rateMax, rate)
+}
+
+// snapshot 生成 WebSocket 会话报告（调用方持有读锁，没有会话时返回 nil）
+func (s *webSocketStats) snapshot(totalTime time.Duration) *WebSocketBreakdown {
+	if s.sessions == 0 {
+		return nil
+	}
+	breakdown := &WebSocketBreakdown{
+		Sessions:           s.sessions,
+		MessagesSent:       s.sent,
+		MessagesReceived:   s.received,
+		Pings:              s.pings,
+		MaxConnReceiveRate: s.rateMax,
+		CloseReasons:       maps.Clone(s.reasons),
+	}
+	if totalTime > 0 {
+		breakdown.SentPerSec = float64(s.sent) / totalTime.Seconds()
+		breakdown.ReceivedPerSec = float64(s.received) / totalTime.Seconds()
+	}
+	breakdown.Latencies = append(breakdown.Latencies,
+		newPhaseStat("connect", "建立连接", s.connectTotal, s.sessions, s.connects))
+	if count := s.roundTrips.Count(); count > 0 {
+		breakdown.Latencies = append(breakdown.Latencies,
+			newPhaseStat("round_trip", "往返延迟", s.roundTripTotal, count, s.roundTrips))
+	}
+	if s.connected > 0 {
+		breakdown.AvgConnReceiveRate = s.rateTotal / float64(s.connected)
+		breakdown.Latencies = append(breakdown.Latencies,
+			newPhaseStat("lifetime", "连接时长", s.lifetimeTotal, s.connected, s.lifetimes))
+	}
+	return breakdown
+}
+
+// WebSocketBreakdown WebSocket 会话统计
+type WebSocketBreakdown struct {
+	Sessions           uint64            `json:"sessions"`              // 会话（连接）数
+	MessagesSent       uint64            `json:"messages_sent"`         // 发送消息总数
+	MessagesReceived   uint64            `json:"messages_received"`     // 接收消息总数
+	Pings              uint64            `json:"pings"`                 // 发送心跳总数
+	SentPerSec         float64           `json:"sent_per_sec"`          // 每秒发送消息数
+	ReceivedPerSec     float64           `json:"received_per_sec"`      // 每秒接收消息数
+	AvgConnReceiveRate float64           `json:"avg_conn_receive_rate"` // 单连接平均每秒接收消息数
+	MaxConnReceiveRate float64           `json:"max_conn_receive_rate"` // 单连接最大每秒接收消息数
+	Latencies          []*PhaseStat      `json:"latencies"`             // 建立连接、往返延迟、连接时长分布
+	CloseReasons       map[string]uint64 `json:"close_reasons"`         // 断开原因 -> 会话数
+}
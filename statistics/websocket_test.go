@@ -0,0 +1,62 @@
+/*
+ * @Author: kamalyes 501893067@qq.com
+ * @Date: 2026-02-14 00:00:00
+ * @LastEditors: kamalyes 501893067@qq.com
+ * @LastEditTime: 2026-02-14 00:00:00
+ * @FilePath: \go-stress\statistics\websocket_test.go
+ * @Description: WebSocket 会话统计测试
+ *
+ * Copyright (c) 2026 by kamalyes, All Rights Reserved.
+ */
+package statistics
+
+import (
+	"testing"
+	"time"
+
+	"github.com/kamalyes/go-stress/logger"
+	"github.com/kamalyes/go-stress/storage"
+	"github.com/stretchr/testify/assert"
+)
+
+// 测试 WebSocket 会话统计聚合
+func TestCollectorWebSocket(t *testing.T) {
+	c := NewCollector(storage.NewMemoryStorage("test", logger.Default), logger.Default)
+	defer c.Close()
+
+	// 非会话请求不产生会话统计
+	c.Collect(&RequestResult{Success: true, Duration: time.Millisecond})
+	assert.Nil(t, NewReportBuilder(c).BuildSummary(time.Second).WebSocket)
+
+	c.Collect(&RequestResult{Success: true, Duration: 2 * time.Second, WebSocket: &WebSocketStats{
+		Connect: 10 * time.Millisecond, Lifetime: 2 * time.Second, Sent: 2, Received: 20, Pings: 3,
+		RoundTrips: []time.Duration{4 * time.Millisecond, 6 * time.Millisecond}, CloseReason: "hold_expired",
+	}})
+	c.Collect(&RequestResult{Success: false, Duration: time.Second, WebSocket: &WebSocketStats{
+		Connect: 30 * time.Millisecond, Lifetime: time.Second, Sent: 1, Received: 2, CloseReason: "server_close_1011",
+	}})
+	// 建连失败的会话只计入建连耗时
+	c.Collect(&RequestResult{Success: false, Duration: 20 * time.Millisecond, WebSocket: &WebSocketStats{
+		Connect: 20 * time.Millisecond, CloseReason: "dial_failed",
+	}})
+
+	ws := NewReportBuilder(c).BuildSummary(4 * time.Second).WebSocket
+	assert.NotNil(t, ws)
+	assert.Equal(t, uint64(3), ws.Sessions)
+	assert.Equal(t, uint64(3), ws.MessagesSent)
+	assert.Equal(t, uint64(22), ws.MessagesReceived)
+	assert.Equal(t, uint64(3), ws.Pings)
+	assert.InDelta(t, 5.5, ws.ReceivedPerSec, 0.01)
+	assert.InDelta(t, 6.0, ws.AvgConnReceiveRate, 0.01) // (10/s + 2/s) / 2
+	assert.InDelta(t, 10.0, ws.MaxConnReceiveRate, 0.01)
+	assert.Equal(t, map[string]uint64{"hold_expired": 1, "server_close_1011": 1, "dial_failed": 1}, ws.CloseReasons)
+
+	assert.Len(t, ws.Latencies, 3)
+	connect, roundTrip, lifetime := ws.Latencies[0], ws.Latencies[1], ws.Latencies[2]
+	assert.Equal(t, "connect", connect.Name)
+	assert.Equal(t, 20*time.Millisecond, connect.AvgLatency)
+	assert.Equal(t, "round_trip", roundTrip.Name)
+	assert.Equal(t, 5*time.Millisecond, roundTrip.AvgLatency)
+	assert.Equal(t, "lifetime", lifetime.Name)
+	assert.Equal(t, 1500*time.Millisecond, lifetime.AvgLatency)
+}
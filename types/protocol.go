@@ -12,6 +12,8 @@
 
 import (
 	"context"
+	"strconv"
+	"strings"
 	"time"
 )
 
@@ -22,6 +24,10 @@
 	ProtocolHTTP      ProtocolType = "http"
 	ProtocolGRPC      ProtocolType = "grpc"
 	ProtocolWebSocket ProtocolType = "websocket"
+	ProtocolSSE       ProtocolType = "sse"
+	ProtocolTCP       ProtocolType = "tcp"
+	ProtocolUDP       ProtocolType = "udp"
+	ProtocolMQTT      ProtocolType = "mqtt"
 )
 
 // String 返回协议类型的字符串表示
@@ -29,6 +35,42 @@ func (p ProtocolType) String() string {
 	return string(p)
 }
 
+// grpcCodeNames gRPC 状态码名称（下标即状态码）
+var grpcCodeNames = [...]string{
+	"OK", "CANCELLED", "UNKNOWN", "INVALID_ARGUMENT", "DEADLINE_EXCEEDED", "NOT_FOUND",
+	"ALREADY_EXISTS", "PERMISSION_DENIED", "RESOURCE_EXHAUSTED", "FAILED_PRECONDITION", "ABORTED",
+	"OUT_OF_RANGE", "UNIMPLEMENTED", "INTERNAL", "UNAVAILABLE", "DATA_LOSS", "UNAUTHENTICATED",
+}
+
+// GRPCCodeName 返回 gRPC 状态码名称（OK、UNAVAILABLE、DEADLINE_EXCEEDED…）
+func GRPCCodeName(code int) string {
+	if code >= 0 && code < len(grpcCodeNames) {
+		return grpcCodeNames[code]
+	}
+	return "CODE(" + strconv.Itoa(code) + ")"
+}
+
+// GRPCCodeFromName 根据名称解析 gRPC 状态码（不区分大小写）
+func GRPCCodeFromName(name string) (int, bool) {
+	for code, n := range grpcCodeNames {
+		if strings.EqualFold(n, name) {
+			return code, true
+		}
+	}
+	return 0, false
+}
+
+// StatusCodeLabel 状态码展示文本：HTTP 状态码（>=100）显示数字，gRPC 状态码（<100）显示名称
+func StatusCodeLabel(code int) string {
+	if code < 100 {
+		return GRPCCodeName(code)
+	}
+	return strconv.Itoa(code)
+}
+
+// GRPCMetadataKey 请求 Metadata 中 gRPC 调用 metadata（map[string]string，已完成变量替换）的键
+const GRPCMetadataKey = "grpc_metadata"
+
 // Request 通用请求结构
 type Request struct {
 	URL      string            `json:"url" yaml:"url"`
@@ -36,6 +78,7 @@ type Request struct {
 	Headers  map[string]string `json:"headers" yaml:"headers"`
 	Body     string            `json:"body" yaml:"body"`
 	Metadata map[string]any    `json:"metadata" yaml:"metadata"` // 协议特定数据
+	Deadline time.Time         `json:"-" yaml:"-"`               // 长连接请求（WebSocket 会话/gRPC 流/SSE）的最迟结束时间，零值表示不限制
 }
 
 // Response 通用响应结构
@@ -49,8 +92,75 @@ type Response struct {
 	RequestBody    string               `json:"request_body"`
 	RequestQuery   string               `json:"request_query"`
 	Duration       time.Duration        `json:"duration"`
+	Phases         *PhaseTimings        `json:"phases,omitempty"`    // 各阶段耗时（仅HTTP）
+	Stream         *StreamStats         `json:"stream,omitempty"`    // 流式调用统计（仅gRPC流）
+	WebSocket      *WebSocketStats      `json:"websocket,omitempty"` // 会话统计（仅WebSocket会话模式）
+	MQTT           *MQTTStats           `json:"mqtt,omitempty"`      // 连接和收发统计（仅MQTT）
+	Events         []SSEEvent           `json:"events,omitempty"`    // 接收的事件（仅SSE，提取器逐个事件提取）
 	Error          error                `json:"error,omitempty"`
 	Verifications  []VerificationResult `json:"verifications,omitempty"`
+	Protocol       ProtocolType         `json:"protocol,omitempty"` // 响应所属协议（gRPC 的状态码 0 表示 OK）
+}
+
+// IsSuccessStatus 状态码是否表示成功（HTTP 2xx，gRPC OK）
+func (r *Response) IsSuccessStatus() bool {
+	if r.Protocol == ProtocolGRPC {
+		return r.StatusCode == 0
+	}
+	return r.StatusCode >= 200 && r.StatusCode < 300
+}
+
+// PhaseTimings HTTP 请求各阶段耗时（通过 httptrace 采集，复用连接时 DNS/连接/TLS 为 0）
+type PhaseTimings struct {
+	DNS        time.Duration `json:"dns"`         // DNS 解析
+	Connect    time.Duration `json:"connect"`     // TCP 连接
+	TLS        time.Duration `json:"tls"`         // TLS 握手
+	TTFB       time.Duration `json:"ttfb"`        // 等待首字节（请求发送完成到收到首字节，即服务端处理时间）
+	Transfer   time.Duration `json:"transfer"`    // 响应传输（首字节到读取完响应体）
+	ConnReused bool          `json:"conn_reused"` // 是否复用了连接
+}
+
+// StreamStats 单个流的统计（gRPC 客户端流/服务端流/双向流）
+type StreamStats struct {
+	Kind             string          `json:"kind"`                        // client/server/bidi
+	Sent             int             `json:"sent"`                        // 发送消息数
+	Received         int             `json:"received"`                    // 接收消息数
+	FirstMessage     time.Duration   `json:"first_message"`               // 建立流到收到首条消息的耗时
+	MessageLatencies []time.Duration `json:"message_latencies,omitempty"` // 单条消息延迟（发送到收到对应响应：按顺序关联，客户端流距最后一条发送）
+	InterArrivals    []time.Duration `json:"inter_arrivals,omitempty"`    // 相邻接收消息的间隔（首条距建立流）
+	Code             string          `json:"code"`                        // 流结束时的 gRPC 状态码（SSE 为 OK/HTTP_<状态码>/ERROR）
+	Reconnects       int             `json:"reconnects,omitempty"`        // 重连次数（仅SSE）
+}
+
+// SSEEvent Server-Sent Events 事件
+type SSEEvent struct {
+	ID    string `json:"id,omitempty"`    // 事件ID（id 字段）
+	Event string `json:"event,omitempty"` // 事件类型（event 字段，未设置时为 message）
+	Data  string `json:"data"`            // 事件数据（多行 data 以换行连接）
+}
+
+// WebSocketStats 单个 WebSocket 会话的统计（会话模式）
+type WebSocketStats struct {
+	Connect     time.Duration   `json:"connect"`               // 建立连接（含握手）耗时
+	Lifetime    time.Duration   `json:"lifetime"`              // 连接保持时长
+	Sent        int             `json:"sent"`                  // 发送消息数
+	Received    int             `json:"received"`              // 接收消息数
+	Pings       int             `json:"pings"`                 // 发送的心跳数
+	RoundTrips  []time.Duration `json:"round_trips,omitempty"` // 按关联ID匹配的往返延迟
+	CloseReason string          `json:"close_reason"`          // 断开原因
+}
+
+// MQTTConnectAccepted MQTT 建立连接成功的结果（MQTTStats.ConnectCode）
+const MQTTConnectAccepted = "accepted"
+
+// MQTTStats 单次 MQTT 请求的统计（接收数据为上次请求以来订阅收到的消息）
+type MQTTStats struct {
+	Connect     time.Duration   `json:"connect,omitempty"`      // 本次请求建立连接的耗时（复用连接时为0）
+	ConnectCode string          `json:"connect_code,omitempty"` // 本次请求建立连接的结果（accepted 或拒绝原因），复用连接时为空
+	Published   int             `json:"published"`              // 发布消息数
+	Received    int             `json:"received"`               // 接收消息数
+	Retained    int             `json:"retained"`               // 接收的保留消息数
+	Latencies   []time.Duration `json:"latencies,omitempty"`    // 端到端延迟（按消息中嵌入的发布时间戳计算）
 }
 
 // Client 协议客户端接口
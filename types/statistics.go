@@ -18,20 +18,26 @@
 
 // RequestResult 请求结果（用于统计和存储）
 type RequestResult struct {
-	ID         string        `json:"id"`                    // 唯一ID（Snowflake生成）
-	NodeID     string        `json:"node_id,omitempty"`    // 节点ID（分布式模式下标识数据来源，单机模式为"local"）
-	TaskID     string        `json:"task_id,omitempty"`    // 任务ID（分布式模式由Master分配，单机模式生成唯一ID）
-	Success    bool          `json:"success"`               // 是否成功
-	StatusCode int           `json:"status_code"`           // HTTP 状态码
-	Duration   time.Duration `json:"duration"`              // 请求耗时
-	Size       float64       `json:"size"`                  // 响应大小
-	Error      error         `json:"-"`                     // 错误信息（不序列化）
-	ErrorMsg   string        `json:"error,omitempty"`       // 错误消息（用于存储和序列化）
-	Timestamp  time.Time     `json:"timestamp"`             // 时间戳
-	Skipped    bool          `json:"skipped"`               // 是否被跳过（因依赖失败）
-	SkipReason string        `json:"skip_reason,omitempty"` // 跳过原因（记录具体哪个依赖API失败）
-	GroupID    uint64        `json:"group_id"`              // 分组ID（同一个worker的依赖链共享同一个GroupID）
-	APIName    string        `json:"api_name,omitempty"`    // API名称（如 create_ticket, send_message）
+	ID         string          `json:"id"`                    // 唯一ID（Snowflake生成）
+	NodeID     string          `json:"node_id,omitempty"`     // 节点ID（分布式模式下标识数据来源，单机模式为"local"）
+	TaskID     string          `json:"task_id,omitempty"`     // 任务ID（分布式模式由Master分配，单机模式生成唯一ID）
+	Success    bool            `json:"success"`               // 是否成功
+	StatusCode int             `json:"status_code"`           // HTTP 状态码
+	Duration   time.Duration   `json:"duration"`              // 请求耗时
+	Phases     *PhaseTimings   `json:"phases,omitempty"`      // 各阶段耗时（仅HTTP）
+	Stream     *StreamStats    `json:"stream,omitempty"`      // 流式调用统计（仅gRPC流）
+	WebSocket  *WebSocketStats `json:"websocket,omitempty"`   // 会话统计（仅WebSocket会话模式）
+	MQTT       *MQTTStats      `json:"mqtt,omitempty"`        // 连接和收发统计（仅MQTT）
+	Protocol   ProtocolType    `json:"protocol,omitempty"`    // 请求所属协议
+	Size       float64         `json:"size"`                  // 响应大小
+	Error      error           `json:"-"`                     // 错误信息（不序列化）
+	ErrorMsg   string          `json:"error,omitempty"`       // 错误消息（用于存储和序列化）
+	Timestamp  time.Time       `json:"timestamp"`             // 时间戳
+	Skipped    bool            `json:"skipped"`               // 是否被跳过（因依赖失败）
+	SkipReason string          `json:"skip_reason,omitempty"` // 跳过原因（记录具体哪个依赖API失败）
+	GroupID    uint64          `json:"group_id"`              // 分组ID（同一个worker的依赖链共享同一个GroupID）
+	APIName    string          `json:"api_name,omitempty"`    // API名称（如 create_ticket, send_message）
+	Scenario   string          `json:"scenario,omitempty"`    // 所属场景名称（多场景模式）
 
 	// 请求详情
 	URL     string            `json:"url,omitempty"`     // 请求URL
@@ -51,6 +57,19 @@ type RequestResult struct {
 	ExtractedVars map[string]string `json:"extracted_vars,omitempty"` // 提取的变量
 }
 
+// HasStatusCode 是否有有效状态码（gRPC 的 0 表示 OK，其他协议的 0 表示未收到响应）
+func (r *RequestResult) HasStatusCode() bool {
+	return !r.Skipped && (r.StatusCode > 0 || r.Protocol == ProtocolGRPC)
+}
+
+// APIStatsKey 接口分组统计的名称，多场景模式下为 场景/接口，避免不同场景的同名接口合并统计
+func APIStatsKey(scenario, api string) string {
+	if scenario == "" {
+		return api
+	}
+	return scenario + "/" + api
+}
+
 // VerificationResult 验证结果
 type VerificationResult struct {
 	Type        VerifyType `json:"type"`                  // 验证类型：STATUS_CODE, JSONPATH, CONTAINS等
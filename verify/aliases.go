@@ -42,7 +42,13 @@
 	VerifyTypeSuffix       = types.VerifyTypeSuffix
 	VerifyTypeEmpty        = types.VerifyTypeEmpty
 	VerifyTypeNotEmpty     = types.VerifyTypeNotEmpty
+
+	ProtocolGRPC = types.ProtocolGRPC
 )
 
 // 函数别名
-var NewVerificationResultFromCompare = types.NewVerificationResultFromCompare
+var (
+	NewVerificationResultFromCompare = types.NewVerificationResultFromCompare
+	GRPCCodeName                     = types.GRPCCodeName
+	GRPCCodeFromName                 = types.GRPCCodeFromName
+)
@@ -102,8 +102,66 @@ func (v *HTTPVerifier) Verify(resp *Response) (bool, error) {
 	}
 }
 
+// statusFailure 记录状态码不成功（HTTP 非 2xx、gRPC 非 OK）导致的验证失败
+func (v *HTTPVerifier) statusFailure(resp *Response) (bool, error) {
+	result := VerificationResult{
+		Type:    v.config.Type,
+		Success: false,
+		Message: fmt.Sprintf("HTTP请求失败，状态码: %d", resp.StatusCode),
+		Expect:  "2xx",
+		Actual:  fmt.Sprintf("%d", resp.StatusCode),
+	}
+	if resp.Protocol == ProtocolGRPC {
+		result.Message = fmt.Sprintf("gRPC请求失败，状态码: %s", GRPCCodeName(resp.StatusCode))
+		result.Expect = GRPCCodeName(0)
+		result.Actual = GRPCCodeName(resp.StatusCode)
+	}
+	resp.Verifications = append(resp.Verifications, result)
+	return false, fmt.Errorf("%s", result.Message)
+}
+
+// verifyGRPCStatusCode 验证 gRPC 状态码，期望值支持名称（OK、UNAVAILABLE…）或数值
+// 未配置或为 HTTP 默认的 200 时期望 OK
+func (v *HTTPVerifier) verifyGRPCStatusCode(resp *Response) (bool, error) {
+	expectedCode := 0
+	switch exp := v.config.Expect.(type) {
+	case int:
+		expectedCode = exp
+	case float64:
+		expectedCode = int(exp)
+	case string:
+		exp = strings.TrimSpace(exp)
+		if code, ok := GRPCCodeFromName(exp); ok {
+			expectedCode = code
+		} else if parsed, err := strconv.Atoi(exp); err == nil {
+			expectedCode = parsed
+		}
+	}
+	if expectedCode == 200 {
+		expectedCode = 0
+	}
+
+	operator := v.config.Operator
+	if operator == "" {
+		operator = validator.OpEqual
+	}
+	compareResult := validator.ValidateStatusCode(resp.StatusCode, expectedCode, operator)
+	result := NewVerificationResultFromCompare(v.config.Type, compareResult)
+	result.Expect = GRPCCodeName(expectedCode)
+	result.Actual = GRPCCodeName(resp.StatusCode)
+	resp.Verifications = append(resp.Verifications, result)
+
+	if !result.Success {
+		return false, fmt.Errorf("gRPC状态码验证失败: 期望 %s, 实际 %s", result.Expect, result.Actual)
+	}
+	return true, nil
+}
+
 // verifyStatusCode 验证状态码 - 使用 validator.ValidateStatusCode
 func (v *HTTPVerifier) verifyStatusCode(resp *Response) (bool, error) {
+	if resp.Protocol == ProtocolGRPC {
+		return v.verifyGRPCStatusCode(resp)
+	}
 	expectedCode := 200 // 默认期望200
 	operator := v.config.Operator
 	if operator == "" {
@@ -141,16 +199,8 @@ func (v *HTTPVerifier) verifyStatusCode(resp *Response) (bool, error) {
 // verifyJSONPath 验证JSON路径 - 使用 validator.ValidateJSONPath
 func (v *HTTPVerifier) verifyJSONPath(resp *Response) (bool, error) {
 	// 检查状态码
-	if resp.StatusCode < 200 || resp.StatusCode >= 300 {
-		result := VerificationResult{
-			Type:    v.config.Type,
-			Success: false,
-			Message: fmt.Sprintf("HTTP请求失败，状态码: %d", resp.StatusCode),
-			Expect:  "2xx",
-			Actual:  fmt.Sprintf("%d", resp.StatusCode),
-		}
-		resp.Verifications = append(resp.Verifications, result)
-		return false, fmt.Errorf("HTTP请求失败: %s", result.Message)
+	if !resp.IsSuccessStatus() {
+		return v.statusFailure(resp)
 	}
 
 	// 确定操作符
@@ -198,16 +248,8 @@ func (v *HTTPVerifier) verifyJSONPath(resp *Response) (bool, error) {
 // verifyContains 验证包含字符串 - 使用 validator.ValidateContains
 func (v *HTTPVerifier) verifyContains(resp *Response) (bool, error) {
 	// 检查状态码是否为成功状态
-	if resp.StatusCode < 200 || resp.StatusCode >= 300 {
-		result := VerificationResult{
-			Type:    v.config.Type,
-			Success: false,
-			Message: fmt.Sprintf("HTTP请求失败，状态码: %d", resp.StatusCode),
-			Expect:  "2xx",
-			Actual:  fmt.Sprintf("%d", resp.StatusCode),
-		}
-		resp.Verifications = append(resp.Verifications, result)
-		return false, fmt.Errorf("HTTP请求失败: %s", result.Message)
+	if !resp.IsSuccessStatus() {
+		return v.statusFailure(resp)
 	}
 
 	containsStr, ok := v.config.Expect.(string)
@@ -238,16 +280,8 @@ func (v *HTTPVerifier) verifyContains(resp *Response) (bool, error) {
 // verifyRegex 验证正则表达式 - 使用 validator.ValidateRegex
 func (v *HTTPVerifier) verifyRegex(resp *Response) (bool, error) {
 	// 检查状态码是否为成功状态
-	if resp.StatusCode < 200 || resp.StatusCode >= 300 {
-		result := VerificationResult{
-			Type:    v.config.Type,
-			Success: false,
-			Message: fmt.Sprintf("HTTP请求失败，状态码: %d", resp.StatusCode),
-			Expect:  "2xx",
-			Actual:  fmt.Sprintf("%d", resp.StatusCode),
-		}
-		resp.Verifications = append(resp.Verifications, result)
-		return false, fmt.Errorf("HTTP请求失败: %s", result.Message)
+	if !resp.IsSuccessStatus() {
+		return v.statusFailure(resp)
 	}
 
 	pattern, ok := v.config.Expect.(string)
@@ -279,16 +313,8 @@ func (v *HTTPVerifier) verifyRegex(resp *Response) (bool, error) {
 
 // verifyJSONSchema 验证 JSON Schema
 func (v *HTTPVerifier) verifyJSONSchema(resp *Response) (bool, error) {
-	if resp.StatusCode < 200 || resp.StatusCode >= 300 {
-		result := VerificationResult{
-			Type:    v.config.Type,
-			Success: false,
-			Message: fmt.Sprintf("HTTP请求失败，状态码: %d", resp.StatusCode),
-			Expect:  "2xx",
-			Actual:  fmt.Sprintf("%d", resp.StatusCode),
-		}
-		resp.Verifications = append(resp.Verifications, result)
-		return false, fmt.Errorf("HTTP请求失败: %s", result.Message)
+	if !resp.IsSuccessStatus() {
+		return v.statusFailure(resp)
 	}
 
 	schema, ok := v.config.Expect.(map[string]interface{})
@@ -318,16 +344,8 @@ func (v *HTTPVerifier) verifyJSONSchema(resp *Response) (bool, error) {
 
 // verifyJSONValid 验证 JSON 格式是否有效
 func (v *HTTPVerifier) verifyJSONValid(resp *Response) (bool, error) {
-	if resp.StatusCode < 200 || resp.StatusCode >= 300 {
-		result := VerificationResult{
-			Type:    v.config.Type,
-			Success: false,
-			Message: fmt.Sprintf("HTTP请求失败，状态码: %d", resp.StatusCode),
-			Expect:  "2xx",
-			Actual:  fmt.Sprintf("%d", resp.StatusCode),
-		}
-		resp.Verifications = append(resp.Verifications, result)
-		return false, fmt.Errorf("HTTP请求失败: %s", result.Message)
+	if !resp.IsSuccessStatus() {
+		return v.statusFailure(resp)
 	}
 
 	// 使用 validator.ValidateJSON - 它返回 error，需要转换为 CompareResult
@@ -357,16 +375,8 @@ func (v *HTTPVerifier) verifyJSONValid(resp *Response) (bool, error) {
 
 // verifyHeader 验证 HTTP 响应头
 func (v *HTTPVerifier) verifyHeader(resp *Response) (bool, error) {
-	if resp.StatusCode < 200 || resp.StatusCode >= 300 {
-		result := VerificationResult{
-			Type:    v.config.Type,
-			Success: false,
-			Message: fmt.Sprintf("HTTP请求失败，状态码: %d", resp.StatusCode),
-			Expect:  "2xx",
-			Actual:  fmt.Sprintf("%d", resp.StatusCode),
-		}
-		resp.Verifications = append(resp.Verifications, result)
-		return false, fmt.Errorf("HTTP请求失败: %s", result.Message)
+	if !resp.IsSuccessStatus() {
+		return v.statusFailure(resp)
 	}
 
 	// 获取要验证的 header 名称（从 JSONPath 字段或其他配置）
@@ -490,16 +500,8 @@ func (v *HTTPVerifier) verifyResponseSize(resp *Response) (bool, error) {
 
 // verifyEmail 验证 Email 格式
 func (v *HTTPVerifier) verifyEmail(resp *Response) (bool, error) {
-	if resp.StatusCode < 200 || resp.StatusCode >= 300 {
-		result := VerificationResult{
-			Type:    v.config.Type,
-			Success: false,
-			Message: fmt.Sprintf("HTTP请求失败，状态码: %d", resp.StatusCode),
-			Expect:  "2xx",
-			Actual:  fmt.Sprintf("%d", resp.StatusCode),
-		}
-		resp.Verifications = append(resp.Verifications, result)
-		return false, fmt.Errorf("HTTP请求失败: %s", result.Message)
+	if !resp.IsSuccessStatus() {
+		return v.statusFailure(resp)
 	}
 
 	// 可以从 JSONPath 提取值，或直接验证 Body
@@ -531,16 +533,8 @@ func (v *HTTPVerifier) verifyEmail(resp *Response) (bool, error) {
 
 // verifyIP 验证 IP 地址格式
 func (v *HTTPVerifier) verifyIP(resp *Response) (bool, error) {
-	if resp.StatusCode < 200 || resp.StatusCode >= 300 {
-		result := VerificationResult{
-			Type:    v.config.Type,
-			Success: false,
-			Message: fmt.Sprintf("HTTP请求失败，状态码: %d", resp.StatusCode),
-			Expect:  "2xx",
-			Actual:  fmt.Sprintf("%d", resp.StatusCode),
-		}
-		resp.Verifications = append(resp.Verifications, result)
-		return false, fmt.Errorf("HTTP请求失败: %s", result.Message)
+	if !resp.IsSuccessStatus() {
+		return v.statusFailure(resp)
 	}
 
 	var valueToCheck string
@@ -570,16 +564,8 @@ func (v *HTTPVerifier) verifyIP(resp *Response) (bool, error) {
 
 // verifyURL 验证 URL 格式
 func (v *HTTPVerifier) verifyURL(resp *Response) (bool, error) {
-	if resp.StatusCode < 200 || resp.StatusCode >= 300 {
-		result := VerificationResult{
-			Type:    v.config.Type,
-			Success: false,
-			Message: fmt.Sprintf("HTTP请求失败，状态码: %d", resp.StatusCode),
-			Expect:  "2xx",
-			Actual:  fmt.Sprintf("%d", resp.StatusCode),
-		}
-		resp.Verifications = append(resp.Verifications, result)
-		return false, fmt.Errorf("HTTP请求失败: %s", result.Message)
+	if !resp.IsSuccessStatus() {
+		return v.statusFailure(resp)
 	}
 
 	var valueToCheck string
@@ -609,16 +595,8 @@ func (v *HTTPVerifier) verifyURL(resp *Response) (bool, error) {
 
 // verifyUUID 验证 UUID 格式
 func (v *HTTPVerifier) verifyUUID(resp *Response) (bool, error) {
-	if resp.StatusCode < 200 || resp.StatusCode >= 300 {
-		result := VerificationResult{
-			Type:    v.config.Type,
-			Success: false,
-			Message: fmt.Sprintf("HTTP请求失败，状态码: %d", resp.StatusCode),
-			Expect:  "2xx",
-			Actual:  fmt.Sprintf("%d", resp.StatusCode),
-		}
-		resp.Verifications = append(resp.Verifications, result)
-		return false, fmt.Errorf("HTTP请求失败: %s", result.Message)
+	if !resp.IsSuccessStatus() {
+		return v.statusFailure(resp)
 	}
 
 	var valueToCheck string
@@ -648,16 +626,8 @@ func (v *HTTPVerifier) verifyUUID(resp *Response) (bool, error) {
 
 // verifyBase64 验证 Base64 编码
 func (v *HTTPVerifier) verifyBase64(resp *Response) (bool, error) {
-	if resp.StatusCode < 200 || resp.StatusCode >= 300 {
-		result := VerificationResult{
-			Type:    v.config.Type,
-			Success: false,
-			Message: fmt.Sprintf("HTTP请求失败，状态码: %d", resp.StatusCode),
-			Expect:  "2xx",
-			Actual:  fmt.Sprintf("%d", resp.StatusCode),
-		}
-		resp.Verifications = append(resp.Verifications, result)
-		return false, fmt.Errorf("HTTP请求失败: %s", result.Message)
+	if !resp.IsSuccessStatus() {
+		return v.statusFailure(resp)
 	}
 
 	var valueToCheck string
@@ -689,16 +659,8 @@ func (v *HTTPVerifier) verifyBase64(resp *Response) (bool, error) {
 
 // verifyLength 验证字符串长度
 func (v *HTTPVerifier) verifyLength(resp *Response) (bool, error) {
-	if resp.StatusCode < 200 || resp.StatusCode >= 300 {
-		result := VerificationResult{
-			Type:    v.config.Type,
-			Success: false,
-			Message: fmt.Sprintf("HTTP请求失败，状态码: %d", resp.StatusCode),
-			Expect:  "2xx",
-			Actual:  fmt.Sprintf("%d", resp.StatusCode),
-		}
-		resp.Verifications = append(resp.Verifications, result)
-		return false, fmt.Errorf("HTTP请求失败: %s", result.Message)
+	if !resp.IsSuccessStatus() {
+		return v.statusFailure(resp)
 	}
 
 	expectedLen, ok := v.config.Expect.(float64)
@@ -751,16 +713,8 @@ func (v *HTTPVerifier) verifyLength(resp *Response) (bool, error) {
 
 // verifyPrefix 验证字符串前缀
 func (v *HTTPVerifier) verifyPrefix(resp *Response) (bool, error) {
-	if resp.StatusCode < 200 || resp.StatusCode >= 300 {
-		result := VerificationResult{
-			Type:    v.config.Type,
-			Success: false,
-			Message: fmt.Sprintf("HTTP请求失败，状态码: %d", resp.StatusCode),
-			Expect:  "2xx",
-			Actual:  fmt.Sprintf("%d", resp.StatusCode),
-		}
-		resp.Verifications = append(resp.Verifications, result)
-		return false, fmt.Errorf("HTTP请求失败: %s", result.Message)
+	if !resp.IsSuccessStatus() {
+		return v.statusFailure(resp)
 	}
 
 	prefix, ok := v.config.Expect.(string)
@@ -803,16 +757,8 @@ func (v *HTTPVerifier) verifyPrefix(resp *Response) (bool, error) {
 
 // verifySuffix 验证字符串后缀
 func (v *HTTPVerifier) verifySuffix(resp *Response) (bool, error) {
-	if resp.StatusCode < 200 || resp.StatusCode >= 300 {
-		result := VerificationResult{
-			Type:    v.config.Type,
-			Success: false,
-			Message: fmt.Sprintf("HTTP请求失败，状态码: %d", resp.StatusCode),
-			Expect:  "2xx",
-			Actual:  fmt.Sprintf("%d", resp.StatusCode),
-		}
-		resp.Verifications = append(resp.Verifications, result)
-		return false, fmt.Errorf("HTTP请求失败: %s", result.Message)
+	if !resp.IsSuccessStatus() {
+		return v.statusFailure(resp)
 	}
 
 	suffix, ok := v.config.Expect.(string)
@@ -855,16 +801,8 @@ func (v *HTTPVerifier) verifySuffix(resp *Response) (bool, error) {
 
 // verifyEmpty 验证字符串为空
 func (v *HTTPVerifier) verifyEmpty(resp *Response) (bool, error) {
-	if resp.StatusCode < 200 || resp.StatusCode >= 300 {
-		result := VerificationResult{
-			Type:    v.config.Type,
-			Success: false,
-			Message: fmt.Sprintf("HTTP请求失败，状态码: %d", resp.StatusCode),
-			Expect:  "2xx",
-			Actual:  fmt.Sprintf("%d", resp.StatusCode),
-		}
-		resp.Verifications = append(resp.Verifications, result)
-		return false, fmt.Errorf("HTTP请求失败: %s", result.Message)
+	if !resp.IsSuccessStatus() {
+		return v.statusFailure(resp)
 	}
 
 	var valueToCheck string
@@ -894,16 +832,8 @@ func (v *HTTPVerifier) verifyEmpty(resp *Response) (bool, error) {
 
 // verifyNotEmpty 验证字符串非空
 func (v *HTTPVerifier) verifyNotEmpty(resp *Response) (bool, error) {
-	if resp.StatusCode < 200 || resp.StatusCode >= 300 {
-		result := VerificationResult{
-			Type:    v.config.Type,
-			Success: false,
-			Message: fmt.Sprintf("HTTP请求失败，状态码: %d", resp.StatusCode),
-			Expect:  "2xx",
-			Actual:  fmt.Sprintf("%d", resp.StatusCode),
-		}
-		resp.Verifications = append(resp.Verifications, result)
-		return false, fmt.Errorf("HTTP请求失败: %s", result.Message)
+	if !resp.IsSuccessStatus() {
+		return v.statusFailure(resp)
 	}
 
 	var valueToCheck string